@@ -1,9 +1,11 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
@@ -18,6 +20,8 @@ import (
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/filewatcher"
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/logger"
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/systeminfo"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/upgrade"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/utils"
 	"github.com/joho/godotenv"
 )
 
@@ -44,6 +48,40 @@ func main() {
 		logger.Logger.Info("PIPELINE_NAME environment variable is not set. Using default value: empty string.")
 	}
 
+	// PIPELINE_TEMPLATE: Optional, builds the PIPELINE_NAME pipeline from a backend template
+	constants.PIPELINE_TEMPLATE = os.Getenv("PIPELINE_TEMPLATE")
+	if params := os.Getenv("PIPELINE_TEMPLATE_PARAMS"); params != "" {
+		if err := json.Unmarshal([]byte(params), &constants.PIPELINE_TEMPLATE_PARAMS); err != nil {
+			logger.Logger.Sugar().Fatalf("PIPELINE_TEMPLATE_PARAMS must be a JSON object: %v", err)
+		}
+	}
+
+	// AGENT_LABELS: Optional, matched against pipeline label selectors when PIPELINE_NAME is empty
+	if labels := os.Getenv("AGENT_LABELS"); labels != "" {
+		parsed, err := utils.ParseLabels(labels)
+		if err != nil {
+			logger.Logger.Sugar().Fatalf("AGENT_LABELS must be a list of key=value pairs: %v", err)
+		}
+		constants.AGENT_LABELS = parsed
+	}
+
+	// AGENT_MODE: Optional, "supervisor" runs COLLECTOR_BINARY as a child process
+	if mode := os.Getenv("AGENT_MODE"); mode != "" {
+		constants.AGENT_MODE = mode
+	}
+	if constants.AGENT_MODE == "supervisor" {
+		constants.COLLECTOR_BINARY = os.Getenv("COLLECTOR_BINARY")
+		if constants.COLLECTOR_BINARY == "" {
+			logger.Logger.Fatal("COLLECTOR_BINARY environment variable must be set in supervisor mode. Exiting...")
+		}
+		constants.COLLECTOR_MEMORY_LIMIT_MIB = intEnv("COLLECTOR_MEMORY_LIMIT_MIB")
+		constants.COLLECTOR_CPU_LIMIT = intEnv("COLLECTOR_CPU_LIMIT")
+	}
+
+	// UPGRADE_PUBLIC_KEY: Optional, enables upgrades signed with this Ed25519 key
+	constants.UPGRADE_PUBLIC_KEY = os.Getenv("UPGRADE_PUBLIC_KEY")
+	upgrader, pendingUpgrade := newUpgrader()
+
 	constants.STARTED_BY = os.Getenv("STARTED_BY")
 	if constants.STARTED_BY == "" {
 		logger.Logger.Info("STARTED_BY environment variable is not set. Using default value: empty string.")
@@ -66,6 +104,7 @@ func main() {
 	}
 	logger.Logger.Info("Agent started successfully")
 
+	filewatcher.KeepBootConfig(constants.AGENT_CONFIG_PATH)
 	go filewatcher.WatchFile(constants.AGENT_CONFIG_PATH, adapter)
 
 	version, err := adapter.GetVersion()
@@ -75,6 +114,15 @@ func main() {
 		constants.AGENT_VERSION = version
 	}
 
+	// Agents that don't report their components can run any pipeline, so a
+	// failure here only loses the backend's check
+	components, err := adapter.GetComponents()
+	if err != nil {
+		logger.Logger.Sugar().Errorf("Error while listing collector components: %v", err)
+	} else {
+		constants.AGENT_COMPONENTS = components
+	}
+
 	// Call Backend server which will be informed about agent being started
 	wg.Add(1)
 	go func() {
@@ -95,7 +143,18 @@ func main() {
 		}
 	}()
 
-	operator_service := *operators.NewOperatorService(adapter)
+	// Confirm an upgrade that restarted the agent once the collector is
+	// healthy, or roll it back
+	if pendingUpgrade != nil {
+		go func() {
+			if upgrader.Finish(pendingUpgrade, adapter.Healthy) {
+				adapter.StopAgent()
+				logger.Logger.Sugar().Fatalf("Failed to restart into previous version: %v", upgrader.Restart())
+			}
+		}()
+	}
+
+	operator_service := *operators.NewOperatorService(adapter, upgrader)
 
 	handler := api.NewRouter(&operator_service)
 
@@ -129,3 +188,62 @@ func main() {
 	adapter.GracefulShutdown()
 
 }
+
+// intEnv parses an optional non-negative integer environment variable.
+func intEnv(name string) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		logger.Logger.Sugar().Fatalf("%s must be a non-negative integer, got %q", name, value)
+	}
+	return n
+}
+
+// newUpgrader returns the upgrader of the collector binary, nil when upgrades
+// are disabled, and the upgrade waiting for this start of the agent. An
+// upgraded binary that exited before passing its health checks is rolled
+// back right away.
+func newUpgrader() (*upgrade.Upgrader, *upgrade.State) {
+	if constants.UPGRADE_PUBLIC_KEY == "" {
+		logger.Logger.Info("UPGRADE_PUBLIC_KEY environment variable is not set. Upgrades are disabled.")
+		return nil, nil
+	}
+	publicKey, err := upgrade.ParsePublicKey(constants.UPGRADE_PUBLIC_KEY)
+	if err != nil {
+		logger.Logger.Sugar().Fatalf("UPGRADE_PUBLIC_KEY must be a base64 Ed25519 public key: %v", err)
+	}
+
+	// In supervisor mode the collector is a separate binary, otherwise it's
+	// built into the agent
+	binary := constants.COLLECTOR_BINARY
+	if constants.AGENT_MODE != "supervisor" {
+		if binary, err = os.Executable(); err != nil {
+			logger.Logger.Sugar().Fatalf("Failed to locate agent binary: %v", err)
+		}
+	}
+
+	report := func(upgradeId int, report upgrade.Report) error {
+		return client.ReportUpgrade(nil, upgradeId, report)
+	}
+	upgrader, err := upgrade.New(binary, publicKey, report, logger.Logger)
+	if err != nil {
+		logger.Logger.Sugar().Fatalf("Failed to set up upgrades: %v", err)
+	}
+
+	pending, err := upgrader.BeginBoot()
+	if err != nil {
+		logger.Logger.Sugar().Errorf("Failed to read pending upgrade: %v", err)
+		return upgrader, nil
+	}
+	if pending != nil && pending.Crashed() {
+		if upgrader.Finish(pending, nil) {
+			logger.Logger.Sugar().Fatalf("Failed to restart into previous version: %v", upgrader.Restart())
+		}
+		// Finish reported that the rollback failed
+		return upgrader, nil
+	}
+	return upgrader, pending
+}
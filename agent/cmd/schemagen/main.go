@@ -0,0 +1,41 @@
+// Command schemagen regenerates the backend's component JSON Schemas, starter
+// UI schemas and schema manifest from the components built into the agent.
+// Run it from the agent directory after registering a component's factory:
+//
+//	go run ./cmd/schemagen
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/adapters"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/schemagen"
+)
+
+func main() {
+	assetsDir := flag.String("assets", "../backend/internal/assets", "backend assets directory holding schemas/ and ui_schemas/")
+	manifestPath := flag.String("manifest", "../backend/internal/db/schema_manifest.go", "backend schema manifest to write")
+	flag.Parse()
+
+	factories, err := adapters.Factories()
+	if err != nil {
+		log.Fatalf("Failed to load component factories: %v", err)
+	}
+	entries := schemagen.Entries(factories)
+
+	if err := schemagen.Write(entries, *assetsDir); err != nil {
+		log.Fatalf("Failed to write schemas: %v", err)
+	}
+
+	manifest, err := schemagen.Manifest(entries)
+	if err != nil {
+		log.Fatalf("Failed to render schema manifest: %v", err)
+	}
+	if err := os.WriteFile(*manifestPath, manifest, 0o644); err != nil {
+		log.Fatalf("Failed to write schema manifest: %v", err)
+	}
+
+	log.Printf("Generated schemas for %d components", len(entries))
+}
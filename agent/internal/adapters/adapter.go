@@ -3,6 +3,9 @@ package adapters
 import (
 	"fmt"
 	"sync"
+
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/constants"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/components"
 )
 
 // Adapter defines the interface for different telemetry collectors
@@ -13,11 +16,18 @@ type Adapter interface {
 	UpdateConfig() error
 	GracefulShutdown() error
 	GetVersion() (string, error)
+	// GetComponents lists the components built into the collector
+	GetComponents() ([]components.Component, error)
 	ValidateConfigInMemory(data *map[string]any) error
+	// Healthy returns why the collector isn't running its config, or nil
+	Healthy() error
 }
 
 func NewAdapter(wg *sync.WaitGroup, agentType string) (Adapter, error) {
 	if agentType == "otel" || agentType == "" {
+		if constants.AGENT_MODE == "supervisor" {
+			return NewSupervisedAdapter(wg), nil
+		}
 		return NewOTELAdapter(wg), nil
 	}
 	return nil, fmt.Errorf("unsupported agent type: %s", agentType)
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/adapters"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/constants"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,6 +29,18 @@ func TestNewAdapter_DefaultEmptyType(t *testing.T) {
 	assert.NotNil(t, adapter)
 }
 
+func TestNewAdapter_Supervisor(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	constants.AGENT_MODE = "supervisor"
+	defer func() { constants.AGENT_MODE = "embedded" }()
+
+	adapter, err := adapters.NewAdapter(wg, "otel")
+	assert.NoError(t, err)
+
+	_, ok := adapter.(*adapters.SupervisedAdapter)
+	assert.True(t, ok, "adapter should be of type SupervisedAdapter")
+}
+
 func TestNewAdapter_UnsupportedType(t *testing.T) {
 	wg := &sync.WaitGroup{}
 
@@ -2,28 +2,35 @@ package adapters
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
-	"os/signal"
 	"runtime/debug"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/core/shutdown"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/collectorstatus"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/components"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/configprovider"
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/logger"
 
 	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/otelcol"
 )
 
+// applyTimeout is how long the collector may take to start the components of
+// an updated config.
+const applyTimeout = 30 * time.Second
+
 type OTELAdapter struct {
-	svc    *otelcol.Collector
-	mu     sync.RWMutex
-	wg     *sync.WaitGroup
-	ctx    context.Context
-	cancel context.CancelFunc
+	svc      *otelcol.Collector
+	provider *configprovider.Provider // reloads svc's config
+	runErr   error                    // why the last collector run stopped
+	mu       sync.RWMutex
+	wg       *sync.WaitGroup
+	ctx      context.Context
+	cancel   context.CancelFunc
 }
 
 func NewOTELAdapter(wg *sync.WaitGroup) *OTELAdapter {
@@ -43,20 +50,26 @@ func (a *OTELAdapter) Initialize() error {
 		return fmt.Errorf("OTEL collector already initialized")
 	}
 
-	svc, err := getNewOTELCollector()
+	provider := configprovider.New()
+	svc, err := getNewOTELCollector(provider)
 	if err != nil {
 		return fmt.Errorf("failed to create OTEL collector: %w", err)
 	}
 
+	provider.Running = func() bool { return svc.GetState() == otelcol.StateRunning }
 	a.svc = svc
+	a.provider = provider
 	a.wg.Add(1)
+	a.runErr = nil
 	go func() {
 		defer a.wg.Done()
-		if err := a.svc.Run(a.ctx); err != nil {
+		err := a.svc.Run(a.ctx)
+		if err != nil {
 			logger.Logger.Sugar().Errorf("OTEL collector stopped with error: %v", err)
 		}
 		a.mu.Lock()
 		a.svc = nil
+		a.runErr = err
 		a.mu.Unlock()
 	}()
 	return nil
@@ -70,20 +83,26 @@ func (a *OTELAdapter) StartAgent() error {
 		return fmt.Errorf("OTEL collector instance already running")
 	}
 
-	svc, err := getNewOTELCollector()
+	provider := configprovider.New()
+	svc, err := getNewOTELCollector(provider)
 	if err != nil {
 		return fmt.Errorf("failed to start OTEL collector: %w", err)
 	}
 
+	provider.Running = func() bool { return svc.GetState() == otelcol.StateRunning }
 	a.svc = svc
+	a.provider = provider
 	a.wg.Add(1)
+	a.runErr = nil
 	go func() {
 		defer a.wg.Done()
-		if err := a.svc.Run(a.ctx); err != nil {
+		err := a.svc.Run(a.ctx)
+		if err != nil {
 			logger.Logger.Sugar().Errorf("OTEL collector stopped with error: %v", err)
 		}
 		a.mu.Lock()
 		a.svc = nil
+		a.runErr = err
 		a.mu.Unlock()
 	}()
 	return nil
@@ -97,48 +116,133 @@ func (a *OTELAdapter) StopAgent() error {
 		return fmt.Errorf("OTEL collector instance not currently running")
 	}
 
+	a.provider.Close()
 	a.svc.Shutdown()
 	a.svc = nil
 	logger.Logger.Info("OTEL collector stopped")
 	return nil
 }
 
+// UpdateConfig makes the collector load the config file and waits until the
+// config's components have started. The error tells why the config couldn't
+// be applied.
 func (a *OTELAdapter) UpdateConfig() error {
 	a.mu.RLock()
 	running := a.svc != nil
+	provider := a.provider
 	a.mu.RUnlock()
 
+	generation := collectorstatus.Generation()
 	if !running {
 		if err := a.StartAgent(); err != nil {
 			return fmt.Errorf("failed to start OTEL collector: %w", err)
 		}
+		if err := a.waitForCollector(generation); err != nil {
+			return err
+		}
 		logger.Logger.Info("OTEL collector started with updated config")
 		return nil
 	}
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGHUP)
-	defer signal.Stop(sigChan)
-
-	logger.Logger.Info("Sending SIGHUP signal to hot-reload otel collector for updating config...")
-	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
-		return fmt.Errorf("failed to send SIGHUP signal: %w", err)
+	logger.Logger.Info("Reloading otel collector for updating config...")
+	if err := a.reload(provider); err != nil {
+		return fmt.Errorf("failed to reload OTEL collector: %w", err)
 	}
 
-	select {
-	case sig := <-sigChan:
-		logger.Logger.Sugar().Infof("Received signal for updating config in otel collector: %s", sig)
-	case <-time.After(5 * time.Second):
-		logger.Logger.Warn("Timeout waiting for config update signal")
+	if err := a.waitForCollector(generation); err != nil {
+		return err
 	}
-
 	logger.Logger.Info("Config updated. OTEL collector restarted")
 	return nil
 }
 
+// reload asks the collector to load its config again, waiting for a
+// collector that is still loading its config to be ready for that.
+func (a *OTELAdapter) reload(provider *configprovider.Provider) error {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(applyTimeout)
+
+	for {
+		err := provider.Reload()
+		if !errors.Is(err, configprovider.ErrNotWatching) {
+			return err
+		}
+
+		a.mu.RLock()
+		running := a.svc != nil && a.provider == provider
+		a.mu.RUnlock()
+		if !running {
+			return fmt.Errorf("OTEL collector stopped")
+		}
+
+		select {
+		case <-ticker.C:
+		case <-timeout:
+			return err
+		}
+	}
+}
+
+// waitForCollector waits until the collector runs a config loaded after the
+// given status generation, or stops trying to. A running collector whose
+// components failed to start isn't healthy either.
+func (a *OTELAdapter) waitForCollector(generation uint64) error {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(applyTimeout)
+
+	for {
+		a.mu.RLock()
+		svc, runErr := a.svc, a.runErr
+		a.mu.RUnlock()
+
+		if svc == nil {
+			if runErr != nil {
+				return fmt.Errorf("OTEL collector failed to apply config: %w", runErr)
+			}
+			return fmt.Errorf("OTEL collector stopped while applying config")
+		}
+		if collectorstatus.Generation() > generation && svc.GetState() == otelcol.StateRunning {
+			return componentErrors(collectorstatus.Failed())
+		}
+
+		select {
+		case <-ticker.C:
+		case <-timeout:
+			return fmt.Errorf("timed out after %s waiting for OTEL collector to apply config", applyTimeout)
+		}
+	}
+}
+
+// Healthy checks that the collector runs and none of its components failed.
+func (a *OTELAdapter) Healthy() error {
+	a.mu.RLock()
+	svc, runErr := a.svc, a.runErr
+	a.mu.RUnlock()
+
+	if svc == nil {
+		if runErr != nil {
+			return fmt.Errorf("OTEL collector stopped: %w", runErr)
+		}
+		return fmt.Errorf("OTEL collector isn't running")
+	}
+	if state := svc.GetState(); state != otelcol.StateRunning {
+		return fmt.Errorf("OTEL collector is %s", state)
+	}
+	return componentErrors(collectorstatus.Failed())
+}
+
 func (a *OTELAdapter) GracefulShutdown() error {
 	logger.Logger.Info("Starting graceful shutdown...")
 
+	// The collector stops with the context, so no more reloads
+	a.mu.RLock()
+	if a.provider != nil {
+		a.provider.Close()
+	}
+	a.mu.RUnlock()
+
 	// Cancel context to stop running goroutines
 	a.cancel()
 
@@ -179,6 +283,15 @@ func (a *OTELAdapter) GetVersion() (string, error) {
 	return "", fmt.Errorf("failed to determine OpenTelemetry Collector version")
 }
 
+// GetComponents lists the components of the collector built into the agent.
+func (a *OTELAdapter) GetComponents() ([]components.Component, error) {
+	factories, err := componentsFactory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build component factories: %w", err)
+	}
+	return components.FromFactories(factories), nil
+}
+
 func (a *OTELAdapter) ValidateConfigInMemory(data *map[string]any) error {
 	if data == nil || *data == nil {
 		return fmt.Errorf("configuration data is nil")
@@ -207,3 +320,14 @@ func (a *OTELAdapter) ValidateConfigInMemory(data *map[string]any) error {
 	logger.Logger.Info("In-memory configuration validation successful")
 	return nil
 }
+
+func componentErrors(failed []collectorstatus.ComponentStatus) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	var errs []string
+	for _, status := range failed {
+		errs = append(errs, fmt.Sprintf("%s %s: %s", status.Kind, status.ID, status.Error))
+	}
+	return fmt.Errorf("OTEL collector components failed: %s", strings.Join(errs, "; "))
+}
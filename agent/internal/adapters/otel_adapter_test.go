@@ -2,13 +2,16 @@ package adapters_test
 
 import (
 	"os"
+	"path/filepath"
 	"sync"
-	"syscall"
 	"testing"
 	"time"
 
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/adapters"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/constants"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/collectorstatus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewOTELAdapter(t *testing.T) {
@@ -63,17 +66,73 @@ func TestStopAgentAfterStart(t *testing.T) {
 }
 
 func TestUpdateConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: 127.0.0.1:0
+exporters:
+  debug: {}
+service:
+  pipelines:
+    logs:
+      receivers: [otlp]
+      exporters: [debug]
+`), 0644))
+	setConfigPath(t, configPath)
+
 	wg := &sync.WaitGroup{}
 	adapter := adapters.NewOTELAdapter(wg)
 
-	go func() {
-		time.Sleep(1 * time.Second)
-		// simulate config reload signal to avoid blocking
-		_ = syscall.Kill(os.Getpid(), syscall.SIGHUP)
-	}()
-
 	err := adapter.UpdateConfig()
 	assert.NoError(t, err)
+	assert.NotEmpty(t, collectorstatus.Components())
+
+	// The running collector reloads in-process, without signals
+	generation := collectorstatus.Generation()
+	require.NoError(t, os.WriteFile(configPath, []byte(`receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: 127.0.0.1:0
+processors:
+  batch: {}
+exporters:
+  debug: {}
+service:
+  pipelines:
+    logs:
+      receivers: [otlp]
+      processors: [batch]
+      exporters: [debug]
+`), 0644))
+
+	err = adapter.UpdateConfig()
+	assert.NoError(t, err)
+	assert.Greater(t, collectorstatus.Generation(), generation)
+	assert.Contains(t, collectorstatus.Components(), collectorstatus.ComponentStatus{ID: "batch", Kind: "processor", Status: "ok"})
+
+	assert.NoError(t, adapter.StopAgent())
+}
+
+func TestUpdateConfigInvalidConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("receivers:\n  unknown: {}\n"), 0644))
+	setConfigPath(t, configPath)
+
+	wg := &sync.WaitGroup{}
+	adapter := adapters.NewOTELAdapter(wg)
+
+	err := adapter.UpdateConfig()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to apply config")
+}
+
+func setConfigPath(t *testing.T, path string) {
+	previous := constants.AGENT_CONFIG_PATH
+	constants.AGENT_CONFIG_PATH = path
+	t.Cleanup(func() { constants.AGENT_CONFIG_PATH = previous })
 }
 
 func TestGetVersion(t *testing.T) {
@@ -3,7 +3,7 @@ package adapters
 import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/confmap"
-	"go.opentelemetry.io/collector/confmap/provider/fileprovider"
+	"go.opentelemetry.io/collector/confmap/provider/envprovider"
 	"go.opentelemetry.io/collector/connector"
 	"go.opentelemetry.io/collector/exporter"
 	"go.opentelemetry.io/collector/exporter/debugexporter"
@@ -20,9 +20,15 @@ import (
 	"go.uber.org/zap/zapcore"
 
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/constants"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/collectorstatus"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/configprovider"
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/logger"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/clickhouseexporter"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/elasticsearchexporter"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fileexporter"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/kafkaexporter"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/loadbalancingexporter"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusexporter"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchmetricsreceiver"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
@@ -30,15 +36,34 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/filelogreceiver"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/googlecloudmonitoringreceiver"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/hostmetricsreceiver"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/jaegerreceiver"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/journaldreceiver"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/k8sclusterreceiver"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/kafkareceiver"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/kubeletstatsreceiver"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/prometheusreceiver"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/syslogreceiver"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckextension"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/attributesprocessor"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/cumulativetodeltaprocessor"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/filterprocessor"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/groupbyattrsprocessor"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/k8sattributesprocessor"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/probabilisticsamplerprocessor"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourcedetectionprocessor"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/resourceprocessor"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/tailsamplingprocessor"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/transformprocessor"
 )
 
+// Factories returns the factories of the components built into the agent.
+// cmd/schemagen generates the backend's component catalog from them.
+func Factories() (otelcol.Factories, error) {
+	return componentsFactory()
+}
+
 func componentsFactory() (otelcol.Factories, error) {
 	factories := otelcol.Factories{}
 
@@ -51,6 +76,13 @@ func componentsFactory() (otelcol.Factories, error) {
 		azuremonitorreceiver.NewFactory().Type():          azuremonitorreceiver.NewFactory(),
 		filelogreceiver.NewFactory().Type():               filelogreceiver.NewFactory(),
 		googlecloudmonitoringreceiver.NewFactory().Type(): googlecloudmonitoringreceiver.NewFactory(),
+		prometheusreceiver.NewFactory().Type():            prometheusreceiver.NewFactory(),
+		kafkareceiver.NewFactory().Type():                 kafkareceiver.NewFactory(),
+		syslogreceiver.NewFactory().Type():                syslogreceiver.NewFactory(),
+		journaldreceiver.NewFactory().Type():              journaldreceiver.NewFactory(),
+		k8sclusterreceiver.NewFactory().Type():            k8sclusterreceiver.NewFactory(),
+		kubeletstatsreceiver.NewFactory().Type():          kubeletstatsreceiver.NewFactory(),
+		jaegerreceiver.NewFactory().Type():                jaegerreceiver.NewFactory(),
 	}
 
 	// Processors
@@ -61,15 +93,25 @@ func componentsFactory() (otelcol.Factories, error) {
 		attributesprocessor.NewFactory().Type():           attributesprocessor.NewFactory(),
 		filterprocessor.NewFactory().Type():               filterprocessor.NewFactory(),
 		tailsamplingprocessor.NewFactory().Type():         tailsamplingprocessor.NewFactory(),
+		transformprocessor.NewFactory().Type():            transformprocessor.NewFactory(),
+		resourceprocessor.NewFactory().Type():             resourceprocessor.NewFactory(),
+		k8sattributesprocessor.NewFactory().Type():        k8sattributesprocessor.NewFactory(),
+		resourcedetectionprocessor.NewFactory().Type():    resourcedetectionprocessor.NewFactory(),
+		groupbyattrsprocessor.NewFactory().Type():         groupbyattrsprocessor.NewFactory(),
+		cumulativetodeltaprocessor.NewFactory().Type():    cumulativetodeltaprocessor.NewFactory(),
 	}
 
 	// Exporters
 	factories.Exporters = map[component.Type]exporter.Factory{
-		otlpexporter.NewFactory().Type():       otlpexporter.NewFactory(),
-		otlphttpexporter.NewFactory().Type():   otlphttpexporter.NewFactory(),
-		debugexporter.NewFactory().Type():      debugexporter.NewFactory(),
-		kafkaexporter.NewFactory().Type():      kafkaexporter.NewFactory(),
-		prometheusexporter.NewFactory().Type(): prometheusexporter.NewFactory(),
+		otlpexporter.NewFactory().Type():          otlpexporter.NewFactory(),
+		otlphttpexporter.NewFactory().Type():      otlphttpexporter.NewFactory(),
+		debugexporter.NewFactory().Type():         debugexporter.NewFactory(),
+		kafkaexporter.NewFactory().Type():         kafkaexporter.NewFactory(),
+		prometheusexporter.NewFactory().Type():    prometheusexporter.NewFactory(),
+		fileexporter.NewFactory().Type():          fileexporter.NewFactory(),
+		loadbalancingexporter.NewFactory().Type(): loadbalancingexporter.NewFactory(),
+		elasticsearchexporter.NewFactory().Type(): elasticsearchexporter.NewFactory(),
+		clickhouseexporter.NewFactory().Type():    clickhouseexporter.NewFactory(),
 	}
 
 	// Connectors (empty for now, but initialized)
@@ -78,17 +120,24 @@ func componentsFactory() (otelcol.Factories, error) {
 	// Extensions
 	factories.Extensions = map[component.Type]extension.Factory{
 		healthcheckextension.NewFactory().Type(): healthcheckextension.NewFactory(),
+		collectorstatus.NewFactory().Type():      collectorstatus.NewFactory(),
 	}
 
 	return factories, nil
 }
 
-func getNewOTELCollector() (*otelcol.Collector, error) {
-	fmp := fileprovider.NewFactory()
+// getNewOTELCollector creates a collector that reads the config file through
+// the given provider, which reloads it on request.
+func getNewOTELCollector(provider *configprovider.Provider) (*otelcol.Collector, error) {
+	// The env provider resolves ${env:...} references, which the backend uses
+	// for secrets it doesn't send in the clear
+	emp := envprovider.NewFactory()
 	configProviderSettings := otelcol.ConfigProviderSettings{
 		ResolverSettings: confmap.ResolverSettings{
-			URIs:              []string{constants.AGENT_CONFIG_PATH},
-			ProviderFactories: []confmap.ProviderFactory{fmp},
+			URIs:              []string{configprovider.URI(constants.AGENT_CONFIG_PATH)},
+			ProviderFactories: []confmap.ProviderFactory{provider.Factory(), emp},
+			// Tracks component statuses so config applies can be reported
+			ConverterFactories: []confmap.ConverterFactory{collectorstatus.NewConverterFactory()},
 		},
 	}
 
@@ -0,0 +1,225 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/config"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/constants"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/core/shutdown"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/components"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/logger"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/supervisor"
+)
+
+// settleTime is how long a supervised collector has to keep running after
+// loading a config for the config to count as applied. A collector exits
+// when it can't load its config.
+const settleTime = 5 * time.Second
+
+// SupervisedAdapter runs the collector binary as a child process instead of
+// inside the agent, so the agent's API stays reachable when the collector
+// crashes or runs out of memory.
+type SupervisedAdapter struct {
+	supervisor *supervisor.Supervisor
+	wg         *sync.WaitGroup
+}
+
+func NewSupervisedAdapter(wg *sync.WaitGroup) *SupervisedAdapter {
+	cfg := supervisor.Config{
+		Binary:         constants.COLLECTOR_BINARY,
+		Args:           []string{"--config", constants.AGENT_CONFIG_PATH},
+		MemoryLimitMiB: constants.COLLECTOR_MEMORY_LIMIT_MIB,
+		CPULimit:       constants.COLLECTOR_CPU_LIMIT,
+	}
+	return &SupervisedAdapter{
+		supervisor: supervisor.New(cfg, logger.Logger),
+		wg:         wg,
+	}
+}
+
+func (a *SupervisedAdapter) Initialize() error {
+	if err := a.supervisor.Start(); err != nil {
+		return fmt.Errorf("failed to start collector process: %w", err)
+	}
+	return nil
+}
+
+func (a *SupervisedAdapter) StartAgent() error {
+	if err := a.supervisor.Start(); err != nil {
+		return fmt.Errorf("failed to start collector process: %w", err)
+	}
+	return nil
+}
+
+func (a *SupervisedAdapter) StopAgent() error {
+	if err := a.supervisor.Stop(); err != nil {
+		return fmt.Errorf("failed to stop collector process: %w", err)
+	}
+	logger.Logger.Info("Collector process stopped")
+	return nil
+}
+
+// UpdateConfig makes the collector process load the config file and waits to
+// see whether it keeps running with it.
+func (a *SupervisedAdapter) UpdateConfig() error {
+	exits := a.supervisor.Status().Exits
+	if !a.supervisor.Status().Supervised {
+		if err := a.supervisor.Start(); err != nil {
+			return fmt.Errorf("failed to start collector process: %w", err)
+		}
+		return a.waitForCollector(exits)
+	}
+
+	logger.Logger.Info("Reloading collector process for updating config...")
+	err := a.supervisor.Signal(syscall.SIGHUP)
+	if errors.Is(err, supervisor.ErrNotRunning) {
+		// The collector is waiting to be restarted after exiting, so restart
+		// it with the new config right away
+		if err := a.supervisor.Stop(); err != nil {
+			return fmt.Errorf("failed to restart collector process: %w", err)
+		}
+		exits = a.supervisor.Status().Exits
+		if err := a.supervisor.Start(); err != nil {
+			return fmt.Errorf("failed to restart collector process: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to reload collector process: %w", err)
+	}
+
+	if err := a.waitForCollector(exits); err != nil {
+		return err
+	}
+	logger.Logger.Info("Config updated. Collector process reloaded")
+	return nil
+}
+
+// waitForCollector waits for the collector to run for settleTime without
+// exiting again since it exited the given number of times.
+func (a *SupervisedAdapter) waitForCollector(exits int) error {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	settled := time.After(settleTime)
+
+	for {
+		status := a.supervisor.Status()
+		if status.Exits > exits {
+			return fmt.Errorf("collector process failed to apply config: %s", status.LastExit)
+		}
+		if !status.Supervised {
+			return fmt.Errorf("collector process stopped while applying config")
+		}
+
+		select {
+		case <-ticker.C:
+		case <-settled:
+			if !a.supervisor.Status().Running {
+				return fmt.Errorf("collector process isn't running")
+			}
+			return nil
+		}
+	}
+}
+
+// Healthy checks that the collector process is running.
+func (a *SupervisedAdapter) Healthy() error {
+	status := a.supervisor.Status()
+	if status.Running {
+		return nil
+	}
+	if status.LastExit != "" {
+		return fmt.Errorf("collector process isn't running: %s", status.LastExit)
+	}
+	return fmt.Errorf("collector process isn't running")
+}
+
+func (a *SupervisedAdapter) GracefulShutdown() error {
+	logger.Logger.Info("Starting graceful shutdown...")
+
+	// Shutdown external services
+	shutdown.ShutdownServer()
+
+	// Stop the collector process
+	if err := a.StopAgent(); err != nil {
+		logger.Logger.Error(fmt.Sprintf("Error stopping agent during shutdown: %v", err))
+	}
+
+	logger.Logger.Info("Waiting for all goroutines to finish...")
+	done := make(chan struct{})
+	go func() {
+		a.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Logger.Info("All goroutines finished successfully")
+	case <-time.After(20 * time.Second):
+		return fmt.Errorf("timed out waiting for goroutines to finish")
+	}
+
+	logger.Logger.Info("Agent shutdown successfully")
+	return nil
+}
+
+// GetVersion returns the version the collector binary reports, e.g.
+// "0.122.0" for "otelcol-contrib version 0.122.0".
+func (a *SupervisedAdapter) GetVersion() (string, error) {
+	output, err := exec.Command(constants.COLLECTOR_BINARY, "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine collector version: %w", err)
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("failed to determine collector version: no output")
+	}
+	return strings.TrimPrefix(fields[len(fields)-1], "v"), nil
+}
+
+// GetComponents lists the components the collector binary reports with its
+// components command.
+func (a *SupervisedAdapter) GetComponents() ([]components.Component, error) {
+	output, err := exec.Command(constants.COLLECTOR_BINARY, "components").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collector components: %w", err)
+	}
+	return components.ParseComponentsOutput(output)
+}
+
+// ValidateConfigInMemory validates the config with the collector binary,
+// which knows the components it was built with.
+func (a *SupervisedAdapter) ValidateConfigInMemory(data *map[string]any) error {
+	if data == nil || *data == nil {
+		return fmt.Errorf("configuration data is nil")
+	}
+
+	dir, err := os.MkdirTemp("", "ctrlb-config-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp dir for validation: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.yaml")
+	if err := config.SaveToYAML(*data, path); err != nil {
+		return fmt.Errorf("failed to write config for validation: %w", err)
+	}
+
+	output, err := exec.Command(constants.COLLECTOR_BINARY, "validate", "--config", path).CombinedOutput()
+	if err != nil {
+		reason := strings.TrimSpace(string(output))
+		if reason == "" {
+			reason = err.Error()
+		}
+		return fmt.Errorf("component validation failed: %s", reason)
+	}
+
+	logger.Logger.Info("In-memory configuration validation successful")
+	return nil
+}
@@ -1,14 +1,23 @@
 package api
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/config"
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/core/operators"
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/logger"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/tap"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/upgrade"
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/utils"
+	"github.com/gorilla/mux"
 )
 
+// maxTapBody bounds an export request the collector sends to a tap
+const maxTapBody = 16 << 20
+
 func NewOperatorHandler(operatorService *operators.OperatorService) *OperatorHandler {
 	operatorHandler := &OperatorHandler{
 		OperatorService: operatorService,
@@ -77,3 +86,134 @@ func (o *OperatorHandler) UpdateCurrentConfig(w http.ResponseWriter, r *http.Req
 	logger.Logger.Info("Successfully updated current config")
 	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Successfully updated current config"})
 }
+
+// GetCurrentConfig returns the config the agent runs together with its hash,
+// which the backend compares with the pipeline's config to detect drift.
+func (o *OperatorHandler) GetCurrentConfig(w http.ResponseWriter, r *http.Request) {
+	logger.Logger.Info("Request received to get current config")
+
+	currentConfig, err := o.OperatorService.GetCurrentConfig()
+	if err != nil {
+		logger.Logger.Sugar().Errorf("Error occured while reading config: %v", err)
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	hash, err := config.Hash(currentConfig)
+	if err != nil {
+		logger.Logger.Sugar().Errorf("Error occured while hashing config: %v", err)
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]any{"config": currentConfig, "config_hash": hash})
+}
+
+// UpgradeAgent accepts an upgrade of the collector binary. The agent restarts
+// to run the new binary and reports the outcome to the backend.
+func (o *OperatorHandler) UpgradeAgent(w http.ResponseWriter, r *http.Request) {
+	logger.Logger.Info("Request received to upgrade agent")
+
+	var command upgrade.Command
+	if err := utils.UnmarshalJSONRequest(r, &command); err != nil {
+		logger.Logger.Sugar().Errorf("Invalid request body: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err := o.OperatorService.UpgradeAgent(command)
+	switch {
+	case errors.Is(err, upgrade.ErrInvalidCommand):
+		utils.SendJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, upgrade.ErrUpgradesDisabled):
+		utils.SendJSONError(w, http.StatusForbidden, err.Error())
+		return
+	case errors.Is(err, upgrade.ErrUpgradeInProgress):
+		utils.SendJSONError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		logger.Logger.Sugar().Errorf("Error occured while upgrading agent: %v", err)
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusAccepted, map[string]string{"message": fmt.Sprintf("Upgrading agent to version %s", command.Version)})
+}
+
+// StartTap inserts a tap after a receiver or processor of the running config.
+// Its samples are sent to the backend as they arrive.
+func (o *OperatorHandler) StartTap(w http.ResponseWriter, r *http.Request) {
+	logger.Logger.Info("Request received to start tap")
+
+	var req tap.Request
+	if err := utils.UnmarshalJSONRequest(r, &req); err != nil {
+		logger.Logger.Sugar().Errorf("Invalid request body: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err := o.OperatorService.StartTap(req)
+	switch {
+	case errors.Is(err, tap.ErrInvalidRequest):
+		utils.SendJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, tap.ErrTapsDisabled):
+		utils.SendJSONError(w, http.StatusForbidden, err.Error())
+		return
+	case errors.Is(err, tap.ErrTapInProgress):
+		utils.SendJSONError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		logger.Logger.Sugar().Errorf("Error occured while starting tap: %v", err)
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusAccepted, map[string]string{"message": fmt.Sprintf("Started tap %s", req.ID)})
+}
+
+// StopTap ends a tap before its limit or duration.
+func (o *OperatorHandler) StopTap(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	logger.Logger.Info(fmt.Sprintf("Request received to stop tap %s", id))
+
+	err := o.OperatorService.StopTap(id)
+	switch {
+	case errors.Is(err, tap.ErrUnknownTap):
+		utils.SendJSONError(w, http.StatusNotFound, err.Error())
+		return
+	case err != nil:
+		logger.Logger.Sugar().Errorf("Error occured while stopping tap: %v", err)
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": fmt.Sprintf("Stopped tap %s", id)})
+}
+
+// ReceiveTap is the OTLP/HTTP endpoint a tap's exporter sends to. It answers
+// with an empty export response; a 404 tells the exporter not to retry once
+// the tap has ended.
+func (o *OperatorHandler) ReceiveTap(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxTapBody))
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = o.OperatorService.ReceiveTap(vars["id"], vars["signal"], body)
+	switch {
+	case errors.Is(err, tap.ErrUnknownTap):
+		utils.SendJSONError(w, http.StatusNotFound, err.Error())
+		return
+	case err != nil:
+		logger.Logger.Sugar().Errorf("Error occured while receiving tap %s: %v", vars["id"], err)
+		utils.SendJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]any{})
+}
@@ -2,6 +2,7 @@ package api_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -9,6 +10,9 @@ import (
 
 	handlers "github.com/ctrlb-hq/ctrlb-collector/agent/internal/api/handlers"
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/core/operators"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/tap"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/upgrade"
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -37,6 +41,34 @@ func (m *MockOperator) UpdateCurrentConfig(cfg map[string]any) error {
 	return args.Error(0)
 }
 
+func (m *MockOperator) GetCurrentConfig() (map[string]any, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]any), args.Error(1)
+}
+
+func (m *MockOperator) UpgradeAgent(command upgrade.Command) error {
+	args := m.Called(command)
+	return args.Error(0)
+}
+
+func (m *MockOperator) StartTap(req tap.Request) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockOperator) StopTap(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockOperator) ReceiveTap(id string, signal string, body []byte) error {
+	args := m.Called(id, signal, body)
+	return args.Error(0)
+}
+
 func TestStartAgent_Success(t *testing.T) {
 	mockOp := new(MockOperator)
 	mockOp.On("StartAgent").Return(nil)
@@ -142,3 +174,130 @@ func TestStopAgent_Failure(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 	mockOp.AssertExpectations(t)
 }
+
+func TestGetCurrentConfig_Success(t *testing.T) {
+	mockOp := new(MockOperator)
+	mockOp.On("GetCurrentConfig").Return(map[string]any{"log_level": "debug"}, nil)
+	h := handlers.NewOperatorHandler(&operators.OperatorService{Operator: mockOp})
+
+	r := httptest.NewRequest(http.MethodGet, "/agent/v1/config", nil)
+	w := httptest.NewRecorder()
+
+	h.GetCurrentConfig(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Config     map[string]any `json:"config"`
+		ConfigHash string         `json:"config_hash"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "debug", body.Config["log_level"])
+	assert.Len(t, body.ConfigHash, 64)
+	mockOp.AssertExpectations(t)
+}
+
+func TestGetCurrentConfig_Failure(t *testing.T) {
+	mockOp := new(MockOperator)
+	mockOp.On("GetCurrentConfig").Return(nil, errors.New("config file missing"))
+	h := handlers.NewOperatorHandler(&operators.OperatorService{Operator: mockOp})
+
+	r := httptest.NewRequest(http.MethodGet, "/agent/v1/config", nil)
+	w := httptest.NewRecorder()
+
+	h.GetCurrentConfig(w, r)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	mockOp.AssertExpectations(t)
+}
+
+func TestUpgradeAgent_Accepted(t *testing.T) {
+	mockOp := new(MockOperator)
+	mockOp.On("UpgradeAgent", mock.MatchedBy(func(command upgrade.Command) bool {
+		return command.UpgradeID == 7 && command.Version == "1.1.0"
+	})).Return(nil)
+	h := handlers.NewOperatorHandler(&operators.OperatorService{Operator: mockOp})
+
+	body := bytes.NewBufferString(`{"upgrade_id": 7, "version": "1.1.0", "url": "https://downloads.example.com/agent"}`)
+	r := httptest.NewRequest(http.MethodPost, "/agent/v1/upgrade", body)
+	w := httptest.NewRecorder()
+
+	h.UpgradeAgent(w, r)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	mockOp.AssertExpectations(t)
+}
+
+func TestUpgradeAgent_Rejected(t *testing.T) {
+	for err, status := range map[error]int{
+		upgrade.ErrInvalidCommand:    http.StatusBadRequest,
+		upgrade.ErrUpgradesDisabled:  http.StatusForbidden,
+		upgrade.ErrUpgradeInProgress: http.StatusConflict,
+	} {
+		mockOp := new(MockOperator)
+		mockOp.On("UpgradeAgent", mock.Anything).Return(err)
+		h := handlers.NewOperatorHandler(&operators.OperatorService{Operator: mockOp})
+
+		r := httptest.NewRequest(http.MethodPost, "/agent/v1/upgrade", bytes.NewBufferString(`{"upgrade_id": 7}`))
+		w := httptest.NewRecorder()
+
+		h.UpgradeAgent(w, r)
+
+		assert.Equal(t, status, w.Code, err.Error())
+	}
+}
+
+func TestStartTap_Accepted(t *testing.T) {
+	mockOp := new(MockOperator)
+	mockOp.On("StartTap", tap.Request{ID: "t1", Component: "batch/Batch_3c", Role: "processor", Signal: "logs", Limit: 10}).Return(nil)
+	h := handlers.NewOperatorHandler(&operators.OperatorService{Operator: mockOp})
+
+	body := bytes.NewBufferString(`{"id": "t1", "component": "batch/Batch_3c", "role": "processor", "signal": "logs", "limit": 10}`)
+	r := httptest.NewRequest(http.MethodPost, "/agent/v1/taps", body)
+	w := httptest.NewRecorder()
+
+	h.StartTap(w, r)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	mockOp.AssertExpectations(t)
+}
+
+func TestStartTap_Rejected(t *testing.T) {
+	for err, status := range map[error]int{
+		tap.ErrInvalidRequest: http.StatusBadRequest,
+		tap.ErrTapsDisabled:   http.StatusForbidden,
+		tap.ErrTapInProgress:  http.StatusConflict,
+	} {
+		mockOp := new(MockOperator)
+		mockOp.On("StartTap", mock.Anything).Return(err)
+		h := handlers.NewOperatorHandler(&operators.OperatorService{Operator: mockOp})
+
+		r := httptest.NewRequest(http.MethodPost, "/agent/v1/taps", bytes.NewBufferString(`{"id": "t1"}`))
+		w := httptest.NewRecorder()
+
+		h.StartTap(w, r)
+
+		assert.Equal(t, status, w.Code, err.Error())
+	}
+}
+
+func TestReceiveTap(t *testing.T) {
+	mockOp := new(MockOperator)
+	mockOp.On("ReceiveTap", "t1", "logs", []byte(`{"resourceLogs":[]}`)).Return(nil)
+	mockOp.On("ReceiveTap", "t2", "logs", mock.Anything).Return(tap.ErrUnknownTap)
+	h := handlers.NewOperatorHandler(&operators.OperatorService{Operator: mockOp})
+
+	r := httptest.NewRequest(http.MethodPost, "/agent/v1/taps/t1/v1/logs", bytes.NewBufferString(`{"resourceLogs":[]}`))
+	r = mux.SetURLVars(r, map[string]string{"id": "t1", "signal": "logs"})
+	w := httptest.NewRecorder()
+	h.ReceiveTap(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{}`, w.Body.String())
+
+	// The exporter doesn't retry once the tap is gone
+	r = httptest.NewRequest(http.MethodPost, "/agent/v1/taps/t2/v1/logs", bytes.NewBufferString(`{}`))
+	r = mux.SetURLVars(r, map[string]string{"id": "t2", "signal": "logs"})
+	w = httptest.NewRecorder()
+	h.ReceiveTap(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockOp.AssertExpectations(t)
+}
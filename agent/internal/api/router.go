@@ -19,7 +19,17 @@ func NewRouter(operatorService *operators.OperatorService) *mux.Router {
 	agentApiV1.HandleFunc("/shutdown", operatorHandler.GracefulShutdown).Methods("POST")
 
 	// Agent configuration (GET and PUT) - Retrieves or updates the current config of the agent
+	agentApiV1.HandleFunc("/config", operatorHandler.GetCurrentConfig).Methods("GET")
 	agentApiV1.HandleFunc("/config", operatorHandler.UpdateCurrentConfig).Methods("POST")
 
+	// Agent upgrade - Replaces the collector binary with a signed version and restarts
+	agentApiV1.HandleFunc("/upgrade", operatorHandler.UpgradeAgent).Methods("POST")
+
+	// Taps - Sample the telemetry leaving a receiver or processor. The tap's
+	// exporter sends OTLP/HTTP to /taps/{id}/v1/{signal}
+	agentApiV1.HandleFunc("/taps", operatorHandler.StartTap).Methods("POST")
+	agentApiV1.HandleFunc("/taps/{id}", operatorHandler.StopTap).Methods("DELETE")
+	agentApiV1.HandleFunc("/taps/{id}/v1/{signal:logs|traces|metrics}", operatorHandler.ReceiveTap).Methods("POST")
+
 	return router
 }
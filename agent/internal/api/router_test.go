@@ -7,6 +7,8 @@ import (
 
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/api"
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/core/operators"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/tap"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/upgrade"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -35,12 +37,44 @@ func (m *MockOperator) UpdateCurrentConfig(cfg map[string]any) error {
 	return args.Error(0)
 }
 
+func (m *MockOperator) GetCurrentConfig() (map[string]any, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]any), args.Error(1)
+}
+
+func (m *MockOperator) UpgradeAgent(command upgrade.Command) error {
+	args := m.Called(command)
+	return args.Error(0)
+}
+
+func (m *MockOperator) StartTap(req tap.Request) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockOperator) StopTap(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockOperator) ReceiveTap(id string, signal string, body []byte) error {
+	args := m.Called(id, signal, body)
+	return args.Error(0)
+}
+
 func TestNewRouter_CallsAreWired(t *testing.T) {
 	mockOperator := new(MockOperator)
 	mockOperator.On("StartAgent").Return(nil)
 	mockOperator.On("StopAgent").Return(nil)
 	mockOperator.On("GracefulShutdown").Return(nil)
 	mockOperator.On("UpdateCurrentConfig", mock.Anything).Return(nil)
+	mockOperator.On("GetCurrentConfig").Return(map[string]any{"log_level": "debug"}, nil)
+	mockOperator.On("StartTap", mock.Anything).Return(nil)
+	mockOperator.On("StopTap", "t1").Return(nil)
+	mockOperator.On("ReceiveTap", "t1", "logs", mock.Anything).Return(nil)
 
 	service := &operators.OperatorService{Operator: mockOperator}
 
@@ -55,6 +89,10 @@ func TestNewRouter_CallsAreWired(t *testing.T) {
 		{"/agent/v1/stop", "POST"},
 		{"/agent/v1/shutdown", "POST"},
 		{"/agent/v1/config", "POST"},
+		{"/agent/v1/config", "GET"},
+		{"/agent/v1/taps", "POST"},
+		{"/agent/v1/taps/t1", "DELETE"},
+		{"/agent/v1/taps/t1/v1/logs", "POST"},
 	}
 
 	for _, route := range routes {
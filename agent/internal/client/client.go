@@ -11,6 +11,8 @@ import (
 
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/constants"
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/systeminfo"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/tap"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/upgrade"
 )
 
 func InformBackendServerStart(sys systeminfo.SystemInfoProvider,
@@ -38,12 +40,16 @@ func InformBackendServerStart(sys systeminfo.SystemInfoProvider,
 	}
 	// Step 3: Create the agent request
 	agentRequest := AgentRequest{
-		IP:           ip,
-		Version:      constants.AGENT_VERSION,
-		Platform:     platform,
-		Hostname:     hostname,
-		PipelineName: constants.PIPELINE_NAME,
-		StartedBy:    constants.STARTED_BY,
+		IP:               ip,
+		Version:          constants.AGENT_VERSION,
+		Platform:         platform,
+		Hostname:         hostname,
+		PipelineName:     constants.PIPELINE_NAME,
+		PipelineTemplate: constants.PIPELINE_TEMPLATE,
+		TemplateParams:   constants.PIPELINE_TEMPLATE_PARAMS,
+		StartedBy:        constants.STARTED_BY,
+		Labels:           constants.AGENT_LABELS,
+		Components:       constants.AGENT_COMPONENTS,
 	}
 
 	// Step 4: Marshal the agent request into JSON
@@ -85,16 +91,19 @@ func InformBackendServerStart(sys systeminfo.SystemInfoProvider,
 	return agentResponse.Config, nil
 }
 
-func InformBackendConfigFileChanged(client *http.Client) error {
+// InformBackendConfigFileChanged reports the hash of the effective config to
+// the backend, which checks it for drift from the pipeline's config.
+func InformBackendConfigFileChanged(client *http.Client, configHash string) error {
 	if client == nil {
 		client = &http.Client{Timeout: 5 * time.Second}
 	}
 
 	url := fmt.Sprintf("%s/api/agent/v1/agents/%v/config-changed", constants.BACKEND_URL, constants.AGENTID)
 
-	info := map[string]string{
-		"time":    time.Now().Format(time.RFC3339),
-		"message": "Config file modified/deleted",
+	info := ConfigChangedRequest{
+		Time:       time.Now().Format(time.RFC3339),
+		Message:    "Config file modified/deleted",
+		ConfigHash: configHash,
 	}
 
 	jsonPayload, err := json.Marshal(info)
@@ -121,3 +130,106 @@ func InformBackendConfigFileChanged(client *http.Client) error {
 
 	return nil
 }
+
+// ReportConfigApply tells the backend whether the collector applied a config,
+// with the statuses of its components.
+func ReportConfigApply(client *http.Client, report ConfigApplyReport) error {
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	url := fmt.Sprintf("%s/api/agent/v1/agents/%v/config-status", constants.BACKEND_URL, constants.AGENTID)
+
+	jsonPayload, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return fmt.Errorf("error creating HTTP request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("non-2xx response: %d - %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// ReportUpgrade tells the backend how an upgrade ended. Upgrades are reported
+// by upgrade ID since the agent registers again, under a new ID, after the
+// restart.
+func ReportUpgrade(client *http.Client, upgradeId int, report upgrade.Report) error {
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	url := fmt.Sprintf("%s/api/agent/v1/upgrades/%d/status", constants.BACKEND_URL, upgradeId)
+
+	jsonPayload, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return fmt.Errorf("error creating HTTP request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("non-2xx response: %d - %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// SendTapSample forwards telemetry caught by a tap to the backend, which
+// streams it to whoever opened the tap.
+func SendTapSample(client *http.Client, sample tap.Sample) error {
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	url := fmt.Sprintf("%s/api/agent/v1/taps/%s/samples", constants.BACKEND_URL, sample.TapID)
+
+	jsonPayload, err := json.Marshal(sample)
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return fmt.Errorf("error creating HTTP request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("non-2xx response: %d - %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
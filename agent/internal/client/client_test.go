@@ -10,6 +10,9 @@ import (
 	"testing"
 
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/constants"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/collectorstatus"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/tap"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/upgrade"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -101,6 +104,7 @@ func TestInformBackendConfigFileChanged_Success(t *testing.T) {
 
 		assert.Contains(t, payload["message"], "Config file modified")
 		assert.NotEmpty(t, payload["time"])
+		assert.Equal(t, "abc123", payload["config_hash"])
 
 		w.WriteHeader(http.StatusOK)
 	}))
@@ -111,6 +115,112 @@ func TestInformBackendConfigFileChanged_Success(t *testing.T) {
 	constants.BACKEND_URL = testServer.URL
 	defer func() { constants.BACKEND_URL = originalBackend }()
 
-	err := InformBackendConfigFileChanged(testServer.Client())
+	err := InformBackendConfigFileChanged(testServer.Client(), "abc123")
 	assert.NoError(t, err)
 }
+
+func TestReportConfigApply_Success(t *testing.T) {
+	constants.AGENTID = 123
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/agent/v1/agents/123/config-status", r.URL.Path)
+
+		var payload ConfigApplyReport
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+
+		assert.Equal(t, "abc123", payload.ConfigHash)
+		assert.Equal(t, ConfigFailed, payload.Status)
+		assert.Equal(t, "port in use", payload.Error)
+		assert.Equal(t, []collectorstatus.ComponentStatus{
+			{ID: "otlp", Kind: "receiver", Status: "permanent_error", Error: "port in use"},
+		}, payload.Components)
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	originalBackend := constants.BACKEND_URL
+	constants.BACKEND_URL = testServer.URL
+	defer func() { constants.BACKEND_URL = originalBackend }()
+
+	err := ReportConfigApply(testServer.Client(), ConfigApplyReport{
+		ConfigHash: "abc123",
+		Status:     ConfigFailed,
+		Error:      "port in use",
+		Components: []collectorstatus.ComponentStatus{
+			{ID: "otlp", Kind: "receiver", Status: "permanent_error", Error: "port in use"},
+		},
+	})
+	assert.NoError(t, err)
+}
+
+func TestReportConfigApply_Non2xx(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("agent not found"))
+	}))
+	defer testServer.Close()
+
+	originalBackend := constants.BACKEND_URL
+	constants.BACKEND_URL = testServer.URL
+	defer func() { constants.BACKEND_URL = originalBackend }()
+
+	err := ReportConfigApply(testServer.Client(), ConfigApplyReport{Status: ConfigApplied})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "agent not found")
+}
+
+func TestReportUpgrade_Success(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/agent/v1/upgrades/7/status", r.URL.Path)
+
+		var payload upgrade.Report
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, upgrade.Report{Status: upgrade.StatusRolledBack, Version: "1.0.0", Error: "collector didn't start"}, payload)
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	originalBackend := constants.BACKEND_URL
+	constants.BACKEND_URL = testServer.URL
+	defer func() { constants.BACKEND_URL = originalBackend }()
+
+	err := ReportUpgrade(testServer.Client(), 7, upgrade.Report{Status: upgrade.StatusRolledBack, Version: "1.0.0", Error: "collector didn't start"})
+	assert.NoError(t, err)
+}
+
+func TestSendTapSample_Success(t *testing.T) {
+	sample := tap.Sample{TapID: "t1", AgentID: 3, Signal: tap.SignalLogs, Count: 1, Data: json.RawMessage(`{"resourceLogs":[]}`)}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/agent/v1/taps/t1/samples", r.URL.Path)
+
+		var payload tap.Sample
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, sample, payload)
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	originalBackend := constants.BACKEND_URL
+	constants.BACKEND_URL = testServer.URL
+	defer func() { constants.BACKEND_URL = originalBackend }()
+
+	assert.NoError(t, SendTapSample(testServer.Client(), sample))
+}
+
+func TestSendTapSample_TapClosed(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "tap isn't open", http.StatusNotFound)
+	}))
+	defer testServer.Close()
+
+	originalBackend := constants.BACKEND_URL
+	constants.BACKEND_URL = testServer.URL
+	defer func() { constants.BACKEND_URL = originalBackend }()
+
+	err := SendTapSample(testServer.Client(), tap.Sample{TapID: "t1"})
+	assert.ErrorContains(t, err, "404")
+}
@@ -1,15 +1,45 @@
 package client
 
+import (
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/collectorstatus"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/components"
+)
+
 type AgentRequest struct {
-	IP           string `json:"ip"`
-	Version      string `json:"version"`       // The version of the agent
-	Hostname     string `json:"hostname"`      // The hostname of the machine running the agent
-	Platform     string `json:"platform"`      // The platform (e.g., OS) the agent is running on
-	PipelineName string `json:"pipeline_name"` // The name of the pipeline
-	StartedBy    string `json:"started_by"`    // The user who started the agent
+	IP               string                 `json:"ip"`
+	Version          string                 `json:"version"`                     // The version of the agent
+	Hostname         string                 `json:"hostname"`                    // The hostname of the machine running the agent
+	Platform         string                 `json:"platform"`                    // The platform (e.g., OS) the agent is running on
+	PipelineName     string                 `json:"pipeline_name"`               // The name of the pipeline
+	PipelineTemplate string                 `json:"pipeline_template,omitempty"` // Template the pipeline is created from
+	TemplateParams   map[string]any         `json:"template_params,omitempty"`   // Parameters for the template
+	StartedBy        string                 `json:"started_by"`                  // The user who started the agent
+	Labels           map[string]string      `json:"labels,omitempty"`            // Labels matched against pipeline label selectors
+	Components       []components.Component `json:"components,omitempty"`        // Components built into the collector
 }
 
 type AgentResponse struct {
 	ID     int64          `json:"id"`     // Unique ID for the agent
 	Config map[string]any `json:"config"` // Associated configuration
 }
+
+type ConfigChangedRequest struct {
+	Time       string `json:"time"`
+	Message    string `json:"message"`
+	ConfigHash string `json:"config_hash"` // Hash of the effective config, empty if the config file is gone
+}
+
+// Outcomes of applying a config, as reported to the backend
+const (
+	ConfigApplied = "applied"
+	ConfigFailed  = "failed"
+)
+
+type ConfigApplyReport struct {
+	Time       string                            `json:"time"`
+	ConfigHash string                            `json:"config_hash"`     // Hash of the config the collector was asked to load
+	Status     string                            `json:"status"`          // ConfigApplied or ConfigFailed
+	Error      string                            `json:"error,omitempty"` // Why the collector couldn't apply the config
+	RolledBack bool                              `json:"rolled_back"`     // Whether the last-known-good config was restored after a failure
+	Components []collectorstatus.ComponentStatus `json:"components"`      // Statuses of the config's components
+}
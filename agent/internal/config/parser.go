@@ -1,6 +1,9 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -49,3 +52,86 @@ func SaveToYAML(inputString map[string]any, filePath string) error {
 
 	return nil
 }
+
+// LoadFromYAML reads the config file the collector runs.
+func LoadFromYAML(filePath string) (map[string]any, error) {
+	yamlData, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	config := map[string]any{}
+	if err := yaml.Unmarshal(yamlData, &config); err != nil {
+		return nil, fmt.Errorf("could not parse YAML file: %v", err)
+	}
+	return config, nil
+}
+
+// Hash returns the hash the backend compares with the config it sent to
+// detect drift: SHA-256 over the config as JSON with sorted keys, without
+// "enabled" keys.
+func Hash(config map[string]any) (string, error) {
+	jsonData, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal config to JSON: %v", err)
+	}
+
+	// Round-trip through JSON so YAML and JSON decoded values hash alike
+	var normalized any
+	if err := json.Unmarshal(jsonData, &normalized); err != nil {
+		return "", fmt.Errorf("could not normalize config: %v", err)
+	}
+	jsonData, err = json.Marshal(stripEnabledRecursive(normalized))
+	if err != nil {
+		return "", fmt.Errorf("could not marshal config to JSON: %v", err)
+	}
+
+	sum := sha256.Sum256(jsonData)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// LastGoodPath is where the last config the collector ran successfully is
+// kept, next to the config file.
+func LastGoodPath(filePath string) string {
+	return filePath + ".last-good"
+}
+
+// SaveLastGood keeps a copy of the config file as the last-known-good config.
+func SaveLastGood(filePath string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("could not read config file: %v", err)
+	}
+
+	if err := writeFileAtomic(LastGoodPath(filePath), data); err != nil {
+		return fmt.Errorf("could not save last-known-good config: %v", err)
+	}
+	return nil
+}
+
+// RestoreLastGood overwrites the config file with the last-known-good config.
+func RestoreLastGood(filePath string) error {
+	data, err := os.ReadFile(LastGoodPath(filePath))
+	if err != nil {
+		return fmt.Errorf("could not read last-known-good config: %w", err)
+	}
+	// The config file is watched, so it must never be seen half-written
+	if err := writeFileAtomic(filePath, data); err != nil {
+		return fmt.Errorf("could not restore config file: %v", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes a temporary file first and renames it into place, so
+// a crash or a reader never sees a partial file.
+func writeFileAtomic(filePath string, data []byte) error {
+	tmpPath := filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
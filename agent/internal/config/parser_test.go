@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -36,3 +37,66 @@ func TestSaveToYAML_InvalidPath(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "could not write YAML")
 }
+
+func TestHash_MatchesSavedConfig(t *testing.T) {
+	filePath := "test_hash_config.yaml"
+	defer os.Remove(filePath)
+
+	config := map[string]any{
+		"receivers":  map[string]any{"otlp": map[string]any{"enabled": true, "endpoint": "0.0.0.0:4317"}},
+		"processors": map[string]any{"batch": map[string]any{"send_batch_size": float64(512), "timeout": "5s"}},
+		"service": map[string]any{
+			"pipelines": map[string]any{"logs": map[string]any{"receivers": []any{"otlp"}, "processors": []any{"batch"}}},
+		},
+	}
+	sent, err := Hash(config)
+	assert.NoError(t, err)
+
+	assert.NoError(t, SaveToYAML(config, filePath))
+	loaded, err := LoadFromYAML(filePath)
+	assert.NoError(t, err)
+
+	effective, err := Hash(loaded)
+	assert.NoError(t, err)
+	assert.Equal(t, sent, effective)
+
+	loaded["processors"].(map[string]any)["batch"].(map[string]any)["timeout"] = "10s"
+	edited, err := Hash(loaded)
+	assert.NoError(t, err)
+	assert.NotEqual(t, sent, edited)
+}
+
+func TestLoadFromYAML_Missing(t *testing.T) {
+	_, err := LoadFromYAML("missing_config.yaml")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestLastGood_SaveAndRestore(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "config.yaml")
+
+	assert.NoError(t, os.WriteFile(filePath, []byte("good: true\n"), 0644))
+	assert.NoError(t, SaveLastGood(filePath))
+
+	assert.NoError(t, os.WriteFile(filePath, []byte("good: false\n"), 0644))
+	assert.NoError(t, RestoreLastGood(filePath))
+
+	data, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "good: true\n", string(data))
+	_, err = os.Stat(LastGoodPath(filePath) + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filePath + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRestoreLastGood_Missing(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(filePath, []byte("good: false\n"), 0644))
+
+	err := RestoreLastGood(filePath)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	data, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "good: false\n", string(data))
+}
@@ -1,5 +1,7 @@
 package constants
 
+import "github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/components"
+
 var (
 	AGENT_CONFIG_PATH = "./config.yaml"
 	AGENT_TYPE        = "otel"
@@ -8,7 +10,34 @@ var (
 	PORT              = "3421"
 	TESTING           = false
 	PIPELINE_NAME     = ""
+	PIPELINE_TEMPLATE = ""
 	STARTED_BY        = "Admin"
 )
 
 var AGENTID int64
+
+// AGENT_MODE is "supervisor" to run COLLECTOR_BINARY as a child process,
+// restarted when it exits; any other value runs the collector inside the
+// agent.
+var (
+	AGENT_MODE                 = "embedded"
+	COLLECTOR_BINARY           = ""
+	COLLECTOR_MEMORY_LIMIT_MIB = 0
+	COLLECTOR_CPU_LIMIT        = 0
+)
+
+// UPGRADE_PUBLIC_KEY is the base64 Ed25519 key that binaries pushed by the
+// backend must be signed with. Upgrades are refused when it's empty.
+var UPGRADE_PUBLIC_KEY = ""
+
+// PIPELINE_TEMPLATE_PARAMS are the parameters for PIPELINE_TEMPLATE, parsed
+// from the JSON object in the PIPELINE_TEMPLATE_PARAMS environment variable.
+var PIPELINE_TEMPLATE_PARAMS map[string]any
+
+// AGENT_LABELS are sent at registration, parsed from the "key=value,..." list
+// in the AGENT_LABELS environment variable.
+var AGENT_LABELS map[string]string
+
+// AGENT_COMPONENTS are the components built into the collector, sent at
+// registration so the backend rejects pipelines the agent can't run.
+var AGENT_COMPONENTS []components.Component
@@ -1,7 +1,14 @@
 package operators
 
 import (
+	"fmt"
+
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/adapters"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/client"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/constants"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/logger"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/tap"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/upgrade"
 )
 
 type Operator interface {
@@ -9,14 +16,33 @@ type Operator interface {
 	StopAgent() error
 	GracefulShutdown() error
 	UpdateCurrentConfig(map[string]any) error
+	GetCurrentConfig() (map[string]any, error)
+	UpgradeAgent(upgrade.Command) error
+	StartTap(tap.Request) error
+	StopTap(id string) error
+	ReceiveTap(id string, signal string, body []byte) error
 }
 
 type OperatorService struct {
 	Operator Operator
 }
 
-func NewOperatorService(adapter adapters.Adapter) *OperatorService {
+// NewOperatorService returns the service operating the adapter's collector.
+// upgrader may be nil, which disables upgrades.
+func NewOperatorService(adapter adapters.Adapter, upgrader *upgrade.Upgrader) *OperatorService {
 	operator := NewOtelOperator(adapter)
+	operator.Upgrader = upgrader
+
+	// Taps export to the agent's own API, which forwards samples to the backend
+	endpoint := fmt.Sprintf("http://127.0.0.1:%s/agent/v1/taps", constants.PORT)
+	sendSample := func(sample tap.Sample) error {
+		sample.AgentID = constants.AGENTID
+		return client.SendTapSample(nil, sample)
+	}
+	operator.Taps = tap.NewManager(constants.AGENT_CONFIG_PATH, endpoint, sendSample, logger.Logger)
+	operator.Taps.Validate = func(cfg map[string]any) error {
+		return adapter.ValidateConfigInMemory(&cfg)
+	}
 
 	return &OperatorService{Operator: operator}
 }
@@ -36,3 +62,23 @@ func (o *OperatorService) GracefulShutdown() error {
 func (o *OperatorService) UpdateCurrentConfig(updateConfigRequest map[string]any) error {
 	return o.Operator.UpdateCurrentConfig(updateConfigRequest)
 }
+
+func (o *OperatorService) GetCurrentConfig() (map[string]any, error) {
+	return o.Operator.GetCurrentConfig()
+}
+
+func (o *OperatorService) UpgradeAgent(command upgrade.Command) error {
+	return o.Operator.UpgradeAgent(command)
+}
+
+func (o *OperatorService) StartTap(req tap.Request) error {
+	return o.Operator.StartTap(req)
+}
+
+func (o *OperatorService) StopTap(id string) error {
+	return o.Operator.StopTap(id)
+}
+
+func (o *OperatorService) ReceiveTap(id string, signal string, body []byte) error {
+	return o.Operator.ReceiveTap(id, signal, body)
+}
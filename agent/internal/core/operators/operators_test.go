@@ -4,6 +4,9 @@ import (
 	"testing"
 
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/core/operators"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/components"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/tap"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/upgrade"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -18,10 +21,12 @@ func (m *mockAdapter) UpdateConfig() error                               { retur
 func (m *mockAdapter) GracefulShutdown() error                           { return nil }
 func (m *mockAdapter) GetVersion() (string, error)                       { return "mock", nil }
 func (m *mockAdapter) ValidateConfigInMemory(data *map[string]any) error { return nil }
+func (m *mockAdapter) Healthy() error                                    { return nil }
+func (m *mockAdapter) GetComponents() ([]components.Component, error)    { return nil, nil }
 
 func TestNewOperatorService_ReturnsOtelOperator(t *testing.T) {
 	adapter := &mockAdapter{}
-	service := operators.NewOperatorService(adapter)
+	service := operators.NewOperatorService(adapter, nil)
 
 	assert.NotNil(t, service)
 	assert.NotNil(t, service.Operator)
@@ -55,6 +60,34 @@ func (m *MockOperator) UpdateCurrentConfig(cfg map[string]any) error {
 	return args.Error(0)
 }
 
+func (m *MockOperator) GetCurrentConfig() (map[string]any, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]any), args.Error(1)
+}
+
+func (m *MockOperator) UpgradeAgent(command upgrade.Command) error {
+	args := m.Called(command)
+	return args.Error(0)
+}
+
+func (m *MockOperator) StartTap(req tap.Request) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockOperator) StopTap(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockOperator) ReceiveTap(id string, signal string, body []byte) error {
+	args := m.Called(id, signal, body)
+	return args.Error(0)
+}
+
 func TestOperatorService_StartAgent(t *testing.T) {
 	mockOp := new(MockOperator)
 	mockOp.On("StartAgent").Return(nil)
@@ -98,3 +131,15 @@ func TestOperatorService_UpdateCurrentConfig(t *testing.T) {
 	assert.NoError(t, err)
 	mockOp.AssertExpectations(t)
 }
+
+func TestOperatorService_GetCurrentConfig(t *testing.T) {
+	mockOp := new(MockOperator)
+	mockOp.On("GetCurrentConfig").Return(map[string]any{"log_level": "debug"}, nil)
+
+	service := &operators.OperatorService{Operator: mockOp}
+	cfg, err := service.GetCurrentConfig()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "debug", cfg["log_level"])
+	mockOp.AssertExpectations(t)
+}
@@ -3,16 +3,25 @@ package operators
 import (
 	"encoding/json"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/adapters"
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/config"
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/constants"
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/logger"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/tap"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/upgrade"
 )
 
 type OtelOperator struct {
 	BaseURL string
 	Adapter adapters.Adapter
+	// Upgrader replaces the collector binary, nil when upgrades are disabled
+	Upgrader  *upgrade.Upgrader
+	upgrading atomic.Bool
+	// Taps samples telemetry from the running config, nil when taps are
+	// disabled
+	Taps *tap.Manager
 }
 
 func NewOtelOperator(adapter adapters.Adapter) *OtelOperator {
@@ -76,6 +85,11 @@ func (otc *OtelOperator) UpdateCurrentConfig(updateConfigRequest map[string]any)
 		return fmt.Errorf("configuration validation failed: %w", err)
 	}
 
+	// The new config replaces a running tap
+	if otc.Taps != nil {
+		otc.Taps.Abandon()
+	}
+
 	// If validation passes, save to the actual config path
 	if err := config.SaveToYAML(updateConfigRequest, constants.AGENT_CONFIG_PATH); err != nil {
 		return fmt.Errorf("failed to save config to final location: %w", err)
@@ -84,3 +98,82 @@ func (otc *OtelOperator) UpdateCurrentConfig(updateConfigRequest map[string]any)
 	logger.Logger.Info("Configuration updated and validated successfully")
 	return nil
 }
+
+// GetCurrentConfig returns the config the collector runs, as saved on disk,
+// without a running tap.
+func (otc *OtelOperator) GetCurrentConfig() (map[string]any, error) {
+	cfg, err := config.LoadFromYAML(constants.AGENT_CONFIG_PATH)
+	if err != nil {
+		return nil, err
+	}
+	if tap.Contains(cfg) {
+		return tap.Remove(cfg), nil
+	}
+	return cfg, nil
+}
+
+// UpgradeAgent starts upgrading the collector binary to the command's version
+// and restarts the agent into it. Since the agent restarts, the outcome is
+// reported to the backend rather than returned.
+func (otc *OtelOperator) UpgradeAgent(command upgrade.Command) error {
+	if otc.Upgrader == nil {
+		return upgrade.ErrUpgradesDisabled
+	}
+	if err := command.Validate(); err != nil {
+		return err
+	}
+	if !otc.upgrading.CompareAndSwap(false, true) {
+		return upgrade.ErrUpgradeInProgress
+	}
+
+	go func() {
+		defer otc.upgrading.Store(false)
+		logger.Logger.Info(fmt.Sprintf("Upgrading from version %s to %s", constants.AGENT_VERSION, command.Version))
+
+		if err := otc.Upgrader.Install(command, constants.AGENT_VERSION); err != nil {
+			logger.Logger.Error(fmt.Sprintf("Failed to install version %s: %v", command.Version, err))
+			otc.Upgrader.ReportFailure(command, constants.AGENT_VERSION, err)
+			return
+		}
+
+		// Stop the collector so the restarted agent can bind its ports
+		if err := otc.Adapter.StopAgent(); err != nil {
+			logger.Logger.Error(fmt.Sprintf("Error stopping collector before restart: %v", err))
+		}
+		err := otc.Upgrader.Restart()
+
+		logger.Logger.Error(fmt.Sprintf("Failed to restart into version %s: %v", command.Version, err))
+		if cancelErr := otc.Upgrader.Cancel(); cancelErr != nil {
+			logger.Logger.Error(fmt.Sprintf("Failed to restore previous binary: %v", cancelErr))
+		}
+		otc.Upgrader.ReportFailure(command, constants.AGENT_VERSION, fmt.Errorf("failed to restart agent: %w", err))
+		if err := otc.Adapter.StartAgent(); err != nil {
+			logger.Logger.Error(fmt.Sprintf("Failed to start collector again: %v", err))
+		}
+	}()
+	return nil
+}
+
+// StartTap inserts a tap into the running config. The collector reloads it
+// like any other config change.
+func (otc *OtelOperator) StartTap(req tap.Request) error {
+	if otc.Taps == nil {
+		return tap.ErrTapsDisabled
+	}
+	return otc.Taps.Start(req)
+}
+
+func (otc *OtelOperator) StopTap(id string) error {
+	if otc.Taps == nil {
+		return tap.ErrUnknownTap
+	}
+	return otc.Taps.Stop(id)
+}
+
+// ReceiveTap takes what the collector exported to a tap.
+func (otc *OtelOperator) ReceiveTap(id string, signal string, body []byte) error {
+	if otc.Taps == nil {
+		return tap.ErrUnknownTap
+	}
+	return otc.Taps.Receive(id, signal, body)
+}
@@ -3,10 +3,15 @@ package operators_test
 import (
 	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/constants"
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/core/operators"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/components"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/tap"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/upgrade"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -50,6 +55,19 @@ func (m *MockAdapter) ValidateConfigInMemory(data *map[string]any) error {
 	return args.Error(0)
 }
 
+func (m *MockAdapter) Healthy() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockAdapter) GetComponents() ([]components.Component, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]components.Component), args.Error(1)
+}
+
 func TestOtelOperator_Initialize(t *testing.T) {
 	mockAdapter := new(MockAdapter)
 	mockAdapter.On("Initialize").Return(nil)
@@ -125,3 +143,43 @@ func TestStartAgent_Failure(t *testing.T) {
 	assert.Equal(t, "fail to start", err.Error())
 	mockAdapter.AssertExpectations(t)
 }
+
+func TestUpgradeAgent_Rejected(t *testing.T) {
+	mockAdapter := new(MockAdapter)
+	op := operators.NewOtelOperator(mockAdapter)
+
+	command := upgrade.Command{UpgradeID: 7, Version: "1.1.0", URL: "https://downloads.example.com/agent"}
+	assert.ErrorIs(t, op.UpgradeAgent(command), upgrade.ErrUpgradesDisabled)
+
+	op.Upgrader = &upgrade.Upgrader{Binary: "agent"}
+	assert.ErrorIs(t, op.UpgradeAgent(command), upgrade.ErrInvalidCommand)
+	mockAdapter.AssertNotCalled(t, "StopAgent")
+}
+
+func TestStartTap_Disabled(t *testing.T) {
+	op := operators.NewOtelOperator(new(MockAdapter))
+
+	assert.ErrorIs(t, op.StartTap(tap.Request{ID: "t1"}), tap.ErrTapsDisabled)
+	assert.ErrorIs(t, op.ReceiveTap("t1", "logs", nil), tap.ErrUnknownTap)
+}
+
+func TestGetCurrentConfig_WithoutTap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+exporters:
+  debug/Debug_1a: {}
+  otlphttp/ctrlb_tap: {endpoint: "http://127.0.0.1:3421/agent/v1/taps/t1"}
+service:
+  pipelines:
+    logs/pipeline_1: {receivers: [otlp/Otlp_2b], exporters: [debug/Debug_1a]}
+    logs/ctrlb_tap_1: {receivers: [otlp/Otlp_2b], exporters: [otlphttp/ctrlb_tap]}
+`), 0o644))
+	originalPath := constants.AGENT_CONFIG_PATH
+	constants.AGENT_CONFIG_PATH = path
+	defer func() { constants.AGENT_CONFIG_PATH = originalPath }()
+
+	cfg, err := operators.NewOtelOperator(new(MockAdapter)).GetCurrentConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"debug/Debug_1a": map[string]any{}}, cfg["exporters"])
+	assert.Len(t, cfg["service"].(map[string]any)["pipelines"], 1)
+}
@@ -0,0 +1,166 @@
+// Package collectorstatus tracks the status of the collector's components. It
+// provides an extension that the collector reports component status changes
+// to, and a config converter that adds the extension to every config the
+// collector loads, so config files don't have to mention it.
+package collectorstatus
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/extension"
+)
+
+const typeStr = "ctrlb_status"
+
+// ComponentStatus is the last status a component of the running config reported.
+type ComponentStatus struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+var (
+	mu         sync.Mutex
+	statuses   = map[string]ComponentStatus{}
+	generation uint64
+)
+
+// Components returns the statuses reported since the collector last loaded
+// its config, sorted by kind and ID.
+func Components() []ComponentStatus {
+	mu.Lock()
+	defer mu.Unlock()
+
+	components := make([]ComponentStatus, 0, len(statuses))
+	for _, status := range statuses {
+		components = append(components, status)
+	}
+	sort.Slice(components, func(i, j int) bool {
+		if components[i].Kind != components[j].Kind {
+			return components[i].Kind < components[j].Kind
+		}
+		return components[i].ID < components[j].ID
+	})
+	return components
+}
+
+// Failed returns the components that reported a permanent or fatal error,
+// which the collector doesn't recover from on its own.
+func Failed() []ComponentStatus {
+	var failed []ComponentStatus
+	for _, status := range Components() {
+		if status.Status == "permanent_error" || status.Status == "fatal_error" {
+			failed = append(failed, status)
+		}
+	}
+	return failed
+}
+
+// Generation counts how often the collector has started with a config. It
+// increases once a (re)loaded config's components start.
+func Generation() uint64 {
+	mu.Lock()
+	defer mu.Unlock()
+	return generation
+}
+
+// NewFactory returns the factory of the status extension.
+func NewFactory() extension.Factory {
+	return extension.NewFactory(
+		component.MustNewType(typeStr),
+		func() component.Config { return &struct{}{} },
+		func(context.Context, extension.Settings, component.Config) (extension.Extension, error) {
+			// A new extension is created for every loaded config, whose
+			// components replace the ones of the previous config
+			mu.Lock()
+			statuses = map[string]ComponentStatus{}
+			mu.Unlock()
+			return &statusExtension{}, nil
+		},
+		component.StabilityLevelAlpha,
+	)
+}
+
+type statusExtension struct{}
+
+func (e *statusExtension) Start(context.Context, component.Host) error {
+	mu.Lock()
+	generation++
+	mu.Unlock()
+	return nil
+}
+
+func (e *statusExtension) Shutdown(context.Context) error {
+	return nil
+}
+
+// ComponentStatusChanged implements componentstatus.Watcher.
+func (e *statusExtension) ComponentStatusChanged(source *componentstatus.InstanceID, event *componentstatus.Event) {
+	status := ComponentStatus{
+		ID:     source.ComponentID().String(),
+		Kind:   strings.ToLower(source.Kind().String()),
+		Status: statusName(event.Status()),
+	}
+	if err := event.Err(); err != nil {
+		status.Error = err.Error()
+	}
+
+	mu.Lock()
+	statuses[status.Kind+"/"+status.ID] = status
+	mu.Unlock()
+}
+
+func statusName(status componentstatus.Status) string {
+	switch status {
+	case componentstatus.StatusStarting:
+		return "starting"
+	case componentstatus.StatusOK:
+		return "ok"
+	case componentstatus.StatusRecoverableError:
+		return "recoverable_error"
+	case componentstatus.StatusPermanentError:
+		return "permanent_error"
+	case componentstatus.StatusFatalError:
+		return "fatal_error"
+	case componentstatus.StatusStopping:
+		return "stopping"
+	case componentstatus.StatusStopped:
+		return "stopped"
+	}
+	return "none"
+}
+
+// NewConverterFactory returns a converter that enables the status extension
+// in the configs the collector loads.
+func NewConverterFactory() confmap.ConverterFactory {
+	return confmap.NewConverterFactory(func(confmap.ConverterSettings) confmap.Converter {
+		return converter{}
+	})
+}
+
+type converter struct{}
+
+func (converter) Convert(_ context.Context, conf *confmap.Conf) error {
+	var enabled []any
+	if existing, ok := conf.Get("service::extensions").([]any); ok {
+		for _, name := range existing {
+			if name == typeStr {
+				return nil
+			}
+		}
+		enabled = append(enabled, existing...)
+	}
+	enabled = append(enabled, typeStr)
+
+	return conf.Merge(confmap.NewFromStringMap(map[string]any{
+		"extensions": map[string]any{typeStr: map[string]any{}},
+		"service":    map[string]any{"extensions": enabled},
+	}))
+}
@@ -0,0 +1,75 @@
+package collectorstatus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/extension"
+)
+
+func TestConverterAddsExtension(t *testing.T) {
+	conf := confmap.NewFromStringMap(map[string]any{
+		"extensions": map[string]any{"health_check": map[string]any{}},
+		"service": map[string]any{
+			"extensions": []any{"health_check"},
+		},
+	})
+
+	require.NoError(t, NewConverterFactory().Create(confmap.ConverterSettings{}).Convert(context.Background(), conf))
+
+	assert.Equal(t, []any{"health_check", typeStr}, conf.Get("service::extensions"))
+	assert.True(t, conf.IsSet("extensions::health_check"))
+	assert.True(t, conf.IsSet("extensions::"+typeStr))
+
+	// Converting again doesn't enable the extension twice
+	require.NoError(t, NewConverterFactory().Create(confmap.ConverterSettings{}).Convert(context.Background(), conf))
+	assert.Equal(t, []any{"health_check", typeStr}, conf.Get("service::extensions"))
+}
+
+func TestConverterWithoutExtensions(t *testing.T) {
+	conf := confmap.NewFromStringMap(map[string]any{
+		"service": map[string]any{"pipelines": map[string]any{}},
+	})
+
+	require.NoError(t, NewConverterFactory().Create(confmap.ConverterSettings{}).Convert(context.Background(), conf))
+
+	assert.Equal(t, []any{typeStr}, conf.Get("service::extensions"))
+	assert.True(t, conf.IsSet("service::pipelines"))
+}
+
+func TestExtensionTracksStatuses(t *testing.T) {
+	factory := NewFactory()
+	ext, err := factory.Create(context.Background(), extension.Settings{ID: component.NewID(factory.Type())}, factory.CreateDefaultConfig())
+	require.NoError(t, err)
+
+	before := Generation()
+	require.NoError(t, ext.Start(context.Background(), nil))
+	assert.Equal(t, before+1, Generation())
+
+	watcher := ext.(componentstatus.Watcher)
+	otlp := componentstatus.NewInstanceID(component.MustNewID("otlp"), component.KindReceiver)
+	batch := componentstatus.NewInstanceID(component.MustNewID("batch"), component.KindProcessor)
+	watcher.ComponentStatusChanged(otlp, componentstatus.NewEvent(componentstatus.StatusStarting))
+	watcher.ComponentStatusChanged(batch, componentstatus.NewEvent(componentstatus.StatusOK))
+	watcher.ComponentStatusChanged(otlp, componentstatus.NewPermanentErrorEvent(errors.New("port in use")))
+
+	assert.Equal(t, []ComponentStatus{
+		{ID: "batch", Kind: "processor", Status: "ok"},
+		{ID: "otlp", Kind: "receiver", Status: "permanent_error", Error: "port in use"},
+	}, Components())
+
+	assert.Equal(t, []ComponentStatus{
+		{ID: "otlp", Kind: "receiver", Status: "permanent_error", Error: "port in use"},
+	}, Failed())
+
+	// The extension of the next config starts without statuses
+	_, err = factory.Create(context.Background(), extension.Settings{ID: component.NewID(factory.Type())}, factory.CreateDefaultConfig())
+	require.NoError(t, err)
+	assert.Empty(t, Components())
+}
@@ -0,0 +1,165 @@
+// Package components lists the components built into the collector, so the
+// backend only lets pipelines use components the agent can run.
+package components
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/otelcol"
+	"gopkg.in/yaml.v3"
+)
+
+// Kinds of components
+const (
+	KindReceiver  = "receiver"
+	KindProcessor = "processor"
+	KindExporter  = "exporter"
+	KindConnector = "connector"
+	KindExtension = "extension"
+)
+
+// Component is a component built into the collector.
+type Component struct {
+	Kind string `json:"kind"`
+	Type string `json:"type"` // e.g. "otlp"
+	// Stability is the component's stability level per supported signal,
+	// e.g. {"traces": "stable"}. Extensions use the key "extension" and
+	// connectors "<signal>-to-<signal>".
+	Stability map[string]string `json:"stability"`
+}
+
+// stabilities builds a Stability map, leaving out unsupported signals.
+func stabilities(levels map[string]component.StabilityLevel) map[string]string {
+	result := make(map[string]string, len(levels))
+	for key, level := range levels {
+		if level != component.StabilityLevelUndefined {
+			result[key] = strings.ToLower(level.String())
+		}
+	}
+	return result
+}
+
+// FromFactories lists the components of the collector's factories.
+func FromFactories(factories otelcol.Factories) []Component {
+	var components []Component
+	for componentType, factory := range factories.Receivers {
+		components = append(components, Component{Kind: KindReceiver, Type: componentType.String(), Stability: stabilities(map[string]component.StabilityLevel{
+			"traces":  factory.TracesStability(),
+			"metrics": factory.MetricsStability(),
+			"logs":    factory.LogsStability(),
+		})})
+	}
+	for componentType, factory := range factories.Processors {
+		components = append(components, Component{Kind: KindProcessor, Type: componentType.String(), Stability: stabilities(map[string]component.StabilityLevel{
+			"traces":  factory.TracesStability(),
+			"metrics": factory.MetricsStability(),
+			"logs":    factory.LogsStability(),
+		})})
+	}
+	for componentType, factory := range factories.Exporters {
+		components = append(components, Component{Kind: KindExporter, Type: componentType.String(), Stability: stabilities(map[string]component.StabilityLevel{
+			"traces":  factory.TracesStability(),
+			"metrics": factory.MetricsStability(),
+			"logs":    factory.LogsStability(),
+		})})
+	}
+	for componentType, factory := range factories.Connectors {
+		components = append(components, Component{Kind: KindConnector, Type: componentType.String(), Stability: stabilities(map[string]component.StabilityLevel{
+			"traces-to-traces":   factory.TracesToTracesStability(),
+			"traces-to-metrics":  factory.TracesToMetricsStability(),
+			"traces-to-logs":     factory.TracesToLogsStability(),
+			"metrics-to-traces":  factory.MetricsToTracesStability(),
+			"metrics-to-metrics": factory.MetricsToMetricsStability(),
+			"metrics-to-logs":    factory.MetricsToLogsStability(),
+			"logs-to-traces":     factory.LogsToTracesStability(),
+			"logs-to-metrics":    factory.LogsToMetricsStability(),
+			"logs-to-logs":       factory.LogsToLogsStability(),
+		})})
+	}
+	for componentType, factory := range factories.Extensions {
+		components = append(components, Component{Kind: KindExtension, Type: componentType.String(), Stability: stabilities(map[string]component.StabilityLevel{
+			"extension": factory.Stability(),
+		})})
+	}
+	sortComponents(components)
+	return components
+}
+
+// componentsOutput is the part of the output of the collector's components
+// command that lists components.
+type componentsOutput struct {
+	Receivers  []listedComponent `yaml:"receivers"`
+	Processors []listedComponent `yaml:"processors"`
+	Exporters  []listedComponent `yaml:"exporters"`
+	Connectors []listedComponent `yaml:"connectors"`
+	Extensions []listedComponent `yaml:"extensions"`
+}
+
+type listedComponent struct {
+	Name      string            `yaml:"name"`
+	Stability map[string]string `yaml:"stability"`
+}
+
+// ParseComponentsOutput lists the components in the YAML output of a
+// collector binary's "components" command.
+func ParseComponentsOutput(output []byte) ([]Component, error) {
+	var parsed componentsOutput
+	if err := yaml.Unmarshal(output, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse components output: %w", err)
+	}
+
+	var components []Component
+	for _, group := range []struct {
+		kind   string
+		listed []listedComponent
+	}{
+		{KindReceiver, parsed.Receivers},
+		{KindProcessor, parsed.Processors},
+		{KindExporter, parsed.Exporters},
+		{KindConnector, parsed.Connectors},
+		{KindExtension, parsed.Extensions},
+	} {
+		for _, listed := range group.listed {
+			stability := make(map[string]string, len(listed.Stability))
+			for key, level := range listed.Stability {
+				if level = strings.ToLower(level); level != "" && level != "undefined" {
+					stability[key] = level
+				}
+			}
+			components = append(components, Component{Kind: group.kind, Type: listed.Name, Stability: stability})
+		}
+	}
+	if len(components) == 0 {
+		return nil, fmt.Errorf("components output lists no components")
+	}
+	sortComponents(components)
+	return components, nil
+}
+
+func sortComponents(components []Component) {
+	sort.Slice(components, func(i, j int) bool {
+		if components[i].Kind != components[j].Kind {
+			return components[i].Kind < components[j].Kind
+		}
+		return components[i].Type < components[j].Type
+	})
+}
+
+// catalogNameOverrides maps component types whose backend catalog name
+// doesn't follow the "<type without underscores>_<kind>" convention.
+var catalogNameOverrides = map[string]string{
+	"exporter/otlp":    "otlp_grpc_exporter",
+	"exporter/logging": "debug_exporter",
+}
+
+// CatalogName returns the component's name in the backend catalog, e.g.
+// "memorylimiter_processor" for the memory_limiter processor.
+func (c Component) CatalogName() string {
+	if name, ok := catalogNameOverrides[c.Kind+"/"+c.Type]; ok {
+		return name
+	}
+	return strings.ReplaceAll(c.Type, "_", "") + "_" + c.Kind
+}
@@ -0,0 +1,88 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/otelcol"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/batchprocessor"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/otlpreceiver"
+
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/collectorstatus"
+)
+
+func TestFromFactories(t *testing.T) {
+	factories := otelcol.Factories{
+		Receivers: map[component.Type]receiver.Factory{
+			otlpreceiver.NewFactory().Type(): otlpreceiver.NewFactory(),
+		},
+		Processors: map[component.Type]processor.Factory{
+			batchprocessor.NewFactory().Type(): batchprocessor.NewFactory(),
+		},
+		Extensions: map[component.Type]extension.Factory{
+			collectorstatus.NewFactory().Type(): collectorstatus.NewFactory(),
+		},
+	}
+
+	components := FromFactories(factories)
+	require.Len(t, components, 3)
+	assert.Equal(t, Component{Kind: KindExtension, Type: "ctrlb_status", Stability: map[string]string{"extension": "alpha"}}, components[0])
+	assert.Equal(t, KindProcessor, components[1].Kind)
+	assert.Equal(t, "batch", components[1].Type)
+	assert.Equal(t, Component{Kind: KindReceiver, Type: "otlp", Stability: map[string]string{
+		"traces": "stable", "metrics": "stable", "logs": "stable",
+	}}, components[2])
+}
+
+func TestParseComponentsOutput(t *testing.T) {
+	output := []byte(`buildinfo:
+    command: otelcol-contrib
+    version: 0.122.0
+receivers:
+    - name: otlp
+      module: go.opentelemetry.io/collector/receiver/otlpreceiver v0.122.0
+      stability:
+        logs: Beta
+        metrics: Stable
+        traces: Stable
+    - name: filelog
+      stability:
+        logs: Beta
+        metrics: Undefined
+        traces: Undefined
+connectors:
+    - name: count
+      stability:
+        logs-to-metrics: Alpha
+        traces-to-traces: Undefined
+extensions:
+    - name: health_check
+      stability:
+        extension: Beta
+providers:
+    - scheme: env
+`)
+
+	components, err := ParseComponentsOutput(output)
+	require.NoError(t, err)
+	assert.Equal(t, []Component{
+		{Kind: KindConnector, Type: "count", Stability: map[string]string{"logs-to-metrics": "alpha"}},
+		{Kind: KindExtension, Type: "health_check", Stability: map[string]string{"extension": "beta"}},
+		{Kind: KindReceiver, Type: "filelog", Stability: map[string]string{"logs": "beta"}},
+		{Kind: KindReceiver, Type: "otlp", Stability: map[string]string{"logs": "beta", "metrics": "stable", "traces": "stable"}},
+	}, components)
+
+	_, err = ParseComponentsOutput([]byte("otelcol-contrib version 0.122.0"))
+	assert.Error(t, err)
+}
+
+func TestCatalogName(t *testing.T) {
+	assert.Equal(t, "memorylimiter_processor", Component{Kind: KindProcessor, Type: "memory_limiter"}.CatalogName())
+	assert.Equal(t, "otlp_receiver", Component{Kind: KindReceiver, Type: "otlp"}.CatalogName())
+	assert.Equal(t, "otlp_grpc_exporter", Component{Kind: KindExporter, Type: "otlp"}.CatalogName())
+}
@@ -0,0 +1,118 @@
+// Package configprovider provides the collector's config from the agent's
+// config file, and lets the agent make the collector load it again. It
+// replaces reloading the collector with SIGHUP, which reaches the whole
+// process.
+package configprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const scheme = "ctrlbfile"
+
+// ErrNotWatching is returned by Reload while no collector is watching the
+// config, e.g. before it loaded it or while a reload is in progress.
+var ErrNotWatching = errors.New("OTEL collector isn't watching its config")
+
+// Provider reads YAML config files for a single collector.
+type Provider struct {
+	// Running reports whether the collector runs, as it starts closing the
+	// channel the watcher sends to once it doesn't. May be nil.
+	Running func() bool
+
+	mu      sync.Mutex
+	watcher confmap.WatcherFunc
+	closed  bool
+}
+
+// New returns a provider for one collector; each collector needs its own.
+func New() *Provider {
+	return &Provider{}
+}
+
+// URI is the config URI the collector should resolve to read filePath
+// through the provider.
+func URI(filePath string) string {
+	return scheme + ":" + filePath
+}
+
+// Factory returns a factory that always hands out this provider.
+func (p *Provider) Factory() confmap.ProviderFactory {
+	return confmap.NewProviderFactory(func(confmap.ProviderSettings) confmap.Provider {
+		return p
+	})
+}
+
+// Reload makes the collector load its config again. It returns once the
+// collector was told to; the collector reloads on its own goroutine.
+func (p *Provider) Reload() error {
+	// Holding the lock while telling the collector keeps Close from returning
+	// until it's told, so it's never told after it shut down
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed || p.watcher == nil || (p.Running != nil && !p.Running()) {
+		return ErrNotWatching
+	}
+	watcher := p.watcher
+	// The collector watches again once it retrieved the config again
+	p.watcher = nil
+	watcher(&confmap.ChangeEvent{})
+	return nil
+}
+
+// Close stops reloads for good. The agent closes the provider before it shuts
+// the collector down.
+func (p *Provider) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	p.watcher = nil
+}
+
+// Retrieve implements confmap.Provider.
+func (p *Provider) Retrieve(_ context.Context, uri string, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	if !strings.HasPrefix(uri, scheme+":") {
+		return nil, fmt.Errorf("%q uri is not supported by %q provider", uri, scheme)
+	}
+
+	content, err := os.ReadFile(strings.TrimPrefix(uri, scheme+":"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the file %v: %w", uri, err)
+	}
+
+	p.mu.Lock()
+	if !p.closed {
+		p.watcher = watcher
+	}
+	p.mu.Unlock()
+
+	return confmap.NewRetrievedFromYAML(content, confmap.WithRetrievedClose(func(context.Context) error {
+		p.stopWatching()
+		return nil
+	}))
+}
+
+// Scheme implements confmap.Provider.
+func (p *Provider) Scheme() string {
+	return scheme
+}
+
+// Shutdown implements confmap.Provider.
+func (p *Provider) Shutdown(context.Context) error {
+	p.Close()
+	return nil
+}
+
+func (p *Provider) stopWatching() {
+	p.mu.Lock()
+	p.watcher = nil
+	p.mu.Unlock()
+}
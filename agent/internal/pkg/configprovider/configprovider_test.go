@@ -0,0 +1,99 @@
+package configprovider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func newResolver(t *testing.T, provider *Provider, filePath string) *confmap.Resolver {
+	resolver, err := confmap.NewResolver(confmap.ResolverSettings{
+		URIs:              []string{URI(filePath)},
+		ProviderFactories: []confmap.ProviderFactory{provider.Factory()},
+	})
+	require.NoError(t, err)
+	return resolver
+}
+
+func TestReload(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte("receivers:\n  otlp: {}\n"), 0644))
+
+	provider := New()
+	resolver := newResolver(t, provider, filePath)
+
+	// Nothing to reload before the config was loaded
+	assert.ErrorIs(t, provider.Reload(), ErrNotWatching)
+
+	conf, err := resolver.Resolve(context.Background())
+	require.NoError(t, err)
+	assert.True(t, conf.IsSet("receivers::otlp"))
+
+	require.NoError(t, provider.Reload())
+	assert.NoError(t, <-resolver.Watch())
+	// A reload is only requested once until the config is loaded again
+	assert.ErrorIs(t, provider.Reload(), ErrNotWatching)
+
+	require.NoError(t, os.WriteFile(filePath, []byte("exporters:\n  debug: {}\n"), 0644))
+	conf, err = resolver.Resolve(context.Background())
+	require.NoError(t, err)
+	assert.True(t, conf.IsSet("exporters::debug"))
+	assert.False(t, conf.IsSet("receivers::otlp"))
+
+	require.NoError(t, provider.Reload())
+	assert.NoError(t, <-resolver.Watch())
+}
+
+func TestReloadAfterShutdown(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte("receivers:\n  otlp: {}\n"), 0644))
+
+	provider := New()
+	resolver := newResolver(t, provider, filePath)
+
+	_, err := resolver.Resolve(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, resolver.Shutdown(context.Background()))
+
+	assert.ErrorIs(t, provider.Reload(), ErrNotWatching)
+}
+
+func TestRetrieveMissingFile(t *testing.T) {
+	provider := New()
+	resolver := newResolver(t, provider, filepath.Join(t.TempDir(), "missing.yaml"))
+
+	_, err := resolver.Resolve(context.Background())
+	assert.Error(t, err)
+	assert.ErrorIs(t, provider.Reload(), ErrNotWatching)
+}
+
+func TestReloadAfterClose(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte("receivers:\n  otlp: {}\n"), 0644))
+
+	provider := New()
+	resolver := newResolver(t, provider, filePath)
+
+	_, err := resolver.Resolve(context.Background())
+	require.NoError(t, err)
+	provider.Close()
+	assert.ErrorIs(t, provider.Reload(), ErrNotWatching)
+
+	// A collector that shut down isn't told either, even without Close
+	provider = New()
+	running := true
+	provider.Running = func() bool { return running }
+	resolver = newResolver(t, provider, filePath)
+	_, err = resolver.Resolve(context.Background())
+	require.NoError(t, err)
+	running = false
+	assert.ErrorIs(t, provider.Reload(), ErrNotWatching)
+	running = true
+	require.NoError(t, provider.Reload())
+	assert.NoError(t, <-resolver.Watch())
+}
@@ -8,16 +8,28 @@ import (
 
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/adapters"
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/client"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/config"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/collectorstatus"
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/logger"
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/tap"
 	"github.com/fsnotify/fsnotify"
 )
 
+// reportDelay is how long the config file has to stay unchanged before its
+// hash is reported. Configs are saved by removing and rewriting the file, so
+// reporting every event would report a missing config in between.
+const reportDelay = 2 * time.Second
+
 type FileWatcher struct {
-	filePath string
-	adapter  adapters.Adapter
-	watcher  *fsnotify.Watcher
-	done     chan struct{}
-	wg       sync.WaitGroup
+	filePath    string
+	adapter     adapters.Adapter
+	watcher     *fsnotify.Watcher
+	done        chan struct{}
+	wg          sync.WaitGroup
+	reportTimer *time.Timer
+	// restoredHash is the hash of the config a rollback wrote, whose file
+	// events don't need another reload
+	restoredHash string
 }
 
 func NewFileWatcher(filePath string, adapter adapters.Adapter) (*FileWatcher, error) {
@@ -36,18 +48,135 @@ func NewFileWatcher(filePath string, adapter adapters.Adapter) (*FileWatcher, er
 
 func (fw *FileWatcher) onFileChange() {
 	logger.Logger.Info(fmt.Sprintf("Config modified in path: %s, restarting engine...", fw.filePath))
-	fw.adapter.UpdateConfig()
+	fw.applyConfig()
 }
 
 func (fw *FileWatcher) onFileRecreated() {
 	logger.Logger.Info(fmt.Sprintf("Config recreated: %s", fw.filePath))
-	fw.adapter.UpdateConfig()
+	fw.applyConfig()
+}
+
+// applyConfig makes the collector load the config file and reports to the
+// backend whether it could, with the statuses of the config's components. A
+// config that fails is replaced by the last-known-good config. A running tap
+// isn't part of the reported config, nor kept as last-known-good.
+func (fw *FileWatcher) applyConfig() {
+	var hash, fileHash string
+	tapped := false
+	if cfg, err := config.LoadFromYAML(fw.filePath); err == nil {
+		fileHash, _ = config.Hash(cfg)
+		hash, _ = config.Hash(tap.Remove(cfg))
+		tapped = tap.Contains(cfg)
+	}
+	if fileHash != "" && fileHash == fw.restoredHash {
+		// Written by the rollback, which already restarted the collector
+		return
+	}
+	fw.restoredHash = ""
+
+	report := client.ConfigApplyReport{ConfigHash: hash, Status: client.ConfigApplied}
+	err := fw.adapter.UpdateConfig()
+	report.Components = collectorstatus.Components()
+	if err == nil {
+		// The last-known-good config stays the one without the tap
+		if !tapped {
+			if err := config.SaveLastGood(fw.filePath); err != nil {
+				logger.Logger.Error(fmt.Sprintf("Failed to keep last-known-good config: %v", err))
+			}
+		}
+	} else {
+		logger.Logger.Error(fmt.Sprintf("Failed to apply config %s: %v", fw.filePath, err))
+		report.Status = client.ConfigFailed
+		report.Error = err.Error()
+
+		if err := fw.rollback(fileHash); err != nil {
+			logger.Logger.Error(fmt.Sprintf("Failed to roll back config %s: %v", fw.filePath, err))
+			report.Error += "; rollback failed: " + err.Error()
+		} else {
+			report.RolledBack = true
+		}
+	}
+	report.Time = time.Now().Format(time.RFC3339)
+
+	if err := client.ReportConfigApply(nil, report); err != nil {
+		logger.Logger.Error(fmt.Sprintf("Failed to report config apply to backend: %v", err))
+	}
+}
+
+// rollback restores the last-known-good config over the failed config and
+// restarts the collector with it.
+func (fw *FileWatcher) rollback(failedHash string) error {
+	lastGood, err := config.LoadFromYAML(config.LastGoodPath(fw.filePath))
+	if err != nil {
+		return fmt.Errorf("no last-known-good config: %w", err)
+	}
+	lastGoodHash, err := config.Hash(lastGood)
+	if err != nil {
+		return err
+	}
+	if lastGoodHash == failedHash {
+		return fmt.Errorf("the last-known-good config is the one that failed")
+	}
+
+	if err := config.RestoreLastGood(fw.filePath); err != nil {
+		return err
+	}
+	fw.restoredHash = lastGoodHash
+	if err := fw.adapter.UpdateConfig(); err != nil {
+		return fmt.Errorf("collector failed with the last-known-good config too: %w", err)
+	}
+
+	logger.Logger.Warn(fmt.Sprintf("Rolled back %s to the last-known-good config", fw.filePath))
+	return nil
+}
+
+// KeepBootConfig saves the config the collector started with as the
+// last-known-good config, so the first failed push after startup has a config
+// to roll back to. A config with a tap in it isn't kept.
+func KeepBootConfig(filePath string) {
+	cfg, err := config.LoadFromYAML(filePath)
+	if err != nil || tap.Contains(cfg) {
+		return
+	}
+	if err := config.SaveLastGood(filePath); err != nil {
+		logger.Logger.Error(fmt.Sprintf("Failed to keep last-known-good config: %v", err))
+	}
 }
 
 func (fw *FileWatcher) handleFileDeletionError() {
 	logger.Logger.Error(fmt.Sprintf("Warning: Config no longer exists: %s", fw.filePath))
 }
 
+// scheduleReport reports the effective config once the file settles.
+func (fw *FileWatcher) scheduleReport() {
+	if fw.reportTimer == nil {
+		fw.reportTimer = time.AfterFunc(reportDelay, fw.reportEffectiveConfig)
+		return
+	}
+	fw.reportTimer.Reset(reportDelay)
+}
+
+// reportEffectiveConfig sends the hash of the config file to the backend. A
+// missing file is reported with an empty hash.
+func (fw *FileWatcher) reportEffectiveConfig() {
+	var hash string
+	cfg, err := config.LoadFromYAML(fw.filePath)
+	switch {
+	case err == nil:
+		if hash, err = config.Hash(tap.Remove(cfg)); err != nil {
+			logger.Logger.Error(fmt.Sprintf("Failed to hash config %s: %v", fw.filePath, err))
+			return
+		}
+	case !os.IsNotExist(err):
+		logger.Logger.Error(fmt.Sprintf("Failed to read config %s: %v", fw.filePath, err))
+		return
+	}
+
+	if err := client.InformBackendConfigFileChanged(nil, hash); err != nil {
+		logger.Logger.Error(fmt.Sprintf("Failed to report config to backend: %v", err))
+	}
+}
+
 func (fw *FileWatcher) Start() error {
 	if err := fw.watcher.Add(fw.filePath); err != nil {
 		return err
@@ -62,6 +191,9 @@ func (fw *FileWatcher) Start() error {
 func (fw *FileWatcher) Stop() {
 	close(fw.done)
 	fw.wg.Wait()
+	if fw.reportTimer != nil {
+		fw.reportTimer.Stop()
+	}
 	fw.watcher.Close()
 }
 
@@ -81,7 +213,7 @@ func (fw *FileWatcher) watchLoop() {
 				return
 			}
 
-			client.InformBackendConfigFileChanged(nil)
+			fw.scheduleReport()
 
 			switch {
 			case event.Op&fsnotify.Write == fsnotify.Write && fileExists:
@@ -105,6 +237,7 @@ func (fw *FileWatcher) watchLoop() {
 					fileExists = true
 					if err := fw.watcher.Add(fw.filePath); err == nil {
 						fw.onFileRecreated()
+						fw.scheduleReport()
 					}
 				}
 			}
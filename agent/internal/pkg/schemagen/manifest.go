@@ -0,0 +1,38 @@
+package schemagen
+
+import (
+	"bytes"
+	"go/format"
+	"text/template"
+)
+
+var manifestTemplate = template.Must(template.New("manifest").Parse(`// Code generated by schemagen from the agent's collector components. DO NOT EDIT.
+
+package database
+
+func GetComponentTypeMap() map[string]string {
+	return map[string]string{
+{{- range .}}
+		{{printf "%q" .Name}}: {{printf "%q" .Kind}},
+{{- end}}
+	}
+}
+
+func GetSignalSupportMap() map[string][]string {
+	return map[string][]string{
+{{- range .}}
+		{{printf "%q" .Name}}: { {{- range $i, $signal := .Signals}}{{if $i}}, {{end}}{{printf "%q" $signal}}{{end -}} },
+{{- end}}
+	}
+}
+`))
+
+// Manifest renders the backend's schema manifest, which maps every catalog
+// component to its kind and supported signals.
+func Manifest(entries []Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := manifestTemplate.Execute(&buf, entries); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,450 @@
+// Package schemagen generates the backend's component JSON Schemas and
+// starter UI schemas from the default configs of the collector's component
+// factories, so adding a component to the catalog is a matter of registering
+// its factory and regenerating.
+package schemagen
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/otelcol"
+
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/components"
+)
+
+// Entry is a component of the backend catalog.
+type Entry struct {
+	Name    string // catalog name, e.g. "batch_processor"
+	Kind    string
+	Title   string   // e.g. "Batch Processor Configuration"
+	Signals []string // traces, metrics and logs, in that order, as supported
+	Factory component.Factory
+}
+
+// Entries lists the receivers, processors and exporters of the factories,
+// sorted by catalog name. Connectors and extensions aren't pipeline nodes, so
+// the catalog doesn't hold them.
+func Entries(factories otelcol.Factories) []Entry {
+	byID := make(map[string]component.Factory)
+	for componentType, factory := range factories.Receivers {
+		byID[components.KindReceiver+"/"+componentType.String()] = factory
+	}
+	for componentType, factory := range factories.Processors {
+		byID[components.KindProcessor+"/"+componentType.String()] = factory
+	}
+	for componentType, factory := range factories.Exporters {
+		byID[components.KindExporter+"/"+componentType.String()] = factory
+	}
+
+	var entries []Entry
+	for _, c := range components.FromFactories(factories) {
+		factory, ok := byID[c.Kind+"/"+c.Type]
+		if !ok {
+			continue
+		}
+		var signals []string
+		for _, signal := range []string{"traces", "metrics", "logs"} {
+			if _, ok := c.Stability[signal]; ok {
+				signals = append(signals, signal)
+			}
+		}
+		entries = append(entries, Entry{
+			Name:    c.CatalogName(),
+			Kind:    c.Kind,
+			Title:   humanize(c.Type) + " " + humanize(c.Kind) + " Configuration",
+			Signals: signals,
+			Factory: factory,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// field is a config field in declaration order, which maps lose.
+type field struct {
+	key  string
+	node *node
+}
+
+// node is the schema of a config value together with its fields, so UI
+// schemas can list them in the order the config struct declares them.
+type node struct {
+	schema map[string]any
+	fields []field
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	opaqueType          = reflect.TypeOf(configopaque.String(""))
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Generate returns the JSON Schema of a component config, with the values of
+// the given default config as defaults, and a starter UI schema for it.
+func Generate(title string, defaultConfig component.Config) (schema, uiSchema map[string]any) {
+	root := walk(reflect.TypeOf(defaultConfig), reflect.ValueOf(defaultConfig), map[reflect.Type]bool{})
+	root.schema["title"] = title
+	return root.schema, uiSchemaFor(root, root.schema)
+}
+
+// walk builds the schema of values of type t. v holds the default value and
+// may be invalid when there is none.
+func walk(t reflect.Type, v reflect.Value, seen map[reflect.Type]bool) *node {
+	if t == nil {
+		return &node{schema: map[string]any{}}
+	}
+	if t.Kind() == reflect.Pointer {
+		var elem reflect.Value
+		if v.IsValid() && !v.IsNil() {
+			elem = v.Elem()
+		}
+		return walk(t.Elem(), elem, seen)
+	}
+
+	schema := map[string]any{}
+	if def, ok := defaultValue(v); ok {
+		schema["default"] = def
+	}
+
+	switch {
+	case t == durationType:
+		schema["type"] = "string"
+		return &node{schema: schema}
+	case t == opaqueType:
+		schema["type"] = "string"
+		schema["writeOnly"] = true
+		return &node{schema: schema}
+	case t.Kind() != reflect.String && reflect.PointerTo(t).Implements(textUnmarshalerType):
+		// Types such as component IDs and log levels are written as strings
+		schema["type"] = "string"
+		return &node{schema: schema}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		schema["type"] = "string"
+	case reflect.Bool:
+		schema["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema["type"] = "integer"
+	case reflect.Float32, reflect.Float64:
+		schema["type"] = "number"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			schema["type"] = "string"
+			break
+		}
+		schema["type"] = "array"
+		schema["items"] = walk(t.Elem(), reflect.Value{}, seen).schema
+	case reflect.Map:
+		schema["type"] = "object"
+		schema["additionalProperties"] = walk(t.Elem(), reflect.Value{}, seen).schema
+	case reflect.Struct:
+		schema["type"] = "object"
+		if seen[t] {
+			// Recursive config types are left open below the first level
+			return &node{schema: schema}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		n := &node{schema: schema}
+		walkFields(n, t, v, seen)
+		if len(n.fields) > 0 {
+			properties := make(map[string]any, len(n.fields))
+			for _, f := range n.fields {
+				f.node.schema["title"] = humanize(f.key)
+				properties[f.key] = f.node.schema
+			}
+			schema["properties"] = properties
+		}
+		return n
+	}
+	// Interfaces and anything else accept any value
+	return &node{schema: schema}
+}
+
+// walkFields adds the fields of struct type t to n, the way mapstructure
+// decodes them: squashed fields are inlined and "-" fields skipped. Fields
+// without a mapstructure tag fall back to their yaml tag, which configs
+// unmarshaled through YAML (e.g. Prometheus scrape configs) use.
+func walkFields(n *node, t reflect.Type, v reflect.Value, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+		switch structField.Type.Kind() {
+		case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+			continue
+		}
+
+		tag, ok := structField.Tag.Lookup("mapstructure")
+		if !ok {
+			tag = strings.Replace(structField.Tag.Get("yaml"), "inline", "squash", 1)
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+
+		var fieldValue reflect.Value
+		if v.IsValid() {
+			fieldValue = v.Field(i)
+		}
+
+		if strings.Contains(options, "squash") {
+			fieldType := structField.Type
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+				if fieldValue.IsValid() {
+					if fieldValue.IsNil() {
+						fieldValue = reflect.Value{}
+					} else {
+						fieldValue = fieldValue.Elem()
+					}
+				}
+			}
+			if fieldType.Kind() == reflect.Struct {
+				walkFields(n, fieldType, fieldValue, seen)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = structField.Name
+		}
+		n.fields = append(n.fields, field{key: name, node: walk(structField.Type, fieldValue, seen)})
+	}
+}
+
+// defaultValue returns the JSON value of a non-zero default. Structs have
+// their defaults on their fields instead.
+func defaultValue(v reflect.Value) (any, bool) {
+	if !v.IsValid() || v.IsZero() {
+		return nil, false
+	}
+
+	t := v.Type()
+	switch {
+	case t == durationType:
+		return time.Duration(v.Int()).String(), true
+	case t == opaqueType:
+		// Never publish secrets, not even default ones
+		return nil, false
+	case t.Kind() == reflect.String:
+		return v.String(), true
+	case t.Implements(textMarshalerType):
+		if text, err := v.Interface().(encoding.TextMarshaler).MarshalText(); err == nil {
+			return string(text), true
+		}
+		return nil, false
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return defaultValue(v.Elem())
+	case reflect.Bool:
+		return v.Bool(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nil, false
+		}
+		values := make([]any, v.Len())
+		for i := range values {
+			value, ok := defaultValue(v.Index(i))
+			if !ok {
+				return nil, false
+			}
+			values[i] = value
+		}
+		return values, true
+	case reflect.Map:
+		values := make(map[string]any, v.Len())
+		for _, key := range v.MapKeys() {
+			name, ok := defaultValue(key)
+			value, valueOk := defaultValue(v.MapIndex(key))
+			if !ok || !valueOk {
+				return nil, false
+			}
+			values[fmt.Sprint(name)] = value
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// uiSchemaFor returns a JSON Forms layout with a control per config field.
+// Nested objects become groups; labels are taken from the schema titles.
+func uiSchemaFor(root *node, schema map[string]any) map[string]any {
+	return map[string]any{
+		"type":     "VerticalLayout",
+		"elements": uiElements(root, schema, "#"),
+	}
+}
+
+func uiElements(n *node, schema map[string]any, scope string) []any {
+	properties, _ := schema["properties"].(map[string]any)
+	elements := []any{}
+	for _, f := range n.fields {
+		propertySchema, _ := properties[f.key].(map[string]any)
+		if propertySchema == nil {
+			continue
+		}
+		label, _ := propertySchema["title"].(string)
+		fieldScope := scope + "/properties/" + f.key
+
+		if len(f.node.fields) > 0 {
+			elements = append(elements, map[string]any{
+				"type":     "Group",
+				"label":    label,
+				"elements": uiElements(f.node, propertySchema, fieldScope),
+			})
+			continue
+		}
+
+		control := map[string]any{
+			"type":  "Control",
+			"scope": fieldScope,
+			"label": label,
+		}
+		if writeOnly, _ := propertySchema["writeOnly"].(bool); writeOnly {
+			control["options"] = map[string]any{"format": "password"}
+		}
+		elements = append(elements, control)
+	}
+	return elements
+}
+
+// preservedKeywords are schema annotations that can't be derived from config
+// structs. Merge keeps them from hand-written schemas. "version" is the
+// backend's schema version, bumped by hand along with a config migration.
+var preservedKeywords = []string{
+	"version", "title", "description", "enum", "examples", "format", "pattern",
+	"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum",
+	"minLength", "maxLength", "minItems", "maxItems",
+}
+
+// Merge copies the hand-written annotations of an existing schema into the
+// generated one, for every property both have. Required properties are kept
+// as long as they still exist.
+func Merge(generated, existing map[string]any) {
+	for _, keyword := range preservedKeywords {
+		if value, ok := existing[keyword]; ok {
+			generated[keyword] = value
+		}
+	}
+
+	generatedProperties, _ := generated["properties"].(map[string]any)
+	existingProperties, _ := existing["properties"].(map[string]any)
+	if required, ok := existing["required"].([]any); ok {
+		var kept []any
+		for _, name := range required {
+			if key, ok := name.(string); ok && generatedProperties[key] != nil {
+				kept = append(kept, key)
+			}
+		}
+		if len(kept) > 0 {
+			generated["required"] = kept
+		}
+	}
+
+	for key, value := range generatedProperties {
+		generatedProperty, _ := value.(map[string]any)
+		existingProperty, _ := existingProperties[key].(map[string]any)
+		if generatedProperty != nil && existingProperty != nil {
+			Merge(generatedProperty, existingProperty)
+		}
+	}
+	for _, keyword := range []string{"items", "additionalProperties"} {
+		generatedChild, _ := generated[keyword].(map[string]any)
+		existingChild, _ := existing[keyword].(map[string]any)
+		if generatedChild != nil && existingChild != nil {
+			Merge(generatedChild, existingChild)
+		}
+	}
+}
+
+// Write generates the schemas of the entries into the schemas and ui_schemas
+// directories of assetsDir. Existing schemas are regenerated but keep their
+// hand-written annotations; existing UI schemas are left alone.
+func Write(entries []Entry, assetsDir string) error {
+	for _, entry := range entries {
+		schema, uiSchema := Generate(entry.Title, entry.Factory.CreateDefaultConfig())
+
+		schemaPath := filepath.Join(assetsDir, "schemas", entry.Name+".json")
+		existing, err := os.ReadFile(schemaPath)
+		switch {
+		case err == nil:
+			var existingSchema map[string]any
+			if err := json.Unmarshal(existing, &existingSchema); err != nil {
+				return fmt.Errorf("invalid JSON in %s: %w", schemaPath, err)
+			}
+			Merge(schema, existingSchema)
+		case !errors.Is(err, os.ErrNotExist):
+			return err
+		}
+		if err := writeJSON(schemaPath, schema); err != nil {
+			return err
+		}
+
+		uiSchemaPath := filepath.Join(assetsDir, "ui_schemas", entry.Name+".json")
+		if _, err := os.Stat(uiSchemaPath); err == nil {
+			continue
+		}
+		if err := writeJSON(uiSchemaPath, uiSchema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSON(path string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// initialisms are words humanize spells out in a specific case.
+var initialisms = map[string]string{
+	"api": "API", "aws": "AWS", "ca": "CA", "cpu": "CPU", "grpc": "gRPC", "http": "HTTP",
+	"id": "ID", "mib": "MiB", "otlp": "OTLP", "tls": "TLS", "ttl": "TTL", "url": "URL",
+}
+
+// humanize turns a config key or component type into a title, e.g.
+// "send_batch_size" -> "Send Batch Size" and "tls" -> "TLS".
+func humanize(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool { return r == '_' || r == '-' || r == '.' })
+	for i, word := range words {
+		if initialism, ok := initialisms[strings.ToLower(word)]; ok {
+			words[i] = initialism
+			continue
+		}
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,211 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/otlpexporter"
+	"go.opentelemetry.io/collector/otelcol"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/batchprocessor"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/otlpreceiver"
+)
+
+type tlsConfig struct {
+	CAFile   string `mapstructure:"ca_file"`
+	Insecure bool   `mapstructure:"insecure"`
+}
+
+type ClientConfig struct {
+	Endpoint string    `mapstructure:"endpoint"`
+	TLS      tlsConfig `mapstructure:"tls"`
+}
+
+type testConfig struct {
+	ClientConfig `mapstructure:",squash"`
+	Timeout      time.Duration       `mapstructure:"timeout"`
+	APIKey       configopaque.String `mapstructure:"api_key"`
+	Retries      *int                `mapstructure:"retries"`
+	Headers      map[string]string   `mapstructure:"headers"`
+	Exporters    []component.ID      `mapstructure:"exporters"`
+	Ratio        float64             `mapstructure:"ratio"`
+	Internal     string              `mapstructure:"-"`
+	unexported   string
+}
+
+func TestGenerate(t *testing.T) {
+	retries := 3
+	schema, uiSchema := Generate("Test Exporter Configuration", &testConfig{
+		ClientConfig: ClientConfig{Endpoint: "localhost:4317"},
+		Timeout:      5 * time.Second,
+		APIKey:       "hunter2",
+		Retries:      &retries,
+		Headers:      map[string]string{"x-team": "infra"},
+		Exporters:    []component.ID{component.MustNewID("otlp")},
+	})
+
+	assert.Equal(t, "Test Exporter Configuration", schema["title"])
+	assert.Equal(t, "object", schema["type"])
+	properties := schema["properties"].(map[string]any)
+	assert.Len(t, properties, 8)
+
+	assert.Equal(t, map[string]any{"type": "string", "title": "Endpoint", "default": "localhost:4317"}, properties["endpoint"])
+	assert.Equal(t, map[string]any{"type": "string", "title": "Timeout", "default": "5s"}, properties["timeout"])
+	assert.Equal(t, map[string]any{"type": "string", "title": "API Key", "writeOnly": true}, properties["api_key"])
+	assert.Equal(t, map[string]any{"type": "integer", "title": "Retries", "default": int64(3)}, properties["retries"])
+	assert.Equal(t, map[string]any{"type": "number", "title": "Ratio"}, properties["ratio"])
+	assert.Equal(t, map[string]any{
+		"type": "object", "title": "Headers",
+		"additionalProperties": map[string]any{"type": "string"},
+		"default":              map[string]any{"x-team": "infra"},
+	}, properties["headers"])
+	assert.Equal(t, map[string]any{
+		"type": "array", "title": "Exporters",
+		"items":   map[string]any{"type": "string"},
+		"default": []any{"otlp"},
+	}, properties["exporters"])
+	tls := properties["tls"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "boolean", "title": "Insecure"}, tls["properties"].(map[string]any)["insecure"])
+
+	elements := uiSchema["elements"].([]any)
+	assert.Equal(t, "VerticalLayout", uiSchema["type"])
+	assert.Equal(t, map[string]any{"type": "Control", "scope": "#/properties/endpoint", "label": "Endpoint"}, elements[0])
+	assert.Equal(t, map[string]any{"type": "Group", "label": "TLS", "elements": []any{
+		map[string]any{"type": "Control", "scope": "#/properties/tls/properties/ca_file", "label": "CA File"},
+		map[string]any{"type": "Control", "scope": "#/properties/tls/properties/insecure", "label": "Insecure"},
+	}}, elements[1])
+	assert.Equal(t, map[string]any{"type": "Control", "scope": "#/properties/api_key", "label": "API Key",
+		"options": map[string]any{"format": "password"}}, elements[3])
+}
+
+func TestGenerateFromFactory(t *testing.T) {
+	schema, _ := Generate("Batch Processor Configuration", batchprocessor.NewFactory().CreateDefaultConfig())
+
+	properties := schema["properties"].(map[string]any)
+	assert.Equal(t, "200ms", properties["timeout"].(map[string]any)["default"])
+	assert.Equal(t, "integer", properties["send_batch_size"].(map[string]any)["type"])
+	assert.Equal(t, uint64(8192), properties["send_batch_size"].(map[string]any)["default"])
+	assert.Equal(t, map[string]any{"type": "string"}, properties["metadata_keys"].(map[string]any)["items"])
+}
+
+type yamlConfig struct {
+	Global struct {
+		ScrapeInterval string `yaml:"scrape_interval,omitempty"`
+	} `yaml:"global"`
+	Inlined struct {
+		JobName string `yaml:"job_name"`
+	} `yaml:",inline"`
+	Skipped string `yaml:"-"`
+}
+
+func TestGenerateYAMLTags(t *testing.T) {
+	schema, _ := Generate("Scrape Configuration", &yamlConfig{})
+
+	properties := schema["properties"].(map[string]any)
+	assert.Len(t, properties, 2)
+	assert.Contains(t, properties, "job_name")
+	global := properties["global"].(map[string]any)
+	assert.Contains(t, global["properties"], "scrape_interval")
+}
+
+func TestMerge(t *testing.T) {
+	generated := map[string]any{
+		"title": "Batch Processor Configuration",
+		"type":  "object",
+		"properties": map[string]any{
+			"timeout":         map[string]any{"type": "string", "title": "Timeout", "default": "200ms"},
+			"send_batch_size": map[string]any{"type": "integer", "title": "Send Batch Size"},
+		},
+	}
+	var existing map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"title": "Batch Processor",
+		"version": 2,
+		"type": "object",
+		"properties": {
+			"timeout": {"type": "string", "default": "5s", "description": "Maximum time to wait.", "minLength": 1},
+			"removed": {"type": "string", "description": "Gone from the collector."}
+		},
+		"required": ["timeout", "removed"]
+	}`), &existing))
+
+	Merge(generated, existing)
+	assert.Equal(t, "Batch Processor", generated["title"])
+	assert.Equal(t, float64(2), generated["version"])
+	assert.Equal(t, []any{"timeout"}, generated["required"])
+	assert.Equal(t, map[string]any{
+		"type": "string", "title": "Timeout", "default": "200ms",
+		"description": "Maximum time to wait.", "minLength": float64(1),
+	}, generated["properties"].(map[string]any)["timeout"])
+	assert.NotContains(t, generated["properties"], "removed")
+}
+
+func testFactories() otelcol.Factories {
+	return otelcol.Factories{
+		Receivers: map[component.Type]receiver.Factory{
+			otlpreceiver.NewFactory().Type(): otlpreceiver.NewFactory(),
+		},
+		Processors: map[component.Type]processor.Factory{
+			batchprocessor.NewFactory().Type(): batchprocessor.NewFactory(),
+		},
+		Exporters: map[component.Type]exporter.Factory{
+			otlpexporter.NewFactory().Type(): otlpexporter.NewFactory(),
+		},
+	}
+}
+
+func TestEntries(t *testing.T) {
+	entries := Entries(testFactories())
+	require.Len(t, entries, 3)
+	assert.Equal(t, "batch_processor", entries[0].Name)
+	assert.Equal(t, "Batch Processor Configuration", entries[0].Title)
+	assert.Equal(t, "otlp_grpc_exporter", entries[1].Name)
+	assert.Equal(t, "exporter", entries[1].Kind)
+	assert.Equal(t, "otlp_receiver", entries[2].Name)
+	assert.Equal(t, "OTLP Receiver Configuration", entries[2].Title)
+	assert.Equal(t, []string{"traces", "metrics", "logs"}, entries[2].Signals)
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "schemas"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "ui_schemas"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schemas", "batch_processor.json"),
+		[]byte(`{"title": "Batch Processor", "properties": {"timeout": {"description": "Hand-written."}}}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ui_schemas", "batch_processor.json"), []byte(`{"type": "Group"}`), 0o644))
+
+	require.NoError(t, Write(Entries(testFactories()), dir))
+
+	var schema map[string]any
+	data, err := os.ReadFile(filepath.Join(dir, "schemas", "batch_processor.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &schema))
+	assert.Equal(t, "Batch Processor", schema["title"])
+	timeout := schema["properties"].(map[string]any)["timeout"].(map[string]any)
+	assert.Equal(t, "Hand-written.", timeout["description"])
+	assert.Equal(t, "200ms", timeout["default"])
+
+	data, err = os.ReadFile(filepath.Join(dir, "ui_schemas", "batch_processor.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"type": "Group"}`, string(data))
+
+	assert.FileExists(t, filepath.Join(dir, "schemas", "otlp_receiver.json"))
+	assert.FileExists(t, filepath.Join(dir, "ui_schemas", "otlp_receiver.json"))
+}
+
+func TestManifest(t *testing.T) {
+	manifest, err := Manifest(Entries(testFactories()))
+	require.NoError(t, err)
+	assert.Contains(t, string(manifest), "// Code generated by schemagen")
+	assert.Contains(t, string(manifest), `"otlp_grpc_exporter": "exporter",`)
+	assert.Contains(t, string(manifest), `"batch_processor":    {"traces", "metrics", "logs"},`)
+}
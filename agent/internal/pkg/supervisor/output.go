@@ -0,0 +1,60 @@
+package supervisor
+
+import (
+	"bytes"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// maxLineLength bounds the output kept for a line without a newline yet.
+const maxLineLength = 64 * 1024
+
+// lineWriter logs the collector's output line by line and remembers the last
+// line, which usually tells why the collector exited.
+type lineWriter struct {
+	log *zap.Logger
+
+	mu       sync.Mutex
+	buf      []byte
+	lastLine string
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.logLine(string(bytes.TrimRight(w.buf[:i], "\r")))
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) > maxLineLength {
+		w.logLine(string(w.buf))
+		w.buf = nil
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) logLine(line string) {
+	if line == "" {
+		return
+	}
+	w.lastLine = line
+	w.log.Info(line)
+}
+
+// last returns the last line written, including one without a newline.
+func (w *lineWriter) last() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.logLine(string(w.buf))
+		w.buf = nil
+	}
+	return w.lastLine
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package supervisor
+
+import (
+	"errors"
+	"syscall"
+)
+
+func sysProcAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+// residentMemory isn't supported outside Linux, so the memory limit is only
+// enforced through the collector's Go runtime there.
+func residentMemory(int) (int64, error) {
+	return 0, errors.New("reading process memory is only supported on linux")
+}
@@ -0,0 +1,293 @@
+// Package supervisor runs the collector as a child process of the agent. It
+// restarts the collector with backoff when it exits, forwards its output to
+// the agent's logger and enforces resource limits, so a crashing collector
+// doesn't take the agent down with it.
+package supervisor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMinBackoff = time.Second
+	defaultMaxBackoff = time.Minute
+	// stableAfter is how long the collector has to run before its next exit
+	// restarts it without the backoff of earlier exits.
+	stableAfter = 30 * time.Second
+	// stopTimeout is how long the collector may take to shut down before it
+	// is killed.
+	stopTimeout = 10 * time.Second
+	// memoryCheckInterval is how often the collector's memory is checked
+	// against the limit.
+	memoryCheckInterval = time.Second
+)
+
+var (
+	ErrAlreadyRunning = errors.New("collector process already supervised")
+	ErrNotRunning     = errors.New("collector process not running")
+)
+
+// Config tells how to run the collector.
+type Config struct {
+	Binary string
+	Args   []string
+	// MemoryLimitMiB caps the collector's memory. The collector's Go runtime
+	// is asked to stay below it, and the collector is restarted when its
+	// resident memory exceeds it anyway. Zero means no limit.
+	MemoryLimitMiB int
+	// CPULimit caps how many CPUs the collector uses at once. Zero means no
+	// limit.
+	CPULimit   int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// Status describes the supervised collector.
+type Status struct {
+	Supervised bool      `json:"supervised"`
+	Running    bool      `json:"running"`
+	PID        int       `json:"pid,omitempty"`
+	StartedAt  time.Time `json:"started_at,omitempty"`
+	// Exits counts how often the collector exited or failed to start while
+	// supervised.
+	Exits    int    `json:"exits"`
+	LastExit string `json:"last_exit,omitempty"`
+}
+
+// Supervisor keeps one collector process running.
+type Supervisor struct {
+	cfg Config
+	log *zap.Logger
+
+	mu       sync.Mutex
+	status   Status
+	process  *os.Process
+	stop     chan struct{}
+	done     chan struct{}
+	killedBy string // why the supervisor killed the running process
+}
+
+// New returns a supervisor for the collector. The collector's output is
+// logged to log, which may be nil.
+func New(cfg Config, log *zap.Logger) *Supervisor {
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = defaultMinBackoff
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = max(defaultMaxBackoff, cfg.MinBackoff)
+	}
+	if log == nil {
+		log = zap.NewNop()
+	}
+	return &Supervisor{cfg: cfg, log: log.Named("collector")}
+}
+
+// Start launches the collector and keeps restarting it until Stop.
+func (s *Supervisor) Start() error {
+	if s.cfg.Binary == "" {
+		return fmt.Errorf("no collector binary configured")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return ErrAlreadyRunning
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	s.status.Supervised = true
+	go s.run(s.stop, s.done)
+	return nil
+}
+
+// Stop shuts the collector down and stops restarting it.
+func (s *Supervisor) Stop() error {
+	s.mu.Lock()
+	if s.stop == nil {
+		s.mu.Unlock()
+		return ErrNotRunning
+	}
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.status.Supervised = false
+	s.mu.Unlock()
+
+	close(stop)
+	<-done
+	return nil
+}
+
+// Signal sends sig to the running collector.
+func (s *Supervisor) Signal(sig os.Signal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.process == nil {
+		return ErrNotRunning
+	}
+	return s.process.Signal(sig)
+}
+
+// Status returns the current status of the collector.
+func (s *Supervisor) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+func (s *Supervisor) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	backoff := s.cfg.MinBackoff
+	for {
+		started := time.Now()
+		err := s.runOnce(stop)
+
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		s.mu.Lock()
+		s.status.Exits++
+		s.status.LastExit = err.Error()
+		s.mu.Unlock()
+
+		if time.Since(started) >= stableAfter {
+			backoff = s.cfg.MinBackoff
+		}
+		s.log.Sugar().Warnf("Collector process exited: %v. Restarting in %s", err, backoff)
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, s.cfg.MaxBackoff)
+	}
+}
+
+// runOnce runs the collector until it exits or stop is closed, and tells why
+// it exited.
+func (s *Supervisor) runOnce(stop <-chan struct{}) error {
+	stdout := &lineWriter{log: s.log}
+	stderr := &lineWriter{log: s.log}
+	cmd := exec.Command(s.cfg.Binary, s.cfg.Args...)
+	cmd.Env = append(os.Environ(), s.limitEnv()...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.SysProcAttr = sysProcAttr()
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start: %w", err)
+	}
+	s.log.Sugar().Infof("Collector process started [PID:%d]", cmd.Process.Pid)
+
+	s.mu.Lock()
+	s.process = cmd.Process
+	s.killedBy = ""
+	s.status.Running = true
+	s.status.PID = cmd.Process.Pid
+	s.status.StartedAt = time.Now()
+	s.mu.Unlock()
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	watchdogDone := make(chan struct{})
+	defer close(watchdogDone)
+	if s.cfg.MemoryLimitMiB > 0 {
+		go s.watchMemory(cmd.Process, watchdogDone)
+	}
+
+	var err error
+	select {
+	case err = <-exited:
+	case <-stop:
+		err = terminate(cmd.Process, exited)
+	}
+
+	s.mu.Lock()
+	s.process = nil
+	s.status.Running = false
+	s.status.PID = 0
+	killedBy := s.killedBy
+	s.mu.Unlock()
+
+	if killedBy != "" {
+		return errors.New(killedBy)
+	}
+	if err == nil {
+		err = errors.New("exited with status 0")
+	}
+	if last := stderr.last(); last != "" {
+		return fmt.Errorf("%w: %s", err, last)
+	}
+	return err
+}
+
+// limitEnv returns the environment that makes the collector's Go runtime
+// respect the limits. The memory limit is a soft one, below the limit that
+// gets the collector restarted.
+func (s *Supervisor) limitEnv() []string {
+	var env []string
+	if s.cfg.MemoryLimitMiB > 0 {
+		env = append(env, "GOMEMLIMIT="+strconv.Itoa(s.cfg.MemoryLimitMiB*80/100)+"MiB")
+	}
+	if s.cfg.CPULimit > 0 {
+		env = append(env, "GOMAXPROCS="+strconv.Itoa(s.cfg.CPULimit))
+	}
+	return env
+}
+
+// watchMemory kills the process once its resident memory exceeds the limit.
+func (s *Supervisor) watchMemory(process *os.Process, done <-chan struct{}) {
+	ticker := time.NewTicker(memoryCheckInterval)
+	defer ticker.Stop()
+
+	limit := int64(s.cfg.MemoryLimitMiB) << 20
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		rss, err := residentMemory(process.Pid)
+		if err != nil {
+			// The process is gone, or its memory can't be read on this OS
+			return
+		}
+		if rss > limit {
+			reason := fmt.Sprintf("killed after exceeding the memory limit of %d MiB (resident: %d MiB)", s.cfg.MemoryLimitMiB, rss>>20)
+			s.log.Sugar().Errorf("Collector process %s", reason)
+			s.mu.Lock()
+			s.killedBy = reason
+			s.mu.Unlock()
+			_ = process.Kill()
+			return
+		}
+	}
+}
+
+// terminate asks the process to shut down, and kills it if it doesn't in
+// time.
+func terminate(process *os.Process, exited <-chan error) error {
+	_ = process.Signal(syscall.SIGTERM)
+	select {
+	case err := <-exited:
+		return err
+	case <-time.After(stopTimeout):
+		_ = process.Kill()
+		return <-exited
+	}
+}
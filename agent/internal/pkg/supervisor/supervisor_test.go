@@ -0,0 +1,117 @@
+package supervisor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func shell(script string) Config {
+	return Config{
+		Binary:     "/bin/sh",
+		Args:       []string{"-c", script},
+		MinBackoff: 10 * time.Millisecond,
+		MaxBackoff: 40 * time.Millisecond,
+	}
+}
+
+func TestRestartsExitedCollector(t *testing.T) {
+	s := New(shell("echo 'config is invalid' >&2; exit 3"), nil)
+	require.NoError(t, s.Start())
+	defer s.Stop()
+
+	require.Eventually(t, func() bool { return s.Status().Exits >= 3 }, 5*time.Second, 10*time.Millisecond)
+	status := s.Status()
+	assert.True(t, status.Supervised)
+	assert.Equal(t, "exit status 3: config is invalid", status.LastExit)
+}
+
+func TestStop(t *testing.T) {
+	s := New(shell("exec sleep 30"), nil)
+	require.NoError(t, s.Start())
+	assert.ErrorIs(t, s.Start(), ErrAlreadyRunning)
+	require.Eventually(t, func() bool { return s.Status().Running }, 5*time.Second, 10*time.Millisecond)
+	pid := s.Status().PID
+
+	require.NoError(t, s.Stop())
+	status := s.Status()
+	assert.False(t, status.Supervised)
+	assert.False(t, status.Running)
+	assert.Zero(t, status.Exits)
+	assert.ErrorIs(t, syscall.Kill(pid, 0), syscall.ESRCH)
+
+	assert.ErrorIs(t, s.Stop(), ErrNotRunning)
+	assert.ErrorIs(t, s.Signal(syscall.SIGHUP), ErrNotRunning)
+}
+
+func TestSignal(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "reloaded")
+	core, logs := observer.New(zap.InfoLevel)
+	s := New(shell("trap 'touch "+marker+"' HUP; echo ready; while true; do sleep 0.05; done"), zap.New(core))
+	require.NoError(t, s.Start())
+	defer s.Stop()
+
+	require.Eventually(t, func() bool { return logs.FilterMessage("ready").Len() == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, s.Signal(syscall.SIGHUP))
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(marker)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Zero(t, s.Status().Exits)
+}
+
+func TestForwardsOutputWithLimits(t *testing.T) {
+	cfg := shell(`echo "limits $GOMEMLIMIT $GOMAXPROCS"; printf 'no newline' >&2; exec sleep 30`)
+	cfg.MemoryLimitMiB = 100
+	cfg.CPULimit = 2
+	core, logs := observer.New(zap.InfoLevel)
+	s := New(cfg, zap.New(core))
+	require.NoError(t, s.Start())
+	defer s.Stop()
+
+	require.Eventually(t, func() bool { return logs.FilterMessage("limits 80MiB 2").Len() == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "collector", logs.FilterMessage("limits 80MiB 2").All()[0].LoggerName)
+}
+
+func TestKillsCollectorOverMemoryLimit(t *testing.T) {
+	if _, err := residentMemory(os.Getpid()); err != nil {
+		t.Skip(err)
+	}
+
+	// Any process uses more than the 1 MiB limit
+	cfg := shell("exec sleep 30")
+	cfg.MemoryLimitMiB = 1
+	s := New(cfg, nil)
+	require.NoError(t, s.Start())
+	defer s.Stop()
+
+	require.Eventually(t, func() bool { return s.Status().Exits >= 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.True(t, strings.HasPrefix(s.Status().LastExit, "killed after exceeding the memory limit of 1 MiB"), s.Status().LastExit)
+}
+
+func TestLineWriter(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	w := &lineWriter{log: zap.New(core)}
+
+	_, _ = w.Write([]byte("first\r\nsec"))
+	_, _ = w.Write([]byte("ond\n\nthi"))
+	// The last line is logged once it's asked for, even without a newline
+	assert.Equal(t, "thi", w.last())
+	assert.Equal(t, []string{"first", "second", "thi"}, messages(logs))
+}
+
+func messages(logs *observer.ObservedLogs) []string {
+	var msgs []string
+	for _, entry := range logs.All() {
+		msgs = append(msgs, entry.Message)
+	}
+	return msgs
+}
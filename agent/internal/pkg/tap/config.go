@@ -0,0 +1,173 @@
+package tap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ExporterID is the exporter taps send what they catch to, the agent's own
+// API.
+const ExporterID = "otlphttp/ctrlb_tap"
+
+// pipelinePrefix starts the name of every pipeline a tap adds, after the
+// signal: logs/ctrlb_tap_1
+const pipelinePrefix = "ctrlb_tap_"
+
+// Insert returns a copy of the collector config that also sends what leaves
+// the requested component to endpoint. Every pipeline of the signal that has
+// the component gets a tap pipeline: the tapped receiver alone, or the
+// pipeline's receivers and its processors up to the tapped processor,
+// exporting to ExporterID.
+func Insert(cfg map[string]any, req Request, endpoint string) (map[string]any, error) {
+	tapped := Remove(cfg)
+	service, _ := tapped["service"].(map[string]any)
+	pipelines, _ := service["pipelines"].(map[string]any)
+
+	names := make([]string, 0, len(pipelines))
+	for name := range pipelines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	taps := map[string]any{}
+	for _, name := range names {
+		if signal, _, _ := strings.Cut(name, "/"); signal != req.Signal {
+			continue
+		}
+		pipeline, _ := pipelines[name].(map[string]any)
+		receivers := stringList(pipeline["receivers"])
+		processors := stringList(pipeline["processors"])
+
+		var tap map[string]any
+		switch req.Role {
+		case RoleReceiver:
+			if indexOf(receivers, req.Component) >= 0 {
+				tap = map[string]any{"receivers": []any{req.Component}}
+			}
+		case RoleProcessor:
+			if i := indexOf(processors, req.Component); i >= 0 {
+				tap = map[string]any{
+					"receivers":  anyList(receivers),
+					"processors": anyList(processors[:i+1]),
+				}
+			}
+		}
+		if tap != nil {
+			tap["exporters"] = []any{ExporterID}
+			taps[fmt.Sprintf("%s/%s%d", req.Signal, pipelinePrefix, len(taps)+1)] = tap
+		}
+	}
+	if len(taps) == 0 {
+		return nil, fmt.Errorf("%w: %s %s isn't in a %s pipeline", ErrInvalidRequest, req.Role, req.Component, req.Signal)
+	}
+
+	for name, tap := range taps {
+		pipelines[name] = tap
+	}
+	exporters, ok := tapped["exporters"].(map[string]any)
+	if !ok {
+		exporters = map[string]any{}
+		tapped["exporters"] = exporters
+	}
+	exporters[ExporterID] = map[string]any{
+		"endpoint":    endpoint,
+		"encoding":    "json",
+		"compression": "none",
+	}
+	return tapped, nil
+}
+
+// Remove returns a copy of the collector config without taps. Only the maps
+// that change are copied, and a config without taps hashes the same after.
+func Remove(cfg map[string]any) map[string]any {
+	clean := make(map[string]any, len(cfg))
+	for key, value := range cfg {
+		clean[key] = value
+	}
+
+	if current, ok := cfg["exporters"].(map[string]any); ok {
+		exporters := make(map[string]any, len(current))
+		for id, exporter := range current {
+			if id != ExporterID {
+				exporters[id] = exporter
+			}
+		}
+		clean["exporters"] = exporters
+	}
+
+	if current, ok := cfg["service"].(map[string]any); ok {
+		service := make(map[string]any, len(current))
+		for key, value := range current {
+			service[key] = value
+		}
+		if current, ok := current["pipelines"].(map[string]any); ok {
+			pipelines := make(map[string]any, len(current))
+			for name, pipeline := range current {
+				if !isTapPipeline(name) {
+					pipelines[name] = pipeline
+				}
+			}
+			service["pipelines"] = pipelines
+		}
+		clean["service"] = service
+	}
+
+	return clean
+}
+
+// Contains reports whether the collector config has a tap.
+func Contains(cfg map[string]any) bool {
+	if exporters, ok := cfg["exporters"].(map[string]any); ok {
+		if _, ok := exporters[ExporterID]; ok {
+			return true
+		}
+	}
+	service, _ := cfg["service"].(map[string]any)
+	pipelines, _ := service["pipelines"].(map[string]any)
+	for name := range pipelines {
+		if isTapPipeline(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func isTapPipeline(name string) bool {
+	_, id, _ := strings.Cut(name, "/")
+	return strings.HasPrefix(id, pipelinePrefix)
+}
+
+// stringList reads a list of component IDs, decoded from either YAML or JSON.
+func stringList(value any) []string {
+	switch list := value.(type) {
+	case []string:
+		return list
+	case []any:
+		ids := make([]string, 0, len(list))
+		for _, item := range list {
+			if id, ok := item.(string); ok {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	}
+	return nil
+}
+
+func anyList(ids []string) []any {
+	list := make([]any, len(ids))
+	for i, id := range ids {
+		list[i] = id
+	}
+	return list
+}
+
+func indexOf(ids []string, id string) int {
+	for i, candidate := range ids {
+		if candidate == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,74 @@
+package tap
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// Trim decodes an OTLP/JSON export request of the signal and keeps at most
+// limit of its items: log records, spans or metrics. It returns the kept
+// items as OTLP/JSON and how many they are.
+func Trim(signal string, body []byte, limit int) ([]byte, int, error) {
+	switch signal {
+	case SignalLogs:
+		logs, err := (&plog.JSONUnmarshaler{}).UnmarshalLogs(body)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to decode logs: %w", err)
+		}
+		kept := 0
+		logs.ResourceLogs().RemoveIf(func(resource plog.ResourceLogs) bool {
+			resource.ScopeLogs().RemoveIf(func(scope plog.ScopeLogs) bool {
+				scope.LogRecords().RemoveIf(func(plog.LogRecord) bool {
+					kept++
+					return kept > limit
+				})
+				return scope.LogRecords().Len() == 0
+			})
+			return resource.ScopeLogs().Len() == 0
+		})
+		data, err := (&plog.JSONMarshaler{}).MarshalLogs(logs)
+		return data, logs.LogRecordCount(), err
+
+	case SignalTraces:
+		traces, err := (&ptrace.JSONUnmarshaler{}).UnmarshalTraces(body)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to decode traces: %w", err)
+		}
+		kept := 0
+		traces.ResourceSpans().RemoveIf(func(resource ptrace.ResourceSpans) bool {
+			resource.ScopeSpans().RemoveIf(func(scope ptrace.ScopeSpans) bool {
+				scope.Spans().RemoveIf(func(ptrace.Span) bool {
+					kept++
+					return kept > limit
+				})
+				return scope.Spans().Len() == 0
+			})
+			return resource.ScopeSpans().Len() == 0
+		})
+		data, err := (&ptrace.JSONMarshaler{}).MarshalTraces(traces)
+		return data, traces.SpanCount(), err
+
+	case SignalMetrics:
+		metrics, err := (&pmetric.JSONUnmarshaler{}).UnmarshalMetrics(body)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to decode metrics: %w", err)
+		}
+		kept := 0
+		metrics.ResourceMetrics().RemoveIf(func(resource pmetric.ResourceMetrics) bool {
+			resource.ScopeMetrics().RemoveIf(func(scope pmetric.ScopeMetrics) bool {
+				scope.Metrics().RemoveIf(func(pmetric.Metric) bool {
+					kept++
+					return kept > limit
+				})
+				return scope.Metrics().Len() == 0
+			})
+			return resource.ScopeMetrics().Len() == 0
+		})
+		data, err := (&pmetric.JSONMarshaler{}).MarshalMetrics(metrics)
+		return data, metrics.MetricCount(), err
+	}
+	return nil, 0, fmt.Errorf("%w: unknown signal %q", ErrInvalidRequest, signal)
+}
@@ -0,0 +1,271 @@
+// Package tap samples the telemetry leaving a receiver or processor of the
+// running config. A tap is an extra pipeline in the config, copying the
+// tapped pipeline up to the component, that exports to the agent's own API
+// with the OTLP/HTTP exporter in JSON. The agent forwards what arrives to the
+// backend until it has as many items as requested or the tap times out, and
+// then takes the tap out of the config again.
+package tap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/config"
+	"go.uber.org/zap"
+)
+
+// Components a tap can follow
+const (
+	RoleReceiver  = "receiver"
+	RoleProcessor = "processor"
+)
+
+const (
+	SignalLogs    = "logs"
+	SignalTraces  = "traces"
+	SignalMetrics = "metrics"
+)
+
+// Why a tap ended, as reported to the backend
+const (
+	ReasonLimit    = "limit"
+	ReasonTimeout  = "timeout"
+	ReasonStopped  = "stopped"
+	ReasonReplaced = "config_replaced"
+)
+
+const (
+	DefaultLimit    = 100
+	MaxLimit        = 1000
+	DefaultDuration = time.Minute
+	MaxDuration     = 10 * time.Minute
+)
+
+var (
+	ErrInvalidRequest = errors.New("invalid tap request")
+	ErrTapsDisabled   = errors.New("taps are disabled")
+	ErrTapInProgress  = errors.New("a tap is already running")
+	ErrUnknownTap     = errors.New("tap isn't running")
+)
+
+var tapID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Request tells the agent which component to tap.
+type Request struct {
+	ID          string `json:"id"`
+	Component   string `json:"component"` // Collector ID of the component, e.g. batch/Batch_1a2b3c
+	Role        string `json:"role"`      // receiver or processor
+	Signal      string `json:"signal"`    // logs, traces or metrics
+	Limit       int    `json:"limit"`     // Log records, spans or metrics to sample, DefaultLimit if 0
+	DurationSec int    `json:"duration_sec"`
+}
+
+// Sample is telemetry caught by a tap, sent to the backend as it arrives.
+// The last sample of a tap is Done and says why the tap ended.
+type Sample struct {
+	TapID   string          `json:"tap_id"`
+	AgentID int64           `json:"agent_id"`
+	Signal  string          `json:"signal"`
+	Count   int             `json:"count"`          // Items in Data, or caught in total when Done
+	Data    json.RawMessage `json:"data,omitempty"` // OTLP/JSON export request
+	Done    bool            `json:"done"`
+	Reason  string          `json:"reason,omitempty"`
+}
+
+// Validate checks the request before the config is touched.
+func (r Request) Validate() error {
+	if !tapID.MatchString(r.ID) {
+		return fmt.Errorf("%w: id must be letters, digits, _ or -", ErrInvalidRequest)
+	}
+	if r.Component == "" {
+		return fmt.Errorf("%w: component is required", ErrInvalidRequest)
+	}
+	if r.Role != RoleReceiver && r.Role != RoleProcessor {
+		return fmt.Errorf("%w: role must be %s or %s", ErrInvalidRequest, RoleReceiver, RoleProcessor)
+	}
+	switch r.Signal {
+	case SignalLogs, SignalTraces, SignalMetrics:
+	default:
+		return fmt.Errorf("%w: signal must be %s, %s or %s", ErrInvalidRequest, SignalLogs, SignalTraces, SignalMetrics)
+	}
+	if r.Limit < 0 || r.Limit > MaxLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidRequest, MaxLimit)
+	}
+	if r.DurationSec < 0 || time.Duration(r.DurationSec)*time.Second > MaxDuration {
+		return fmt.Errorf("%w: duration_sec must be at most %d", ErrInvalidRequest, int(MaxDuration.Seconds()))
+	}
+	return nil
+}
+
+// Manager runs one tap at a time on the agent's config file. The file
+// watcher reloads the collector whenever a tap is inserted or removed.
+type Manager struct {
+	ConfigPath string
+	// Endpoint is the base URL of the agent's tap API; a tap exports to
+	// Endpoint/<id>
+	Endpoint string
+	// Validate checks the tapped config before it's saved, may be nil
+	Validate func(map[string]any) error
+	Send     func(Sample) error
+	log      *zap.Logger
+
+	mu      sync.Mutex
+	session *session
+}
+
+type session struct {
+	request Request
+	count   int
+	timer   *time.Timer
+}
+
+// NewManager returns a manager tapping the config at configPath. log may be
+// nil.
+func NewManager(configPath string, endpoint string, send func(Sample) error, log *zap.Logger) *Manager {
+	if log == nil {
+		log = zap.NewNop()
+	}
+	return &Manager{
+		ConfigPath: configPath,
+		Endpoint:   endpoint,
+		Send:       send,
+		log:        log,
+	}
+}
+
+// Start inserts the tap into the config. It ends by itself at the request's
+// limit or duration.
+func (m *Manager) Start(req Request) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	if req.Limit == 0 {
+		req.Limit = DefaultLimit
+	}
+	duration := time.Duration(req.DurationSec) * time.Second
+	if duration == 0 {
+		duration = DefaultDuration
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.session != nil {
+		return ErrTapInProgress
+	}
+
+	cfg, err := config.LoadFromYAML(m.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	tapped, err := Insert(cfg, req, m.Endpoint+"/"+req.ID)
+	if err != nil {
+		return err
+	}
+	if m.Validate != nil {
+		if err := m.Validate(tapped); err != nil {
+			return fmt.Errorf("tapped config is invalid: %w", err)
+		}
+	}
+	if err := config.SaveToYAML(tapped, m.ConfigPath); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	m.session = &session{
+		request: req,
+		timer:   time.AfterFunc(duration, func() { m.end(req.ID, ReasonTimeout, true) }),
+	}
+	m.log.Info(fmt.Sprintf("Started tap %s on %s %s for %s", req.ID, req.Role, req.Component, req.Signal))
+	return nil
+}
+
+// Receive takes an OTLP/JSON export request the collector sent to the tap
+// and forwards what the tap still needs to the backend.
+func (m *Manager) Receive(id string, signal string, body []byte) error {
+	m.mu.Lock()
+	s := m.session
+	if s == nil || s.request.ID != id {
+		m.mu.Unlock()
+		return ErrUnknownTap
+	}
+	if signal != s.request.Signal || s.count >= s.request.Limit {
+		m.mu.Unlock()
+		return nil
+	}
+	data, count, err := Trim(signal, body, s.request.Limit-s.count)
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	s.count += count
+	full := s.count >= s.request.Limit
+	m.mu.Unlock()
+
+	if count > 0 {
+		m.send(Sample{TapID: id, Signal: signal, Count: count, Data: data})
+	}
+	if full {
+		m.end(id, ReasonLimit, true)
+	}
+	return nil
+}
+
+// Stop ends the tap before its limit or duration.
+func (m *Manager) Stop(id string) error {
+	return m.end(id, ReasonStopped, true)
+}
+
+// Abandon ends the running tap without touching the config, for when the
+// config is replaced by one without it.
+func (m *Manager) Abandon() {
+	m.mu.Lock()
+	s := m.session
+	m.mu.Unlock()
+	if s != nil {
+		m.end(s.request.ID, ReasonReplaced, false)
+	}
+}
+
+func (m *Manager) end(id string, reason string, removeTap bool) error {
+	m.mu.Lock()
+	s := m.session
+	if s == nil || s.request.ID != id {
+		m.mu.Unlock()
+		return ErrUnknownTap
+	}
+	s.timer.Stop()
+	m.session = nil
+
+	var err error
+	if removeTap {
+		err = m.removeTap()
+	}
+	m.mu.Unlock()
+
+	if err != nil {
+		m.log.Error(fmt.Sprintf("Failed to remove tap %s from config: %v", id, err))
+	}
+	m.log.Info(fmt.Sprintf("Ended tap %s after %d items: %s", id, s.count, reason))
+	m.send(Sample{TapID: id, Signal: s.request.Signal, Count: s.count, Done: true, Reason: reason})
+	return err
+}
+
+func (m *Manager) removeTap() error {
+	cfg, err := config.LoadFromYAML(m.ConfigPath)
+	if err != nil {
+		return err
+	}
+	if !Contains(cfg) {
+		return nil
+	}
+	return config.SaveToYAML(Remove(cfg), m.ConfigPath)
+}
+
+func (m *Manager) send(sample Sample) {
+	if err := m.Send(sample); err != nil {
+		m.log.Error(fmt.Sprintf("Failed to send sample of tap %s to backend: %v", sample.TapID, err))
+	}
+}
@@ -0,0 +1,232 @@
+package tap
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const collectorConfig = `
+receivers:
+  otlp/Otlp_1a:
+    protocols:
+      grpc:
+        endpoint: 0.0.0.0:4317
+  filelog/Files_2b:
+    include: [/var/log/app.log]
+processors:
+  batch/Batch_3c: {}
+  filter/DropDebug_4d: {}
+exporters:
+  debug/Debug_5e: {}
+service:
+  pipelines:
+    logs/pipeline_1:
+      receivers: [otlp/Otlp_1a, filelog/Files_2b]
+      processors: [filter/DropDebug_4d, batch/Batch_3c]
+      exporters: [debug/Debug_5e]
+    traces/pipeline_2:
+      receivers: [otlp/Otlp_1a]
+      processors: [batch/Batch_3c]
+      exporters: [debug/Debug_5e]
+`
+
+const logsRequest = `{"resourceLogs":[{"resource":{},"scopeLogs":[
+	{"scope":{},"logRecords":[{"body":{"stringValue":"one"}},{"body":{"stringValue":"two"}}]},
+	{"scope":{},"logRecords":[{"body":{"stringValue":"three"}}]}
+]}]}`
+
+func writeConfig(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(collectorConfig), 0o644))
+	return path
+}
+
+func loadConfig(t *testing.T) map[string]any {
+	cfg, err := config.LoadFromYAML(writeConfig(t))
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestInsert_Processor(t *testing.T) {
+	cfg := loadConfig(t)
+	req := Request{ID: "t1", Component: "filter/DropDebug_4d", Role: RoleProcessor, Signal: SignalLogs}
+
+	tapped, err := Insert(cfg, req, "http://127.0.0.1:3421/agent/v1/taps/t1")
+	require.NoError(t, err)
+
+	pipelines := tapped["service"].(map[string]any)["pipelines"].(map[string]any)
+	assert.Len(t, pipelines, 3)
+	assert.Equal(t, map[string]any{
+		"receivers":  []any{"otlp/Otlp_1a", "filelog/Files_2b"},
+		"processors": []any{"filter/DropDebug_4d"},
+		"exporters":  []any{ExporterID},
+	}, pipelines["logs/ctrlb_tap_1"])
+	assert.Equal(t, "http://127.0.0.1:3421/agent/v1/taps/t1", tapped["exporters"].(map[string]any)[ExporterID].(map[string]any)["endpoint"])
+	assert.True(t, Contains(tapped))
+
+	// The config inserted into is left as it was
+	assert.False(t, Contains(cfg))
+	assert.Len(t, cfg["service"].(map[string]any)["pipelines"], 2)
+
+	hash, err := config.Hash(cfg)
+	require.NoError(t, err)
+	removedHash, err := config.Hash(Remove(tapped))
+	require.NoError(t, err)
+	assert.Equal(t, hash, removedHash)
+}
+
+func TestInsert_Receiver(t *testing.T) {
+	req := Request{ID: "t1", Component: "otlp/Otlp_1a", Role: RoleReceiver, Signal: SignalTraces}
+
+	tapped, err := Insert(loadConfig(t), req, "http://127.0.0.1:3421/agent/v1/taps/t1")
+	require.NoError(t, err)
+
+	pipelines := tapped["service"].(map[string]any)["pipelines"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"receivers": []any{"otlp/Otlp_1a"},
+		"exporters": []any{ExporterID},
+	}, pipelines["traces/ctrlb_tap_1"])
+	assert.NotContains(t, pipelines, "logs/ctrlb_tap_1")
+}
+
+func TestInsert_ComponentNotInPipeline(t *testing.T) {
+	for _, req := range []Request{
+		{ID: "t1", Component: "filelog/Files_2b", Role: RoleReceiver, Signal: SignalTraces},
+		{ID: "t1", Component: "otlp/Otlp_1a", Role: RoleProcessor, Signal: SignalLogs},
+		{ID: "t1", Component: "batch/Missing", Role: RoleProcessor, Signal: SignalLogs},
+	} {
+		_, err := Insert(loadConfig(t), req, "http://127.0.0.1:3421/agent/v1/taps/t1")
+		assert.ErrorIs(t, err, ErrInvalidRequest, req.Component)
+	}
+}
+
+func TestRequest_Validate(t *testing.T) {
+	valid := Request{ID: "tap-1", Component: "batch/Batch_3c", Role: RoleProcessor, Signal: SignalLogs, Limit: 10, DurationSec: 30}
+	assert.NoError(t, valid.Validate())
+
+	for _, change := range []func(*Request){
+		func(r *Request) { r.ID = "../config" },
+		func(r *Request) { r.Component = "" },
+		func(r *Request) { r.Role = "exporter" },
+		func(r *Request) { r.Signal = "profiles" },
+		func(r *Request) { r.Limit = MaxLimit + 1 },
+		func(r *Request) { r.DurationSec = 3600 },
+	} {
+		req := valid
+		change(&req)
+		assert.ErrorIs(t, req.Validate(), ErrInvalidRequest)
+	}
+}
+
+func TestTrim(t *testing.T) {
+	data, count, err := Trim(SignalLogs, []byte(logsRequest), 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Contains(t, string(data), `"two"`)
+	assert.NotContains(t, string(data), `"three"`)
+
+	_, count, err = Trim(SignalLogs, []byte(logsRequest), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	_, count, err = Trim(SignalTraces, []byte(`{"resourceSpans":[{"scopeSpans":[{"spans":[{"name":"a"},{"name":"b"}]}]}]}`), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	_, count, err = Trim(SignalMetrics, []byte(`{"resourceMetrics":[{"scopeMetrics":[{"metrics":[{"name":"a"},{"name":"b"}]}]}]}`), 5)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	_, _, err = Trim(SignalLogs, []byte(`{not json`), 1)
+	assert.Error(t, err)
+}
+
+type samples struct {
+	mu   sync.Mutex
+	sent []Sample
+}
+
+func (s *samples) send(sample Sample) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, sample)
+	return nil
+}
+
+func (s *samples) all() []Sample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Sample(nil), s.sent...)
+}
+
+func TestManager_EndsAtLimit(t *testing.T) {
+	path := writeConfig(t)
+	sent := &samples{}
+	manager := NewManager(path, "http://127.0.0.1:3421/agent/v1/taps", sent.send, nil)
+
+	req := Request{ID: "t1", Component: "batch/Batch_3c", Role: RoleProcessor, Signal: SignalLogs, Limit: 4}
+	require.NoError(t, manager.Start(req))
+	assert.ErrorIs(t, manager.Start(Request{ID: "t2", Component: "batch/Batch_3c", Role: RoleProcessor, Signal: SignalLogs}), ErrTapInProgress)
+
+	cfg, err := config.LoadFromYAML(path)
+	require.NoError(t, err)
+	assert.True(t, Contains(cfg))
+
+	assert.ErrorIs(t, manager.Receive("other", SignalLogs, []byte(logsRequest)), ErrUnknownTap)
+	require.NoError(t, manager.Receive("t1", SignalLogs, []byte(logsRequest)))
+	require.NoError(t, manager.Receive("t1", SignalLogs, []byte(logsRequest)))
+	assert.ErrorIs(t, manager.Receive("t1", SignalLogs, []byte(logsRequest)), ErrUnknownTap)
+
+	sent1 := sent.all()
+	require.Len(t, sent1, 3)
+	assert.Equal(t, 3, sent1[0].Count)
+	assert.Equal(t, 1, sent1[1].Count)
+	assert.True(t, json.Valid(sent1[1].Data))
+	assert.Equal(t, Sample{TapID: "t1", Signal: SignalLogs, Count: 4, Done: true, Reason: ReasonLimit}, sent1[2])
+
+	cfg, err = config.LoadFromYAML(path)
+	require.NoError(t, err)
+	assert.False(t, Contains(cfg))
+}
+
+func TestManager_EndsAtTimeout(t *testing.T) {
+	path := writeConfig(t)
+	sent := &samples{}
+	manager := NewManager(path, "http://127.0.0.1:3421/agent/v1/taps", sent.send, nil)
+
+	require.NoError(t, manager.Start(Request{ID: "t1", Component: "otlp/Otlp_1a", Role: RoleReceiver, Signal: SignalTraces, DurationSec: 1}))
+
+	assert.Eventually(t, func() bool {
+		sent := sent.all()
+		return len(sent) == 1 && sent[0].Done && sent[0].Reason == ReasonTimeout
+	}, 3*time.Second, 50*time.Millisecond)
+
+	cfg, err := config.LoadFromYAML(path)
+	require.NoError(t, err)
+	assert.False(t, Contains(cfg))
+	assert.ErrorIs(t, manager.Stop("t1"), ErrUnknownTap)
+}
+
+func TestManager_Abandon(t *testing.T) {
+	path := writeConfig(t)
+	sent := &samples{}
+	manager := NewManager(path, "http://127.0.0.1:3421/agent/v1/taps", sent.send, nil)
+
+	require.NoError(t, manager.Start(Request{ID: "t1", Component: "otlp/Otlp_1a", Role: RoleReceiver, Signal: SignalLogs}))
+	manager.Abandon()
+
+	assert.Equal(t, []Sample{{TapID: "t1", Signal: SignalLogs, Done: true, Reason: ReasonReplaced}}, sent.all())
+	// The config isn't touched, it's being replaced
+	cfg, err := config.LoadFromYAML(path)
+	require.NoError(t, err)
+	assert.True(t, Contains(cfg))
+	require.NoError(t, manager.Start(Request{ID: "t2", Component: "otlp/Otlp_1a", Role: RoleReceiver, Signal: SignalLogs}))
+}
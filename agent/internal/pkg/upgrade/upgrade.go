@@ -0,0 +1,418 @@
+// Package upgrade replaces the collector binary with a version pushed by the
+// backend. The new binary is downloaded next to the current one, verified
+// against its SHA-256 checksum and the Ed25519 signature of that checksum,
+// and renamed over the current binary. The agent then restarts into it, and
+// rolls back to the previous binary if the new one doesn't pass its health
+// checks.
+//
+// What an upgrade is waiting for is kept in a state file next to the binary,
+// so it survives the restart.
+package upgrade
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Outcomes of an upgrade, as reported to the backend
+const (
+	StatusSucceeded  = "succeeded"
+	StatusFailed     = "failed"
+	StatusRolledBack = "rolled_back"
+)
+
+const (
+	defaultHealthTimeout = time.Minute
+	defaultSettleTime    = 10 * time.Second
+	healthCheckInterval  = time.Second
+	reportAttempts       = 5
+	reportRetryInterval  = 3 * time.Second
+)
+
+var (
+	ErrInvalidCommand    = errors.New("invalid upgrade command")
+	ErrUpgradesDisabled  = errors.New("upgrades are disabled, UPGRADE_PUBLIC_KEY isn't set")
+	ErrUpgradeInProgress = errors.New("an upgrade is already in progress")
+)
+
+// Command tells the agent which binary to upgrade to.
+type Command struct {
+	UpgradeID int    `json:"upgrade_id"`
+	Version   string `json:"version"`
+	URL       string `json:"url"`
+	SHA256    string `json:"sha256"`    // Hex SHA-256 checksum of the binary
+	Signature string `json:"signature"` // Base64 Ed25519 signature of the checksum
+}
+
+// Report tells the backend how an upgrade ended.
+type Report struct {
+	Status  string `json:"status"`
+	Version string `json:"version"` // Version the agent runs after the upgrade
+	Error   string `json:"error,omitempty"`
+}
+
+// State is an upgrade waiting for the restarted agent to confirm or roll it
+// back.
+type State struct {
+	UpgradeID   int    `json:"upgrade_id"`
+	Version     string `json:"version"`
+	FromVersion string `json:"from_version"`
+	// Boots counts how often the agent started since the binary was swapped.
+	// A second boot before the upgrade is confirmed means the new binary
+	// exited before passing its health checks.
+	Boots      int    `json:"boots"`
+	RolledBack bool   `json:"rolled_back"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Upgrader replaces Binary, the collector binary, and restarts the agent.
+// In embedded mode Binary is the agent itself.
+type Upgrader struct {
+	Binary    string
+	PublicKey ed25519.PublicKey
+	Client    *http.Client
+	// Report sends the outcome of an upgrade to the backend.
+	Report func(upgradeID int, report Report) error
+	// HealthTimeout is how long the restarted collector has to become
+	// healthy, and SettleTime how long it then has to stay healthy.
+	HealthTimeout time.Duration
+	SettleTime    time.Duration
+
+	executable string // the agent binary, restarted after a swap
+	log        *zap.Logger
+}
+
+// New returns an upgrader for binary that accepts binaries signed with
+// publicKey. log may be nil.
+func New(binary string, publicKey ed25519.PublicKey, report func(int, Report) error, log *zap.Logger) (*Upgrader, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key must be %d bytes, got %d", ed25519.PublicKeySize, len(publicKey))
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate agent binary: %w", err)
+	}
+	if log == nil {
+		log = zap.NewNop()
+	}
+	return &Upgrader{
+		Binary:        binary,
+		PublicKey:     publicKey,
+		Client:        &http.Client{Timeout: 10 * time.Minute},
+		Report:        report,
+		HealthTimeout: defaultHealthTimeout,
+		SettleTime:    defaultSettleTime,
+		executable:    executable,
+		log:           log,
+	}, nil
+}
+
+// ParsePublicKey decodes a base64 Ed25519 public key.
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("public key isn't base64: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key must be %d bytes, got %d", ed25519.PublicKeySize, len(key))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// Validate checks that a command can be carried out.
+func (c Command) Validate() error {
+	if c.UpgradeID <= 0 || c.Version == "" {
+		return fmt.Errorf("%w: upgrade ID and version are required", ErrInvalidCommand)
+	}
+	parsed, err := url.Parse(c.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: url must be an http(s) URL", ErrInvalidCommand)
+	}
+	if digest, err := hex.DecodeString(c.SHA256); err != nil || len(digest) != sha256.Size {
+		return fmt.Errorf("%w: sha256 must be a hex SHA-256 checksum", ErrInvalidCommand)
+	}
+	if signature, err := base64.StdEncoding.DecodeString(c.Signature); err != nil || len(signature) != ed25519.SignatureSize {
+		return fmt.Errorf("%w: signature must be a base64 Ed25519 signature", ErrInvalidCommand)
+	}
+	return nil
+}
+
+// Install downloads and verifies the binary of the command and swaps it in
+// place of Binary, keeping the current binary to roll back to. The agent has
+// to be restarted to run the new binary.
+func (u *Upgrader) Install(command Command, fromVersion string) error {
+	if err := command.Validate(); err != nil {
+		return err
+	}
+
+	download := u.Binary + ".download"
+	defer os.Remove(download)
+	if err := u.download(command, download); err != nil {
+		return err
+	}
+
+	// Keep the current binary under a second name, so renaming the new one
+	// over it doesn't lose it
+	backup := u.backupPath()
+	if err := os.Remove(backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove old backup binary: %w", err)
+	}
+	if err := os.Link(u.Binary, backup); err != nil {
+		return fmt.Errorf("failed to back up current binary: %w", err)
+	}
+
+	state := State{UpgradeID: command.UpgradeID, Version: command.Version, FromVersion: fromVersion}
+	if err := u.saveState(state); err != nil {
+		os.Remove(backup)
+		return err
+	}
+	if err := os.Rename(download, u.Binary); err != nil {
+		os.Remove(u.statePath())
+		os.Remove(backup)
+		return fmt.Errorf("failed to replace binary: %w", err)
+	}
+
+	u.log.Info(fmt.Sprintf("Installed version %s in place of %s", command.Version, fromVersion))
+	return nil
+}
+
+// download writes the binary of the command to path and verifies it.
+func (u *Upgrader) download(command Command, path string) error {
+	resp, err := u.Client.Get(command.URL)
+	if err != nil {
+		return fmt.Errorf("failed to download binary: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download binary: status %d", resp.StatusCode)
+	}
+
+	mode := os.FileMode(0o755)
+	if info, err := os.Stat(u.Binary); err == nil {
+		mode = info.Mode().Perm()
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return fmt.Errorf("failed to create download file: %w", err)
+	}
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(file, hash), resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download binary: %w", err)
+	}
+
+	return u.verify(command, hash.Sum(nil))
+}
+
+// verify checks a downloaded binary's digest against the checksum and
+// signature of the command.
+func (u *Upgrader) verify(command Command, digest []byte) error {
+	expected, _ := hex.DecodeString(command.SHA256)
+	if !bytes.Equal(digest, expected) {
+		return fmt.Errorf("checksum mismatch: expected %s, got %x", command.SHA256, digest)
+	}
+	signature, _ := base64.StdEncoding.DecodeString(command.Signature)
+	if !ed25519.Verify(u.PublicKey, digest, signature) {
+		return fmt.Errorf("signature verification failed")
+	}
+	return nil
+}
+
+// BeginBoot records that the agent started and returns the upgrade waiting
+// for it, or nil when there is none.
+func (u *Upgrader) BeginBoot() (*State, error) {
+	state, err := u.loadState()
+	if err != nil || state == nil {
+		return nil, err
+	}
+	state.Boots++
+	if err := u.saveState(*state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Crashed reports whether the new binary exited before passing its health
+// checks.
+func (s *State) Crashed() bool {
+	return !s.RolledBack && s.Boots > 1
+}
+
+// Finish ends the upgrade waiting for the agent after its restart. It reports
+// a rollback that restarted the agent, or waits for the new collector to
+// become healthy and confirms the upgrade. Finish returns true when the
+// previous binary was restored and the agent has to be restarted into it.
+func (u *Upgrader) Finish(state *State, healthy func() error) bool {
+	if state.RolledBack {
+		u.report(state.UpgradeID, Report{Status: StatusRolledBack, Version: state.FromVersion, Error: state.Error})
+		u.clear()
+		return false
+	}
+
+	var err error
+	if state.Crashed() {
+		err = fmt.Errorf("version %s exited before passing health checks", state.Version)
+	} else {
+		err = u.waitHealthy(healthy)
+	}
+	if err == nil {
+		u.log.Info(fmt.Sprintf("Upgrade to version %s passed health checks", state.Version))
+		u.report(state.UpgradeID, Report{Status: StatusSucceeded, Version: state.Version})
+		u.clear()
+		return false
+	}
+
+	u.log.Error(fmt.Sprintf("Upgrade to version %s failed, rolling back to %s: %v", state.Version, state.FromVersion, err))
+	if rollbackErr := u.rollback(state, err); rollbackErr != nil {
+		u.log.Error(fmt.Sprintf("Failed to roll back upgrade: %v", rollbackErr))
+		u.report(state.UpgradeID, Report{Status: StatusFailed, Version: state.Version, Error: fmt.Sprintf("%v; rollback failed: %v", err, rollbackErr)})
+		u.clear()
+		return false
+	}
+	return true
+}
+
+// waitHealthy waits for healthy to pass for SettleTime without failing, and
+// returns its last error if it doesn't within HealthTimeout.
+func (u *Upgrader) waitHealthy(healthy func() error) error {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+	timeout := time.After(u.HealthTimeout)
+
+	var healthySince time.Time
+	for {
+		err := healthy()
+		if err != nil {
+			healthySince = time.Time{}
+		} else {
+			if healthySince.IsZero() {
+				healthySince = time.Now()
+			}
+			if time.Since(healthySince) >= u.SettleTime {
+				return nil
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-timeout:
+			if err == nil {
+				err = fmt.Errorf("collector didn't stay healthy for %s", u.SettleTime)
+			}
+			return fmt.Errorf("health checks failed after %s: %w", u.HealthTimeout, err)
+		}
+	}
+}
+
+// rollback restores the previous binary and records why, for the restarted
+// agent to report.
+func (u *Upgrader) rollback(state *State, reason error) error {
+	if err := os.Rename(u.backupPath(), u.Binary); err != nil {
+		return fmt.Errorf("failed to restore previous binary: %w", err)
+	}
+	state.RolledBack = true
+	state.Error = reason.Error()
+	return u.saveState(*state)
+}
+
+// Cancel restores the previous binary after Install, when the agent couldn't
+// restart into the new one.
+func (u *Upgrader) Cancel() error {
+	if err := os.Rename(u.backupPath(), u.Binary); err != nil {
+		return fmt.Errorf("failed to restore previous binary: %w", err)
+	}
+	return os.Remove(u.statePath())
+}
+
+// Restart replaces the agent process with a new run of the agent binary. It
+// only returns on failure.
+func (u *Upgrader) Restart() error {
+	u.log.Info("Restarting agent")
+	return syscall.Exec(u.executable, os.Args, os.Environ())
+}
+
+// ReportFailure tells the backend that an upgrade failed before the binary
+// was swapped.
+func (u *Upgrader) ReportFailure(command Command, version string, err error) {
+	u.report(command.UpgradeID, Report{Status: StatusFailed, Version: version, Error: err.Error()})
+}
+
+// report sends a report to the backend, retrying while the backend can't be
+// reached.
+func (u *Upgrader) report(upgradeID int, report Report) {
+	if u.Report == nil {
+		return
+	}
+	var err error
+	for attempt := 1; attempt <= reportAttempts; attempt++ {
+		if err = u.Report(upgradeID, report); err == nil {
+			return
+		}
+		if attempt < reportAttempts {
+			time.Sleep(reportRetryInterval)
+		}
+	}
+	u.log.Error(fmt.Sprintf("Failed to report upgrade %d %s: %v", upgradeID, report.Status, err))
+}
+
+// clear forgets the upgrade and its backup binary.
+func (u *Upgrader) clear() {
+	os.Remove(u.backupPath())
+	os.Remove(u.statePath())
+}
+
+func (u *Upgrader) backupPath() string {
+	return u.Binary + ".previous"
+}
+
+func (u *Upgrader) statePath() string {
+	return u.Binary + ".upgrade.json"
+}
+
+func (u *Upgrader) loadState() (*State, error) {
+	data, err := os.ReadFile(u.statePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upgrade state: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse upgrade state: %w", err)
+	}
+	return &state, nil
+}
+
+func (u *Upgrader) saveState(state State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode upgrade state: %w", err)
+	}
+	tmp := u.statePath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write upgrade state: %w", err)
+	}
+	if err := os.Rename(tmp, u.statePath()); err != nil {
+		return fmt.Errorf("failed to write upgrade state: %w", err)
+	}
+	return nil
+}
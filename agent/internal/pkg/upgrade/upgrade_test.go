@@ -0,0 +1,194 @@
+package upgrade
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reports struct {
+	mu   sync.Mutex
+	sent map[int]Report
+}
+
+func (r *reports) send(upgradeID int, report Report) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent[upgradeID] = report
+	return nil
+}
+
+// setup returns an upgrader for a binary holding "old", and a server serving
+// "new" together with a command for it signed with the upgrader's key.
+func setup(t *testing.T) (*Upgrader, Command, *reports) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "agent")
+	require.NoError(t, os.WriteFile(binary, []byte("old"), 0o755))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("new"))
+	}))
+	t.Cleanup(server.Close)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte("new"))
+
+	sent := &reports{sent: map[int]Report{}}
+	upgrader, err := New(binary, publicKey, sent.send, nil)
+	require.NoError(t, err)
+	upgrader.HealthTimeout = 100 * time.Millisecond
+	upgrader.SettleTime = 0
+
+	command := Command{
+		UpgradeID: 7,
+		Version:   "1.1.0",
+		URL:       server.URL + "/agent",
+		SHA256:    hex.EncodeToString(digest[:]),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, digest[:])),
+	}
+	return upgrader, command, sent
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestInstall(t *testing.T) {
+	upgrader, command, _ := setup(t)
+
+	require.NoError(t, upgrader.Install(command, "1.0.0"))
+	assert.Equal(t, "new", readFile(t, upgrader.Binary))
+	assert.Equal(t, "old", readFile(t, upgrader.Binary+".previous"))
+	assert.NoFileExists(t, upgrader.Binary+".download")
+
+	info, err := os.Stat(upgrader.Binary)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+	state, err := upgrader.BeginBoot()
+	require.NoError(t, err)
+	assert.Equal(t, &State{UpgradeID: 7, Version: "1.1.0", FromVersion: "1.0.0", Boots: 1}, state)
+}
+
+func TestInstallRejectsUnverifiedBinary(t *testing.T) {
+	upgrader, command, _ := setup(t)
+
+	tampered := command
+	digest := sha256.Sum256([]byte("other"))
+	tampered.SHA256 = hex.EncodeToString(digest[:])
+	assert.ErrorContains(t, upgrader.Install(tampered, "1.0.0"), "checksum mismatch")
+
+	_, otherKey, _ := ed25519.GenerateKey(nil)
+	digest = sha256.Sum256([]byte("new"))
+	forged := command
+	forged.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(otherKey, digest[:]))
+	assert.ErrorContains(t, upgrader.Install(forged, "1.0.0"), "signature verification failed")
+
+	invalid := command
+	invalid.URL = "file:///tmp/agent"
+	assert.ErrorIs(t, upgrader.Install(invalid, "1.0.0"), ErrInvalidCommand)
+
+	assert.Equal(t, "old", readFile(t, upgrader.Binary))
+	assert.NoFileExists(t, upgrader.Binary+".previous")
+	assert.NoFileExists(t, upgrader.Binary+".download")
+	state, err := upgrader.BeginBoot()
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func TestFinishConfirmsHealthyUpgrade(t *testing.T) {
+	upgrader, command, sent := setup(t)
+	require.NoError(t, upgrader.Install(command, "1.0.0"))
+
+	state, err := upgrader.BeginBoot()
+	require.NoError(t, err)
+	assert.False(t, upgrader.Finish(state, func() error { return nil }))
+
+	assert.Equal(t, Report{Status: StatusSucceeded, Version: "1.1.0"}, sent.sent[7])
+	assert.Equal(t, "new", readFile(t, upgrader.Binary))
+	assert.NoFileExists(t, upgrader.Binary+".previous")
+	assert.NoFileExists(t, upgrader.Binary+".upgrade.json")
+}
+
+func TestFinishRollsBackUnhealthyUpgrade(t *testing.T) {
+	upgrader, command, sent := setup(t)
+	require.NoError(t, upgrader.Install(command, "1.0.0"))
+
+	state, err := upgrader.BeginBoot()
+	require.NoError(t, err)
+	assert.True(t, upgrader.Finish(state, func() error { return errors.New("receiver otlp failed") }))
+	assert.Equal(t, "old", readFile(t, upgrader.Binary))
+	assert.Empty(t, sent.sent)
+
+	// The previous binary reports the rollback after the restart
+	state, err = upgrader.BeginBoot()
+	require.NoError(t, err)
+	assert.True(t, state.RolledBack)
+	assert.False(t, upgrader.Finish(state, nil))
+	assert.Equal(t, StatusRolledBack, sent.sent[7].Status)
+	assert.Equal(t, "1.0.0", sent.sent[7].Version)
+	assert.Contains(t, sent.sent[7].Error, "receiver otlp failed")
+	assert.NoFileExists(t, upgrader.Binary+".upgrade.json")
+}
+
+func TestFinishRollsBackCrashedUpgrade(t *testing.T) {
+	upgrader, command, _ := setup(t)
+	require.NoError(t, upgrader.Install(command, "1.0.0"))
+
+	// The new binary exited before finishing its first boot
+	_, err := upgrader.BeginBoot()
+	require.NoError(t, err)
+	state, err := upgrader.BeginBoot()
+	require.NoError(t, err)
+	assert.True(t, state.Crashed())
+
+	assert.True(t, upgrader.Finish(state, func() error {
+		t.Fatal("crashed upgrade must not be health checked")
+		return nil
+	}))
+	assert.Equal(t, "old", readFile(t, upgrader.Binary))
+
+	state, err = upgrader.BeginBoot()
+	require.NoError(t, err)
+	assert.Equal(t, "version 1.1.0 exited before passing health checks", state.Error)
+	assert.False(t, state.Crashed())
+}
+
+func TestParsePublicKey(t *testing.T) {
+	publicKey, _, _ := ed25519.GenerateKey(nil)
+	parsed, err := ParsePublicKey(base64.StdEncoding.EncodeToString(publicKey))
+	assert.NoError(t, err)
+	assert.Equal(t, publicKey, parsed)
+
+	_, err = ParsePublicKey("c2hvcnQ=")
+	assert.Error(t, err)
+	_, err = ParsePublicKey("not base64!")
+	assert.Error(t, err)
+}
+
+func TestCancel(t *testing.T) {
+	upgrader, command, _ := setup(t)
+	require.NoError(t, upgrader.Install(command, "1.0.0"))
+
+	require.NoError(t, upgrader.Cancel())
+	assert.Equal(t, "old", readFile(t, upgrader.Binary))
+	assert.NoFileExists(t, upgrader.Binary+".previous")
+	state, err := upgrader.BeginBoot()
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+}
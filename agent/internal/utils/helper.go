@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/ctrlb-hq/ctrlb-collector/agent/internal/pkg/logger"
 )
@@ -55,3 +56,20 @@ func SendJSONError(w http.ResponseWriter, statusCode int, errMsg string) {
 	// Write the JSON response to the response writer
 	w.Write(jsonData)
 }
+
+// ParseLabels reads agent labels written as "key=value,key2=value2".
+func ParseLabels(raw string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, found := strings.Cut(pair, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !found || key == "" || value == "" {
+			return nil, fmt.Errorf("invalid label %q, expected key=value", pair)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
@@ -8,6 +8,7 @@ import (
 	"os/signal"
 	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/agent"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/api"
@@ -16,11 +17,18 @@ import (
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/constants"
 	database "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/db"
 	frontendagent "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/agent"
+	frontendgroup "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/group"
+	frontendjob "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/job"
 	frontendnode "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/node"
 	frontendpipeline "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/pipeline"
+	frontendsecret "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/secret"
+	frontendtemplate "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/template"
+	frontendupgrade "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/upgrade"
 
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/middleware"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/jobs"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/queue"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/secrets"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
 	"github.com/joho/godotenv"
 )
@@ -61,6 +69,41 @@ func main() {
 		constants.CHECK_INTERVAL_SEC = 10
 	}
 
+	if bulkConcurrencyEnv := os.Getenv("BULK_CONCURRENCY"); bulkConcurrencyEnv != "" {
+		count, err := strconv.Atoi(bulkConcurrencyEnv)
+		if err == nil && count > 0 {
+			constants.BULK_CONCURRENCY = count
+		}
+	}
+
+	if maxAttemptsEnv := os.Getenv("JOB_MAX_ATTEMPTS"); maxAttemptsEnv != "" {
+		count, err := strconv.Atoi(maxAttemptsEnv)
+		if err == nil && count > 0 {
+			constants.JOB_MAX_ATTEMPTS = count
+		}
+	}
+
+	if retryBackoffEnv := os.Getenv("JOB_RETRY_BACKOFF_SEC"); retryBackoffEnv != "" {
+		count, err := strconv.Atoi(retryBackoffEnv)
+		if err == nil && count > 0 {
+			constants.JOB_RETRY_BACKOFF_SEC = count
+		}
+	}
+
+	if upgradeTimeoutEnv := os.Getenv("UPGRADE_TIMEOUT_SEC"); upgradeTimeoutEnv != "" {
+		count, err := strconv.Atoi(upgradeTimeoutEnv)
+		if err == nil && count > 0 {
+			constants.UPGRADE_TIMEOUT_SEC = count
+		}
+	}
+
+	if disconnectedExpiryEnv := os.Getenv("DISCONNECTED_EXPIRY_SEC"); disconnectedExpiryEnv != "" {
+		count, err := strconv.Atoi(disconnectedExpiryEnv)
+		if err == nil && count > 0 {
+			constants.DISCONNECTED_EXPIRY_SEC = count
+		}
+	}
+
 	if portEnv := os.Getenv("PORT"); portEnv != "" {
 		constants.PORT = portEnv
 	} else {
@@ -74,6 +117,27 @@ func main() {
 		constants.ENV = "prod" // Default value
 	}
 
+	// Without a master key the secrets store is disabled
+	var secretsCipher *secrets.Cipher
+	if masterKey := os.Getenv("SECRETS_MASTER_KEY"); masterKey != "" {
+		cipher, err := secrets.NewCipher(masterKey)
+		if err != nil {
+			utils.Logger.Sugar().Fatalf("Invalid SECRETS_MASTER_KEY: %v", err)
+		}
+		secretsCipher = cipher
+	} else {
+		utils.Logger.Warn("SECRETS_MASTER_KEY is not set, the secrets store is disabled")
+	}
+
+	switch resolution := os.Getenv("SECRETS_RESOLUTION"); resolution {
+	case "":
+		constants.SECRETS_RESOLUTION = secrets.ResolveAtCompile
+	case secrets.ResolveAtCompile, secrets.ResolveOnAgent:
+		constants.SECRETS_RESOLUTION = resolution
+	default:
+		utils.Logger.Sugar().Fatalf("SECRETS_RESOLUTION must be %q or %q, got %q", secrets.ResolveAtCompile, secrets.ResolveOnAgent, resolution)
+	}
+
 	db, err := database.DBInit("./backend.db")
 	if err != nil {
 		utils.Logger.Sugar().Fatal("Failed to initialize DB: %s", err)
@@ -97,6 +161,14 @@ func main() {
 
 	utils.Logger.Info("Component schemas loaded into database")
 
+	migrated, err := database.MigrateNodeConfigs(db, database.GetConfigMigrations())
+	if err != nil {
+		utils.Logger.Sugar().Fatalf("Failed to migrate node configs: %v", err)
+	}
+	if len(migrated) > 0 {
+		utils.Logger.Sugar().Infof("Checked %d node configs saved against older component schemas, see GET /component/migrations", len(migrated))
+	}
+
 	agentQueueRepository := queue.NewQueueRepository(db)
 
 	agentQueue := queue.NewQueue(constants.WORKER_COUNT, constants.CHECK_INTERVAL_SEC, agentQueueRepository)
@@ -112,15 +184,51 @@ func main() {
 	frontendAgentRepository := frontendagent.NewFrontendAgentRepository(db)
 	frontendPipelineRepository := frontendpipeline.NewFrontendPipelineRepository(db)
 	frontendNodeRepository := frontendnode.NewFrontendNodeRepository(db)
-
-	frontendAgentService := frontendagent.NewFrontendAgentService(frontendAgentRepository, agentQueue)
-	frontendPipelineService := frontendpipeline.NewFrontendPipelineService(frontendPipelineRepository)
+	frontendTemplateRepository := frontendtemplate.NewFrontendTemplateRepository(db)
+	frontendSecretRepository := frontendsecret.NewFrontendSecretRepository(db)
+	frontendGroupRepository := frontendgroup.NewFrontendGroupRepository(db)
+
+	frontendSecretService := frontendsecret.NewFrontendSecretService(frontendSecretRepository, secretsCipher)
+	jobManager := jobs.NewManager(jobs.NewJobRepository(db))
+	frontendPipelineService := frontendpipeline.NewFrontendPipelineService(frontendPipelineRepository, frontendSecretService, jobManager)
+
+	// Config pushes that failed are retried from the delivery outbox, and
+	// right away when their agent reconnects
+	agentQueue.OnReconnect(func(agentId string) {
+		if err := frontendPipelineService.RedeliverConfig(agentId); err != nil {
+			utils.Logger.Sugar().Errorf("Failed to redeliver config to reconnected agent [ID:%s]: %v", agentId, err)
+		}
+	})
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := frontendPipelineService.RetryConfigDeliveries(); err != nil {
+				utils.Logger.Sugar().Errorf("Failed to retry config deliveries: %v", err)
+			}
+		}
+	}()
+	frontendAgentService := frontendagent.NewFrontendAgentService(frontendAgentRepository, agentQueue, frontendPipelineService)
 	frontendNodeService := frontendnode.NewFrontendNodeService(frontendNodeRepository)
+	frontendTemplateService := frontendtemplate.NewFrontendTemplateService(frontendTemplateRepository, frontendPipelineService)
+	frontendGroupService := frontendgroup.NewFrontendGroupService(frontendGroupRepository, frontendAgentService, jobManager)
+	frontendJobService := frontendjob.NewFrontendJobService(jobManager)
+	frontendUpgradeService := frontendupgrade.NewFrontendUpgradeService(frontendupgrade.NewFrontendUpgradeRepository(db), frontendGroupService, jobManager)
+
+	// Every job type's executor is registered by now
+	if err = jobManager.Resume(); err != nil {
+		utils.Logger.Sugar().Fatalf("Failed to resume unfinished jobs: %v", err)
+	}
 
-	agentService := agent.NewAgentService(agentRepository, agentQueue, frontendPipelineService)
+	agentService := agent.NewAgentService(agentRepository, agentQueue, frontendPipelineService, frontendTemplateService)
 	authService := auth.NewAuthService(authRepository)
+	if adminEmail := os.Getenv("ADMIN_EMAIL"); adminEmail != "" {
+		if err := authService.BootstrapAdmin(adminEmail, os.Getenv("ADMIN_PASSWORD")); err != nil {
+			utils.Logger.Sugar().Fatalf("Failed to set up admin %s: %v", adminEmail, err)
+		}
+	}
 
-	handler := api.NewHandler(agentService, authService, frontendAgentService, frontendPipelineService, frontendNodeService)
+	handler := api.NewHandler(agentService, authService, frontendAgentService, frontendPipelineService, frontendNodeService, frontendTemplateService, frontendSecretService, frontendGroupService, frontendJobService, frontendUpgradeService)
 
 	router := api.NewRouter(handler)
 
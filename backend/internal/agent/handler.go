@@ -1,7 +1,9 @@
 package agent
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
@@ -52,12 +54,20 @@ func (a *AgentHandler) RegisterAgent(w http.ResponseWriter, r *http.Request) {
 }
 
 // ConfigChangedPing handles the ping from an agent indicating that its configuration has changed.
+// The body carries the hash of the agent's effective config; older agents send none.
 func (a *AgentHandler) ConfigChangedPing(w http.ResponseWriter, r *http.Request) {
 	agentID := mux.Vars(r)["id"]
 
+	var req models.ConfigChangedRequest
+	if err := utils.UnmarshalJSONRequest(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		utils.Logger.Error("Invalid request body")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
 	utils.Logger.Info(fmt.Sprintf("Received config changed ping from agent: %s", agentID))
 
-	if err := a.AgentService.ConfigChangedPing(agentID); err != nil {
+	if err := a.AgentService.ConfigChangedPing(agentID, req.ConfigHash); err != nil {
 		utils.Logger.Error(fmt.Sprintf("Error syncing config for agent %s: %v", agentID, err))
 		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -65,3 +75,33 @@ func (a *AgentHandler) ConfigChangedPing(w http.ResponseWriter, r *http.Request)
 
 	utils.WriteJSONResponse(w, http.StatusOK, nil)
 }
+
+// ReportConfigApply handles an agent's report of applying a config: whether
+// its collector loaded it, the collector's error and its component statuses.
+func (a *AgentHandler) ReportConfigApply(w http.ResponseWriter, r *http.Request) {
+	agentID := mux.Vars(r)["id"]
+
+	req := &models.ConfigApplyRequest{}
+	if err := utils.UnmarshalJSONRequest(r, req); err != nil {
+		utils.Logger.Error("Invalid request body")
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Received config %s report from agent: %s", req.Status, agentID))
+
+	if err := a.AgentService.ReportConfigApply(agentID, req); err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error recording config apply for agent %s: %v", agentID, err))
+		switch {
+		case errors.Is(err, utils.ErrInvalidConfigApplyStatus):
+			utils.SendJSONError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, utils.ErrAgentDoesNotExists):
+			utils.SendJSONError(w, http.StatusNotFound, err.Error())
+		default:
+			utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	utils.WriteJSONResponse(w, http.StatusOK, nil)
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 )
@@ -16,15 +17,20 @@ import (
 // MockAgentService for handler tests
 type MockAgentService struct {
 	RegisterAgentFunc     func(req *models.AgentRegisterRequest) (*AgentRegisterResponse, error)
-	ConfigChangedPingFunc func(agentID string) error
+	ConfigChangedPingFunc func(agentID string, configHash *string) error
+	ReportConfigApplyFunc func(agentID string, req *models.ConfigApplyRequest) error
 }
 
 func (m *MockAgentService) RegisterAgent(req *models.AgentRegisterRequest) (*AgentRegisterResponse, error) {
 	return m.RegisterAgentFunc(req)
 }
 
-func (m *MockAgentService) ConfigChangedPing(agentID string) error {
-	return m.ConfigChangedPingFunc(agentID)
+func (m *MockAgentService) ConfigChangedPing(agentID string, configHash *string) error {
+	return m.ConfigChangedPingFunc(agentID, configHash)
+}
+
+func (m *MockAgentService) ReportConfigApply(agentID string, req *models.ConfigApplyRequest) error {
+	return m.ReportConfigApplyFunc(agentID, req)
 }
 
 func TestAgentHandler_RegisterAgent_Success(t *testing.T) {
@@ -100,7 +106,7 @@ func TestAgentHandler_RegisterAgent_ServiceError(t *testing.T) {
 
 func TestAgentHandler_ConfigChangedPing_Success(t *testing.T) {
 	mockService := &MockAgentService{
-		ConfigChangedPingFunc: func(agentID string) error {
+		ConfigChangedPingFunc: func(agentID string, configHash *string) error {
 			return nil
 		},
 	}
@@ -118,7 +124,7 @@ func TestAgentHandler_ConfigChangedPing_Success(t *testing.T) {
 
 func TestAgentHandler_ConfigChangedPing_Failure(t *testing.T) {
 	mockService := &MockAgentService{
-		ConfigChangedPingFunc: func(agentID string) error {
+		ConfigChangedPingFunc: func(agentID string, configHash *string) error {
 			return errors.New("sync failed")
 		},
 	}
@@ -133,3 +139,71 @@ func TestAgentHandler_ConfigChangedPing_Failure(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, rr.Code)
 }
+
+func TestAgentHandler_ConfigChangedPing_WithConfigHash(t *testing.T) {
+	var received *string
+	mockService := &MockAgentService{
+		ConfigChangedPingFunc: func(agentID string, configHash *string) error {
+			received = configHash
+			return nil
+		},
+	}
+
+	handler := NewAgentHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodPost, "/agents/1/config-changed", bytes.NewBufferString(`{"config_hash": "abc123"}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	rr := httptest.NewRecorder()
+
+	handler.ConfigChangedPing(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	if assert.NotNil(t, received) {
+		assert.Equal(t, "abc123", *received)
+	}
+}
+
+func TestAgentHandler_ReportConfigApply_Success(t *testing.T) {
+	var received *models.ConfigApplyRequest
+	mockService := &MockAgentService{
+		ReportConfigApplyFunc: func(agentID string, req *models.ConfigApplyRequest) error {
+			received = req
+			return nil
+		},
+	}
+
+	handler := NewAgentHandler(mockService)
+
+	body := `{"config_hash": "abc123", "status": "failed", "error": "port in use",
+		"components": [{"id": "otlp", "kind": "receiver", "status": "permanent_error", "error": "port in use"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/agents/1/config-status", bytes.NewBufferString(body))
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	rr := httptest.NewRecorder()
+
+	handler.ReportConfigApply(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	if assert.NotNil(t, received) {
+		assert.Equal(t, "abc123", received.ConfigHash)
+		assert.Equal(t, models.ConfigApplyFailed, received.Status)
+		assert.Equal(t, []models.ComponentStatus{{ID: "otlp", Kind: "receiver", Status: "permanent_error", Error: "port in use"}}, received.Components)
+	}
+}
+
+func TestAgentHandler_ReportConfigApply_InvalidStatus(t *testing.T) {
+	mockService := &MockAgentService{
+		ReportConfigApplyFunc: func(agentID string, req *models.ConfigApplyRequest) error {
+			return utils.ErrInvalidConfigApplyStatus
+		},
+	}
+
+	handler := NewAgentHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodPost, "/agents/1/config-status", bytes.NewBufferString(`{"status": "maybe"}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	rr := httptest.NewRecorder()
+
+	handler.ReportConfigApply(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -2,10 +2,13 @@ package agent
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"sort"
 
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/constants"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/configcompiler"
 )
 
 // AgentRepository interacts with the agent database.
@@ -48,8 +51,37 @@ func (ar *AgentRepository) RegisterAgent(req *models.AgentRegisterRequest) (*Age
 	response.ID = id
 	
 
+	for _, key := range sortedLabelKeys(req.Labels) {
+		if _, err := ar.db.Exec("INSERT INTO agents_labels (agent_id, key, value) VALUES (?, ?, ?) ON CONFLICT(agent_id, key) DO UPDATE SET value = excluded.value", id, key, req.Labels[key]); err != nil {
+			return nil, errors.New("error inserting agent label: " + err.Error())
+		}
+	}
+
+	for _, component := range req.Components {
+		stability, err := json.Marshal(component.Stability)
+		if err != nil {
+			return nil, errors.New("error encoding component stability: " + err.Error())
+		}
+		if _, err := ar.db.Exec(`
+			INSERT INTO agent_components (agent_id, kind, type, name, stability_json) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(agent_id, kind, type) DO UPDATE SET name = excluded.name, stability_json = excluded.stability_json`,
+			id, component.Kind, component.Type, configcompiler.CatalogComponentName(component.Kind, component.Type), string(stability),
+		); err != nil {
+			return nil, errors.New("error inserting agent component: " + err.Error())
+		}
+	}
+
 	// Setting default config
 	response.Config = constants.DefaultConfig
 
 	return response, nil
 }
+
+func sortedLabelKeys(labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -31,6 +31,18 @@ func setupTestDB(t *testing.T) *sql.DB {
 	`)
 	assert.NoError(t, err)
 
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS agent_components (
+			agent_id INTEGER NOT NULL,
+			kind TEXT NOT NULL,
+			type TEXT NOT NULL,
+			name TEXT NOT NULL,
+			stability_json TEXT NOT NULL,
+			PRIMARY KEY (agent_id, kind, type)
+		);
+	`)
+	assert.NoError(t, err)
+
 	// Optional: mock pipelines table if you want to test FK behavior
 	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS pipelines (
 		pipeline_id INTEGER PRIMARY KEY
@@ -90,3 +102,35 @@ func TestRegisterAgent(t *testing.T) {
 	assert.Equal(t, constants.TelemetryService, service["telemetry"])
 	assert.Contains(t, service["pipelines"], "logs/default")
 }
+
+func TestRegisterAgent_StoresComponents(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAgentRepository(db)
+
+	resp, err := repo.RegisterAgent(&models.AgentRegisterRequest{
+		Name:     "NewAgent",
+		Hostname: "new-agent.local",
+		Components: []models.AgentComponent{
+			{Kind: "receiver", Type: "otlp", Stability: map[string]string{"logs": "stable"}},
+			{Kind: "processor", Type: "memory_limiter", Stability: map[string]string{"logs": "beta"}},
+			{Kind: "exporter", Type: "otlp", Stability: map[string]string{"logs": "stable"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	rows, err := db.Query("SELECT name, stability_json FROM agent_components WHERE agent_id = ? ORDER BY name", resp.ID)
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	stored := map[string]string{}
+	for rows.Next() {
+		var name, stability string
+		assert.NoError(t, rows.Scan(&name, &stability))
+		stored[name] = stability
+	}
+	assert.Equal(t, map[string]string{
+		"otlp_receiver":           `{"logs":"stable"}`,
+		"memorylimiter_processor": `{"logs":"beta"}`,
+		"otlp_grpc_exporter":      `{"logs":"stable"}`,
+	}, stored)
+}
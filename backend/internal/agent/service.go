@@ -4,11 +4,14 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/constants"
 	frontendpipeline "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/pipeline"
+	frontendtemplate "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/template"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/configcompiler"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/queue"
 )
 
@@ -19,7 +22,8 @@ type AgentRepositoryInterface interface {
 
 type AgentServiceInterface interface {
 	RegisterAgent(req *models.AgentRegisterRequest) (*AgentRegisterResponse, error)
-	ConfigChangedPing(agentID string) error
+	ConfigChangedPing(agentID string, configHash *string) error
+	ReportConfigApply(agentID string, req *models.ConfigApplyRequest) error
 }
 
 // AgentService manages agent operations.
@@ -27,14 +31,16 @@ type AgentService struct {
 	AgentRepository      AgentRepositoryInterface
 	AgentQueue           queue.AgentQueueInterface
 	FrontendAgentService frontendpipeline.FrontendPipelineServiceInterface
+	TemplateService      frontendtemplate.FrontendTemplateServiceInterface
 }
 
 // NewAgentService creates a new AgentService instance.
-func NewAgentService(agentRepository AgentRepositoryInterface, agentQueue queue.AgentQueueInterface, frontendPipelineService frontendpipeline.FrontendPipelineServiceInterface) *AgentService {
+func NewAgentService(agentRepository AgentRepositoryInterface, agentQueue queue.AgentQueueInterface, frontendPipelineService frontendpipeline.FrontendPipelineServiceInterface, templateService frontendtemplate.FrontendTemplateServiceInterface) *AgentService {
 	return &AgentService{
 		AgentRepository:      agentRepository,
 		AgentQueue:           agentQueue,
 		FrontendAgentService: frontendPipelineService,
+		TemplateService:      templateService,
 	}
 }
 
@@ -48,6 +54,28 @@ func (a *AgentService) RegisterAgent(req *models.AgentRegisterRequest) (*AgentRe
 	hash := sha256.Sum256(fmt.Appendf(nil, "%s-%s-%s", req.Platform, req.Hostname, req.Version))
 	req.Name = fmt.Sprintf("%s-agent-%s", req.Platform, hex.EncodeToString(hash[:6]))
 
+	for key, value := range req.Labels {
+		if key == "" || value == "" {
+			return nil, fmt.Errorf("label key and value cannot be empty")
+		}
+	}
+
+	// Render the template first so a bad template name or parameter doesn't
+	// leave a registered agent behind
+	pipelineGraph := constants.DefaultPipelineGraph
+	if req.PipelineName != "" && req.PipelineTemplate != "" {
+		graph, err := a.TemplateService.RenderTemplateByName(req.PipelineTemplate, req.TemplateParams)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render pipeline template %s: %w", req.PipelineTemplate, err)
+		}
+		pipelineGraph = *graph
+	}
+	if req.PipelineName != "" {
+		if missing := missingComponents(pipelineGraph, req.Components); len(missing) > 0 {
+			return nil, fmt.Errorf("pipeline %s uses components that aren't built into the agent: %s", req.PipelineName, strings.Join(missing, ", "))
+		}
+	}
+
 	response, err := a.AgentRepository.RegisterAgent(req)
 	if err != nil {
 		return nil, err
@@ -58,11 +86,25 @@ func (a *AgentService) RegisterAgent(req *models.AgentRegisterRequest) (*AgentRe
 		createDefaultPipelineReq.Name = req.PipelineName
 		createDefaultPipelineReq.AgentIDs = []int{int(response.ID)}
 		createDefaultPipelineReq.CreatedBy = req.StartedBy
-		createDefaultPipelineReq.PipelineGraph = constants.DefaultPipelineGraph
+		createDefaultPipelineReq.PipelineGraph = pipelineGraph
 		_, err := a.FrontendAgentService.CreatePipeline(createDefaultPipelineReq)
 		if err != nil {
 			return nil, err
 		}
+	} else {
+		// Agents started without a pipeline name go to the first pipeline
+		// whose label selector matches them, and get its config right away
+		pipelineId, err := a.FrontendAgentService.AssignAgentBySelector(int(response.ID))
+		if err != nil {
+			return nil, err
+		}
+		if pipelineId != nil {
+			config, err := a.FrontendAgentService.GetPipelineConfig(*pipelineId)
+			if err != nil {
+				return nil, err
+			}
+			response.Config = config
+		}
 	}
 
 	err = a.AgentQueue.AddAgent(fmt.Sprint(response.ID), req.Hostname, req.IP)
@@ -73,11 +115,44 @@ func (a *AgentService) RegisterAgent(req *models.AgentRegisterRequest) (*AgentRe
 	return response, nil
 }
 
-// ConfigChangedPing notifies frontend to sync config.
-func (a *AgentService) ConfigChangedPing(agentID string) error {
-	err := a.FrontendAgentService.SyncConfig(agentID)
-	if err != nil {
-		return err
+// missingComponents lists the catalog names of the graph's components that
+// aren't among the agent's components. Agents that don't report components
+// are assumed to have them all.
+func missingComponents(graph models.PipelineGraph, components []models.AgentComponent) []string {
+	if len(components) == 0 {
+		return nil
+	}
+
+	available := make(map[string]bool, len(components))
+	for _, component := range components {
+		for _, name := range configcompiler.CatalogComponentNames(component.Kind, component.Type) {
+			available[name] = true
+		}
+	}
+
+	var missing []string
+	for _, node := range graph.Nodes {
+		if !available[node.ComponentName] {
+			missing = append(missing, node.ComponentName)
+			available[node.ComponentName] = true
+		}
 	}
-	return nil
+	return missing
+}
+
+// ConfigChangedPing checks the effective config hash the agent reported for
+// drift from its pipeline's config. Agents that don't report a hash get their
+// pipeline's config pushed again.
+func (a *AgentService) ConfigChangedPing(agentID string, configHash *string) error {
+	if configHash == nil {
+		return a.FrontendAgentService.SyncConfig(agentID)
+	}
+
+	_, err := a.FrontendAgentService.ReportEffectiveConfig(agentID, *configHash)
+	return err
+}
+
+// ReportConfigApply records whether the agent's collector applied a config.
+func (a *AgentService) ReportConfigApply(agentID string, req *models.ConfigApplyRequest) error {
+	return a.FrontendAgentService.RecordConfigApply(agentID, *req)
 }
@@ -7,6 +7,7 @@ import (
 
 	frontendpipeline "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/pipeline"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/configexport"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -44,6 +45,10 @@ func (m *MockAgentQueue) RefreshMonitoring() error {
 	return m.RefreshFunc()
 }
 
+func (m *MockAgentQueue) OnReconnect(handler func(agentID string)) {
+	// No-op for testing
+}
+
 func (m *MockAgentQueue) StartStatusCheck() {
 	// No-op for testing
 }
@@ -53,7 +58,11 @@ func (m *MockAgentQueue) CheckAllAgents() {
 }
 
 type MockFrontendPipeline struct {
-	SyncFunc func(agentId string) error
+	SyncFunc   func(agentId string) error
+	ReportFunc func(agentId string, effectiveHash string) (*models.ConfigDrift, error)
+	RecordFunc func(agentId string, req models.ConfigApplyRequest) error
+	CreateFunc func(createPipelineRequest models.CreatePipelineRequest) (string, error)
+	AssignFunc func(agentId int) (*int, error)
 }
 
 func (m *MockFrontendPipeline) GetAllPipelines() ([]*frontendpipeline.Pipeline, error) {
@@ -66,6 +75,9 @@ func (m *MockFrontendPipeline) GetPipelineOverview(pipelineId int) (*frontendpip
 	return nil, nil
 }
 func (m *MockFrontendPipeline) CreatePipeline(createPipelineRequest models.CreatePipelineRequest) (string, error) {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(createPipelineRequest)
+	}
 	return "", nil
 }
 func (m *MockFrontendPipeline) DeletePipeline(pipelineId int) error {
@@ -77,18 +89,125 @@ func (m *MockFrontendPipeline) GetAllAgentsAttachedToPipeline(pipelineId int) ([
 func (m *MockFrontendPipeline) DetachAgentFromPipeline(pipelineId int, agentId int) error {
 	return nil
 }
-func (m *MockFrontendPipeline) AttachAgentToPipeline(pipelineId int, agentId int) error {
-	return nil
+func (m *MockFrontendPipeline) AttachAgentToPipeline(pipelineId int, agentId int) ([]string, error) {
+	return nil, nil
 }
 func (m *MockFrontendPipeline) GetPipelineGraph(pipelineId int) (*models.PipelineGraph, error) {
 	return nil, nil
 }
-func (m *MockFrontendPipeline) SyncPipelineGraph(pipelineId int, pipelineGraph models.PipelineGraph) error {
+func (m *MockFrontendPipeline) SyncPipelineGraph(pipelineId int, pipelineGraph models.PipelineGraph) ([]string, error) {
+	return nil, nil
+}
+func (m *MockFrontendPipeline) GetPipelineTelemetry(pipelineId int) (*models.PipelineTelemetry, error) {
+	return nil, nil
+}
+func (m *MockFrontendPipeline) UpdatePipelineTelemetry(pipelineId int, telemetry models.PipelineTelemetry) error {
 	return nil
 }
+func (m *MockFrontendPipeline) LintPipeline(pipelineId int) (*models.LintReport, error) {
+	return nil, nil
+}
+func (m *MockFrontendPipeline) ImportPipeline(collectorConfig map[string]any) (*models.ImportPipelineResponse, error) {
+	return nil, nil
+}
+func (m *MockFrontendPipeline) ExportPipeline(pipelineId int, format string, opts configexport.Options) ([]byte, error) {
+	return nil, nil
+}
+func (m *MockFrontendPipeline) SetPipelineEnvironment(pipelineId int, environment string) error {
+	return nil
+}
+func (m *MockFrontendPipeline) ClonePipeline(pipelineId int, req models.ClonePipelineRequest) (string, error) {
+	return "", nil
+}
+func (m *MockFrontendPipeline) PreviewPromotion(pipelineId int, to string) (*models.PipelinePromotion, error) {
+	return nil, nil
+}
+func (m *MockFrontendPipeline) PromotePipeline(pipelineId int, req models.PromotePipelineRequest) (*models.PipelinePromotion, error) {
+	return nil, nil
+}
+func (m *MockFrontendPipeline) SetPipelineSelector(pipelineId int, selector string) (*models.PipelineSelectorStatus, error) {
+	return nil, nil
+}
+func (m *MockFrontendPipeline) GetSelectorStatus(pipelineId int) (*models.PipelineSelectorStatus, error) {
+	return nil, nil
+}
+func (m *MockFrontendPipeline) AssignAgentBySelector(agentId int) (*int, error) {
+	if m.AssignFunc == nil {
+		return nil, nil
+	}
+	return m.AssignFunc(agentId)
+}
+func (m *MockFrontendPipeline) GetPipelineConfig(pipelineId int) (map[string]any, error) {
+	return map[string]any{"pipeline": pipelineId}, nil
+}
 func (m *MockFrontendPipeline) SyncConfig(agentId string) error {
 	return m.SyncFunc(agentId)
 }
+func (m *MockFrontendPipeline) RedeliverConfig(agentId string) error {
+	return nil
+}
+func (m *MockFrontendPipeline) RetryConfigDeliveries() error {
+	return nil
+}
+func (m *MockFrontendPipeline) SetPipelineDriftPolicy(pipelineId int, policy string) error {
+	return nil
+}
+func (m *MockFrontendPipeline) ReportEffectiveConfig(agentId string, effectiveHash string) (*models.ConfigDrift, error) {
+	return m.ReportFunc(agentId, effectiveHash)
+}
+func (m *MockFrontendPipeline) GetConfigDrift(agentId int) (*models.ConfigDrift, error) {
+	return nil, nil
+}
+func (m *MockFrontendPipeline) GetPipelineConfigDrift(pipelineId int) ([]models.ConfigDrift, error) {
+	return nil, nil
+}
+func (m *MockFrontendPipeline) GetEffectiveConfig(agentId int) (map[string]any, error) {
+	return nil, nil
+}
+func (m *MockFrontendPipeline) RecordConfigApply(agentId string, req models.ConfigApplyRequest) error {
+	return m.RecordFunc(agentId, req)
+}
+func (m *MockFrontendPipeline) GetConfigApplies(agentId int) ([]models.ConfigApply, error) {
+	return nil, nil
+}
+func (m *MockFrontendPipeline) GetPipelineRollout(pipelineId int) (*models.ConfigRollout, error) {
+	return nil, nil
+}
+func (m *MockFrontendPipeline) GetAgentComponents(agentId int) ([]models.AgentComponent, error) {
+	return nil, nil
+}
+func (m *MockFrontendPipeline) StartTap(pipelineId int, nodeId int, opts models.TapOptions) (*frontendpipeline.NodeTap, error) {
+	return nil, nil
+}
+func (m *MockFrontendPipeline) PublishTapSample(tapId string, sample models.TapSample) error {
+	return nil
+}
+
+type MockTemplateService struct {
+	RenderFunc func(name string, params map[string]any) (*models.PipelineGraph, error)
+}
+
+func (m *MockTemplateService) GetAllTemplates() ([]models.PipelineTemplate, error) {
+	return nil, nil
+}
+func (m *MockTemplateService) GetTemplate(templateId int) (*models.PipelineTemplate, error) {
+	return nil, nil
+}
+func (m *MockTemplateService) CreateTemplate(template models.PipelineTemplate) (int, error) {
+	return 0, nil
+}
+func (m *MockTemplateService) UpdateTemplate(templateId int, template models.PipelineTemplate) error {
+	return nil
+}
+func (m *MockTemplateService) DeleteTemplate(templateId int) error {
+	return nil
+}
+func (m *MockTemplateService) InstantiateTemplate(templateId int, req models.InstantiateTemplateRequest) (string, error) {
+	return "", nil
+}
+func (m *MockTemplateService) RenderTemplateByName(name string, params map[string]any) (*models.PipelineGraph, error) {
+	return m.RenderFunc(name, params)
+}
 
 func TestAgentService_RegisterAgent_Success(t *testing.T) {
 	mockRepo := &MockAgentRepository{
@@ -105,7 +224,7 @@ func TestAgentService_RegisterAgent_Success(t *testing.T) {
 	}
 	mockFrontend := &MockFrontendPipeline{}
 
-	svc := NewAgentService(mockRepo, mockQueue, mockFrontend)
+	svc := NewAgentService(mockRepo, mockQueue, mockFrontend, &MockTemplateService{})
 
 	req := &models.AgentRegisterRequest{
 		Platform: "linux",
@@ -138,7 +257,7 @@ func TestAgentService_RegisterAgent_RepoError(t *testing.T) {
 	}
 	mockFrontend := &MockFrontendPipeline{}
 
-	svc := NewAgentService(mockRepo, mockQueue, mockFrontend)
+	svc := NewAgentService(mockRepo, mockQueue, mockFrontend, &MockTemplateService{})
 
 	req := &models.AgentRegisterRequest{
 		Platform: "linux",
@@ -153,6 +272,103 @@ func TestAgentService_RegisterAgent_RepoError(t *testing.T) {
 	assert.Nil(t, resp)
 }
 
+func TestAgentService_RegisterAgent_FromTemplate(t *testing.T) {
+	mockRepo := &MockAgentRepository{
+		RegisterFunc: func(req *models.AgentRegisterRequest) (*AgentRegisterResponse, error) {
+			return &AgentRegisterResponse{ID: 7}, nil
+		},
+	}
+	mockQueue := &MockAgentQueue{
+		AddFunc:     func(agentID string, hostname string, ip string) error { return nil },
+		RemoveFunc:  func(id string) error { return nil },
+		RefreshFunc: func() error { return nil },
+	}
+	templateGraph := models.PipelineGraph{Nodes: []models.PipelineNodes{{ComponentID: 1, Name: "kafka"}}}
+	mockTemplates := &MockTemplateService{
+		RenderFunc: func(name string, params map[string]any) (*models.PipelineGraph, error) {
+			assert.Equal(t, "kafka-logs", name)
+			assert.Equal(t, map[string]any{"kafka_brokers": "broker:9092"}, params)
+			return &templateGraph, nil
+		},
+	}
+	var created models.CreatePipelineRequest
+	mockFrontend := &MockFrontendPipeline{
+		CreateFunc: func(req models.CreatePipelineRequest) (string, error) {
+			created = req
+			return "1", nil
+		},
+	}
+
+	svc := NewAgentService(mockRepo, mockQueue, mockFrontend, mockTemplates)
+
+	_, err := svc.RegisterAgent(&models.AgentRegisterRequest{
+		Platform:         "linux",
+		Hostname:         "test-host",
+		PipelineName:     "edge-logs",
+		PipelineTemplate: "kafka-logs",
+		TemplateParams:   map[string]any{"kafka_brokers": "broker:9092"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "edge-logs", created.Name)
+	assert.Equal(t, []int{7}, created.AgentIDs)
+	assert.Equal(t, templateGraph, created.PipelineGraph)
+}
+
+func TestAgentService_RegisterAgent_TemplateError(t *testing.T) {
+	registered := false
+	mockRepo := &MockAgentRepository{
+		RegisterFunc: func(req *models.AgentRegisterRequest) (*AgentRegisterResponse, error) {
+			registered = true
+			return &AgentRegisterResponse{ID: 7}, nil
+		},
+	}
+	mockTemplates := &MockTemplateService{
+		RenderFunc: func(name string, params map[string]any) (*models.PipelineGraph, error) {
+			return nil, errors.New("template doesn't exist")
+		},
+	}
+
+	svc := NewAgentService(mockRepo, &MockAgentQueue{}, &MockFrontendPipeline{}, mockTemplates)
+
+	_, err := svc.RegisterAgent(&models.AgentRegisterRequest{PipelineName: "edge-logs", PipelineTemplate: "missing"})
+	assert.Error(t, err)
+	assert.False(t, registered)
+}
+
+func TestAgentService_RegisterAgent_TemplateWithMissingComponent(t *testing.T) {
+	registered := false
+	mockRepo := &MockAgentRepository{
+		RegisterFunc: func(req *models.AgentRegisterRequest) (*AgentRegisterResponse, error) {
+			registered = true
+			return &AgentRegisterResponse{ID: 7}, nil
+		},
+	}
+	mockTemplates := &MockTemplateService{
+		RenderFunc: func(name string, params map[string]any) (*models.PipelineGraph, error) {
+			return &models.PipelineGraph{Nodes: []models.PipelineNodes{
+				{ComponentID: 1, Name: "kafka", ComponentName: "kafka_receiver"},
+				{ComponentID: 2, Name: "sampler", ComponentName: "acme_sampling_processor"},
+				{ComponentID: 3, Name: "otlp", ComponentName: "otlp_grpc_exporter"},
+			}}, nil
+		},
+	}
+
+	svc := NewAgentService(mockRepo, &MockAgentQueue{}, &MockFrontendPipeline{}, mockTemplates)
+
+	_, err := svc.RegisterAgent(&models.AgentRegisterRequest{
+		PipelineName:     "edge-logs",
+		PipelineTemplate: "kafka-logs",
+		Components: []models.AgentComponent{
+			{Kind: "receiver", Type: "otlp"},
+			{Kind: "processor", Type: "acme_sampling"},
+			{Kind: "exporter", Type: "otlp"},
+		},
+	})
+	assert.EqualError(t, err, "pipeline edge-logs uses components that aren't built into the agent: kafka_receiver")
+	assert.False(t, registered)
+}
+
 func TestAgentService_ConfigChangedPing_Success(t *testing.T) {
 	mockRepo := &MockAgentRepository{}
 	mockQueue := &MockAgentQueue{
@@ -166,9 +382,9 @@ func TestAgentService_ConfigChangedPing_Success(t *testing.T) {
 		},
 	}
 
-	svc := NewAgentService(mockRepo, mockQueue, mockFrontend)
+	svc := NewAgentService(mockRepo, mockQueue, mockFrontend, &MockTemplateService{})
 
-	err := svc.ConfigChangedPing("agent-id-123")
+	err := svc.ConfigChangedPing("agent-id-123", nil)
 	assert.NoError(t, err)
 }
 
@@ -185,8 +401,87 @@ func TestAgentService_ConfigChangedPing_Failure(t *testing.T) {
 		},
 	}
 
-	svc := NewAgentService(mockRepo, mockQueue, mockFrontend)
+	svc := NewAgentService(mockRepo, mockQueue, mockFrontend, &MockTemplateService{})
+
+	err := svc.ConfigChangedPing("agent-id-123", nil)
+	assert.Error(t, err)
+}
+
+func TestAgentService_RegisterAgent_AssignsBySelector(t *testing.T) {
+	mockRepo := &MockAgentRepository{
+		RegisterFunc: func(req *models.AgentRegisterRequest) (*AgentRegisterResponse, error) {
+			return &AgentRegisterResponse{ID: 4, Config: map[string]any{"dummy": "value"}}, nil
+		},
+	}
+	mockQueue := &MockAgentQueue{
+		AddFunc:     func(agentID string, hostname string, ip string) error { return nil },
+		RemoveFunc:  func(id string) error { return nil },
+		RefreshFunc: func() error { return nil },
+	}
+	pipelineId := 9
+	mockFrontend := &MockFrontendPipeline{
+		AssignFunc: func(agentId int) (*int, error) {
+			assert.Equal(t, 4, agentId)
+			return &pipelineId, nil
+		},
+	}
+
+	svc := NewAgentService(mockRepo, mockQueue, mockFrontend, &MockTemplateService{})
+
+	resp, err := svc.RegisterAgent(&models.AgentRegisterRequest{
+		Platform: "linux",
+		Hostname: "edge-1",
+		Labels:   map[string]string{"env": "prod"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"pipeline": 9}, resp.Config)
+}
+
+func TestAgentService_RegisterAgent_EmptyLabel(t *testing.T) {
+	svc := NewAgentService(&MockAgentRepository{}, &MockAgentQueue{}, &MockFrontendPipeline{}, &MockTemplateService{})
+
+	_, err := svc.RegisterAgent(&models.AgentRegisterRequest{
+		Platform: "linux",
+		Labels:   map[string]string{"env": ""},
+	})
 
-	err := svc.ConfigChangedPing("agent-id-123")
 	assert.Error(t, err)
 }
+
+func TestAgentService_ConfigChangedPing_ReportsEffectiveConfig(t *testing.T) {
+	var reported string
+	mockFrontend := &MockFrontendPipeline{
+		SyncFunc: func(agentId string) error {
+			t.Fatal("config shouldn't be pushed blindly")
+			return nil
+		},
+		ReportFunc: func(agentId string, effectiveHash string) (*models.ConfigDrift, error) {
+			reported = effectiveHash
+			return &models.ConfigDrift{Status: models.ConfigInSync}, nil
+		},
+	}
+
+	svc := NewAgentService(&MockAgentRepository{}, &MockAgentQueue{}, mockFrontend, &MockTemplateService{})
+
+	hash := "abc123"
+	assert.NoError(t, svc.ConfigChangedPing("5", &hash))
+	assert.Equal(t, "abc123", reported)
+}
+
+func TestAgentService_ReportConfigApply(t *testing.T) {
+	var recorded models.ConfigApplyRequest
+	mockFrontend := &MockFrontendPipeline{
+		RecordFunc: func(agentId string, req models.ConfigApplyRequest) error {
+			assert.Equal(t, "5", agentId)
+			recorded = req
+			return nil
+		},
+	}
+
+	svc := NewAgentService(&MockAgentRepository{}, &MockAgentQueue{}, mockFrontend, &MockTemplateService{})
+
+	err := svc.ReportConfigApply("5", &models.ConfigApplyRequest{ConfigHash: "abc123", Status: models.ConfigApplied})
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", recorded.ConfigHash)
+}
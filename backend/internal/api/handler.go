@@ -4,8 +4,13 @@ import (
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/agent"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/auth"
 	frontendagent "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/agent"
+	frontendgroup "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/group"
+	frontendjob "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/job"
 	frontendnode "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/node"
 	frontendpipeline "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/pipeline"
+	frontendsecret "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/secret"
+	frontendtemplate "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/template"
+	frontendupgrade "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/upgrade"
 )
 
 type Handler struct {
@@ -14,6 +19,13 @@ type Handler struct {
 	FrontendAgentHandler    *frontendagent.FrontendAgentHandler
 	FrontendPipelineHandler *frontendpipeline.FrontendPipelineHandler
 	FrontendNodeHandler     *frontendnode.FrontendNodeHandler
+	FrontendTemplateHandler *frontendtemplate.FrontendTemplateHandler
+	FrontendSecretHandler   *frontendsecret.FrontendSecretHandler
+	FrontendGroupHandler    *frontendgroup.FrontendGroupHandler
+	FrontendJobHandler      *frontendjob.FrontendJobHandler
+	FrontendUpgradeHandler  *frontendupgrade.FrontendUpgradeHandler
+	// UserRole looks up a user's role for admin-only routes
+	UserRole func(email string) (string, error)
 }
 
 func NewHandler(
@@ -22,6 +34,11 @@ func NewHandler(
 	frontendAgentServiceV2 frontendagent.FrontendAgentServiceInterface,
 	frontendPipelineServiceV2 frontendpipeline.FrontendPipelineServiceInterface,
 	frontendNodeServiceV2 frontendnode.FrontendNodeServiceInterface,
+	frontendTemplateServiceV2 frontendtemplate.FrontendTemplateServiceInterface,
+	frontendSecretServiceV2 frontendsecret.FrontendSecretServiceInterface,
+	frontendGroupServiceV2 frontendgroup.FrontendGroupServiceInterface,
+	frontendJobServiceV2 frontendjob.FrontendJobServiceInterface,
+	frontendUpgradeServiceV2 frontendupgrade.FrontendUpgradeServiceInterface,
 ) *Handler {
 	return &Handler{
 		AgentHandler:            agent.NewAgentHandler(agentService),
@@ -29,5 +46,11 @@ func NewHandler(
 		FrontendAgentHandler:    frontendagent.NewFrontendAgentHandler(frontendAgentServiceV2),
 		FrontendPipelineHandler: frontendpipeline.NewFrontendPipelineHandler(frontendPipelineServiceV2),
 		FrontendNodeHandler:     frontendnode.NewFrontendNodeHandler(frontendNodeServiceV2),
+		FrontendTemplateHandler: frontendtemplate.NewFrontendTemplateHandler(frontendTemplateServiceV2),
+		FrontendSecretHandler:   frontendsecret.NewFrontendSecretHandler(frontendSecretServiceV2),
+		FrontendGroupHandler:    frontendgroup.NewFrontendGroupHandler(frontendGroupServiceV2),
+		FrontendJobHandler:      frontendjob.NewFrontendJobHandler(frontendJobServiceV2),
+		FrontendUpgradeHandler:  frontendupgrade.NewFrontendUpgradeHandler(frontendUpgradeServiceV2),
+		UserRole:                authService.UserRole,
 	}
 }
@@ -18,6 +18,9 @@ func NewRouter(handler *Handler) *mux.Router {
 
 	agentAPIsV1.HandleFunc("/agents", handler.AgentHandler.RegisterAgent).Methods("POST")
 	agentAPIsV1.HandleFunc("/agents/{id}/config-changed", handler.AgentHandler.ConfigChangedPing).Methods("POST")
+	agentAPIsV1.HandleFunc("/agents/{id}/config-status", handler.AgentHandler.ReportConfigApply).Methods("POST")
+	agentAPIsV1.HandleFunc("/upgrades/{id}/status", handler.FrontendUpgradeHandler.ReportUpgrade).Methods("POST")
+	agentAPIsV1.HandleFunc("/taps/{id}/samples", handler.FrontendPipelineHandler.ReceiveTapSample).Methods("POST")
 
 	frontendAgentAPIsV2 := router.PathPrefix("/api/frontend/v2").Subrouter()
 	frontendAgentAPIsV2.Use(middleware.AuthMiddleware())
@@ -31,26 +34,84 @@ func NewRouter(handler *Handler) *mux.Router {
 	frontendAgentAPIsV2.HandleFunc("/agents/{id}/healthmetrics", handler.FrontendAgentHandler.GetHealthMetricsForGraph).Methods("GET")
 	frontendAgentAPIsV2.HandleFunc("/agents/{id}/ratemetrics", handler.FrontendAgentHandler.GetRateMetricsForGraph).Methods("GET")
 	frontendAgentAPIsV2.HandleFunc("/agents/{id}/labels", handler.FrontendAgentHandler.AddLabels).Methods("POST")
+	frontendAgentAPIsV2.HandleFunc("/agents/{id}/drift", handler.FrontendPipelineHandler.GetConfigDrift).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/agents/{id}/effective-config", handler.FrontendPipelineHandler.GetEffectiveConfig).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/agents/{id}/config-status", handler.FrontendPipelineHandler.GetConfigApplies).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/agents/{id}/components", handler.FrontendPipelineHandler.GetAgentComponents).Methods("GET")
+
+	frontendAgentAPIsV2.HandleFunc("/agent-groups", handler.FrontendGroupHandler.GetAllGroups).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/agent-groups", handler.FrontendGroupHandler.CreateGroup).Methods("POST")
+	frontendAgentAPIsV2.HandleFunc("/agent-groups/{id}", handler.FrontendGroupHandler.GetGroup).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/agent-groups/{id}", handler.FrontendGroupHandler.UpdateGroup).Methods("PUT")
+	frontendAgentAPIsV2.HandleFunc("/agent-groups/{id}", handler.FrontendGroupHandler.DeleteGroup).Methods("DELETE")
+	frontendAgentAPIsV2.HandleFunc("/agent-groups/{id}/agents", handler.FrontendGroupHandler.GetGroupAgents).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/agent-groups/{id}/actions", handler.FrontendGroupHandler.RunBulkAction).Methods("POST")
+
+	frontendAgentAPIsV2.HandleFunc("/jobs", handler.FrontendJobHandler.GetAllJobs).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/jobs/{id}", handler.FrontendJobHandler.GetJob).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/jobs/{id}/cancel", handler.FrontendJobHandler.CancelJob).Methods("POST")
+
+	frontendAgentAPIsV2.HandleFunc("/agent-artifacts", handler.FrontendUpgradeHandler.GetAllArtifacts).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/agent-artifacts", handler.FrontendUpgradeHandler.CreateArtifact).Methods("POST")
+	frontendAgentAPIsV2.HandleFunc("/agent-artifacts/{id}", handler.FrontendUpgradeHandler.DeleteArtifact).Methods("DELETE")
+	frontendAgentAPIsV2.HandleFunc("/agent-versions", handler.FrontendUpgradeHandler.GetFleetVersions).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/agent-upgrades", handler.FrontendUpgradeHandler.GetUpgrades).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/agent-upgrades", handler.FrontendUpgradeHandler.StartUpgrade).Methods("POST")
 
 	frontendAgentAPIsV2.HandleFunc("/unassigned-agents", handler.FrontendAgentHandler.GetUnmanagedAgents).Methods("GET")
 	frontendAgentAPIsV2.HandleFunc("/latest-agent", handler.FrontendAgentHandler.GetLatestAgentSince).Methods("GET")
 
 	frontendAgentAPIsV2.HandleFunc("/pipelines", handler.FrontendPipelineHandler.GetAllPipelines).Methods("GET")
 	frontendAgentAPIsV2.HandleFunc("/pipelines", handler.FrontendPipelineHandler.CreatePipeline).Methods("POST")
+	frontendAgentAPIsV2.HandleFunc("/pipelines/import", handler.FrontendPipelineHandler.ImportPipeline).Methods("POST")
 	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}", handler.FrontendPipelineHandler.GetPipelineInfo).Methods("GET")
 	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}", handler.FrontendPipelineHandler.DeletePipeline).Methods("DELETE")
 	frontendAgentAPIsV2.HandleFunc("/pipelines-overview/{id}", handler.FrontendPipelineHandler.GetPipelineOverview).Methods("GET")
 
 	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}/graph", handler.FrontendPipelineHandler.GetPipelineGraph).Methods("GET")
 	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}/graph", handler.FrontendPipelineHandler.SyncPipelineGraph).Methods("POST")
+	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}/telemetry", handler.FrontendPipelineHandler.GetPipelineTelemetry).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}/telemetry", handler.FrontendPipelineHandler.UpdatePipelineTelemetry).Methods("PUT")
+	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}/lint", handler.FrontendPipelineHandler.LintPipeline).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}/export", handler.FrontendPipelineHandler.ExportPipeline).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}/environment", handler.FrontendPipelineHandler.SetPipelineEnvironment).Methods("PUT")
+	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}/selector", handler.FrontendPipelineHandler.GetPipelineSelector).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}/selector", handler.FrontendPipelineHandler.SetPipelineSelector).Methods("PUT")
+	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}/drift", handler.FrontendPipelineHandler.GetPipelineConfigDrift).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}/drift-policy", handler.FrontendPipelineHandler.SetPipelineDriftPolicy).Methods("PUT")
+	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}/rollout", handler.FrontendPipelineHandler.GetPipelineRollout).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}/clone", handler.FrontendPipelineHandler.ClonePipeline).Methods("POST")
+	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}/promote", handler.FrontendPipelineHandler.PreviewPromotion).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}/promote", handler.FrontendPipelineHandler.PromotePipeline).Methods("POST")
+	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}/nodes/{node}/tap", handler.FrontendPipelineHandler.TapPipelineNode).Methods("GET")
 
 	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}/agents", handler.FrontendPipelineHandler.GetAllAgentsAttachedToPipeline).Methods("GET")
 	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}/agents/{agent_id}", handler.FrontendPipelineHandler.DetachAgentFromPipeline).Methods("DELETE")
 	frontendAgentAPIsV2.HandleFunc("/pipelines/{id}/agents/{agent_id}", handler.FrontendPipelineHandler.AttachAgentToPipeline).Methods("POST")
 
+	frontendAgentAPIsV2.HandleFunc("/templates", handler.FrontendTemplateHandler.GetAllTemplates).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/templates", handler.FrontendTemplateHandler.CreateTemplate).Methods("POST")
+	frontendAgentAPIsV2.HandleFunc("/templates/{id}", handler.FrontendTemplateHandler.GetTemplate).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/templates/{id}", handler.FrontendTemplateHandler.UpdateTemplate).Methods("PUT")
+	frontendAgentAPIsV2.HandleFunc("/templates/{id}", handler.FrontendTemplateHandler.DeleteTemplate).Methods("DELETE")
+	frontendAgentAPIsV2.HandleFunc("/templates/{id}/instantiate", handler.FrontendTemplateHandler.InstantiateTemplate).Methods("POST")
+
+	frontendAgentAPIsV2.HandleFunc("/secrets", handler.FrontendSecretHandler.GetAllSecrets).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/secrets", handler.FrontendSecretHandler.CreateSecret).Methods("POST")
+	frontendAgentAPIsV2.HandleFunc("/secrets/{name}", handler.FrontendSecretHandler.UpdateSecret).Methods("PUT")
+	frontendAgentAPIsV2.HandleFunc("/secrets/{name}", handler.FrontendSecretHandler.DeleteSecret).Methods("DELETE")
+
 	frontendAgentAPIsV2.HandleFunc("/component", handler.FrontendNodeHandler.GetComponent).Methods("GET")
 	frontendAgentAPIsV2.HandleFunc("/component/schema/{name}", handler.FrontendNodeHandler.GetComponentSchema).Methods("GET")
 	frontendAgentAPIsV2.HandleFunc("/component/ui-schema/{name}", handler.FrontendNodeHandler.GetComponentUISchema).Methods("GET")
+	frontendAgentAPIsV2.HandleFunc("/component/migrations", handler.FrontendNodeHandler.GetComponentMigrations).Methods("GET")
+
+	customComponentAPIsV2 := frontendAgentAPIsV2.PathPrefix("/component/custom").Subrouter()
+	customComponentAPIsV2.Use(middleware.RequireRole("admin", handler.UserRole))
+
+	customComponentAPIsV2.HandleFunc("", handler.FrontendNodeHandler.CreateCustomComponent).Methods("POST")
+	customComponentAPIsV2.HandleFunc("/{name}", handler.FrontendNodeHandler.UpdateCustomComponent).Methods("PUT")
+	customComponentAPIsV2.HandleFunc("/{name}", handler.FrontendNodeHandler.DeleteCustomComponent).Methods("DELETE")
 
 	return router
 }
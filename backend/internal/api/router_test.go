@@ -10,8 +10,13 @@ import (
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/api"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/auth"
 	frontendagent "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/agent"
+	frontendgroup "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/group"
+	frontendjob "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/job"
 	frontendnode "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/node"
 	frontendpipeline "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/pipeline"
+	frontendsecret "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/secret"
+	frontendtemplate "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/template"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
 )
 
 func setupMockHandler() *api.Handler {
@@ -21,6 +26,10 @@ func setupMockHandler() *api.Handler {
 		FrontendAgentHandler:    &frontendagent.FrontendAgentHandler{},
 		FrontendPipelineHandler: &frontendpipeline.FrontendPipelineHandler{},
 		FrontendNodeHandler:     &frontendnode.FrontendNodeHandler{},
+		FrontendTemplateHandler: &frontendtemplate.FrontendTemplateHandler{},
+		FrontendSecretHandler:   &frontendsecret.FrontendSecretHandler{},
+		FrontendGroupHandler:    &frontendgroup.FrontendGroupHandler{},
+		FrontendJobHandler:      &frontendjob.FrontendJobHandler{},
 	}
 }
 
@@ -100,6 +109,27 @@ func TestRouter_FrontendRoutes_Unauthorized(t *testing.T) {
 	}
 }
 
+func TestRouter_CustomComponentRoutes_RequireAdmin(t *testing.T) {
+	handler := setupMockHandler()
+	handler.UserRole = func(email string) (string, error) {
+		return "user", nil
+	}
+	router := api.NewRouter(handler)
+
+	token, err := utils.GenerateAccessToken("viewer@example.com")
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/frontend/v2/component/custom", strings.NewReader(`{}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected forbidden 403, got %d", rec.Code)
+	}
+}
+
 func TestRouter_MethodNotAllowed(t *testing.T) {
 	handler := setupMockHandler()
 	router := api.NewRouter(handler)
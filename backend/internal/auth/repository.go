@@ -71,3 +71,15 @@ func (a *AuthRepository) UserExists(email string) bool {
 	}
 	return count > 0
 }
+
+// SetUserRole changes the role of an existing user.
+func (a *AuthRepository) SetUserRole(email, role string) error {
+	result, err := a.db.Exec("UPDATE user SET role = ? WHERE email = ?", role, email)
+	if err != nil {
+		return fmt.Errorf("failed to update user role: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
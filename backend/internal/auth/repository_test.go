@@ -96,3 +96,17 @@ func TestUserExists(t *testing.T) {
 	exists = repo.UserExists(user.Email)
 	assert.True(t, exists, "user should exist after registration")
 }
+
+func TestSetUserRole(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAuthRepository(db)
+
+	assert.NoError(t, repo.RegisterUser(User{Email: "ops@example.com", Name: "Ops", Password: "pass", Role: "user"}))
+	assert.NoError(t, repo.SetUserRole("ops@example.com", "admin"))
+
+	user, err := repo.Login("ops@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", user.Role)
+
+	assert.Error(t, repo.SetUserRole("missing@example.com", "admin"))
+}
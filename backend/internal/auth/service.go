@@ -13,6 +13,7 @@ type AuthRepositoryInterface interface {
 	RegisterUser(user User) error
 	Login(email string) (*User, error)
 	UserExists(email string) bool
+	SetUserRole(email, role string) error
 }
 
 type AuthServiceInterface interface {
@@ -108,6 +109,37 @@ func (a *AuthService) Login(request *models.LoginRequest) (*UserResponse, error)
 	return response, nil
 }
 
+// UserRole returns the role of a registered user, for role-restricted routes.
+func (a *AuthService) UserRole(email string) (string, error) {
+	user, err := a.AuthRepository.Login(email)
+	if err != nil {
+		return "", err
+	}
+	return user.Role, nil
+}
+
+// BootstrapAdmin makes sure the account with the given email is an admin. An
+// existing user is promoted; otherwise the account is created with password.
+func (a *AuthService) BootstrapAdmin(email, password string) error {
+	if a.AuthRepository.UserExists(email) {
+		return a.AuthRepository.SetUserRole(email, "admin")
+	}
+	if password == "" {
+		return errors.New("the account doesn't exist and no password was given to create it")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return a.AuthRepository.RegisterUser(User{
+		Email:    email,
+		Name:     "Admin",
+		Password: string(hashedPassword),
+		Role:     "admin",
+	})
+}
+
 // Login handles user login and returns both access and refresh tokens
 func (a *AuthService) RefreshToken(req RefreshTokenRequest) (any, error) {
 
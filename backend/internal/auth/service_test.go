@@ -29,6 +29,11 @@ func (m *MockAuthRepository) UserExists(email string) bool {
 	return args.Bool(0)
 }
 
+func (m *MockAuthRepository) SetUserRole(email, role string) error {
+	args := m.Called(email, role)
+	return args.Error(0)
+}
+
 func TestAuthService_RegisterUser(t *testing.T) {
 	mockRepo := new(MockAuthRepository)
 	svc := NewAuthService(mockRepo)
@@ -105,3 +110,36 @@ func TestAuthService_RefreshToken(t *testing.T) {
 	// Mock the user existence
 	mockRepo.On("UserExists", "test@example.com").Return(true)
 }
+
+func TestAuthService_UserRole(t *testing.T) {
+	mockRepo := new(MockAuthRepository)
+	svc := NewAuthService(mockRepo)
+
+	mockRepo.On("Login", "admin@example.com").Return(&User{Email: "admin@example.com", Role: "admin"}, nil)
+	role, err := svc.UserRole("admin@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", role)
+}
+
+func TestAuthService_BootstrapAdmin(t *testing.T) {
+	// An existing user is promoted
+	mockRepo := new(MockAuthRepository)
+	svc := NewAuthService(mockRepo)
+	mockRepo.On("UserExists", "admin@example.com").Return(true)
+	mockRepo.On("SetUserRole", "admin@example.com", "admin").Return(nil)
+	assert.NoError(t, svc.BootstrapAdmin("admin@example.com", ""))
+	mockRepo.AssertExpectations(t)
+
+	// A missing one is created, which needs a password
+	mockRepo = new(MockAuthRepository)
+	svc = NewAuthService(mockRepo)
+	mockRepo.On("UserExists", "admin@example.com").Return(false)
+	assert.Error(t, svc.BootstrapAdmin("admin@example.com", ""))
+
+	mockRepo.On("RegisterUser", mock.MatchedBy(func(user User) bool {
+		return user.Email == "admin@example.com" && user.Role == "admin" &&
+			bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("s3cret")) == nil
+	})).Return(nil)
+	assert.NoError(t, svc.BootstrapAdmin("admin@example.com", "s3cret"))
+	mockRepo.AssertExpectations(t)
+}
@@ -2,6 +2,23 @@ package constants
 
 import "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
 
+// DefaultTelemetryMetricsPort is where the collector exposes its own metrics
+// when a pipeline doesn't override it. The agent queue polls this port.
+const DefaultTelemetryMetricsPort = 8888
+
+// DefaultPipelineTelemetry mirrors TelemetryService for pipelines that have no
+// telemetry settings of their own.
+var DefaultPipelineTelemetry = models.PipelineTelemetry{
+	Logs: models.TelemetryLogs{
+		Level: "info",
+	},
+	Metrics: models.TelemetryMetrics{
+		Level: "detailed",
+		Host:  "0.0.0.0",
+		Port:  DefaultTelemetryMetricsPort,
+	},
+}
+
 var TelemetryService = map[string]any{
 	"metrics": map[string]any{
 		"level": "detailed",
@@ -11,7 +28,7 @@ var TelemetryService = map[string]any{
 					"exporter": map[string]any{
 						"prometheus": map[string]any{
 							"host": "0.0.0.0",
-							"port": 8888,
+							"port": DefaultTelemetryMetricsPort,
 						},
 					},
 				},
@@ -82,3 +99,14 @@ var DefaultPipelineGraph = models.PipelineGraph{
 		},
 	},
 }
+
+// Upstream collector image used by exported Helm values and Kubernetes
+// manifests. The version matches the collector built into the agent.
+const (
+	ExportCollectorImageRepository = "otel/opentelemetry-collector-contrib"
+	ExportCollectorImageTag        = "0.122.0"
+	DefaultExportNamespace         = "observability"
+)
+
+// PromotionEnvironments is the order pipelines are promoted through.
+var PromotionEnvironments = []string{"dev", "staging", "prod"}
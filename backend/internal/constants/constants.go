@@ -5,6 +5,37 @@ var (
 	ENV                = "dev"
 	WORKER_COUNT       = 4
 	CHECK_INTERVAL_SEC = 60
+	BULK_CONCURRENCY   = 10
+)
+
+// Jobs retry a failed target up to JOB_MAX_ATTEMPTS times in total, waiting
+// JOB_RETRY_BACKOFF_SEC before the first retry and doubling it each time.
+var (
+	JOB_MAX_ATTEMPTS      = 3
+	JOB_RETRY_BACKOFF_SEC = 5
 )
 
 var JWT_SECRET string
+
+// SECRETS_RESOLUTION decides how ${secret:name} references reach agents:
+// "compile" sends decrypted values, "env" sends ${env:CTRLB_SECRET_<NAME>}.
+var SECRETS_RESOLUTION = "compile"
+
+// Failed config pushes are retried after CONFIG_RETRY_BACKOFF_SEC, doubling
+// the wait per attempt up to CONFIG_RETRY_MAX_BACKOFF_SEC.
+var (
+	CONFIG_RETRY_BACKOFF_SEC     = 5
+	CONFIG_RETRY_MAX_BACKOFF_SEC = 300
+)
+
+// Each retry round sends at most CONFIG_RETRY_BATCH due deliveries, with
+// BULK_CONCURRENCY of them in flight at a time.
+var CONFIG_RETRY_BATCH = 50
+
+// An agent that stays disconnected for DISCONNECTED_EXPIRY_SEC is dropped from
+// the status check queue; it's queued again when it registers.
+var DISCONNECTED_EXPIRY_SEC = 86400
+
+// An agent has UPGRADE_TIMEOUT_SEC to download, install and health-check a new
+// version and report back before its upgrade counts as failed.
+var UPGRADE_TIMEOUT_SEC = 600
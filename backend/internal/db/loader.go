@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io/fs"
 	"strings"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
 )
 
 func LoadSchemasFromDirectory(
@@ -20,10 +22,21 @@ func LoadSchemasFromDirectory(
 		return fmt.Errorf("failed to read schema directory: %w", err)
 	}
 
+	// Schemas are replaced on every start so changes shipped with a new
+	// release take effect; MigrateNodeConfigs then upgrades stored configs.
+	// Custom schemas registered through the API are never replaced
 	insertQuery := `
-	INSERT OR IGNORE INTO component_schemas (
-		name, type, display_name, supported_signals, schema_json, ui_schema_json
-	) VALUES (?, ?, ?, ?, ?, ?);
+	INSERT INTO component_schemas (
+		name, type, display_name, supported_signals, schema_json, ui_schema_json, version
+	) VALUES (?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(name) DO UPDATE SET
+		type = excluded.type,
+		display_name = excluded.display_name,
+		supported_signals = excluded.supported_signals,
+		schema_json = excluded.schema_json,
+		ui_schema_json = excluded.ui_schema_json,
+		version = excluded.version
+	WHERE component_schemas.custom = 0;
 	`
 
 	for _, file := range schemaFiles {
@@ -51,6 +64,15 @@ func LoadSchemasFromDirectory(
 			displayName = title
 		}
 
+		// Schemas without a version are at version 1
+		version := 1
+		if v, ok := schemaMap["version"].(float64); ok {
+			if v < 1 || v != float64(int(v)) {
+				return fmt.Errorf("invalid version in %s: must be a positive integer", file.Name())
+			}
+			version = int(v)
+		}
+
 		// Load corresponding UI schema
 		uiSchemaBytes, err := fs.ReadFile(uiSchemasFS, file.Name())
 		if err != nil {
@@ -62,10 +84,13 @@ func LoadSchemasFromDirectory(
 		signalStr := strings.Join(signals, ",")
 
 		// Insert into DB
-		_, err = db.Exec(insertQuery, name, componentType, displayName, signalStr, string(schemaBytes), string(uiSchemaBytes))
+		res, err := db.Exec(insertQuery, name, componentType, displayName, signalStr, string(schemaBytes), string(uiSchemaBytes), version)
 		if err != nil {
 			return fmt.Errorf("failed to insert schema for %s: %w", name, err)
 		}
+		if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+			utils.Logger.Sugar().Warnf("Custom component schema %s shadows the built-in schema of the same name", name)
+		}
 	}
 
 	return nil
@@ -2,10 +2,12 @@ package database_test
 
 import (
 	"database/sql"
+	"io/fs"
 	"strings"
 	"testing"
 	"testing/fstest"
 
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/assets"
 	database "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/db"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -104,3 +106,85 @@ func TestLoadSchemasFromDirectory_InvalidJSON(t *testing.T) {
 		t.Errorf("expected error to mention 'invalid JSON', got %v", err)
 	}
 }
+
+func TestSchemaManifestCoversAssets(t *testing.T) {
+	schemaFiles, err := fs.Glob(assets.Schemas, "schemas/*.json")
+	if err != nil {
+		t.Fatalf("failed to list schemas: %v", err)
+	}
+
+	typeMapping := database.GetComponentTypeMap()
+	signalMapping := database.GetSignalSupportMap()
+	for _, file := range schemaFiles {
+		name := strings.TrimSuffix(strings.TrimPrefix(file, "schemas/"), ".json")
+		if typeMapping[name] == "" {
+			t.Errorf("schema %s has no component type in the manifest", name)
+		}
+		if len(signalMapping[name]) == 0 {
+			t.Errorf("schema %s has no supported signals in the manifest", name)
+		}
+		if _, err := fs.Stat(assets.UI_Schemas, "ui_schemas/"+name+".json"); err != nil {
+			t.Errorf("schema %s has no UI schema: %v", name, err)
+		}
+	}
+	if len(schemaFiles) != len(typeMapping) {
+		t.Errorf("manifest lists %d components, assets have %d schemas", len(typeMapping), len(schemaFiles))
+	}
+}
+
+func TestLoadSchemasFromDirectory_ReplacesSchemas(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	load := func(schema string) error {
+		return database.LoadSchemasFromDirectory(db,
+			fstest.MapFS{"testcomponent.json": &fstest.MapFile{Data: []byte(schema)}},
+			fstest.MapFS{"testcomponent.json": &fstest.MapFile{Data: []byte(`{}`)}},
+			map[string]string{"testcomponent": "receiver"},
+			map[string][]string{"testcomponent": {"logs"}})
+	}
+	if err := load(`{"title": "Test Component"}`); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := load(`{"title": "Renamed Component", "version": 2}`); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var displayName string
+	var version int
+	if err := db.QueryRow(`SELECT display_name, version FROM component_schemas WHERE name = ?`, "testcomponent").Scan(&displayName, &version); err != nil {
+		t.Fatalf("failed querying component: %v", err)
+	}
+	if displayName != "Renamed Component" || version != 2 {
+		t.Errorf("expected the reloaded schema at version 2, got %q at version %d", displayName, version)
+	}
+
+	if err := load(`{"title": "Test Component", "version": 1.5}`); err == nil || !strings.Contains(err.Error(), "invalid version") {
+		t.Errorf("expected an invalid version error, got %v", err)
+	}
+}
+
+func TestLoadSchemasFromDirectory_KeepsCustomSchemas(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	if _, err := db.Exec(`INSERT INTO component_schemas (name, type, display_name, supported_signals, schema_json, ui_schema_json, custom)
+		VALUES ('testcomponent', 'receiver', 'Custom Component', 'logs', '{}', '{}', 1)`); err != nil {
+		t.Fatalf("failed inserting custom component: %v", err)
+	}
+	if err := database.LoadSchemasFromDirectory(db,
+		fstest.MapFS{"testcomponent.json": &fstest.MapFile{Data: []byte(`{"title": "Test Component"}`)}},
+		fstest.MapFS{"testcomponent.json": &fstest.MapFile{Data: []byte(`{}`)}},
+		map[string]string{"testcomponent": "receiver"},
+		map[string][]string{"testcomponent": {"logs"}}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var displayName string
+	if err := db.QueryRow(`SELECT display_name FROM component_schemas WHERE name = ?`, "testcomponent").Scan(&displayName); err != nil {
+		t.Fatalf("failed querying component: %v", err)
+	}
+	if displayName != "Custom Component" {
+		t.Errorf("expected the custom schema to be kept, got %q", displayName)
+	}
+}
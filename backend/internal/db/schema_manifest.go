@@ -1,57 +1,83 @@
+// Code generated by schemagen from the agent's collector components. DO NOT EDIT.
+
 package database
 
 func GetComponentTypeMap() map[string]string {
 	return map[string]string{
-		// Receivers
-		"otlp_receiver":                  "receiver",
-		"hostmetrics_receiver":           "receiver",
-		"awscloudwatchmetrics_receiver":  "receiver",
+		"attributes_processor":           "processor",
 		"awscloudwatch_receiver":         "receiver",
+		"awscloudwatchmetrics_receiver":  "receiver",
 		"azuremonitor_receiver":          "receiver",
+		"batch_processor":                "processor",
+		"clickhouse_exporter":            "exporter",
+		"cumulativetodelta_processor":    "processor",
+		"debug_exporter":                 "exporter",
+		"elasticsearch_exporter":         "exporter",
+		"file_exporter":                  "exporter",
 		"filelog_receiver":               "receiver",
+		"filter_processor":               "processor",
 		"googlecloudmonitoring_receiver": "receiver",
-
-		// Processors
-		"batch_processor":                "processor",
+		"groupbyattrs_processor":         "processor",
+		"hostmetrics_receiver":           "receiver",
+		"jaeger_receiver":                "receiver",
+		"journald_receiver":              "receiver",
+		"k8sattributes_processor":        "processor",
+		"k8scluster_receiver":            "receiver",
+		"kafka_exporter":                 "exporter",
+		"kafka_receiver":                 "receiver",
+		"kubeletstats_receiver":          "receiver",
+		"loadbalancing_exporter":         "exporter",
 		"memorylimiter_processor":        "processor",
+		"otlp_grpc_exporter":             "exporter",
+		"otlp_receiver":                  "receiver",
+		"otlphttp_exporter":              "exporter",
 		"probabilisticsampler_processor": "processor",
-		"attributes_processor":           "processor",
-		"filter_processor":               "processor",
+		"prometheus_exporter":            "exporter",
+		"prometheus_receiver":            "receiver",
+		"resource_processor":             "processor",
+		"resourcedetection_processor":    "processor",
+		"syslog_receiver":                "receiver",
 		"tailsampling_processor":         "processor",
-
-		// Exporters
-		"otlp_grpc_exporter":  "exporter",
-		"otlphttp_exporter":   "exporter",
-		"debug_exporter":      "exporter",
-		"kafka_exporter":      "exporter",
-		"prometheus_exporter": "exporter",
+		"transform_processor":            "processor",
 	}
 }
 
 func GetSignalSupportMap() map[string][]string {
 	return map[string][]string{
-		// Receivers
-		"otlp_receiver":                  {"traces", "metrics", "logs"},
-		"hostmetrics_receiver":           {"metrics"},
-		"awscloudwatchmetrics_receiver":  {"metrics"},
+		"attributes_processor":           {"traces", "metrics", "logs"},
 		"awscloudwatch_receiver":         {"logs"},
+		"awscloudwatchmetrics_receiver":  {"metrics"},
 		"azuremonitor_receiver":          {"metrics"},
+		"batch_processor":                {"traces", "metrics", "logs"},
+		"clickhouse_exporter":            {"traces", "metrics", "logs"},
+		"cumulativetodelta_processor":    {"metrics"},
+		"debug_exporter":                 {"traces", "metrics", "logs"},
+		"elasticsearch_exporter":         {"traces", "metrics", "logs"},
+		"file_exporter":                  {"traces", "metrics", "logs"},
 		"filelog_receiver":               {"logs"},
+		"filter_processor":               {"traces", "metrics", "logs"},
 		"googlecloudmonitoring_receiver": {"metrics"},
-
-		// Processors
-		"batch_processor":                {"traces", "metrics", "logs"},
+		"groupbyattrs_processor":         {"traces", "metrics", "logs"},
+		"hostmetrics_receiver":           {"metrics", "logs"},
+		"jaeger_receiver":                {"traces"},
+		"journald_receiver":              {"logs"},
+		"k8sattributes_processor":        {"traces", "metrics", "logs"},
+		"k8scluster_receiver":            {"metrics", "logs"},
+		"kafka_exporter":                 {"traces", "metrics", "logs"},
+		"kafka_receiver":                 {"traces", "metrics", "logs"},
+		"kubeletstats_receiver":          {"metrics"},
+		"loadbalancing_exporter":         {"traces", "metrics", "logs"},
 		"memorylimiter_processor":        {"traces", "metrics", "logs"},
-		"probabilisticsampler_processor": {"traces"},
-		"attributes_processor":           {"traces", "metrics", "logs"},
-		"filter_processor":               {"traces", "metrics", "logs"},
+		"otlp_grpc_exporter":             {"traces", "metrics", "logs"},
+		"otlp_receiver":                  {"traces", "metrics", "logs"},
+		"otlphttp_exporter":              {"traces", "metrics", "logs"},
+		"probabilisticsampler_processor": {"traces", "logs"},
+		"prometheus_exporter":            {"metrics"},
+		"prometheus_receiver":            {"metrics"},
+		"resource_processor":             {"traces", "metrics", "logs"},
+		"resourcedetection_processor":    {"traces", "metrics", "logs"},
+		"syslog_receiver":                {"logs"},
 		"tailsampling_processor":         {"traces"},
-
-		// Exporters
-		"otlp_grpc_exporter":  {"traces", "metrics", "logs"},
-		"otlphttp_exporter":   {"traces", "metrics", "logs"},
-		"debug_exporter":      {"traces", "metrics", "logs"},
-		"kafka_exporter":      {"traces", "metrics", "logs"},
-		"prometheus_exporter": {"metrics"},
+		"transform_processor":            {"traces", "metrics", "logs"},
 	}
 }
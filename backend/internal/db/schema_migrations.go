@@ -0,0 +1,230 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+)
+
+// ConfigMigration upgrades a node config from schema version From to
+// From+1. Migrate edits the config in place and returns the changes it made.
+type ConfigMigration struct {
+	From    int
+	Migrate func(config map[string]any) ([]string, error)
+}
+
+// GetConfigMigrations returns the node config migrations of each component,
+// keyed by component_schemas name. When a change to a schema breaks existing
+// configs, bump the "version" of its schema file and add the migration from
+// the previous version here.
+func GetConfigMigrations() map[string][]ConfigMigration {
+	return map[string][]ConfigMigration{}
+}
+
+// RenameField moves a config field, e.g. RenameField(1, "tls.insecure",
+// "tls.insecure_skip_verify"). Paths are dot-separated.
+func RenameField(from int, oldPath, newPath string) ConfigMigration {
+	return ConfigMigration{From: from, Migrate: func(config map[string]any) ([]string, error) {
+		value, ok := removeField(config, oldPath)
+		if !ok {
+			return nil, nil
+		}
+		if err := setField(config, newPath, value); err != nil {
+			return nil, err
+		}
+		return []string{fmt.Sprintf("renamed %s to %s", oldPath, newPath)}, nil
+	}}
+}
+
+// RemoveField drops a config field the component no longer accepts.
+func RemoveField(from int, path string) ConfigMigration {
+	return ConfigMigration{From: from, Migrate: func(config map[string]any) ([]string, error) {
+		if _, ok := removeField(config, path); !ok {
+			return nil, nil
+		}
+		return []string{fmt.Sprintf("removed %s", path)}, nil
+	}}
+}
+
+func removeField(config map[string]any, path string) (any, bool) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		nested, ok := config[key].(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		config = nested
+	}
+	value, ok := config[keys[len(keys)-1]]
+	if ok {
+		delete(config, keys[len(keys)-1])
+	}
+	return value, ok
+}
+
+func setField(config map[string]any, path string, value any) error {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		if config[key] == nil {
+			config[key] = map[string]any{}
+		}
+		nested, ok := config[key].(map[string]any)
+		if !ok {
+			return fmt.Errorf("can't set %s: %s isn't an object", path, key)
+		}
+		config = nested
+	}
+	if _, exists := config[keys[len(keys)-1]]; exists {
+		return fmt.Errorf("can't set %s: the field already exists", path)
+	}
+	config[keys[len(keys)-1]] = value
+	return nil
+}
+
+type outdatedNode struct {
+	componentID   int
+	pipelineID    int
+	componentName string
+	name          string
+	config        string
+	fromVersion   int
+	toVersion     int
+}
+
+// MigrateNodeConfigs upgrades the configs of pipeline nodes saved against an
+// older version of their component's schema. A node is only rewritten when
+// every migration up to the current version succeeds; otherwise its config is
+// left as it was and the error is reported. Every node looked at is recorded
+// in component_migrations and returned, except for failures already recorded
+// for the same node and versions, so restarts don't record them again.
+func MigrateNodeConfigs(db *sql.DB, migrations map[string][]ConfigMigration) ([]models.ComponentMigration, error) {
+	rows, err := db.Query(`
+		SELECT pc.component_id, pc.pipeline_id, pc.component_name, COALESCE(pc.name, ''), COALESCE(pc.config, ''), pc.schema_version, cs.version
+		FROM pipeline_components pc
+		JOIN component_schemas cs ON cs.name = pc.component_name
+		WHERE pc.schema_version < cs.version
+		ORDER BY pc.pipeline_id, pc.component_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outdated node configs: %w", err)
+	}
+	var nodes []outdatedNode
+	for rows.Next() {
+		var node outdatedNode
+		if err := rows.Scan(&node.componentID, &node.pipelineID, &node.componentName, &node.name, &node.config, &node.fromVersion, &node.toVersion); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan outdated node config: %w", err)
+		}
+		nodes = append(nodes, node)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query outdated node configs: %w", err)
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var reports []models.ComponentMigration
+	for _, node := range nodes {
+		report := models.ComponentMigration{
+			PipelineID:    node.pipelineID,
+			ComponentID:   node.componentID,
+			ComponentName: node.componentName,
+			Name:          node.name,
+			FromVersion:   node.fromVersion,
+			ToVersion:     node.toVersion,
+			Changes:       []string{},
+			MigratedAt:    utils.GetCurrentTime(),
+		}
+
+		config, changes, err := migrateConfig(node, migrations[node.componentName])
+		if err != nil {
+			var failures int
+			if err := tx.QueryRow(`
+				SELECT COUNT(*) FROM component_migrations
+				WHERE component_id = ? AND from_version = ? AND to_version = ? AND error != ''
+			`, node.componentID, node.fromVersion, node.toVersion).Scan(&failures); err != nil {
+				return nil, fmt.Errorf("failed to query migrations of node %s: %w", node.name, err)
+			}
+			if failures > 0 {
+				continue
+			}
+			report.Error = err.Error()
+			utils.Logger.Sugar().Warnf("Left config of node %s (pipeline %d) at schema version %d: %v", node.name, node.pipelineID, node.fromVersion, err)
+		} else {
+			report.Changes = changes
+			if _, err := tx.Exec(`UPDATE pipeline_components SET config = ?, schema_version = ? WHERE component_id = ?`, config, node.toVersion, node.componentID); err != nil {
+				return nil, fmt.Errorf("failed to update config of node %s: %w", node.name, err)
+			}
+			utils.Logger.Sugar().Infof("Migrated config of node %s (pipeline %d) from schema version %d to %d: %s", node.name, node.pipelineID, node.fromVersion, node.toVersion, strings.Join(changes, "; "))
+		}
+
+		changesJSON, err := json.Marshal(report.Changes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal changes of node %s: %w", node.name, err)
+		}
+		res, err := tx.Exec(`
+			INSERT INTO component_migrations (pipeline_id, component_id, component_name, name, from_version, to_version, changes_json, error, migrated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, report.PipelineID, report.ComponentID, report.ComponentName, report.Name, report.FromVersion, report.ToVersion, string(changesJSON), report.Error, report.MigratedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record migration of node %s: %w", node.name, err)
+		}
+		if report.ID, err = res.LastInsertId(); err != nil {
+			return nil, fmt.Errorf("failed to record migration of node %s: %w", node.name, err)
+		}
+		reports = append(reports, report)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit node config migrations: %w", err)
+	}
+	return reports, nil
+}
+
+// migrateConfig runs a node's migrations from its schema version to the
+// current one, returning the migrated config JSON.
+func migrateConfig(node outdatedNode, migrations []ConfigMigration) (string, []string, error) {
+	config := map[string]any{}
+	if node.config != "" && node.config != "null" {
+		if err := json.Unmarshal([]byte(node.config), &config); err != nil {
+			return "", nil, fmt.Errorf("invalid config JSON: %w", err)
+		}
+	}
+
+	changes := []string{}
+	for version := node.fromVersion; version < node.toVersion; version++ {
+		var migration *ConfigMigration
+		for i := range migrations {
+			if migrations[i].From == version {
+				migration = &migrations[i]
+				break
+			}
+		}
+		if migration == nil {
+			return "", nil, fmt.Errorf("no migration from schema version %d of %s", version, node.componentName)
+		}
+		migrationChanges, err := migration.Migrate(config)
+		if err != nil {
+			return "", nil, fmt.Errorf("migration from schema version %d failed: %w", version, err)
+		}
+		changes = append(changes, migrationChanges...)
+	}
+
+	migrated, err := json.Marshal(config)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal migrated config: %w", err)
+	}
+	return string(migrated), changes, nil
+}
@@ -0,0 +1,98 @@
+package database_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	database "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateNodeConfigs(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	load := func(schema string) {
+		require.NoError(t, database.LoadSchemasFromDirectory(db,
+			fstest.MapFS{"kafka_exporter.json": {Data: []byte(schema)}},
+			fstest.MapFS{"kafka_exporter.json": {Data: []byte(`{}`)}},
+			map[string]string{"kafka_exporter": "exporter"},
+			map[string][]string{"kafka_exporter": {"logs"}}))
+	}
+	load(`{"title": "Kafka Exporter", "type": "object"}`)
+
+	_, err := db.Exec("INSERT INTO pipelines (pipeline_id, name, created_by) VALUES (1, 'edge', 'admin')")
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO pipeline_components (component_id, pipeline_id, component_role, component_name, name, config, supported_signals) VALUES
+		(1, 1, 'exporter', 'kafka_exporter', 'kafka', '{"brokers": ["kafka:9092"], "auth": {"plain_text": {"username": "ctrlb"}}, "legacy": true}', 'logs'),
+		(2, 1, 'exporter', 'kafka_exporter', 'broken', 'not json', 'logs')`)
+	require.NoError(t, err)
+
+	reports, err := database.MigrateNodeConfigs(db, nil)
+	require.NoError(t, err)
+	assert.Empty(t, reports, "configs are at the current schema version")
+
+	load(`{"title": "Kafka Exporter", "type": "object", "version": 3}`)
+	migrations := map[string][]database.ConfigMigration{
+		"kafka_exporter": {
+			database.RenameField(1, "auth.plain_text.username", "auth.sasl.username"),
+			database.RemoveField(2, "legacy"),
+		},
+	}
+	reports, err = database.MigrateNodeConfigs(db, migrations)
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+
+	assert.Equal(t, "kafka", reports[0].Name)
+	assert.Equal(t, 1, reports[0].FromVersion)
+	assert.Equal(t, 3, reports[0].ToVersion)
+	assert.Equal(t, []string{"renamed auth.plain_text.username to auth.sasl.username", "removed legacy"}, reports[0].Changes)
+	assert.Empty(t, reports[0].Error)
+	assert.Contains(t, reports[1].Error, "invalid config JSON")
+
+	var config string
+	var version int
+	require.NoError(t, db.QueryRow("SELECT config, schema_version FROM pipeline_components WHERE component_id = 1").Scan(&config, &version))
+	assert.JSONEq(t, `{"brokers": ["kafka:9092"], "auth": {"plain_text": {}, "sasl": {"username": "ctrlb"}}}`, config)
+	assert.Equal(t, 3, version)
+	require.NoError(t, db.QueryRow("SELECT config, schema_version FROM pipeline_components WHERE component_id = 2").Scan(&config, &version))
+	assert.Equal(t, "not json", config, "configs that fail to migrate are left alone")
+	assert.Equal(t, 1, version)
+
+	var recorded int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM component_migrations WHERE pipeline_id = 1").Scan(&recorded))
+	assert.Equal(t, 2, recorded)
+
+	// A restart doesn't record the same failure again
+	reports, err = database.MigrateNodeConfigs(db, migrations)
+	require.NoError(t, err)
+	assert.Empty(t, reports)
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM component_migrations WHERE pipeline_id = 1").Scan(&recorded))
+	assert.Equal(t, 2, recorded)
+}
+
+func TestMigrateNodeConfigs_MissingMigration(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	require.NoError(t, database.LoadSchemasFromDirectory(db,
+		fstest.MapFS{"batch_processor.json": {Data: []byte(`{"type": "object", "version": 2}`)}},
+		fstest.MapFS{"batch_processor.json": {Data: []byte(`{}`)}},
+		map[string]string{"batch_processor": "processor"},
+		map[string][]string{"batch_processor": {"logs"}}))
+	_, err := db.Exec("INSERT INTO pipelines (pipeline_id, name, created_by) VALUES (1, 'edge', 'admin')")
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO pipeline_components (pipeline_id, component_role, component_name, name, config, supported_signals) VALUES
+		(1, 'processor', 'batch_processor', 'batch', '{"timeout": "5s"}', 'logs')`)
+	require.NoError(t, err)
+
+	reports, err := database.MigrateNodeConfigs(db, database.GetConfigMigrations())
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, "no migration from schema version 1 of batch_processor", reports[0].Error)
+
+	var version int
+	require.NoError(t, db.QueryRow("SELECT schema_version FROM pipeline_components").Scan(&version))
+	assert.Equal(t, 1, version)
+}
@@ -31,6 +31,9 @@ func DBInit(dbPath string) (*sql.DB, error) {
 	if err := createAgentsLabelsTable(db); err != nil {
 		return nil, err
 	}
+	if err := createAgentComponentsTable(db); err != nil {
+		return nil, err
+	}
 	if err := createAggregatedAgentMetricsTable(db); err != nil {
 		return nil, err
 	}
@@ -43,15 +46,84 @@ func DBInit(dbPath string) (*sql.DB, error) {
 	if err := createPipelinesTable(db); err != nil {
 		return nil, err
 	}
+	if err := addColumnIfMissing(db, "pipelines", "telemetry_json", "TEXT DEFAULT NULL"); err != nil {
+		return nil, err
+	}
+	if err := addColumnIfMissing(db, "pipelines", "environment", "TEXT DEFAULT NULL"); err != nil {
+		return nil, err
+	}
+	if err := addColumnIfMissing(db, "pipelines", "label_selector", "TEXT DEFAULT NULL"); err != nil {
+		return nil, err
+	}
+	if err := addColumnIfMissing(db, "pipelines", "drift_policy", "TEXT DEFAULT NULL"); err != nil {
+		return nil, err
+	}
 	if err := createPipelineComponentsTable(db); err != nil {
 		return nil, err
 	}
+	if err := addColumnIfMissing(db, "pipeline_components", "schema_version", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return nil, err
+	}
 	if err := createPipelineComponentDependenciesTable(db); err != nil {
 		return nil, err
 	}
 	if err := createComponentSchemasTable(db); err != nil {
 		return nil, err
 	}
+	if err := addColumnIfMissing(db, "component_schemas", "version", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return nil, err
+	}
+	if err := addColumnIfMissing(db, "component_schemas", "custom", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return nil, err
+	}
+	if err := createComponentMigrationsTable(db); err != nil {
+		return nil, err
+	}
+	if err := createPipelineTemplatesTable(db); err != nil {
+		return nil, err
+	}
+	if err := createSecretsTable(db); err != nil {
+		return nil, err
+	}
+	if err := createAgentGroupsTable(db); err != nil {
+		return nil, err
+	}
+	if err := createAgentGroupMembersTable(db); err != nil {
+		return nil, err
+	}
+	if err := createConfigDeliveriesTable(db); err != nil {
+		return nil, err
+	}
+	if err := createConfigDriftTable(db); err != nil {
+		return nil, err
+	}
+	if err := createConfigAppliesTable(db); err != nil {
+		return nil, err
+	}
+	if err := addColumnIfMissing(db, "config_applies", "rolled_back", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return nil, err
+	}
+	if err := createAgentArtifactsTable(db); err != nil {
+		return nil, err
+	}
+	if err := createAgentUpgradesTable(db); err != nil {
+		return nil, err
+	}
+	if err := addColumnIfMissing(db, "agent_upgrades", "job_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return nil, err
+	}
+	if err := createJobsTable(db); err != nil {
+		return nil, err
+	}
+	if err := addColumnIfMissing(db, "jobs", "concurrency", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return nil, err
+	}
+	if err := addColumnIfMissing(db, "jobs", "halt_on_failure", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return nil, err
+	}
+	if err := createJobTargetsTable(db); err != nil {
+		return nil, err
+	}
 
 	utils.Logger.Info("All tables created (or verified) successfully.")
 	return db, nil
@@ -116,6 +188,26 @@ func createAgentsLabelsTable(db *sql.DB) error {
 	return err
 }
 
+// Collector components each agent reported at registration
+func createAgentComponentsTable(db *sql.DB) error {
+	query := `
+    CREATE TABLE IF NOT EXISTS agent_components (
+		agent_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,             -- receiver, processor, exporter, connector or extension
+		type TEXT NOT NULL,             -- collector component type, e.g. otlp
+		name TEXT NOT NULL,             -- component_schemas name, e.g. otlp_receiver
+		stability_json TEXT NOT NULL,   -- stability level per supported signal
+		PRIMARY KEY (agent_id, kind, type),
+		FOREIGN KEY (agent_id) REFERENCES agents(id) ON DELETE CASCADE
+	);
+    `
+	_, err := db.Exec(query)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error creating agent_components table: %v", err))
+	}
+	return err
+}
+
 // Aggregated agent metrics table with a Unix timestamp for updated_at
 func createAggregatedAgentMetricsTable(db *sql.DB) error {
 	query := `
@@ -196,6 +288,9 @@ func createPipelinesTable(db *sql.DB) error {
         pipeline_id INTEGER PRIMARY KEY AUTOINCREMENT,
         name TEXT NOT NULL,
 		config_json TEXT,
+		telemetry_json TEXT DEFAULT NULL, -- JSON-encoded service.telemetry settings
+		environment TEXT DEFAULT NULL,    -- environment label used for promotion, e.g. dev
+		label_selector TEXT DEFAULT NULL, -- agents matching this selector are attached automatically
         created_by TEXT NOT NULL,
         created_at INTEGER DEFAULT (strftime('%s', 'now')),
         updated_at INTEGER DEFAULT (strftime('%s', 'now'))
@@ -221,6 +316,7 @@ func createPipelineComponentsTable(db *sql.DB) error {
         name TEXT,
         config TEXT,
 		supported_signals TEXT NOT NULL, -- Comma-separated: traces,metrics,logs
+		schema_version INTEGER NOT NULL DEFAULT 1, -- component schema version the config was saved against
         created_at INTEGER DEFAULT (strftime('%s', 'now')), -- Unix timestamp
         FOREIGN KEY (pipeline_id) REFERENCES pipelines(pipeline_id) ON DELETE CASCADE
     );
@@ -262,6 +358,8 @@ func createComponentSchemasTable(db *sql.DB) error {
         supported_signals TEXT NOT NULL,     				-- Comma-separated: traces,metrics,logs
         schema_json TEXT NOT NULL,           				-- Full JSON schema
 		ui_schema_json TEXT NOT NULL,                 				-- UI schema for configuration
+		version INTEGER NOT NULL DEFAULT 1,           				-- Schema version, bumped when configs need migrating
+		custom INTEGER NOT NULL DEFAULT 0,            				-- 1 if registered through the API rather than shipped
         created_at INTEGER DEFAULT (strftime('%s', 'now')) 	-- Unix timestamp
     );
     `
@@ -271,3 +369,309 @@ func createComponentSchemasTable(db *sql.DB) error {
 	}
 	return err
 }
+
+// Node config migrations run when a component schema's version changed
+func createComponentMigrationsTable(db *sql.DB) error {
+	query := `
+    CREATE TABLE IF NOT EXISTS component_migrations (
+        migration_id INTEGER PRIMARY KEY AUTOINCREMENT,
+        pipeline_id INTEGER NOT NULL,
+        component_id INTEGER NOT NULL,
+        component_name TEXT NOT NULL,
+        name TEXT NOT NULL DEFAULT '',         -- node name
+        from_version INTEGER NOT NULL,
+        to_version INTEGER NOT NULL,
+        changes_json TEXT NOT NULL,            -- JSON array of change descriptions
+        error TEXT NOT NULL DEFAULT '',        -- set when the config was left unmigrated
+        migrated_at INTEGER DEFAULT (strftime('%s', 'now')),
+        FOREIGN KEY (pipeline_id) REFERENCES pipelines(pipeline_id) ON DELETE CASCADE
+    );
+    `
+	_, err := db.Exec(query)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error creating component_migrations table: %v", err))
+	}
+	return err
+}
+
+// addColumnIfMissing adds a column to a table created by an older release.
+// CREATE TABLE IF NOT EXISTS leaves existing tables untouched, so columns
+// introduced later have to be added explicitly.
+func addColumnIfMissing(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s);", table))
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error reading columns of %s table: %v", table, err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    int
+			defaultVal sql.NullString
+			primaryKey int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &primaryKey); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", table, column, definition))
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error adding column %s to %s table: %v", column, table, err))
+	}
+	return err
+}
+
+// Agent groups table: static groups list their members, label groups select
+// them with a label selector
+func createAgentGroupsTable(db *sql.DB) error {
+	query := `
+    CREATE TABLE IF NOT EXISTS agent_groups (
+        group_id INTEGER PRIMARY KEY AUTOINCREMENT,
+        name TEXT NOT NULL UNIQUE,
+        description TEXT NOT NULL DEFAULT '',
+        selector TEXT DEFAULT NULL,           -- label selector; NULL for static groups
+        created_by TEXT NOT NULL,
+        created_at INTEGER DEFAULT (strftime('%s', 'now')),
+        updated_at INTEGER DEFAULT (strftime('%s', 'now'))
+    );
+    `
+	_, err := db.Exec(query)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error creating agent_groups table: %v", err))
+	}
+	return err
+}
+
+// Members of static agent groups
+func createAgentGroupMembersTable(db *sql.DB) error {
+	query := `
+    CREATE TABLE IF NOT EXISTS agent_group_members (
+        group_id INTEGER NOT NULL,
+        agent_id INTEGER NOT NULL,
+        PRIMARY KEY (group_id, agent_id),
+        FOREIGN KEY (group_id) REFERENCES agent_groups(group_id) ON DELETE CASCADE,
+        FOREIGN KEY (agent_id) REFERENCES agents(id) ON DELETE CASCADE
+    );
+    `
+	_, err := db.Exec(query)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error creating agent_group_members table: %v", err))
+	}
+	return err
+}
+
+// Config delivery outbox: the config version each agent should run and
+// whether it got there, so failed pushes are retried
+func createConfigDeliveriesTable(db *sql.DB) error {
+	query := `
+    CREATE TABLE IF NOT EXISTS config_deliveries (
+        agent_id INTEGER PRIMARY KEY,
+        pipeline_id INTEGER NOT NULL,
+        config_version TEXT NOT NULL,         -- hash of the compiled config
+        status TEXT NOT NULL,                 -- pending, delivered or rejected
+        attempts INTEGER NOT NULL DEFAULT 0,
+        last_error TEXT NOT NULL DEFAULT '',
+        next_attempt_at INTEGER DEFAULT NULL, -- set while pending
+        updated_at INTEGER NOT NULL,
+        delivered_at INTEGER DEFAULT NULL,
+        FOREIGN KEY (agent_id) REFERENCES agents(id) ON DELETE CASCADE,
+        FOREIGN KEY (pipeline_id) REFERENCES pipelines(pipeline_id) ON DELETE CASCADE
+    );
+    `
+	_, err := db.Exec(query)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error creating config_deliveries table: %v", err))
+	}
+	return err
+}
+
+// Config drift table: the last check of each agent's effective config against
+// its pipeline's config
+func createConfigDriftTable(db *sql.DB) error {
+	query := `
+    CREATE TABLE IF NOT EXISTS config_drift (
+        agent_id INTEGER PRIMARY KEY,
+        pipeline_id INTEGER NOT NULL,
+        status TEXT NOT NULL,                 -- in_sync or drifted
+        desired_hash TEXT NOT NULL,
+        effective_hash TEXT NOT NULL,         -- as reported by the agent
+        action TEXT NOT NULL DEFAULT '',      -- what the drift policy did
+        error TEXT NOT NULL DEFAULT '',
+        drifted_since INTEGER DEFAULT NULL,
+        checked_at INTEGER NOT NULL,
+        FOREIGN KEY (agent_id) REFERENCES agents(id) ON DELETE CASCADE,
+        FOREIGN KEY (pipeline_id) REFERENCES pipelines(pipeline_id) ON DELETE CASCADE
+    );
+    `
+	_, err := db.Exec(query)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error creating config_drift table: %v", err))
+	}
+	return err
+}
+
+// Config applies table: agents' reports of loading configs into their
+// collector, newest kept per agent
+func createConfigAppliesTable(db *sql.DB) error {
+	query := `
+    CREATE TABLE IF NOT EXISTS config_applies (
+        apply_id INTEGER PRIMARY KEY AUTOINCREMENT,
+        agent_id INTEGER NOT NULL,
+        pipeline_id INTEGER DEFAULT NULL,     -- the agent's pipeline when it reported
+        config_version TEXT NOT NULL,         -- hash of the config file the agent loaded
+        status TEXT NOT NULL,                 -- applied or failed
+        error TEXT NOT NULL DEFAULT '',
+        rolled_back INTEGER NOT NULL DEFAULT 0, -- the agent restored its last-known-good config
+        components_json TEXT NOT NULL,        -- component statuses reported by the collector
+        reported_at INTEGER NOT NULL,
+        FOREIGN KEY (agent_id) REFERENCES agents(id) ON DELETE CASCADE,
+        FOREIGN KEY (pipeline_id) REFERENCES pipelines(pipeline_id) ON DELETE SET NULL
+    );
+    `
+	_, err := db.Exec(query)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error creating config_applies table: %v", err))
+	}
+	return err
+}
+
+// Released agent binaries, one per version and platform
+func createAgentArtifactsTable(db *sql.DB) error {
+	query := `
+    CREATE TABLE IF NOT EXISTS agent_artifacts (
+        artifact_id INTEGER PRIMARY KEY AUTOINCREMENT,
+        version TEXT NOT NULL,
+        os TEXT NOT NULL,
+        arch TEXT NOT NULL,
+        url TEXT NOT NULL,
+        sha256 TEXT NOT NULL,                 -- hex digest of the binary
+        signature TEXT NOT NULL,              -- base64 Ed25519 signature of the digest
+        created_at INTEGER NOT NULL,
+        UNIQUE (version, os, arch)
+    );
+    `
+	_, err := db.Exec(query)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error creating agent_artifacts table: %v", err))
+	}
+	return err
+}
+
+// Agent upgrade attempts, updated when the agent reports back
+func createAgentUpgradesTable(db *sql.DB) error {
+	query := `
+    CREATE TABLE IF NOT EXISTS agent_upgrades (
+        upgrade_id INTEGER PRIMARY KEY AUTOINCREMENT,
+        agent_id INTEGER NOT NULL,
+        job_id TEXT NOT NULL DEFAULT '',      -- rollout job that started the upgrade
+        from_version TEXT NOT NULL,
+        to_version TEXT NOT NULL,
+        status TEXT NOT NULL,                 -- in_progress, succeeded, failed or rolled_back
+        error TEXT NOT NULL DEFAULT '',
+        created_at INTEGER NOT NULL,
+        updated_at INTEGER NOT NULL,
+        FOREIGN KEY (agent_id) REFERENCES agents(id) ON DELETE CASCADE
+    );
+    `
+	_, err := db.Exec(query)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error creating agent_upgrades table: %v", err))
+	}
+	return err
+}
+
+// Jobs table: long-running operations such as bulk actions, resumed after a
+// backend restart until they finish
+func createJobsTable(db *sql.DB) error {
+	query := `
+    CREATE TABLE IF NOT EXISTS jobs (
+        job_id TEXT PRIMARY KEY,
+        type TEXT NOT NULL,
+        status TEXT NOT NULL,
+        params_json TEXT NOT NULL,            -- JSON handed to the job type's executor
+        concurrency INTEGER NOT NULL DEFAULT 0, -- 0 uses the job manager's limit
+        halt_on_failure INTEGER NOT NULL DEFAULT 0,
+        created_at INTEGER NOT NULL,
+        updated_at INTEGER NOT NULL,
+        finished_at INTEGER DEFAULT NULL
+    );
+    `
+	_, err := db.Exec(query)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error creating jobs table: %v", err))
+	}
+	return err
+}
+
+// Per-target state of jobs, in the order the targets were given
+func createJobTargetsTable(db *sql.DB) error {
+	query := `
+    CREATE TABLE IF NOT EXISTS job_targets (
+        job_id TEXT NOT NULL,
+        position INTEGER NOT NULL,
+        target TEXT NOT NULL,
+        status TEXT NOT NULL,
+        attempts INTEGER NOT NULL DEFAULT 0,
+        error TEXT NOT NULL DEFAULT '',
+        PRIMARY KEY (job_id, position),
+        FOREIGN KEY (job_id) REFERENCES jobs(job_id) ON DELETE CASCADE
+    );
+    `
+	_, err := db.Exec(query)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error creating job_targets table: %v", err))
+	}
+	return err
+}
+
+// Secrets table: encrypted values referenced from node configs as ${secret:name}
+func createSecretsTable(db *sql.DB) error {
+	query := `
+    CREATE TABLE IF NOT EXISTS secrets (
+        secret_id INTEGER PRIMARY KEY AUTOINCREMENT,
+        name TEXT NOT NULL UNIQUE,
+        description TEXT NOT NULL DEFAULT '',
+        value BLOB NOT NULL,                  -- AES-GCM nonce followed by the ciphertext
+        created_by TEXT NOT NULL,
+        created_at INTEGER DEFAULT (strftime('%s', 'now')),
+        updated_at INTEGER DEFAULT (strftime('%s', 'now'))
+    );
+    `
+	_, err := db.Exec(query)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error creating secrets table: %v", err))
+	}
+	return err
+}
+
+// Pipeline templates table: reusable graphs with typed ${param} placeholders
+func createPipelineTemplatesTable(db *sql.DB) error {
+	query := `
+    CREATE TABLE IF NOT EXISTS pipeline_templates (
+        template_id INTEGER PRIMARY KEY AUTOINCREMENT,
+        name TEXT NOT NULL UNIQUE,
+        description TEXT NOT NULL DEFAULT '',
+        parameters_json TEXT NOT NULL,        -- JSON array of TemplateParameter
+        graph_json TEXT NOT NULL,             -- JSON-encoded PipelineGraph with placeholders
+        created_by TEXT NOT NULL,
+        created_at INTEGER DEFAULT (strftime('%s', 'now')),
+        updated_at INTEGER DEFAULT (strftime('%s', 'now'))
+    );
+    `
+	_, err := db.Exec(query)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error creating pipeline_templates table: %v", err))
+	}
+	return err
+}
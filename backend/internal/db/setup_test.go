@@ -19,6 +19,7 @@ func TestDBInitWithPath_CreatesAllTables(t *testing.T) {
 		"user",
 		"agents",
 		"agents_labels",
+		"agent_components",
 		"aggregated_agent_metrics",
 		"realtime_agent_metrics",
 		"extensions",
@@ -26,6 +27,18 @@ func TestDBInitWithPath_CreatesAllTables(t *testing.T) {
 		"pipeline_components",
 		"pipeline_component_edges",
 		"component_schemas",
+		"component_migrations",
+		"pipeline_templates",
+		"secrets",
+		"agent_groups",
+		"agent_group_members",
+		"config_deliveries",
+		"config_drift",
+		"config_applies",
+		"agent_artifacts",
+		"agent_upgrades",
+		"jobs",
+		"job_targets",
 	}
 
 	for _, table := range expectedTables {
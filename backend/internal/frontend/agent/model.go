@@ -20,6 +20,8 @@ type AgentInfoWithLabels struct {
 	IP           string            `json:"ip"`            // IP where the agent is running
 	Platform     string            `json:"platform"`      // Operating system platform (e.g., linux, windows)
 	Labels       map[string]string `json:"labels"`        // Labels associated with the agent
+	ConfigDrift  string            `json:"config_drift"`  // Whether the agent runs its pipeline's config (in_sync, drifted or unknown)
+	ConfigStatus string            `json:"config_status"` // Outcome of the agent's last config apply (applied, failed or unknown)
 }
 
 type AgentMetrics struct {
@@ -116,6 +116,27 @@ func (f *FrontendAgentRepository) GetAgent(id string) (*AgentInfoWithLabels, err
 		}
 	}
 
+	// Drift is only known once the agent reported its config to its pipeline
+	if pipelineId.Valid {
+		err = f.db.QueryRow("SELECT status FROM config_drift WHERE agent_id = ? AND pipeline_id = ?", id, pipelineId.Int64).Scan(&agent.ConfigDrift)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				agent.ConfigDrift = "unknown"
+			} else {
+				return nil, err
+			}
+		}
+	}
+
+	err = f.db.QueryRow("SELECT status FROM config_applies WHERE agent_id = ? ORDER BY apply_id DESC LIMIT 1", id).Scan(&agent.ConfigStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			agent.ConfigStatus = "unknown"
+		} else {
+			return nil, err
+		}
+	}
+
 	agent.Labels = make(map[string]string)
 	rows, err := f.db.Query("SELECT key, value FROM agents_labels WHERE agent_id = ?", id)
 	if err != nil {
@@ -72,6 +72,14 @@ func TestGetAgent(t *testing.T) {
 		WithArgs("1").
 		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("healthy"))
 
+	mock.ExpectQuery("SELECT status FROM config_drift WHERE agent_id = \\? AND pipeline_id = \\?").
+		WithArgs("1", int64(123)).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("drifted"))
+
+	mock.ExpectQuery("SELECT status FROM config_applies WHERE agent_id = \\? ORDER BY apply_id DESC LIMIT 1").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("failed"))
+
 	mock.ExpectQuery("SELECT key, value FROM agents_labels WHERE agent_id = ?").
 		WithArgs("1").
 		WillReturnRows(sqlmock.NewRows([]string{"key", "value"}).
@@ -82,7 +90,7 @@ func TestGetAgent(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if agent.Name != "agent1" || agent.Labels["env"] != "prod" {
+	if agent.Name != "agent1" || agent.Labels["env"] != "prod" || agent.ConfigDrift != "drifted" || agent.ConfigStatus != "failed" {
 		t.Errorf("unexpected agent data: %+v", agent)
 	}
 }
@@ -3,8 +3,10 @@ package frontendagent
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
+	frontendpipeline "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/pipeline"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/queue"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
@@ -27,6 +29,7 @@ type FrontendAgentRepositoryInterface interface {
 type FrontendAgentService struct {
 	FrontendAgentRepository FrontendAgentRepositoryInterface
 	AgentQueue              queue.AgentQueueInterface
+	PipelineService         frontendpipeline.FrontendPipelineServiceInterface
 }
 
 type FrontendAgentServiceInterface interface {
@@ -43,11 +46,13 @@ type FrontendAgentServiceInterface interface {
 	GetLatestAgentSince(since string) (*LatestAgentResponse, error)
 }
 
-// NewFrontendAgentService creates a new FrontendAgentService
-func NewFrontendAgentService(frontendAgentRepository FrontendAgentRepositoryInterface, agentQueue queue.AgentQueueInterface) FrontendAgentServiceInterface {
+// NewFrontendAgentService creates a new FrontendAgentService. The pipeline
+// service, when set, assigns agents by label selector after their labels change.
+func NewFrontendAgentService(frontendAgentRepository FrontendAgentRepositoryInterface, agentQueue queue.AgentQueueInterface, pipelineService frontendpipeline.FrontendPipelineServiceInterface) FrontendAgentServiceInterface {
 	return &FrontendAgentService{
 		FrontendAgentRepository: frontendAgentRepository,
 		AgentQueue:              agentQueue,
+		PipelineService:         pipelineService,
 	}
 }
 
@@ -185,7 +190,29 @@ func (f *FrontendAgentService) AddLabels(id string, labels map[string]string) er
 		return utils.ErrAgentDoesNotExists
 	}
 
-	return f.FrontendAgentRepository.AddLabels(id, labels)
+	if err := f.FrontendAgentRepository.AddLabels(id, labels); err != nil {
+		return err
+	}
+
+	if f.PipelineService == nil {
+		return nil
+	}
+
+	// New labels may make an unassigned agent match a pipeline's selector
+	agentId, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("error converting agent ID to int: %v", err)
+	}
+	pipelineId, err := f.PipelineService.AssignAgentBySelector(agentId)
+	if err != nil {
+		return err
+	}
+	if pipelineId != nil {
+		if err := f.PipelineService.SyncConfig(id); err != nil {
+			utils.Logger.Sugar().Errorf("Failed to send config to agent [ID:%v] assigned by label selector: %v", id, err)
+		}
+	}
+	return nil
 }
 
 func (f *FrontendAgentService) sendAgentCommand(hostname, ip, command string) error {
@@ -78,12 +78,14 @@ func (mq *MockQueue) RefreshMonitoring() error {
 	return args.Error(0)
 }
 
+func (mq *MockQueue) OnReconnect(handler func(agentID string)) {}
+
 // --- Tests ---
 
 func TestGetAllUnmanagedAgents(t *testing.T) {
 	repo := new(MockRepo)
 	q := new(MockQueue)
-	svc := frontendagent.NewFrontendAgentService(repo, q)
+	svc := frontendagent.NewFrontendAgentService(repo, q, nil)
 
 	expected := []frontendagent.UnmanagedAgents{{ID: "1"}}
 	repo.On("GetAllUnmanagedAgents").Return(expected, nil)
@@ -96,7 +98,7 @@ func TestGetAllUnmanagedAgents(t *testing.T) {
 func TestGetAgent_Success(t *testing.T) {
 	repo := new(MockRepo)
 	q := new(MockQueue)
-	svc := frontendagent.NewFrontendAgentService(repo, q)
+	svc := frontendagent.NewFrontendAgentService(repo, q, nil)
 
 	agent := &frontendagent.AgentInfoWithLabels{}
 	repo.On("AgentExists", "1").Return(true)
@@ -110,7 +112,7 @@ func TestGetAgent_Success(t *testing.T) {
 func TestGetAgent_NotFound(t *testing.T) {
 	repo := new(MockRepo)
 	q := new(MockQueue)
-	svc := frontendagent.NewFrontendAgentService(repo, q)
+	svc := frontendagent.NewFrontendAgentService(repo, q, nil)
 
 	repo.On("AgentExists", "2").Return(false)
 
@@ -122,7 +124,7 @@ func TestGetAgent_NotFound(t *testing.T) {
 func TestStopAgent_Success(t *testing.T) {
 	repo := new(MockRepo)
 	q := new(MockQueue)
-	svc := frontendagent.NewFrontendAgentService(repo, q)
+	svc := frontendagent.NewFrontendAgentService(repo, q, nil)
 
 	repo.On("AgentExists", "agent-1").Return(true)
 	repo.On("GetAgentNetworkInfoByID", "agent-1").Return("host", "ip", nil)
@@ -137,7 +139,7 @@ func TestStopAgent_Success(t *testing.T) {
 func TestRestartMonitoring(t *testing.T) {
 	repo := new(MockRepo)
 	q := new(MockQueue)
-	svc := frontendagent.NewFrontendAgentService(repo, q)
+	svc := frontendagent.NewFrontendAgentService(repo, q, nil)
 
 	repo.On("AgentExists", "agent-1").Return(true)
 	repo.On("GetAgentNetworkInfoByID", "agent-1").Return("host", "ip", nil)
@@ -150,7 +152,7 @@ func TestRestartMonitoring(t *testing.T) {
 func TestGetHealthMetrics(t *testing.T) {
 	repo := new(MockRepo)
 	q := new(MockQueue)
-	svc := frontendagent.NewFrontendAgentService(repo, q)
+	svc := frontendagent.NewFrontendAgentService(repo, q, nil)
 
 	mockMetrics := &[]frontendagent.AgentMetrics{{}}
 	repo.On("AgentExists", "a1").Return(true)
@@ -164,7 +166,7 @@ func TestGetHealthMetrics(t *testing.T) {
 func TestGetRateMetrics(t *testing.T) {
 	repo := new(MockRepo)
 	q := new(MockQueue)
-	svc := frontendagent.NewFrontendAgentService(repo, q)
+	svc := frontendagent.NewFrontendAgentService(repo, q, nil)
 
 	mockMetrics := &[]frontendagent.AgentMetrics{{}}
 	repo.On("AgentExists", "a1").Return(true)
@@ -178,7 +180,7 @@ func TestGetRateMetrics(t *testing.T) {
 func TestGetLatestAgentSince(t *testing.T) {
 	repo := new(MockRepo)
 	q := new(MockQueue)
-	svc := frontendagent.NewFrontendAgentService(repo, q)
+	svc := frontendagent.NewFrontendAgentService(repo, q, nil)
 
 	mockResp := &frontendagent.LatestAgentResponse{ID: "latest"}
 	repo.On("GetLatestAgentSince", "2024-01-01T00:00:00Z").Return(mockResp, nil)
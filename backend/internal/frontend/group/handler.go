@@ -0,0 +1,172 @@
+package frontendgroup
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+	"github.com/gorilla/mux"
+)
+
+// FrontendGroupHandler handles agent group and bulk operations
+type FrontendGroupHandler struct {
+	FrontendGroupService FrontendGroupServiceInterface
+}
+
+// NewFrontendGroupHandler initializes the handler
+func NewFrontendGroupHandler(frontendGroupService FrontendGroupServiceInterface) *FrontendGroupHandler {
+	return &FrontendGroupHandler{
+		FrontendGroupService: frontendGroupService,
+	}
+}
+
+func (f *FrontendGroupHandler) GetAllGroups(w http.ResponseWriter, r *http.Request) {
+	utils.Logger.Info("Request received to get all agent groups")
+
+	response, err := f.FrontendGroupService.GetAllGroups()
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error while getting all agent groups: %v", err))
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (f *FrontendGroupHandler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	groupId := mux.Vars(r)["id"]
+	groupIdInt, err := strconv.Atoi(groupId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid group ID format")
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to get agent group with ID: %s", groupId))
+
+	response, err := f.FrontendGroupService.GetGroup(groupIdInt)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error getting agent group [ID: %s]: %v", groupId, err))
+		sendGroupError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (f *FrontendGroupHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	var req models.AgentGroupRequest
+	if err := utils.UnmarshalJSONRequest(r, &req); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to create agent group: %s", req.Name))
+
+	groupId, err := f.FrontendGroupService.CreateGroup(req)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error creating agent group %s: %v", req.Name, err))
+		sendGroupError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]any{"message": "Agent group created successfully", "id": groupId})
+}
+
+func (f *FrontendGroupHandler) UpdateGroup(w http.ResponseWriter, r *http.Request) {
+	groupId := mux.Vars(r)["id"]
+	groupIdInt, err := strconv.Atoi(groupId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid group ID format")
+		return
+	}
+
+	var req models.AgentGroupRequest
+	if err := utils.UnmarshalJSONRequest(r, &req); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to update agent group with ID: %s", groupId))
+
+	if err := f.FrontendGroupService.UpdateGroup(groupIdInt, req); err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error updating agent group [ID: %s]: %v", groupId, err))
+		sendGroupError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Agent group updated successfully"})
+}
+
+func (f *FrontendGroupHandler) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	groupId := mux.Vars(r)["id"]
+	groupIdInt, err := strconv.Atoi(groupId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid group ID format")
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to delete agent group with ID: %s", groupId))
+
+	if err := f.FrontendGroupService.DeleteGroup(groupIdInt); err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error deleting agent group [ID: %s]: %v", groupId, err))
+		sendGroupError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Agent group deleted successfully"})
+}
+
+func (f *FrontendGroupHandler) GetGroupAgents(w http.ResponseWriter, r *http.Request) {
+	groupId := mux.Vars(r)["id"]
+	groupIdInt, err := strconv.Atoi(groupId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid group ID format")
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to get agents of agent group with ID: %s", groupId))
+
+	response, err := f.FrontendGroupService.GetGroupAgents(groupIdInt)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error getting agents of agent group [ID: %s]: %v", groupId, err))
+		sendGroupError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (f *FrontendGroupHandler) RunBulkAction(w http.ResponseWriter, r *http.Request) {
+	groupId := mux.Vars(r)["id"]
+	groupIdInt, err := strconv.Atoi(groupId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid group ID format")
+		return
+	}
+
+	var req models.BulkActionRequest
+	if err := utils.UnmarshalJSONRequest(r, &req); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to run %s on agent group with ID: %s", req.Action, groupId))
+
+	job, err := f.FrontendGroupService.RunBulkAction(groupIdInt, req)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error running %s on agent group [ID: %s]: %v", req.Action, groupId, err))
+		sendGroupError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusAccepted, job)
+}
+
+// sendGroupError maps service errors to status codes: 404 for a missing
+// group, 400 for invalid requests and 500 otherwise.
+func sendGroupError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, utils.ErrGroupDoesNotExists):
+		utils.SendJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidGroup):
+		utils.SendJSONError(w, http.StatusBadRequest, err.Error())
+	default:
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,221 @@
+package frontendgroup
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+)
+
+type FrontendGroupRepository struct {
+	db *sql.DB
+}
+
+// NewFrontendGroupRepository creates a new FrontendGroupRepository
+func NewFrontendGroupRepository(db *sql.DB) *FrontendGroupRepository {
+	return &FrontendGroupRepository{db: db}
+}
+
+func (f *FrontendGroupRepository) GroupExists(groupId int) bool {
+	var verifyId int
+	err := f.db.QueryRow("SELECT group_id FROM agent_groups WHERE group_id = ? LIMIT 1", groupId).Scan(&verifyId)
+	return err == nil
+}
+
+func (f *FrontendGroupRepository) GetAllGroups() ([]models.AgentGroup, error) {
+	rows, err := f.db.Query("SELECT group_id, name, description, COALESCE(selector, ''), created_by, created_at, updated_at FROM agent_groups ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agent groups: %w", err)
+	}
+
+	groups := []models.AgentGroup{}
+	for rows.Next() {
+		var group models.AgentGroup
+		if err := rows.Scan(&group.ID, &group.Name, &group.Description, &group.Selector, &group.CreatedBy, &group.CreatedAt, &group.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range groups {
+		if groups[i].AgentIDs, err = f.getGroupMembers(groups[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return groups, nil
+}
+
+func (f *FrontendGroupRepository) GetGroup(groupId int) (*models.AgentGroup, error) {
+	var group models.AgentGroup
+	err := f.db.QueryRow(
+		"SELECT group_id, name, description, COALESCE(selector, ''), created_by, created_at, updated_at FROM agent_groups WHERE group_id = ?", groupId,
+	).Scan(&group.ID, &group.Name, &group.Description, &group.Selector, &group.CreatedBy, &group.CreatedAt, &group.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrGroupDoesNotExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agent group: %w", err)
+	}
+
+	if group.AgentIDs, err = f.getGroupMembers(groupId); err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (f *FrontendGroupRepository) CreateGroup(group models.AgentGroup) (int, error) {
+	tx, err := f.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	now := utils.GetCurrentTime()
+	result, err := tx.Exec(
+		"INSERT INTO agent_groups (name, description, selector, created_by, created_at, updated_at) VALUES (?, ?, NULLIF(?, ''), ?, ?, ?)",
+		group.Name, group.Description, group.Selector, group.CreatedBy, now, now,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert agent group: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get agent group ID: %w", err)
+	}
+
+	if err := insertGroupMembers(tx, int(id), group.AgentIDs); err != nil {
+		return 0, err
+	}
+	return int(id), tx.Commit()
+}
+
+// UpdateGroup replaces a group's name, description, selector and members.
+func (f *FrontendGroupRepository) UpdateGroup(group models.AgentGroup) error {
+	tx, err := f.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		"UPDATE agent_groups SET name = ?, description = ?, selector = NULLIF(?, ''), updated_at = ? WHERE group_id = ?",
+		group.Name, group.Description, group.Selector, utils.GetCurrentTime(), group.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update agent group: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM agent_group_members WHERE group_id = ?", group.ID); err != nil {
+		return fmt.Errorf("failed to clear agent group members: %w", err)
+	}
+	if err := insertGroupMembers(tx, group.ID, group.AgentIDs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (f *FrontendGroupRepository) DeleteGroup(groupId int) error {
+	_, err := f.db.Exec("DELETE FROM agent_groups WHERE group_id = ?", groupId)
+	if err != nil {
+		return fmt.Errorf("failed to delete agent group: %w", err)
+	}
+	return nil
+}
+
+// GetMissingAgents returns the IDs in agentIds that don't belong to an agent.
+func (f *FrontendGroupRepository) GetMissingAgents(agentIds []int) ([]int, error) {
+	var missing []int
+	for _, agentId := range agentIds {
+		var verifyId int
+		err := f.db.QueryRow("SELECT id FROM agents WHERE id = ?", agentId).Scan(&verifyId)
+		if err == sql.ErrNoRows {
+			missing = append(missing, agentId)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to query agent: %w", err)
+		}
+	}
+	return missing, nil
+}
+
+// GetAgentsWithLabels returns every agent with its labels.
+func (f *FrontendGroupRepository) GetAgentsWithLabels() ([]models.AgentLabels, error) {
+	rows, err := f.db.Query("SELECT id, name, pipeline_id FROM agents ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agents: %w", err)
+	}
+
+	agents := []models.AgentLabels{}
+	index := make(map[int64]int)
+	for rows.Next() {
+		var agent models.AgentLabels
+		var pipelineId sql.NullInt64
+		if err := rows.Scan(&agent.ID, &agent.Name, &pipelineId); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if pipelineId.Valid {
+			id := int(pipelineId.Int64)
+			agent.PipelineID = &id
+		}
+		agent.Labels = map[string]string{}
+		index[agent.ID] = len(agents)
+		agents = append(agents, agent)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	labelRows, err := f.db.Query("SELECT agent_id, key, value FROM agents_labels")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agent labels: %w", err)
+	}
+	defer labelRows.Close()
+	for labelRows.Next() {
+		var id int64
+		var key, value string
+		if err := labelRows.Scan(&id, &key, &value); err != nil {
+			return nil, err
+		}
+		if i, ok := index[id]; ok {
+			agents[i].Labels[key] = value
+		}
+	}
+	return agents, labelRows.Err()
+}
+
+func (f *FrontendGroupRepository) getGroupMembers(groupId int) ([]int, error) {
+	rows, err := f.db.Query("SELECT agent_id FROM agent_group_members WHERE group_id = ? ORDER BY agent_id", groupId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agent group members: %w", err)
+	}
+	defer rows.Close()
+
+	var agentIds []int
+	for rows.Next() {
+		var agentId int
+		if err := rows.Scan(&agentId); err != nil {
+			return nil, err
+		}
+		agentIds = append(agentIds, agentId)
+	}
+	return agentIds, rows.Err()
+}
+
+func insertGroupMembers(tx *sql.Tx, groupId int, agentIds []int) error {
+	for _, agentId := range agentIds {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO agent_group_members (group_id, agent_id) VALUES (?, ?)", groupId, agentId); err != nil {
+			return fmt.Errorf("failed to insert agent group member: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package frontendgroup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	frontendagent "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/agent"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/jobs"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/labelselector"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+)
+
+// ErrInvalidGroup is returned for group and bulk action requests that fail
+// validation.
+var ErrInvalidGroup = errors.New("invalid agent group")
+
+// Bulk actions, each running the single-agent operation of the same name.
+const (
+	ActionStart             = "start"
+	ActionStop              = "stop"
+	ActionRestartMonitoring = "restart_monitoring"
+	ActionAddLabels         = "add_labels"
+	ActionDelete            = "delete"
+)
+
+type FrontendGroupRepositoryInterface interface {
+	GroupExists(groupId int) bool
+	GetAllGroups() ([]models.AgentGroup, error)
+	GetGroup(groupId int) (*models.AgentGroup, error)
+	CreateGroup(group models.AgentGroup) (int, error)
+	UpdateGroup(group models.AgentGroup) error
+	DeleteGroup(groupId int) error
+	GetMissingAgents(agentIds []int) ([]int, error)
+	GetAgentsWithLabels() ([]models.AgentLabels, error)
+}
+
+type FrontendGroupServiceInterface interface {
+	GetAllGroups() ([]models.AgentGroup, error)
+	GetGroup(groupId int) (*models.AgentGroup, error)
+	CreateGroup(req models.AgentGroupRequest) (int, error)
+	UpdateGroup(groupId int, req models.AgentGroupRequest) error
+	DeleteGroup(groupId int) error
+	GetGroupAgents(groupId int) ([]models.AgentLabels, error)
+	RunBulkAction(groupId int, req models.BulkActionRequest) (*models.Job, error)
+}
+
+type FrontendGroupService struct {
+	FrontendGroupRepository FrontendGroupRepositoryInterface
+	FrontendAgentService    frontendagent.FrontendAgentServiceInterface
+	Jobs                    *jobs.Manager
+}
+
+// NewFrontendGroupService creates a new FrontendGroupService. Bulk actions
+// run through the agent service as jobs of the job manager, which the service
+// registers its executors with.
+func NewFrontendGroupService(frontendGroupRepository FrontendGroupRepositoryInterface, frontendAgentService frontendagent.FrontendAgentServiceInterface, jobManager *jobs.Manager) FrontendGroupServiceInterface {
+	f := &FrontendGroupService{
+		FrontendGroupRepository: frontendGroupRepository,
+		FrontendAgentService:    frontendAgentService,
+		Jobs:                    jobManager,
+	}
+	for _, action := range []string{ActionStart, ActionStop, ActionRestartMonitoring, ActionAddLabels, ActionDelete} {
+		jobManager.Register(bulkJobType(action), f.runBulkActionTarget)
+	}
+	return f
+}
+
+func (f *FrontendGroupService) GetAllGroups() ([]models.AgentGroup, error) {
+	return f.FrontendGroupRepository.GetAllGroups()
+}
+
+func (f *FrontendGroupService) GetGroup(groupId int) (*models.AgentGroup, error) {
+	return f.FrontendGroupRepository.GetGroup(groupId)
+}
+
+func (f *FrontendGroupService) CreateGroup(req models.AgentGroupRequest) (int, error) {
+	group, err := f.validateGroupRequest(req)
+	if err != nil {
+		return 0, err
+	}
+	if req.CreatedBy == "" {
+		return 0, fmt.Errorf("%w: created_by is required", ErrInvalidGroup)
+	}
+
+	id, err := f.FrontendGroupRepository.CreateGroup(*group)
+	if utils.IsUniqueViolation(err) {
+		return 0, fmt.Errorf("%w: a group named %s already exists", ErrInvalidGroup, req.Name)
+	}
+	return id, err
+}
+
+// UpdateGroup replaces a group's definition. A group can switch between
+// static and label-based.
+func (f *FrontendGroupService) UpdateGroup(groupId int, req models.AgentGroupRequest) error {
+	if !f.FrontendGroupRepository.GroupExists(groupId) {
+		return utils.ErrGroupDoesNotExists
+	}
+
+	group, err := f.validateGroupRequest(req)
+	if err != nil {
+		return err
+	}
+	group.ID = groupId
+
+	err = f.FrontendGroupRepository.UpdateGroup(*group)
+	if utils.IsUniqueViolation(err) {
+		return fmt.Errorf("%w: a group named %s already exists", ErrInvalidGroup, req.Name)
+	}
+	return err
+}
+
+func (f *FrontendGroupService) DeleteGroup(groupId int) error {
+	if !f.FrontendGroupRepository.GroupExists(groupId) {
+		return utils.ErrGroupDoesNotExists
+	}
+
+	return f.FrontendGroupRepository.DeleteGroup(groupId)
+}
+
+// GetGroupAgents resolves a group to its agents: the listed members of a
+// static group, or the agents currently matching a label group's selector.
+func (f *FrontendGroupService) GetGroupAgents(groupId int) ([]models.AgentLabels, error) {
+	group, err := f.FrontendGroupRepository.GetGroup(groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	agents, err := f.FrontendGroupRepository.GetAgentsWithLabels()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches func(agent models.AgentLabels) bool
+	if group.Selector != "" {
+		selector, err := labelselector.Parse(group.Selector)
+		if err != nil {
+			return nil, err
+		}
+		matches = func(agent models.AgentLabels) bool { return selector.Matches(agent.Labels) }
+	} else {
+		members := make(map[int64]bool, len(group.AgentIDs))
+		for _, agentId := range group.AgentIDs {
+			members[int64(agentId)] = true
+		}
+		matches = func(agent models.AgentLabels) bool { return members[agent.ID] }
+	}
+
+	groupAgents := []models.AgentLabels{}
+	for _, agent := range agents {
+		if matches(agent) {
+			groupAgents = append(groupAgents, agent)
+		}
+	}
+	return groupAgents, nil
+}
+
+// RunBulkAction starts a job running the action on every agent in the group
+// and returns it.
+func (f *FrontendGroupService) RunBulkAction(groupId int, req models.BulkActionRequest) (*models.Job, error) {
+	if _, err := f.agentAction(req); err != nil {
+		return nil, err
+	}
+
+	agents, err := f.GetGroupAgents(groupId)
+	if err != nil {
+		return nil, err
+	}
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("%w: the group has no agents", ErrInvalidGroup)
+	}
+
+	targets := make([]string, len(agents))
+	for i, agent := range agents {
+		targets[i] = strconv.FormatInt(agent.ID, 10)
+	}
+
+	return f.Jobs.Enqueue(bulkJobType(req.Action), targets, req)
+}
+
+// runBulkActionTarget is the job executor of bulk actions. An agent deleted
+// since the job started fails without retries.
+func (f *FrontendGroupService) runBulkActionTarget(_, agentId string, params json.RawMessage) error {
+	var req models.BulkActionRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid bulk action params: %w", err))
+	}
+	action, err := f.agentAction(req)
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+
+	err = action(agentId)
+	if errors.Is(err, utils.ErrAgentDoesNotExists) {
+		return jobs.Permanent(err)
+	}
+	return err
+}
+
+func bulkJobType(action string) string {
+	return "agent_group." + action
+}
+
+func (f *FrontendGroupService) agentAction(req models.BulkActionRequest) (func(agentId string) error, error) {
+	switch req.Action {
+	case ActionStart:
+		return f.FrontendAgentService.StartAgent, nil
+	case ActionStop:
+		return f.FrontendAgentService.StopAgent, nil
+	case ActionRestartMonitoring:
+		return f.FrontendAgentService.RestartMonitoring, nil
+	case ActionDelete:
+		return f.FrontendAgentService.DeleteAgent, nil
+	case ActionAddLabels:
+		if len(req.Labels) == 0 {
+			return nil, fmt.Errorf("%w: labels are required for %s", ErrInvalidGroup, ActionAddLabels)
+		}
+		return func(agentId string) error {
+			return f.FrontendAgentService.AddLabels(agentId, req.Labels)
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidGroup, req.Action)
+	}
+}
+
+func (f *FrontendGroupService) validateGroupRequest(req models.AgentGroupRequest) (*models.AgentGroup, error) {
+	if req.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidGroup)
+	}
+
+	group := &models.AgentGroup{
+		Name:        req.Name,
+		Description: req.Description,
+		AgentIDs:    req.AgentIDs,
+		CreatedBy:   req.CreatedBy,
+	}
+
+	if req.Selector != "" {
+		if len(req.AgentIDs) > 0 {
+			return nil, fmt.Errorf("%w: set either selector or agent_ids, not both", ErrInvalidGroup)
+		}
+		selector, err := labelselector.Parse(req.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidGroup, err)
+		}
+		if selector.Empty() {
+			return nil, fmt.Errorf("%w: selector has no requirements", ErrInvalidGroup)
+		}
+		group.Selector = selector.String()
+		return group, nil
+	}
+
+	missing, err := f.FrontendGroupRepository.GetMissingAgents(req.AgentIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: agents %v don't exist", ErrInvalidGroup, missing)
+	}
+	return group, nil
+}
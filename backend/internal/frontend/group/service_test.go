@@ -0,0 +1,147 @@
+package frontendgroup
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	database "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/db"
+	frontendagent "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/agent"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/jobs"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAgentService records the agents each operation was called for.
+type fakeAgentService struct {
+	frontendagent.FrontendAgentServiceInterface
+	mu      sync.Mutex
+	stopped []string
+	labels  map[string]map[string]string
+}
+
+func (f *fakeAgentService) StopAgent(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if id == "3" {
+		return errors.New("agent unreachable")
+	}
+	f.stopped = append(f.stopped, id)
+	return nil
+}
+
+func (f *fakeAgentService) AddLabels(id string, labels map[string]string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.labels[id] = labels
+	return nil
+}
+
+func setupGroupService(t *testing.T) (FrontendGroupServiceInterface, *jobs.Manager, *fakeAgentService) {
+	db, err := database.DBInit(":memory:")
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+
+	for id, env := range map[int]string{1: "prod", 2: "dev", 3: "prod"} {
+		_, err := db.Exec("INSERT INTO agents (id, name, type, version, hostname, platform, registered_at, ip) VALUES (?, ?, 'OTEL', 'v1', 'host', 'linux', 0, '127.0.0.1')", id, "agent-"+env)
+		assert.NoError(t, err)
+		_, err = db.Exec("INSERT INTO agents_labels (agent_id, key, value) VALUES (?, 'env', ?)", id, env)
+		assert.NoError(t, err)
+	}
+
+	agents := &fakeAgentService{labels: map[string]map[string]string{}}
+	jobManager := jobs.NewManager(jobs.NewJobRepository(db))
+	jobManager.Backoff = time.Millisecond
+	return NewFrontendGroupService(NewFrontendGroupRepository(db), agents, jobManager), jobManager, agents
+}
+
+func waitForJob(t *testing.T, jobManager *jobs.Manager, id string) *models.Job {
+	t.Helper()
+	var job *models.Job
+	assert.Eventually(t, func() bool {
+		job, _ = jobManager.GetJob(id)
+		return job != nil && job.Status != models.JobRunning
+	}, time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestGroupService_StaticGroup(t *testing.T) {
+	service, _, _ := setupGroupService(t)
+
+	id, err := service.CreateGroup(models.AgentGroupRequest{Name: "edge", AgentIDs: []int{1, 2}, CreatedBy: "admin"})
+	assert.NoError(t, err)
+
+	group, err := service.GetGroup(id)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, group.AgentIDs)
+
+	agents, err := service.GetGroupAgents(id)
+	assert.NoError(t, err)
+	assert.Len(t, agents, 2)
+
+	assert.NoError(t, service.UpdateGroup(id, models.AgentGroupRequest{Name: "edge", AgentIDs: []int{3}}))
+	agents, err = service.GetGroupAgents(id)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), agents[0].ID)
+
+	_, err = service.CreateGroup(models.AgentGroupRequest{Name: "edge", CreatedBy: "admin"})
+	assert.ErrorIs(t, err, ErrInvalidGroup)
+
+	_, err = service.CreateGroup(models.AgentGroupRequest{Name: "ghosts", AgentIDs: []int{42}, CreatedBy: "admin"})
+	assert.ErrorIs(t, err, ErrInvalidGroup)
+
+	assert.NoError(t, service.DeleteGroup(id))
+	_, err = service.GetGroup(id)
+	assert.ErrorIs(t, err, utils.ErrGroupDoesNotExists)
+}
+
+func TestGroupService_LabelGroupBulkAction(t *testing.T) {
+	service, jobManager, agents := setupGroupService(t)
+
+	id, err := service.CreateGroup(models.AgentGroupRequest{Name: "prod", Selector: "env = prod", CreatedBy: "admin"})
+	assert.NoError(t, err)
+
+	group, err := service.GetGroup(id)
+	assert.NoError(t, err)
+	assert.Equal(t, "env=prod", group.Selector)
+
+	job, err := service.RunBulkAction(id, models.BulkActionRequest{Action: ActionStop})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, job.Total)
+
+	finished := waitForJob(t, jobManager, job.ID)
+	assert.Equal(t, models.JobFailed, finished.Status)
+	assert.Equal(t, 1, finished.Succeeded)
+	assert.Equal(t, []string{"1"}, agents.stopped)
+	assert.Equal(t, models.JobTargetResult{Target: "3", Status: models.JobFailed, Attempts: 3, Error: "agent unreachable"}, finished.Results[1])
+
+	job, err = service.RunBulkAction(id, models.BulkActionRequest{Action: ActionAddLabels, Labels: map[string]string{"tier": "edge"}})
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobCompleted, waitForJob(t, jobManager, job.ID).Status)
+	assert.Len(t, agents.labels, 2)
+}
+
+func TestGroupService_InvalidRequests(t *testing.T) {
+	service, _, _ := setupGroupService(t)
+
+	_, err := service.CreateGroup(models.AgentGroupRequest{Name: "both", Selector: "env=prod", AgentIDs: []int{1}, CreatedBy: "admin"})
+	assert.ErrorIs(t, err, ErrInvalidGroup)
+
+	_, err = service.CreateGroup(models.AgentGroupRequest{Name: "bad", Selector: "env in (", CreatedBy: "admin"})
+	assert.ErrorIs(t, err, ErrInvalidGroup)
+
+	id, err := service.CreateGroup(models.AgentGroupRequest{Name: "staging", Selector: "env=staging", CreatedBy: "admin"})
+	assert.NoError(t, err)
+
+	_, err = service.RunBulkAction(id, models.BulkActionRequest{Action: ActionStop})
+	assert.ErrorIs(t, err, ErrInvalidGroup)
+
+	_, err = service.RunBulkAction(id, models.BulkActionRequest{Action: "reboot"})
+	assert.ErrorIs(t, err, ErrInvalidGroup)
+
+	_, err = service.RunBulkAction(id, models.BulkActionRequest{Action: ActionAddLabels})
+	assert.ErrorIs(t, err, ErrInvalidGroup)
+}
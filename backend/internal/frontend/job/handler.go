@@ -0,0 +1,75 @@
+package frontendjob
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+	"github.com/gorilla/mux"
+)
+
+// FrontendJobHandler handles polling and cancelling background jobs
+type FrontendJobHandler struct {
+	FrontendJobService FrontendJobServiceInterface
+}
+
+// NewFrontendJobHandler initializes the handler
+func NewFrontendJobHandler(frontendJobService FrontendJobServiceInterface) *FrontendJobHandler {
+	return &FrontendJobHandler{
+		FrontendJobService: frontendJobService,
+	}
+}
+
+func (f *FrontendJobHandler) GetAllJobs(w http.ResponseWriter, r *http.Request) {
+	utils.Logger.Info("Request received to get all jobs")
+
+	response, err := f.FrontendJobService.GetAllJobs()
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error while getting all jobs: %v", err))
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (f *FrontendJobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	jobId := mux.Vars(r)["id"]
+
+	utils.Logger.Info(fmt.Sprintf("Request received to get job with ID: %s", jobId))
+
+	response, err := f.FrontendJobService.GetJob(jobId)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error getting job [ID: %s]: %v", jobId, err))
+		sendJobError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (f *FrontendJobHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	jobId := mux.Vars(r)["id"]
+
+	utils.Logger.Info(fmt.Sprintf("Request received to cancel job with ID: %s", jobId))
+
+	response, err := f.FrontendJobService.CancelJob(jobId)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error cancelling job [ID: %s]: %v", jobId, err))
+		sendJobError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusAccepted, response)
+}
+
+// sendJobError maps service errors to status codes: 404 for a missing job,
+// 409 for cancelling a finished one and 500 otherwise.
+func sendJobError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, utils.ErrJobDoesNotExists):
+		utils.SendJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, utils.ErrJobAlreadyFinished):
+		utils.SendJSONError(w, http.StatusConflict, err.Error())
+	default:
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+	}
+}
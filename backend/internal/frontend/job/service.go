@@ -0,0 +1,37 @@
+package frontendjob
+
+import (
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/jobs"
+)
+
+type FrontendJobServiceInterface interface {
+	GetAllJobs() ([]models.Job, error)
+	GetJob(jobId string) (*models.Job, error)
+	CancelJob(jobId string) (*models.Job, error)
+}
+
+type FrontendJobService struct {
+	Jobs *jobs.Manager
+}
+
+// NewFrontendJobService creates a new FrontendJobService over the job manager
+// that runs the jobs.
+func NewFrontendJobService(jobManager *jobs.Manager) FrontendJobServiceInterface {
+	return &FrontendJobService{
+		Jobs: jobManager,
+	}
+}
+
+// GetAllJobs lists jobs without their per-target results.
+func (f *FrontendJobService) GetAllJobs() ([]models.Job, error) {
+	return f.Jobs.GetAllJobs()
+}
+
+func (f *FrontendJobService) GetJob(jobId string) (*models.Job, error) {
+	return f.Jobs.GetJob(jobId)
+}
+
+func (f *FrontendJobService) CancelJob(jobId string) (*models.Job, error) {
+	return f.Jobs.CancelJob(jobId)
+}
@@ -5,7 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
 	"github.com/gorilla/mux"
 )
@@ -22,7 +25,13 @@ func NewFrontendNodeHandler(frontendAgentServiceInterface FrontendNodeServiceInt
 }
 
 func (f *FrontendNodeHandler) GetComponent(w http.ResponseWriter, r *http.Request) {
-	componentType := r.URL.Query().Get("type")
+	query := r.URL.Query()
+	filter := ComponentFilter{
+		Type:      query.Get("type"),
+		Query:     query.Get("q"),
+		Signal:    query.Get("signal"),
+		Stability: strings.ToLower(query.Get("stability")),
+	}
 
 	validTypes := map[string]bool{
 		"receiver":  true,
@@ -31,17 +40,37 @@ func (f *FrontendNodeHandler) GetComponent(w http.ResponseWriter, r *http.Reques
 		"":          true, // allow empty string
 	}
 
-	if !validTypes[componentType] {
+	if !validTypes[filter.Type] {
 		utils.SendJSONError(w, http.StatusBadRequest, "Invalid component type. Must be 'receiver', 'processor', 'destination', or empty.")
 		return
 	}
+	if filter.Signal != "" && !validSignals[filter.Signal] {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid signal. Must be 'traces', 'metrics', 'logs', or empty.")
+		return
+	}
+	if _, ok := stabilityRanks[filter.Stability]; filter.Stability != "" && !ok {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid stability. Must be 'development', 'alpha', 'beta', 'stable', 'deprecated', 'unmaintained', or empty.")
+		return
+	}
+	if raw := query.Get("pipeline_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			utils.SendJSONError(w, http.StatusBadRequest, "Invalid pipeline_id")
+			return
+		}
+		filter.PipelineID = id
+	}
 
-	utils.Logger.Info(fmt.Sprintf("Received request to get all components of type: %s", componentType))
+	utils.Logger.Info(fmt.Sprintf("Received request to get all components of type: %s", filter.Type))
 
-	resp, err := f.FrontendNodeService.GetComponents(componentType)
+	resp, err := f.FrontendNodeService.GetComponents(filter)
 	if err != nil {
 		utils.Logger.Error(fmt.Sprintf("Error occured while getting components: %v", err))
-		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		if errors.Is(err, utils.ErrPipelineDoesNotExists) {
+			utils.SendJSONError(w, http.StatusNotFound, err.Error())
+		} else {
+			utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		}
 		return
 	}
 
@@ -87,3 +116,94 @@ func (f *FrontendNodeHandler) GetComponentUISchema(w http.ResponseWriter, r *htt
 	}
 	utils.WriteJSONResponse(w, http.StatusOK, schema)
 }
+
+// GetComponentMigrations lists the node configs rewritten, or left as they
+// were, when component schemas were upgraded.
+func (f *FrontendNodeHandler) GetComponentMigrations(w http.ResponseWriter, r *http.Request) {
+	pipelineId := 0
+	if raw := r.URL.Query().Get("pipeline_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			utils.SendJSONError(w, http.StatusBadRequest, "Invalid pipeline_id")
+			return
+		}
+		pipelineId = id
+	}
+
+	utils.Logger.Info("Received request to get component migrations")
+
+	migrations, err := f.FrontendNodeService.GetComponentMigrations(pipelineId)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error occurred while getting component migrations: %v", err))
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, migrations)
+}
+
+func (f *FrontendNodeHandler) CreateCustomComponent(w http.ResponseWriter, r *http.Request) {
+	var req models.CustomComponentRequest
+	if err := utils.UnmarshalJSONRequest(r, &req); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to register custom component: %s", req.Name))
+
+	if err := f.FrontendNodeService.CreateCustomComponent(req); err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error registering custom component %s: %v", req.Name, err))
+		sendComponentError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Component registered successfully"})
+}
+
+func (f *FrontendNodeHandler) UpdateCustomComponent(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	var req models.CustomComponentRequest
+	if err := utils.UnmarshalJSONRequest(r, &req); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to update custom component: %s", name))
+
+	if err := f.FrontendNodeService.UpdateCustomComponent(name, req); err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error updating custom component %s: %v", name, err))
+		sendComponentError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Component updated successfully"})
+}
+
+func (f *FrontendNodeHandler) DeleteCustomComponent(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	utils.Logger.Info(fmt.Sprintf("Request received to delete custom component: %s", name))
+
+	if err := f.FrontendNodeService.DeleteCustomComponent(name); err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error deleting custom component %s: %v", name, err))
+		sendComponentError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Component deleted successfully"})
+}
+
+// sendComponentError maps custom component errors to status codes: 404 for a
+// missing component, 400 for invalid requests, 403 for built-in components,
+// 409 for names taken or components in use and 500 otherwise.
+func sendComponentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, utils.ErrComponentDoesNotExists):
+		utils.SendJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidComponent):
+		utils.SendJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrBuiltInComponent):
+		utils.SendJSONError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrComponentExists), errors.Is(err, ErrComponentInUse):
+		utils.SendJSONError(w, http.StatusConflict, err.Error())
+	default:
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -4,22 +4,30 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
 	"github.com/gorilla/mux"
 )
 
 // MockFrontendAgentService is a mock implementation of FrontendAgentServiceInterface
 type MockFrontendAgentService struct {
-	GetComponentsFunc              func(componentType string) (*[]ComponentInfo, error)
+	GetComponentsFunc              func(filter ComponentFilter) (*[]ComponentInfo, error)
 	GetComponentSchemaByNameFunc   func(componentName string) (any, error)
 	GetComponentUISchemaByNameFunc func(componentName string) (any, error)
+	GetComponentMigrationsFunc     func(pipelineId int) ([]models.ComponentMigration, error)
+	CreateCustomComponentFunc      func(req models.CustomComponentRequest) error
+	UpdateCustomComponentFunc      func(name string, req models.CustomComponentRequest) error
+	DeleteCustomComponentFunc      func(name string) error
 }
 
-func (m *MockFrontendAgentService) GetComponents(componentType string) (*[]ComponentInfo, error) {
-	return m.GetComponentsFunc(componentType)
+func (m *MockFrontendAgentService) GetComponents(filter ComponentFilter) (*[]ComponentInfo, error) {
+	return m.GetComponentsFunc(filter)
 }
 
 func (m *MockFrontendAgentService) GetComponentSchemaByName(componentName string) (any, error) {
@@ -30,6 +38,22 @@ func (m *MockFrontendAgentService) GetComponentUISchemaByName(componentName stri
 	return m.GetComponentUISchemaByNameFunc(componentName)
 }
 
+func (m *MockFrontendAgentService) GetComponentMigrations(pipelineId int) ([]models.ComponentMigration, error) {
+	return m.GetComponentMigrationsFunc(pipelineId)
+}
+
+func (m *MockFrontendAgentService) CreateCustomComponent(req models.CustomComponentRequest) error {
+	return m.CreateCustomComponentFunc(req)
+}
+
+func (m *MockFrontendAgentService) UpdateCustomComponent(name string, req models.CustomComponentRequest) error {
+	return m.UpdateCustomComponentFunc(name, req)
+}
+
+func (m *MockFrontendAgentService) DeleteCustomComponent(name string) error {
+	return m.DeleteCustomComponentFunc(name)
+}
+
 // TestFrontendNodeHandler_GetComponent tests GetComponent endpoint
 func TestFrontendNodeHandler_GetComponent(t *testing.T) {
 	tests := []struct {
@@ -65,7 +89,7 @@ func TestFrontendNodeHandler_GetComponent(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := &MockFrontendAgentService{
-				GetComponentsFunc: func(componentType string) (*[]ComponentInfo, error) {
+				GetComponentsFunc: func(filter ComponentFilter) (*[]ComponentInfo, error) {
 					return tt.mockServiceOutput, tt.mockServiceError
 				},
 			}
@@ -87,6 +111,44 @@ func TestFrontendNodeHandler_GetComponent(t *testing.T) {
 	}
 }
 
+// TestFrontendNodeHandler_GetComponent_Filters tests the catalog search parameters
+func TestFrontendNodeHandler_GetComponent_Filters(t *testing.T) {
+	var got ComponentFilter
+	mockService := &MockFrontendAgentService{
+		GetComponentsFunc: func(filter ComponentFilter) (*[]ComponentInfo, error) {
+			got = filter
+			if filter.PipelineID == 9 {
+				return nil, utils.ErrPipelineDoesNotExists
+			}
+			return &[]ComponentInfo{}, nil
+		},
+	}
+	handler := NewFrontendNodeHandler(mockService)
+
+	w := httptest.NewRecorder()
+	handler.GetComponent(w, httptest.NewRequest(http.MethodGet, "/component?type=exporter&q=kafka+sasl&signal=logs&stability=Beta&pipeline_id=3", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	expected := ComponentFilter{Type: "exporter", Query: "kafka sasl", Signal: "logs", Stability: "beta", PipelineID: 3}
+	if got != expected {
+		t.Errorf("expected filter %+v, got %+v", expected, got)
+	}
+
+	for query, status := range map[string]int{
+		"signal=profiles":  http.StatusBadRequest,
+		"stability=gold":   http.StatusBadRequest,
+		"pipeline_id=edge": http.StatusBadRequest,
+		"pipeline_id=9":    http.StatusNotFound,
+	} {
+		w := httptest.NewRecorder()
+		handler.GetComponent(w, httptest.NewRequest(http.MethodGet, "/component?"+query, nil))
+		if w.Code != status {
+			t.Errorf("expected status %d for %s, got %d", status, query, w.Code)
+		}
+	}
+}
+
 // TestFrontendNodeHandler_GetComponentSchema tests GetComponentSchema endpoint
 func TestFrontendNodeHandler_GetComponentSchema(t *testing.T) {
 	tests := []struct {
@@ -227,3 +289,101 @@ func TestFrontendNodeHandler_GetComponentUISchema(t *testing.T) {
 		})
 	}
 }
+
+// TestFrontendNodeHandler_GetComponentMigrations tests GetComponentMigrations endpoint
+func TestFrontendNodeHandler_GetComponentMigrations(t *testing.T) {
+	var gotPipelineId int
+	mockService := &MockFrontendAgentService{
+		GetComponentMigrationsFunc: func(pipelineId int) ([]models.ComponentMigration, error) {
+			gotPipelineId = pipelineId
+			return []models.ComponentMigration{{ID: 1, PipelineID: 7, ComponentName: "kafka_exporter", FromVersion: 1, ToVersion: 2, Changes: []string{"removed legacy"}}}, nil
+		},
+	}
+	handler := NewFrontendNodeHandler(mockService)
+
+	w := httptest.NewRecorder()
+	handler.GetComponentMigrations(w, httptest.NewRequest(http.MethodGet, "/component/migrations?pipeline_id=7", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if gotPipelineId != 7 {
+		t.Errorf("expected pipeline 7, got %d", gotPipelineId)
+	}
+	var migrations []models.ComponentMigration
+	if err := json.NewDecoder(w.Body).Decode(&migrations); err != nil || len(migrations) != 1 || migrations[0].Changes[0] != "removed legacy" {
+		t.Errorf("unexpected response %v (%v)", migrations, err)
+	}
+
+	w = httptest.NewRecorder()
+	handler.GetComponentMigrations(w, httptest.NewRequest(http.MethodGet, "/component/migrations?pipeline_id=edge", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+// TestFrontendNodeHandler_CustomComponents tests the custom component endpoints
+func TestFrontendNodeHandler_CustomComponents(t *testing.T) {
+	var created models.CustomComponentRequest
+	var updated string
+	mockService := &MockFrontendAgentService{
+		CreateCustomComponentFunc: func(req models.CustomComponentRequest) error {
+			created = req
+			if req.Name == "otlp_receiver" {
+				return fmt.Errorf("%w: otlp_receiver", ErrComponentExists)
+			}
+			return nil
+		},
+		UpdateCustomComponentFunc: func(name string, req models.CustomComponentRequest) error {
+			updated = name
+			return fmt.Errorf("%w: batch_processor", ErrBuiltInComponent)
+		},
+		DeleteCustomComponentFunc: func(name string) error {
+			switch name {
+			case "acme_exporter":
+				return fmt.Errorf("%w: used by pipelines edge", ErrComponentInUse)
+			case "missing_exporter":
+				return utils.ErrComponentDoesNotExists
+			}
+			return nil
+		},
+	}
+	handler := NewFrontendNodeHandler(mockService)
+
+	body := `{"name": "acme_receiver", "type": "receiver", "supported_signals": ["logs"], "schema": {"type": "object"}}`
+	w := httptest.NewRecorder()
+	handler.CreateCustomComponent(w, httptest.NewRequest(http.MethodPost, "/component/custom", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if created.Name != "acme_receiver" || string(created.Schema) != `{"type": "object"}` {
+		t.Errorf("unexpected request %+v", created)
+	}
+
+	w = httptest.NewRecorder()
+	handler.CreateCustomComponent(w, httptest.NewRequest(http.MethodPost, "/component/custom", strings.NewReader(`{"name": "otlp_receiver"}`)))
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected status %d, got %d", http.StatusConflict, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.CreateCustomComponent(w, httptest.NewRequest(http.MethodPost, "/component/custom", strings.NewReader(`{`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodPut, "/component/custom/batch_processor", strings.NewReader(body)), map[string]string{"name": "batch_processor"})
+	w = httptest.NewRecorder()
+	handler.UpdateCustomComponent(w, req)
+	if w.Code != http.StatusForbidden || updated != "batch_processor" {
+		t.Errorf("expected status %d for batch_processor, got %d for %q", http.StatusForbidden, w.Code, updated)
+	}
+
+	for name, status := range map[string]int{"acme_exporter": http.StatusConflict, "missing_exporter": http.StatusNotFound, "other_exporter": http.StatusOK} {
+		req := mux.SetURLVars(httptest.NewRequest(http.MethodDelete, "/component/custom/"+name, nil), map[string]string{"name": name})
+		w := httptest.NewRecorder()
+		handler.DeleteCustomComponent(w, req)
+		if w.Code != status {
+			t.Errorf("expected status %d deleting %s, got %d", status, name, w.Code)
+		}
+	}
+}
@@ -1,8 +1,35 @@
 package frontendnode
 
+import "encoding/json"
+
 type ComponentInfo struct {
 	Name             string   `json:"name"`
 	DisplayName      string   `json:"display_name"`
 	Type             string   `json:"type"`
 	SupportedSignals []string `json:"supported_signals"`
+	Version          int      `json:"version"`
+	Custom           bool     `json:"custom"`
+	// Stability is the least stable level agents reported per signal, e.g.
+	// {"logs": "beta"}. It's empty until an agent reports the component.
+	Stability map[string]string `json:"stability,omitempty"`
+	// DefaultConfig holds the schema's defaults, to pre-fill new nodes
+	DefaultConfig map[string]any  `json:"default_config"`
+	Schema        json.RawMessage `json:"-"`
+}
+
+// ComponentFilter narrows down the component catalog. Empty fields don't
+// filter.
+type ComponentFilter struct {
+	Type       string // receiver, processor or exporter
+	Query      string // words that must all appear in the name or display name
+	Signal     string // a supported signal
+	Stability  string // a stability level, for Signal if set and any signal otherwise
+	PipelineID int    // only components every agent attached to the pipeline has
+}
+
+// ComponentNode is a pipeline node built from a component.
+type ComponentNode struct {
+	PipelineName string
+	Name         string
+	Config       string
 }
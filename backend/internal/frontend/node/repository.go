@@ -3,7 +3,13 @@ package frontendnode
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"strings"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/configcompiler"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
 )
 
 type FrontendNodeRepository struct {
@@ -16,15 +22,31 @@ func NewFrontendNodeRepository(db *sql.DB) *FrontendNodeRepository {
 	}
 }
 
-func (f *FrontendNodeRepository) GetComponents(componentType string) (*[]ComponentInfo, error) {
-	var query string
+// GetComponents lists the catalog by name, filtered on the component's own
+// columns: type, signal and search words. Stability and pipeline filters
+// need the agents' reports and are left to the service.
+func (f *FrontendNodeRepository) GetComponents(filter ComponentFilter) (*[]ComponentInfo, error) {
+	var conditions []string
 	var args []any
 
-	query = "SELECT name, display_name, supported_signals, type FROM component_schemas"
-	if componentType != "" {
-		query += " WHERE type = ?"
-		args = append(args, componentType)
+	query := "SELECT name, display_name, supported_signals, type, version, custom, schema_json FROM component_schemas"
+	if filter.Type != "" {
+		conditions = append(conditions, "type = ?")
+		args = append(args, filter.Type)
+	}
+	if filter.Signal != "" {
+		conditions = append(conditions, "(',' || REPLACE(supported_signals, ' ', '') || ',') LIKE ?")
+		args = append(args, "%,"+filter.Signal+",%")
+	}
+	for _, word := range strings.Fields(filter.Query) {
+		pattern := "%" + escapeLike(word) + "%"
+		conditions = append(conditions, `(name LIKE ? ESCAPE '\' OR display_name LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern)
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
+	query += " ORDER BY name"
 
 	rows, err := f.db.Query(query, args...)
 	if err != nil {
@@ -35,8 +57,10 @@ func (f *FrontendNodeRepository) GetComponents(componentType string) (*[]Compone
 	var components []ComponentInfo
 
 	for rows.Next() {
-		var name, displayName, supportedSignalsRaw, typ string
-		if err := rows.Scan(&name, &displayName, &supportedSignalsRaw, &typ); err != nil {
+		var name, displayName, supportedSignalsRaw, typ, schema string
+		var version int
+		var custom bool
+		if err := rows.Scan(&name, &displayName, &supportedSignalsRaw, &typ, &version, &custom, &schema); err != nil {
 			return nil, err
 		}
 
@@ -51,6 +75,9 @@ func (f *FrontendNodeRepository) GetComponents(componentType string) (*[]Compone
 			DisplayName:      displayName,
 			Type:             typ,
 			SupportedSignals: supportedSignals,
+			Version:          version,
+			Custom:           custom,
+			Schema:           json.RawMessage(schema),
 		})
 	}
 
@@ -61,6 +88,57 @@ func (f *FrontendNodeRepository) GetComponents(componentType string) (*[]Compone
 	return &components, nil
 }
 
+// escapeLike escapes LIKE wildcards so search words match literally.
+func escapeLike(word string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(word)
+}
+
+// GetReportedComponents returns the components each agent reported, as
+// catalog name to stability per signal. With a pipeline ID only the agents
+// attached to it are included. Agents that reported no components are left
+// out.
+func (f *FrontendNodeRepository) GetReportedComponents(pipelineId int) (map[int]map[string]map[string]string, error) {
+	query := "SELECT ac.agent_id, ac.kind, ac.type, ac.stability_json FROM agent_components ac"
+	var args []any
+	if pipelineId != 0 {
+		query += " JOIN agents a ON a.id = ac.agent_id WHERE a.pipeline_id = ?"
+		args = append(args, pipelineId)
+	}
+
+	rows, err := f.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agent components: %w", err)
+	}
+	defer rows.Close()
+
+	reported := make(map[int]map[string]map[string]string)
+	for rows.Next() {
+		var agentId int
+		var kind, componentType, stabilityJSON string
+		if err := rows.Scan(&agentId, &kind, &componentType, &stabilityJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan agent component: %w", err)
+		}
+		var stability map[string]string
+		if err := json.Unmarshal([]byte(stabilityJSON), &stability); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal component stability: %w", err)
+		}
+		if reported[agentId] == nil {
+			reported[agentId] = make(map[string]map[string]string)
+		}
+		for _, name := range configcompiler.CatalogComponentNames(kind, componentType) {
+			reported[agentId][name] = stability
+		}
+	}
+	return reported, rows.Err()
+}
+
+// PipelineExists reports whether a pipeline with the given ID exists.
+func (f *FrontendNodeRepository) PipelineExists(pipelineId int) bool {
+	var verifyId int
+	err := f.db.QueryRow("SELECT pipeline_id FROM pipelines WHERE pipeline_id = ? LIMIT 1", pipelineId).Scan(&verifyId)
+	return err == nil
+}
+
 func (f *FrontendNodeRepository) GetComponentSchemaByName(componentName string) (any, error) {
 	query := "SELECT schema_json FROM component_schemas WHERE name = ?"
 
@@ -94,3 +172,96 @@ func (f *FrontendNodeRepository) GetComponentUISchemaByName(componentName string
 
 	return uiSchema, nil
 }
+
+// GetComponentMigrations lists node config migrations, newest first,
+// optionally only those of one pipeline.
+func (f *FrontendNodeRepository) GetComponentMigrations(pipelineId int) ([]models.ComponentMigration, error) {
+	query := "SELECT migration_id, pipeline_id, component_id, component_name, name, from_version, to_version, changes_json, error, migrated_at FROM component_migrations"
+	var args []any
+	if pipelineId != 0 {
+		query += " WHERE pipeline_id = ?"
+		args = append(args, pipelineId)
+	}
+	query += " ORDER BY migration_id DESC"
+
+	rows, err := f.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query component migrations: %w", err)
+	}
+	defer rows.Close()
+
+	migrations := []models.ComponentMigration{}
+	for rows.Next() {
+		var migration models.ComponentMigration
+		var changesJSON string
+		if err := rows.Scan(&migration.ID, &migration.PipelineID, &migration.ComponentID, &migration.ComponentName, &migration.Name,
+			&migration.FromVersion, &migration.ToVersion, &changesJSON, &migration.Error, &migration.MigratedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan component migration: %w", err)
+		}
+		if err := json.Unmarshal([]byte(changesJSON), &migration.Changes); err != nil {
+			return nil, fmt.Errorf("invalid changes of component migration %d: %w", migration.ID, err)
+		}
+		migrations = append(migrations, migration)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query component migrations: %w", err)
+	}
+	return migrations, nil
+}
+
+// IsCustomComponent reports whether a component was registered through the
+// API rather than shipped with the backend.
+func (f *FrontendNodeRepository) IsCustomComponent(name string) (bool, error) {
+	var custom bool
+	err := f.db.QueryRow("SELECT custom FROM component_schemas WHERE name = ?", name).Scan(&custom)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, utils.ErrComponentDoesNotExists
+	}
+	return custom, err
+}
+
+func (f *FrontendNodeRepository) CreateCustomComponent(req models.CustomComponentRequest) error {
+	_, err := f.db.Exec(`
+		INSERT INTO component_schemas (name, type, display_name, supported_signals, schema_json, ui_schema_json, custom)
+		VALUES (?, ?, ?, ?, ?, ?, 1)
+	`, req.Name, req.Type, req.DisplayName, strings.Join(req.SupportedSignals, ","), string(req.Schema), string(req.UISchema))
+	return err
+}
+
+func (f *FrontendNodeRepository) UpdateCustomComponent(req models.CustomComponentRequest) error {
+	_, err := f.db.Exec(`
+		UPDATE component_schemas SET type = ?, display_name = ?, supported_signals = ?, schema_json = ?, ui_schema_json = ?
+		WHERE name = ? AND custom = 1
+	`, req.Type, req.DisplayName, strings.Join(req.SupportedSignals, ","), string(req.Schema), string(req.UISchema), req.Name)
+	return err
+}
+
+func (f *FrontendNodeRepository) DeleteCustomComponent(name string) error {
+	_, err := f.db.Exec("DELETE FROM component_schemas WHERE name = ? AND custom = 1", name)
+	return err
+}
+
+// GetComponentNodes lists the pipeline nodes built from a component.
+func (f *FrontendNodeRepository) GetComponentNodes(name string) ([]ComponentNode, error) {
+	rows, err := f.db.Query(`
+		SELECT p.name, COALESCE(pc.name, ''), COALESCE(pc.config, '')
+		FROM pipeline_components pc
+		JOIN pipelines p ON p.pipeline_id = pc.pipeline_id
+		WHERE pc.component_name = ?
+		ORDER BY p.name, pc.component_id
+	`, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query nodes of component %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	var nodes []ComponentNode
+	for rows.Next() {
+		var node ComponentNode
+		if err := rows.Scan(&node.PipelineName, &node.Name, &node.Config); err != nil {
+			return nil, fmt.Errorf("failed to scan node of component %s: %w", name, err)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, rows.Err()
+}
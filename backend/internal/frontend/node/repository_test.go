@@ -1,9 +1,12 @@
 package frontendnode
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -27,21 +30,26 @@ func TestGetComponents(t *testing.T) {
 		{
 			name:          "Success - All Components",
 			componentType: "",
-			mockRows: sqlmock.NewRows([]string{"name", "display_name", "supported_signals", "type"}).
-				AddRow("button", "Button", "click,hover", "input").
-				AddRow("textbox", "Text Box", "input,focus", "input"),
+			mockRows: sqlmock.NewRows([]string{"name", "display_name", "supported_signals", "type", "version", "custom", "schema_json"}).
+				AddRow("button", "Button", "click,hover", "input", 1, false, "{}").
+				AddRow("textbox", "Text Box", "input,focus", "input", 1, true, "{}"),
 			expectedComponents: &[]ComponentInfo{
 				{
 					Name:             "button",
 					DisplayName:      "Button",
 					Type:             "input",
 					SupportedSignals: []string{"click", "hover"},
+					Version:          1,
+					Schema:           json.RawMessage("{}"),
 				},
 				{
 					Name:             "textbox",
 					DisplayName:      "Text Box",
 					Type:             "input",
 					SupportedSignals: []string{"input", "focus"},
+					Version:          1,
+					Custom:           true,
+					Schema:           json.RawMessage("{}"),
 				},
 			},
 			expectError: false,
@@ -49,22 +57,22 @@ func TestGetComponents(t *testing.T) {
 		{
 			name:          "Return All Receivers",
 			componentType: "receiver",
-			mockRows: sqlmock.NewRows([]string{"name", "display_name", "supported_signals", "type"}).
-				AddRow("awscloudwatch_receiver", "AWS CloudWatch Receiver Configuration", "logs", "receiver").
-				AddRow("awscloudwatchmetrics_receiver", "AWS CloudWatch Metrics Receiver Configuration", "metrics", "receiver").
-				AddRow("azuremonitor_receiver", "Azure Monitor Receiver Configuration", "metrics", "receiver").
-				AddRow("filelog_receiver", "Filelog Receiver Configuration", "logs", "receiver").
-				AddRow("googlecloudmonitoring_receiver", "Google Cloud Monitoring Receiver Configuration", "metrics", "receiver").
-				AddRow("hostmetrics_receiver", "Host Metrics Receiver Configuration", "metrics", "receiver").
-				AddRow("otlp_receiver", "OTLP Receiver Configuration", "traces,metrics,logs", "receiver"),
+			mockRows: sqlmock.NewRows([]string{"name", "display_name", "supported_signals", "type", "version", "custom", "schema_json"}).
+				AddRow("awscloudwatch_receiver", "AWS CloudWatch Receiver Configuration", "logs", "receiver", 1, false, "{}").
+				AddRow("awscloudwatchmetrics_receiver", "AWS CloudWatch Metrics Receiver Configuration", "metrics", "receiver", 1, false, "{}").
+				AddRow("azuremonitor_receiver", "Azure Monitor Receiver Configuration", "metrics", "receiver", 1, false, "{}").
+				AddRow("filelog_receiver", "Filelog Receiver Configuration", "logs", "receiver", 1, false, "{}").
+				AddRow("googlecloudmonitoring_receiver", "Google Cloud Monitoring Receiver Configuration", "metrics", "receiver", 1, false, "{}").
+				AddRow("hostmetrics_receiver", "Host Metrics Receiver Configuration", "metrics", "receiver", 1, false, "{}").
+				AddRow("otlp_receiver", "OTLP Receiver Configuration", "traces,metrics,logs", "receiver", 1, false, "{}"),
 			expectedComponents: &[]ComponentInfo{
-				{"awscloudwatch_receiver", "AWS CloudWatch Receiver Configuration", "receiver", []string{"logs"}},
-				{"awscloudwatchmetrics_receiver", "AWS CloudWatch Metrics Receiver Configuration", "receiver", []string{"metrics"}},
-				{"azuremonitor_receiver", "Azure Monitor Receiver Configuration", "receiver", []string{"metrics"}},
-				{"filelog_receiver", "Filelog Receiver Configuration", "receiver", []string{"logs"}},
-				{"googlecloudmonitoring_receiver", "Google Cloud Monitoring Receiver Configuration", "receiver", []string{"metrics"}},
-				{"hostmetrics_receiver", "Host Metrics Receiver Configuration", "receiver", []string{"metrics"}},
-				{"otlp_receiver", "OTLP Receiver Configuration", "receiver", []string{"traces", "metrics", "logs"}},
+				{Name: "awscloudwatch_receiver", DisplayName: "AWS CloudWatch Receiver Configuration", Type: "receiver", SupportedSignals: []string{"logs"}, Version: 1, Schema: json.RawMessage("{}")},
+				{Name: "awscloudwatchmetrics_receiver", DisplayName: "AWS CloudWatch Metrics Receiver Configuration", Type: "receiver", SupportedSignals: []string{"metrics"}, Version: 1, Schema: json.RawMessage("{}")},
+				{Name: "azuremonitor_receiver", DisplayName: "Azure Monitor Receiver Configuration", Type: "receiver", SupportedSignals: []string{"metrics"}, Version: 1, Schema: json.RawMessage("{}")},
+				{Name: "filelog_receiver", DisplayName: "Filelog Receiver Configuration", Type: "receiver", SupportedSignals: []string{"logs"}, Version: 1, Schema: json.RawMessage("{}")},
+				{Name: "googlecloudmonitoring_receiver", DisplayName: "Google Cloud Monitoring Receiver Configuration", Type: "receiver", SupportedSignals: []string{"metrics"}, Version: 1, Schema: json.RawMessage("{}")},
+				{Name: "hostmetrics_receiver", DisplayName: "Host Metrics Receiver Configuration", Type: "receiver", SupportedSignals: []string{"metrics"}, Version: 1, Schema: json.RawMessage("{}")},
+				{Name: "otlp_receiver", DisplayName: "OTLP Receiver Configuration", Type: "receiver", SupportedSignals: []string{"traces", "metrics", "logs"}, Version: 1, Schema: json.RawMessage("{}")},
 			},
 			expectError: false,
 		},
@@ -74,16 +82,16 @@ func TestGetComponents(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Set up expectations
 			if tt.componentType == "" {
-				mock.ExpectQuery("SELECT name, display_name, supported_signals, type FROM component_schemas").
+				mock.ExpectQuery("SELECT name, display_name, supported_signals, type, version, custom, schema_json FROM component_schemas").
 					WillReturnRows(tt.mockRows)
 			} else {
-				mock.ExpectQuery("SELECT name, display_name, supported_signals, type FROM component_schemas WHERE type = ?").
+				mock.ExpectQuery("SELECT name, display_name, supported_signals, type, version, custom, schema_json FROM component_schemas WHERE type = \\?").
 					WithArgs(tt.componentType).
 					WillReturnRows(tt.mockRows)
 			}
 
 			// Call the method
-			components, err := repo.GetComponents(tt.componentType)
+			components, err := repo.GetComponents(ComponentFilter{Type: tt.componentType})
 
 			// Assert results
 			if tt.expectError {
@@ -97,6 +105,54 @@ func TestGetComponents(t *testing.T) {
 	}
 }
 
+func TestGetComponents_Search(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewFrontendNodeRepository(db)
+
+	mock.ExpectQuery(`FROM component_schemas WHERE type = \? AND \(',' \|\| REPLACE\(supported_signals, ' ', ''\) \|\| ','\) LIKE \? AND \(name LIKE \? .*\) AND \(name LIKE \? .*\) ORDER BY name`).
+		WithArgs("exporter", "%,logs,%", "%kafka%", "%kafka%", `%100\%%`, `%100\%%`).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "display_name", "supported_signals", "type", "version", "custom", "schema_json"}).
+			AddRow("kafka_exporter", "Kafka Exporter", "traces,metrics,logs", "exporter", 2, false, "{}"))
+
+	components, err := repo.GetComponents(ComponentFilter{Type: "exporter", Signal: "logs", Query: " kafka  100% "})
+	assert.NoError(t, err)
+	assert.Len(t, *components, 1)
+	assert.Equal(t, "kafka_exporter", (*components)[0].Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetReportedComponents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewFrontendNodeRepository(db)
+
+	mock.ExpectQuery("SELECT ac.agent_id, ac.kind, ac.type, ac.stability_json FROM agent_components ac JOIN agents a ON a.id = ac.agent_id WHERE a.pipeline_id = \\?").
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"agent_id", "kind", "type", "stability_json"}).
+			AddRow(1, "receiver", "otlp", `{"logs": "Stable"}`).
+			AddRow(1, "processor", "batch", `{"logs": "Beta"}`).
+			AddRow(2, "receiver", "otlp", `{"traces": "Stable"}`).
+			AddRow(2, "processor", "acme_sampling", `{"traces": "Alpha"}`))
+
+	reported, err := repo.GetReportedComponents(3)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]map[string]map[string]string{
+		1: {"otlp_receiver": {"logs": "Stable"}, "batch_processor": {"logs": "Beta"}},
+		// A custom component keeps the underscores of its type
+		2: {"otlp_receiver": {"traces": "Stable"}, "acmesampling_processor": {"traces": "Alpha"}, "acme_sampling_processor": {"traces": "Alpha"}},
+	}, reported)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetComponentSchemaByName(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -148,3 +204,48 @@ func TestGetComponentSchemaByName(t *testing.T) {
 		})
 	}
 }
+
+func TestCustomComponents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewFrontendNodeRepository(db)
+
+	mock.ExpectQuery("SELECT custom FROM component_schemas WHERE name = ?").
+		WithArgs("acme_exporter").
+		WillReturnRows(sqlmock.NewRows([]string{"custom"}).AddRow(true))
+	custom, err := repo.IsCustomComponent("acme_exporter")
+	assert.NoError(t, err)
+	assert.True(t, custom)
+
+	mock.ExpectQuery("SELECT custom FROM component_schemas WHERE name = ?").
+		WithArgs("missing_exporter").
+		WillReturnRows(sqlmock.NewRows([]string{"custom"}))
+	_, err = repo.IsCustomComponent("missing_exporter")
+	assert.ErrorIs(t, err, utils.ErrComponentDoesNotExists)
+
+	mock.ExpectExec("INSERT INTO component_schemas").
+		WithArgs("acme_exporter", "exporter", "Acme Exporter", "logs,traces", `{"type": "object"}`, `{}`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	assert.NoError(t, repo.CreateCustomComponent(models.CustomComponentRequest{
+		Name: "acme_exporter", Type: "exporter", DisplayName: "Acme Exporter", SupportedSignals: []string{"logs", "traces"},
+		Schema: json.RawMessage(`{"type": "object"}`), UISchema: json.RawMessage(`{}`),
+	}))
+
+	mock.ExpectQuery("SELECT p.name, COALESCE\\(pc.name, ''\\), COALESCE\\(pc.config, ''\\)").
+		WithArgs("acme_exporter").
+		WillReturnRows(sqlmock.NewRows([]string{"pipeline", "name", "config"}).AddRow("edge", "acme", `{"endpoint": "acme:4317"}`))
+	nodes, err := repo.GetComponentNodes("acme_exporter")
+	assert.NoError(t, err)
+	assert.Equal(t, []ComponentNode{{PipelineName: "edge", Name: "acme", Config: `{"endpoint": "acme:4317"}`}}, nodes)
+
+	mock.ExpectExec("DELETE FROM component_schemas WHERE name = \\? AND custom = 1").
+		WithArgs("acme_exporter").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.DeleteCustomComponent("acme_exporter"))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
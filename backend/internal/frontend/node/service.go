@@ -1,9 +1,58 @@
 package frontendnode
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/schemavalidator"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+)
+
+// ErrInvalidComponent is returned for custom component requests that fail
+// validation.
+var ErrInvalidComponent = errors.New("invalid component")
+
+// ErrComponentExists is returned when registering a component name already in
+// the catalog.
+var ErrComponentExists = errors.New("component already exists")
+
+// ErrBuiltInComponent is returned when changing a component shipped with the
+// backend.
+var ErrBuiltInComponent = errors.New("built-in components can't be changed")
+
+// ErrComponentInUse is returned when pipeline nodes stop a custom component
+// from being changed or deleted.
+var ErrComponentInUse = errors.New("component is in use")
+
+// Custom component names follow the catalog's <type>_<role> convention. Unlike
+// built-in names, the collector type keeps its underscores, e.g.
+// tail_sampling_processor.
+var customComponentName = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*_(receiver|processor|exporter)$`)
+
+var validSignals = map[string]bool{"traces": true, "metrics": true, "logs": true}
+
+// stabilityRanks orders the collector's stability levels from least to most
+// stable.
+var stabilityRanks = map[string]int{
+	"unmaintained": 0, "deprecated": 1, "development": 2, "alpha": 3, "beta": 4, "stable": 5,
+}
+
 type FrontendNodeRepositoryInterface interface {
-	GetComponents(componentType string) (*[]ComponentInfo, error)
+	GetComponents(filter ComponentFilter) (*[]ComponentInfo, error)
+	GetReportedComponents(pipelineId int) (map[int]map[string]map[string]string, error)
+	PipelineExists(pipelineId int) bool
 	GetComponentSchemaByName(componentName string) (any, error)
 	GetComponentUISchemaByName(componentName string) (any, error)
+	GetComponentMigrations(pipelineId int) ([]models.ComponentMigration, error)
+	IsCustomComponent(name string) (bool, error)
+	CreateCustomComponent(req models.CustomComponentRequest) error
+	UpdateCustomComponent(req models.CustomComponentRequest) error
+	DeleteCustomComponent(name string) error
+	GetComponentNodes(name string) ([]ComponentNode, error)
 }
 
 type FrontendNodeService struct {
@@ -11,9 +60,13 @@ type FrontendNodeService struct {
 }
 
 type FrontendNodeServiceInterface interface {
-	GetComponents(componentType string) (*[]ComponentInfo, error)
+	GetComponents(filter ComponentFilter) (*[]ComponentInfo, error)
 	GetComponentSchemaByName(componentName string) (any, error)
 	GetComponentUISchemaByName(componentName string) (any, error)
+	GetComponentMigrations(pipelineId int) ([]models.ComponentMigration, error)
+	CreateCustomComponent(req models.CustomComponentRequest) error
+	UpdateCustomComponent(name string, req models.CustomComponentRequest) error
+	DeleteCustomComponent(name string) error
 }
 
 func NewFrontendNodeService(frontendNodeRepositoryInterface FrontendNodeRepositoryInterface) *FrontendNodeService {
@@ -22,8 +75,102 @@ func NewFrontendNodeService(frontendNodeRepositoryInterface FrontendNodeReposito
 	}
 }
 
-func (f *FrontendNodeService) GetComponents(componentType string) (*[]ComponentInfo, error) {
-	return f.FrontendNodeRepository.GetComponents(componentType)
+// GetComponents lists the catalog components matching the filter, with the
+// stability the agents reported and the default config of each.
+func (f *FrontendNodeService) GetComponents(filter ComponentFilter) (*[]ComponentInfo, error) {
+	if filter.PipelineID != 0 && !f.FrontendNodeRepository.PipelineExists(filter.PipelineID) {
+		return nil, utils.ErrPipelineDoesNotExists
+	}
+
+	components, err := f.FrontendNodeRepository.GetComponents(filter)
+	if err != nil {
+		return nil, err
+	}
+	reported, err := f.FrontendNodeRepository.GetReportedComponents(filter.PipelineID)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []ComponentInfo{}
+	for _, component := range *components {
+		// Agents that don't report their components are assumed to have
+		// them all, so only the reporting agents can rule one out
+		supported := true
+		var stabilities []map[string]string
+		for _, agentComponents := range reported {
+			stability, ok := agentComponents[component.Name]
+			if !ok {
+				supported = false
+				continue
+			}
+			stabilities = append(stabilities, stability)
+		}
+		if filter.PipelineID != 0 && !supported {
+			continue
+		}
+
+		component.Stability = leastStable(stabilities)
+		if filter.Stability != "" && !hasStability(component.Stability, filter.Signal, filter.Stability) {
+			continue
+		}
+
+		component.DefaultConfig = map[string]any{}
+		var schema map[string]any
+		if err := json.Unmarshal(component.Schema, &schema); err == nil {
+			component.DefaultConfig = defaultConfig(schema)
+		}
+		filtered = append(filtered, component)
+	}
+	return &filtered, nil
+}
+
+// leastStable merges the stability levels agents reported for a component,
+// keeping the least stable level of each signal.
+func leastStable(stabilities []map[string]string) map[string]string {
+	if len(stabilities) == 0 {
+		return nil
+	}
+	merged := make(map[string]string)
+	for _, stability := range stabilities {
+		for signal, level := range stability {
+			level = strings.ToLower(level)
+			if current, ok := merged[signal]; !ok || stabilityRanks[level] < stabilityRanks[current] {
+				merged[signal] = level
+			}
+		}
+	}
+	return merged
+}
+
+func hasStability(stability map[string]string, signal, level string) bool {
+	if signal != "" {
+		return stability[signal] == level
+	}
+	for _, l := range stability {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultConfig collects the defaults of a schema's properties. Objects
+// without a default of their own are filled from their properties' defaults.
+func defaultConfig(schema map[string]any) map[string]any {
+	config := map[string]any{}
+	properties, _ := schema["properties"].(map[string]any)
+	for name, value := range properties {
+		property, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		if def, ok := property["default"]; ok {
+			config[name] = def
+		} else if nested := defaultConfig(property); len(nested) > 0 {
+			config[name] = nested
+		}
+	}
+	return config
 }
 
 func (f *FrontendNodeService) GetComponentSchemaByName(componentName string) (any, error) {
@@ -33,3 +180,205 @@ func (f *FrontendNodeService) GetComponentSchemaByName(componentName string) (an
 func (f *FrontendNodeService) GetComponentUISchemaByName(componentName string) (any, error) {
 	return f.FrontendNodeRepository.GetComponentUISchemaByName(componentName)
 }
+
+func (f *FrontendNodeService) GetComponentMigrations(pipelineId int) ([]models.ComponentMigration, error) {
+	return f.FrontendNodeRepository.GetComponentMigrations(pipelineId)
+}
+
+// CreateCustomComponent adds a component to the catalog. Custom components
+// are kept when the built-in schemas are reloaded at startup.
+func (f *FrontendNodeService) CreateCustomComponent(req models.CustomComponentRequest) error {
+	if _, err := validateCustomComponent(&req); err != nil {
+		return err
+	}
+	if _, err := f.FrontendNodeRepository.IsCustomComponent(req.Name); err == nil {
+		return fmt.Errorf("%w: %s", ErrComponentExists, req.Name)
+	} else if !errors.Is(err, utils.ErrComponentDoesNotExists) {
+		return err
+	}
+
+	err := f.FrontendNodeRepository.CreateCustomComponent(req)
+	if utils.IsUniqueViolation(err) {
+		return fmt.Errorf("%w: %s", ErrComponentExists, req.Name)
+	}
+	return err
+}
+
+// UpdateCustomComponent replaces a custom component's schemas. It's refused
+// when a node built from the component has a config the new schema rejects.
+func (f *FrontendNodeService) UpdateCustomComponent(name string, req models.CustomComponentRequest) error {
+	if err := f.checkCustomComponent(name); err != nil {
+		return err
+	}
+	req.Name = name
+	schema, err := validateCustomComponent(&req)
+	if err != nil {
+		return err
+	}
+
+	nodes, err := f.FrontendNodeRepository.GetComponentNodes(name)
+	if err != nil {
+		return err
+	}
+	var invalid []string
+	for _, node := range nodes {
+		config := map[string]any{}
+		if node.Config != "" && node.Config != "null" {
+			if err := json.Unmarshal([]byte(node.Config), &config); err != nil {
+				return fmt.Errorf("invalid config of node %s in pipeline %s: %w", node.Name, node.PipelineName, err)
+			}
+		}
+		if fieldErrors := schemavalidator.Validate(schema, config); len(fieldErrors) > 0 {
+			invalid = append(invalid, fmt.Sprintf("%s/%s (%s)", node.PipelineName, node.Name, formatFieldErrors(fieldErrors)))
+		}
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("%w: the new schema rejects the config of nodes %s", ErrComponentInUse, strings.Join(invalid, ", "))
+	}
+
+	return f.FrontendNodeRepository.UpdateCustomComponent(req)
+}
+
+// DeleteCustomComponent removes a custom component unless a pipeline still
+// has a node built from it.
+func (f *FrontendNodeService) DeleteCustomComponent(name string) error {
+	if err := f.checkCustomComponent(name); err != nil {
+		return err
+	}
+
+	nodes, err := f.FrontendNodeRepository.GetComponentNodes(name)
+	if err != nil {
+		return err
+	}
+	if len(nodes) > 0 {
+		var pipelines []string
+		seen := map[string]bool{}
+		for _, node := range nodes {
+			if !seen[node.PipelineName] {
+				seen[node.PipelineName] = true
+				pipelines = append(pipelines, node.PipelineName)
+			}
+		}
+		return fmt.Errorf("%w: used by pipelines %s", ErrComponentInUse, strings.Join(pipelines, ", "))
+	}
+	return f.FrontendNodeRepository.DeleteCustomComponent(name)
+}
+
+func (f *FrontendNodeService) checkCustomComponent(name string) error {
+	custom, err := f.FrontendNodeRepository.IsCustomComponent(name)
+	if err != nil {
+		return err
+	}
+	if !custom {
+		return fmt.Errorf("%w: %s", ErrBuiltInComponent, name)
+	}
+	return nil
+}
+
+// validateCustomComponent checks a request and fills in its defaults,
+// returning the decoded schema.
+func validateCustomComponent(req *models.CustomComponentRequest) (map[string]any, error) {
+	if !customComponentName.MatchString(req.Name) {
+		return nil, fmt.Errorf("%w: name %q must look like <type>_<role>, using lowercase letters, digits and underscores", ErrInvalidComponent, req.Name)
+	}
+	if !strings.HasSuffix(req.Name, "_"+req.Type) {
+		return nil, fmt.Errorf("%w: type must be the role the name ends with, %q", ErrInvalidComponent, req.Name[strings.LastIndex(req.Name, "_")+1:])
+	}
+
+	if len(req.SupportedSignals) == 0 {
+		return nil, fmt.Errorf("%w: supported_signals is required", ErrInvalidComponent)
+	}
+	seen := map[string]bool{}
+	for _, signal := range req.SupportedSignals {
+		if !validSignals[signal] {
+			return nil, fmt.Errorf("%w: unknown signal %q, must be traces, metrics or logs", ErrInvalidComponent, signal)
+		}
+		if seen[signal] {
+			return nil, fmt.Errorf("%w: signal %s is listed twice", ErrInvalidComponent, signal)
+		}
+		seen[signal] = true
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(req.Schema, &schema); err != nil || schema == nil {
+		return nil, fmt.Errorf("%w: schema must be a JSON object", ErrInvalidComponent)
+	}
+	if schema["type"] != "object" {
+		return nil, fmt.Errorf("%w: schema type must be \"object\"", ErrInvalidComponent)
+	}
+	if fieldErrors := schemavalidator.CheckSchema(schema); len(fieldErrors) > 0 {
+		return nil, fmt.Errorf("%w: invalid schema: %s", ErrInvalidComponent, formatFieldErrors(fieldErrors))
+	}
+
+	if len(req.UISchema) == 0 {
+		req.UISchema = json.RawMessage(`{}`)
+	}
+	var uiSchema map[string]any
+	if err := json.Unmarshal(req.UISchema, &uiSchema); err != nil || uiSchema == nil {
+		return nil, fmt.Errorf("%w: ui_schema must be a JSON object", ErrInvalidComponent)
+	}
+	for _, scope := range uiSchemaScopes(uiSchema, nil) {
+		if !resolvesScope(schema, scope) {
+			return nil, fmt.Errorf("%w: ui_schema scope %q is not a property of the schema", ErrInvalidComponent, scope)
+		}
+	}
+
+	if req.DisplayName == "" {
+		req.DisplayName = req.Name
+		if title, ok := schema["title"].(string); ok && title != "" {
+			req.DisplayName = title
+		}
+	}
+	return schema, nil
+}
+
+// uiSchemaScopes collects the scopes of a UI schema's controls.
+func uiSchemaScopes(value any, scopes []string) []string {
+	switch typed := value.(type) {
+	case map[string]any:
+		if scope, ok := typed["scope"].(string); ok {
+			scopes = append(scopes, scope)
+		}
+		for _, child := range typed {
+			scopes = uiSchemaScopes(child, scopes)
+		}
+	case []any:
+		for _, child := range typed {
+			scopes = uiSchemaScopes(child, scopes)
+		}
+	}
+	return scopes
+}
+
+// resolvesScope reports whether a scope such as #/properties/tls/properties/insecure
+// points at a schema in the document.
+func resolvesScope(schema map[string]any, scope string) bool {
+	if scope == "#" {
+		return true
+	}
+	path, ok := strings.CutPrefix(scope, "#/")
+	if !ok {
+		return false
+	}
+	var current any = schema
+	for _, token := range strings.Split(path, "/") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return false
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		if current, ok = object[token]; !ok {
+			return false
+		}
+	}
+	_, ok = current.(map[string]any)
+	return ok
+}
+
+func formatFieldErrors(fieldErrors []models.FieldError) string {
+	reasons := make([]string, len(fieldErrors))
+	for i, fieldError := range fieldErrors {
+		reasons[i] = fmt.Sprintf("%s: %s", fieldError.Pointer, fieldError.Reason)
+	}
+	return strings.Join(reasons, "; ")
+}
@@ -1,20 +1,41 @@
 package frontendnode
 
 import (
+	"encoding/json"
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
 )
 
 // MockFrontendNodeRepository is a manual mock for FrontendNodeRepositoryInterface
 type MockFrontendNodeRepository struct {
-	GetComponentsFunc              func(componentType string) (*[]ComponentInfo, error)
+	GetComponentsFunc              func(filter ComponentFilter) (*[]ComponentInfo, error)
+	GetReportedComponentsFunc      func(pipelineId int) (map[int]map[string]map[string]string, error)
+	PipelineExistsFunc             func(pipelineId int) bool
 	GetComponentSchemaByNameFunc   func(componentName string) (any, error)
 	GetComponentUISchemaByNameFunc func(componentName string) (any, error)
+	GetComponentMigrationsFunc     func(pipelineId int) ([]models.ComponentMigration, error)
+	IsCustomComponentFunc          func(name string) (bool, error)
+	CreateCustomComponentFunc      func(req models.CustomComponentRequest) error
+	UpdateCustomComponentFunc      func(req models.CustomComponentRequest) error
+	DeleteCustomComponentFunc      func(name string) error
+	GetComponentNodesFunc          func(name string) ([]ComponentNode, error)
+}
+
+func (m *MockFrontendNodeRepository) GetComponents(filter ComponentFilter) (*[]ComponentInfo, error) {
+	return m.GetComponentsFunc(filter)
 }
 
-func (m *MockFrontendNodeRepository) GetComponents(componentType string) (*[]ComponentInfo, error) {
-	return m.GetComponentsFunc(componentType)
+func (m *MockFrontendNodeRepository) GetReportedComponents(pipelineId int) (map[int]map[string]map[string]string, error) {
+	return m.GetReportedComponentsFunc(pipelineId)
+}
+
+func (m *MockFrontendNodeRepository) PipelineExists(pipelineId int) bool {
+	return m.PipelineExistsFunc(pipelineId)
 }
 
 func (m *MockFrontendNodeRepository) GetComponentSchemaByName(componentName string) (any, error) {
@@ -25,27 +46,54 @@ func (m *MockFrontendNodeRepository) GetComponentUISchemaByName(name string) (an
 	return m.GetComponentUISchemaByNameFunc(name)
 }
 
+func (m *MockFrontendNodeRepository) GetComponentMigrations(pipelineId int) ([]models.ComponentMigration, error) {
+	return m.GetComponentMigrationsFunc(pipelineId)
+}
+
+func (m *MockFrontendNodeRepository) IsCustomComponent(name string) (bool, error) {
+	return m.IsCustomComponentFunc(name)
+}
+
+func (m *MockFrontendNodeRepository) CreateCustomComponent(req models.CustomComponentRequest) error {
+	return m.CreateCustomComponentFunc(req)
+}
+
+func (m *MockFrontendNodeRepository) UpdateCustomComponent(req models.CustomComponentRequest) error {
+	return m.UpdateCustomComponentFunc(req)
+}
+
+func (m *MockFrontendNodeRepository) DeleteCustomComponent(name string) error {
+	return m.DeleteCustomComponentFunc(name)
+}
+
+func (m *MockFrontendNodeRepository) GetComponentNodes(name string) ([]ComponentNode, error) {
+	return m.GetComponentNodesFunc(name)
+}
+
 // TestFrontendNodeService_GetComponents tests the GetComponents method
 func TestFrontendNodeService_GetComponents(t *testing.T) {
 	mockRepo := &MockFrontendNodeRepository{
-		GetComponentsFunc: func(componentType string) (*[]ComponentInfo, error) {
+		GetComponentsFunc: func(filter ComponentFilter) (*[]ComponentInfo, error) {
 			return &[]ComponentInfo{
 				{Name: "ComponentA", Type: "source"},
 				{Name: "ComponentB", Type: "processor"},
 			}, nil
 		},
+		GetReportedComponentsFunc: func(pipelineId int) (map[int]map[string]map[string]string, error) {
+			return nil, nil
+		},
 	}
 
 	service := NewFrontendNodeService(mockRepo)
 
-	result, err := service.GetComponents("source")
+	result, err := service.GetComponents(ComponentFilter{Type: "source"})
 	if err != nil {
 		t.Fatalf("expected no error, got: %v", err)
 	}
 
 	expected := &[]ComponentInfo{
-		{Name: "ComponentA", Type: "source"},
-		{Name: "ComponentB", Type: "processor"},
+		{Name: "ComponentA", Type: "source", DefaultConfig: map[string]any{}},
+		{Name: "ComponentB", Type: "processor", DefaultConfig: map[string]any{}},
 	}
 
 	if !reflect.DeepEqual(result, expected) {
@@ -53,6 +101,68 @@ func TestFrontendNodeService_GetComponents(t *testing.T) {
 	}
 }
 
+// TestFrontendNodeService_GetComponents_Filters tests filtering by what agents report
+func TestFrontendNodeService_GetComponents_Filters(t *testing.T) {
+	var reportedFor int
+	mockRepo := &MockFrontendNodeRepository{
+		PipelineExistsFunc: func(pipelineId int) bool {
+			return pipelineId == 1
+		},
+		GetComponentsFunc: func(filter ComponentFilter) (*[]ComponentInfo, error) {
+			return &[]ComponentInfo{
+				{Name: "batch_processor", Schema: json.RawMessage(`{"type": "object", "properties": {
+					"timeout": {"type": "string", "default": "200ms"},
+					"send_batch_size": {"type": "integer"},
+					"tls": {"type": "object", "properties": {"insecure": {"type": "boolean", "default": false}}}
+				}}`)},
+				{Name: "kafka_exporter"},
+				{Name: "transform_processor"},
+			}, nil
+		},
+		GetReportedComponentsFunc: func(pipelineId int) (map[int]map[string]map[string]string, error) {
+			reportedFor = pipelineId
+			return map[int]map[string]map[string]string{
+				1: {"batch_processor": {"logs": "Stable"}, "kafka_exporter": {"logs": "Beta", "traces": "Beta"}},
+				2: {"batch_processor": {"logs": "Beta"}, "transform_processor": {"logs": "Alpha"}},
+			}, nil
+		},
+	}
+	service := NewFrontendNodeService(mockRepo)
+
+	result, err := service.GetComponents(ComponentFilter{PipelineID: 1})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if reportedFor != 1 || len(*result) != 1 || (*result)[0].Name != "batch_processor" {
+		t.Fatalf("expected only batch_processor, which both agents have, got %+v", *result)
+	}
+	batch := (*result)[0]
+	if !reflect.DeepEqual(batch.Stability, map[string]string{"logs": "beta"}) {
+		t.Errorf("expected the least stable level, got %v", batch.Stability)
+	}
+	expectedConfig := map[string]any{"timeout": "200ms", "tls": map[string]any{"insecure": false}}
+	if !reflect.DeepEqual(batch.DefaultConfig, expectedConfig) {
+		t.Errorf("expected default config %v, got %v", expectedConfig, batch.DefaultConfig)
+	}
+
+	result, err = service.GetComponents(ComponentFilter{Stability: "beta", Signal: "traces"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(*result) != 1 || (*result)[0].Name != "kafka_exporter" {
+		t.Errorf("expected only kafka_exporter, got %+v", *result)
+	}
+
+	result, err = service.GetComponents(ComponentFilter{Stability: "alpha"})
+	if err != nil || len(*result) != 1 || (*result)[0].Name != "transform_processor" {
+		t.Errorf("expected only transform_processor, got %+v (%v)", result, err)
+	}
+
+	if _, err := service.GetComponents(ComponentFilter{PipelineID: 9}); !errors.Is(err, utils.ErrPipelineDoesNotExists) {
+		t.Errorf("expected ErrPipelineDoesNotExists, got %v", err)
+	}
+}
+
 // TestFrontendNodeService_GetComponentSchemaByName tests the GetComponentSchemaByName method
 func TestFrontendNodeService_GetComponentSchemaByName(t *testing.T) {
 	mockRepo := &MockFrontendNodeRepository{
@@ -83,3 +193,158 @@ func TestFrontendNodeService_GetComponentSchemaByName(t *testing.T) {
 		t.Fatal("expected an error for unknown component, got nil")
 	}
 }
+
+const acmeSchema = `{
+	"title": "Acme Exporter",
+	"type": "object",
+	"properties": {
+		"endpoint": {"type": "string", "minLength": 1},
+		"tls": {"type": "object", "properties": {"insecure": {"type": "boolean"}}}
+	},
+	"required": ["endpoint"]
+}`
+
+func customComponentRequest() models.CustomComponentRequest {
+	return models.CustomComponentRequest{
+		Name:             "acme_exporter",
+		Type:             "exporter",
+		SupportedSignals: []string{"logs", "traces"},
+		Schema:           json.RawMessage(acmeSchema),
+		UISchema:         json.RawMessage(`{"type": "VerticalLayout", "elements": [{"type": "Control", "scope": "#/properties/tls/properties/insecure"}]}`),
+	}
+}
+
+// TestFrontendNodeService_CreateCustomComponent tests validation of custom components
+func TestFrontendNodeService_CreateCustomComponent(t *testing.T) {
+	var created models.CustomComponentRequest
+	mockRepo := &MockFrontendNodeRepository{
+		IsCustomComponentFunc: func(name string) (bool, error) {
+			if name == "otlp_exporter" {
+				return false, nil
+			}
+			return false, utils.ErrComponentDoesNotExists
+		},
+		CreateCustomComponentFunc: func(req models.CustomComponentRequest) error {
+			created = req
+			return nil
+		},
+	}
+	service := NewFrontendNodeService(mockRepo)
+
+	if err := service.CreateCustomComponent(customComponentRequest()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if created.DisplayName != "Acme Exporter" {
+		t.Errorf("expected the schema title as display name, got %q", created.DisplayName)
+	}
+
+	// Collector types can have underscores
+	req := customComponentRequest()
+	req.Name = "acme_http_exporter"
+	if err := service.CreateCustomComponent(req); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(req *models.CustomComponentRequest)
+		err    error
+		reason string
+	}{
+		{"name taken", func(req *models.CustomComponentRequest) { req.Name = "otlp_exporter" }, ErrComponentExists, "otlp_exporter"},
+		{"bad name", func(req *models.CustomComponentRequest) { req.Name = "acme__exporter" }, ErrInvalidComponent, "<type>_<role>"},
+		{"role mismatch", func(req *models.CustomComponentRequest) { req.Type = "receiver" }, ErrInvalidComponent, `"exporter"`},
+		{"no signals", func(req *models.CustomComponentRequest) { req.SupportedSignals = nil }, ErrInvalidComponent, "supported_signals"},
+		{"unknown signal", func(req *models.CustomComponentRequest) { req.SupportedSignals = []string{"profiles"} }, ErrInvalidComponent, "profiles"},
+		{"not an object schema", func(req *models.CustomComponentRequest) { req.Schema = json.RawMessage(`{"type": "string"}`) }, ErrInvalidComponent, "schema type"},
+		{"malformed schema", func(req *models.CustomComponentRequest) {
+			req.Schema = json.RawMessage(`{"type": "object", "properties": {"port": {"type": "port"}}}`)
+		}, ErrInvalidComponent, `/properties/port/type: unknown type "port"`},
+		{"dangling scope", func(req *models.CustomComponentRequest) {
+			req.UISchema = json.RawMessage(`{"type": "Control", "scope": "#/properties/brokers"}`)
+		}, ErrInvalidComponent, "#/properties/brokers"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := customComponentRequest()
+			tt.modify(&req)
+			err := service.CreateCustomComponent(req)
+			if !errors.Is(err, tt.err) || !strings.Contains(err.Error(), tt.reason) {
+				t.Errorf("expected %v mentioning %q, got %v", tt.err, tt.reason, err)
+			}
+		})
+	}
+}
+
+// TestFrontendNodeService_UpdateCustomComponent tests that updates keep existing nodes valid
+func TestFrontendNodeService_UpdateCustomComponent(t *testing.T) {
+	updates := 0
+	mockRepo := &MockFrontendNodeRepository{
+		IsCustomComponentFunc: func(name string) (bool, error) {
+			return name == "acme_exporter", nil
+		},
+		GetComponentNodesFunc: func(name string) ([]ComponentNode, error) {
+			return []ComponentNode{{PipelineName: "edge", Name: "acme", Config: `{"endpoint": "acme:4317"}`}}, nil
+		},
+		UpdateCustomComponentFunc: func(req models.CustomComponentRequest) error {
+			updates++
+			return nil
+		},
+	}
+	service := NewFrontendNodeService(mockRepo)
+
+	if err := service.UpdateCustomComponent("acme_exporter", customComponentRequest()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	req := customComponentRequest()
+	req.Schema = json.RawMessage(`{"type": "object", "properties": {"endpoint": {"type": "integer"}, "tls": {"type": "object", "properties": {"insecure": {}}}}}`)
+	err := service.UpdateCustomComponent("acme_exporter", req)
+	if !errors.Is(err, ErrComponentInUse) || !strings.Contains(err.Error(), "edge/acme (/endpoint: expected integer, got string)") {
+		t.Errorf("expected the edge/acme node to block the update, got %v", err)
+	}
+
+	if err := service.UpdateCustomComponent("otlp_exporter", customComponentRequest()); !errors.Is(err, ErrBuiltInComponent) {
+		t.Errorf("expected ErrBuiltInComponent, got %v", err)
+	}
+	if updates != 1 {
+		t.Errorf("expected 1 update, got %d", updates)
+	}
+}
+
+// TestFrontendNodeService_DeleteCustomComponent tests that components in use aren't deleted
+func TestFrontendNodeService_DeleteCustomComponent(t *testing.T) {
+	var deleted []string
+	mockRepo := &MockFrontendNodeRepository{
+		IsCustomComponentFunc: func(name string) (bool, error) {
+			if name == "missing_exporter" {
+				return false, utils.ErrComponentDoesNotExists
+			}
+			return true, nil
+		},
+		GetComponentNodesFunc: func(name string) ([]ComponentNode, error) {
+			if name == "acme_exporter" {
+				return []ComponentNode{{PipelineName: "edge", Name: "a"}, {PipelineName: "edge", Name: "b"}, {PipelineName: "core", Name: "c"}}, nil
+			}
+			return nil, nil
+		},
+		DeleteCustomComponentFunc: func(name string) error {
+			deleted = append(deleted, name)
+			return nil
+		},
+	}
+	service := NewFrontendNodeService(mockRepo)
+
+	if err := service.DeleteCustomComponent("acme_exporter"); !errors.Is(err, ErrComponentInUse) || !strings.Contains(err.Error(), "used by pipelines edge, core") {
+		t.Errorf("expected ErrComponentInUse, got %v", err)
+	}
+	if err := service.DeleteCustomComponent("missing_exporter"); !errors.Is(err, utils.ErrComponentDoesNotExists) {
+		t.Errorf("expected ErrComponentDoesNotExists, got %v", err)
+	}
+	if err := service.DeleteCustomComponent("unused_exporter"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !reflect.DeepEqual(deleted, []string{"unused_exporter"}) {
+		t.Errorf("expected only unused_exporter to be deleted, got %v", deleted)
+	}
+}
@@ -0,0 +1,121 @@
+package frontendpipeline
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/constants"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+)
+
+// DiffPipelineGraphs reports what applying desired over current would change.
+// Node IDs differ between pipelines, so nodes are matched on component name
+// and node name, and edges are compared by the names of their endpoints.
+func DiffPipelineGraphs(current, desired models.PipelineGraph) models.PipelineDiff {
+	diff := models.PipelineDiff{
+		NodesAdded:   []models.PipelineNodes{},
+		NodesRemoved: []models.PipelineNodes{},
+		NodesChanged: []models.PipelineNodeChange{},
+		EdgesAdded:   []models.PipelineEdges{},
+		EdgesRemoved: []models.PipelineEdges{},
+	}
+
+	currentNodes := nodesByKey(current)
+	desiredNodes := nodesByKey(desired)
+
+	for _, key := range sortedKeys(desiredNodes) {
+		after := desiredNodes[key]
+		before, ok := currentNodes[key]
+		if !ok {
+			diff.NodesAdded = append(diff.NodesAdded, after)
+			continue
+		}
+		if !sameConfig(before.Config, after.Config) {
+			diff.NodesChanged = append(diff.NodesChanged, models.PipelineNodeChange{
+				Name:          after.Name,
+				ComponentName: after.ComponentName,
+				Before:        before.Config,
+				After:         after.Config,
+			})
+		}
+	}
+	for _, key := range sortedKeys(currentNodes) {
+		if _, ok := desiredNodes[key]; !ok {
+			diff.NodesRemoved = append(diff.NodesRemoved, currentNodes[key])
+		}
+	}
+
+	currentEdges := edgesByName(current)
+	desiredEdges := edgesByName(desired)
+	for _, key := range sortedKeys(desiredEdges) {
+		if _, ok := currentEdges[key]; !ok {
+			diff.EdgesAdded = append(diff.EdgesAdded, desiredEdges[key])
+		}
+	}
+	for _, key := range sortedKeys(currentEdges) {
+		if _, ok := desiredEdges[key]; !ok {
+			diff.EdgesRemoved = append(diff.EdgesRemoved, currentEdges[key])
+		}
+	}
+
+	diff.TelemetryChanged = !reflect.DeepEqual(effectiveTelemetry(current), effectiveTelemetry(desired))
+	return diff
+}
+
+func nodeKey(node models.PipelineNodes) string {
+	return node.ComponentName + "/" + node.Name
+}
+
+func nodesByKey(graph models.PipelineGraph) map[string]models.PipelineNodes {
+	nodes := make(map[string]models.PipelineNodes, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		nodes[nodeKey(node)] = node
+	}
+	return nodes
+}
+
+// edgesByName rewrites edge endpoints from component IDs to node names.
+// Edges pointing at unknown nodes are dropped.
+func edgesByName(graph models.PipelineGraph) map[string]models.PipelineEdges {
+	names := make(map[string]string, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		names[strconv.Itoa(node.ComponentID)] = node.Name
+	}
+
+	edges := make(map[string]models.PipelineEdges, len(graph.Edges))
+	for _, edge := range graph.Edges {
+		source, okSource := names[edge.Source]
+		target, okTarget := names[edge.Target]
+		if !okSource || !okTarget {
+			continue
+		}
+		edges[source+"->"+target] = models.PipelineEdges{Source: source, Target: target}
+	}
+	return edges
+}
+
+// sameConfig treats a nil config and an empty one as equal, since both
+// compile to an empty component block.
+func sameConfig(a, b map[string]any) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func effectiveTelemetry(graph models.PipelineGraph) models.PipelineTelemetry {
+	if graph.Telemetry == nil {
+		return constants.DefaultPipelineTelemetry
+	}
+	return *graph.Telemetry
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,62 @@
+package frontendpipeline_test
+
+import (
+	"testing"
+
+	frontendpipeline "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/pipeline"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffPipelineGraphs(t *testing.T) {
+	current := models.PipelineGraph{
+		Nodes: []models.PipelineNodes{
+			{ComponentID: 10, Name: "OTLP", ComponentName: "otlp_receiver", Config: map[string]any{}},
+			{ComponentID: 11, Name: "Batch", ComponentName: "batch_processor", Config: map[string]any{"timeout": "1s"}},
+			{ComponentID: 12, Name: "Debug", ComponentName: "debug_exporter"},
+		},
+		Edges: []models.PipelineEdges{{Source: "10", Target: "11"}, {Source: "11", Target: "12"}},
+	}
+	desired := models.PipelineGraph{
+		Nodes: []models.PipelineNodes{
+			{ComponentID: 1, Name: "OTLP", ComponentName: "otlp_receiver"},
+			{ComponentID: 2, Name: "Batch", ComponentName: "batch_processor", Config: map[string]any{"timeout": "5s"}},
+			{ComponentID: 3, Name: "Kafka", ComponentName: "kafka_exporter", Config: map[string]any{"topic": "logs"}},
+		},
+		Edges: []models.PipelineEdges{{Source: "1", Target: "2"}, {Source: "2", Target: "3"}},
+	}
+
+	diff := frontendpipeline.DiffPipelineGraphs(current, desired)
+
+	assert.Len(t, diff.NodesAdded, 1)
+	assert.Equal(t, "Kafka", diff.NodesAdded[0].Name)
+	assert.Len(t, diff.NodesRemoved, 1)
+	assert.Equal(t, "Debug", diff.NodesRemoved[0].Name)
+	assert.Equal(t, []models.PipelineNodeChange{{
+		Name:          "Batch",
+		ComponentName: "batch_processor",
+		Before:        map[string]any{"timeout": "1s"},
+		After:         map[string]any{"timeout": "5s"},
+	}}, diff.NodesChanged)
+	assert.Equal(t, []models.PipelineEdges{{Source: "Batch", Target: "Kafka"}}, diff.EdgesAdded)
+	assert.Equal(t, []models.PipelineEdges{{Source: "Batch", Target: "Debug"}}, diff.EdgesRemoved)
+	assert.False(t, diff.TelemetryChanged)
+}
+
+func TestDiffPipelineGraphs_Identical(t *testing.T) {
+	graph := models.PipelineGraph{
+		Nodes: []models.PipelineNodes{
+			{ComponentID: 1, Name: "OTLP", ComponentName: "otlp_receiver"},
+			{ComponentID: 2, Name: "Debug", ComponentName: "debug_exporter"},
+		},
+		Edges: []models.PipelineEdges{{Source: "1", Target: "2"}},
+	}
+
+	diff := frontendpipeline.DiffPipelineGraphs(graph, graph)
+	assert.Empty(t, diff.NodesAdded)
+	assert.Empty(t, diff.NodesRemoved)
+	assert.Empty(t, diff.NodesChanged)
+	assert.Empty(t, diff.EdgesAdded)
+	assert.Empty(t, diff.EdgesRemoved)
+	assert.False(t, diff.TelemetryChanged)
+}
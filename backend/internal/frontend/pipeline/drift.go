@@ -0,0 +1,276 @@
+package frontendpipeline
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/constants"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/agentclient"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/secrets"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+)
+
+// SetPipelineDriftPolicy sets what happens when an agent of the pipeline
+// reports running a config that differs from the pipeline's.
+func (f *FrontendPipelineService) SetPipelineDriftPolicy(pipelineId int, policy string) error {
+	switch policy {
+	case models.DriftPolicyAutoRemediate, models.DriftPolicyAlert, models.DriftPolicyAdopt:
+	default:
+		return fmt.Errorf("%w %q: must be one of %s, %s or %s", utils.ErrInvalidDriftPolicy, policy,
+			models.DriftPolicyAutoRemediate, models.DriftPolicyAlert, models.DriftPolicyAdopt)
+	}
+
+	if !f.FrontendPipelineRepository.PipelineExists(pipelineId) {
+		return utils.ErrPipelineDoesNotExists
+	}
+	return f.FrontendPipelineRepository.SetPipelineDriftPolicy(pipelineId, policy)
+}
+
+// ReportEffectiveConfig compares the effective config hash an agent reported
+// with the hash of its pipeline's compiled config, records the result and, if
+// they differ, applies the pipeline's drift policy. An empty hash means the
+// agent has no config file. Failures of the policy are recorded on the
+// returned drift rather than returned.
+func (f *FrontendPipelineService) ReportEffectiveConfig(agentId string, effectiveHash string) (*models.ConfigDrift, error) {
+	agentIDInt, err := strconv.Atoi(agentId)
+	if err != nil {
+		return nil, fmt.Errorf("error converting agent ID to int: %v", err)
+	}
+
+	pipelineId, err := f.FrontendPipelineRepository.GetAgentPipelineId(agentId)
+	if err != nil {
+		return nil, err
+	}
+	if pipelineId == nil {
+		// Nothing is desired of agents outside a pipeline
+		return nil, nil
+	}
+
+	config, err := f.GetPipelineConfig(*pipelineId)
+	if err != nil {
+		return nil, err
+	}
+	desiredHash, err := utils.EffectiveConfigHash(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash pipeline config: %w", err)
+	}
+
+	drift := models.ConfigDrift{
+		AgentID:       agentIDInt,
+		PipelineID:    *pipelineId,
+		Status:        models.ConfigInSync,
+		DesiredHash:   desiredHash,
+		EffectiveHash: effectiveHash,
+		CheckedAt:     utils.GetCurrentTime(),
+	}
+	if effectiveHash == desiredHash {
+		f.saveConfigDrift(drift)
+		return &drift, nil
+	}
+
+	drift.Status = models.ConfigDrifted
+	drift.DriftedSince = drift.CheckedAt
+	previous, err := f.FrontendPipelineRepository.GetConfigDrift(agentIDInt)
+	if err != nil {
+		utils.Logger.Sugar().Errorf("Failed to read config drift of agent [ID:%v]: %v", agentId, err)
+	} else if previous != nil && previous.Status == models.ConfigDrifted {
+		drift.DriftedSince = previous.DriftedSince
+	}
+
+	info, err := f.GetPipelineInfo(*pipelineId)
+	if err != nil {
+		return nil, err
+	}
+	agent, err := f.FrontendPipelineRepository.GetAgentInfo(agentIDInt)
+	if err != nil {
+		return nil, err
+	}
+
+	switch info.DriftPolicy {
+	case models.DriftPolicyAlert:
+		drift.Action = models.DriftAlerted
+		utils.Logger.Sugar().Warnf("Agent [ID:%v] runs config %s instead of %s of pipeline [ID:%v]", agentId, effectiveHash, desiredHash, *pipelineId)
+	case models.DriftPolicyAdopt:
+		drift.Action = models.DriftAdopted
+		err = f.adoptAgentConfig(*pipelineId, *agent)
+	default:
+		if f.failedToApply(agentIDInt, desiredHash) {
+			// The agent rolled back from this config; pushing it again would
+			// only fail again
+			drift.Action = models.DriftAlerted
+			err = fmt.Errorf("agent failed to apply the pipeline's config, so it isn't pushed again until the config changes")
+			break
+		}
+		drift.Action = models.DriftRemediated
+		err = f.SyncConfig(agentId)
+	}
+	if err != nil {
+		drift.Error = err.Error()
+		utils.Logger.Sugar().Errorf("Failed to apply %s drift policy to agent [ID:%v]: %v", info.DriftPolicy, agentId, err)
+	}
+
+	f.saveConfigDrift(drift)
+	return &drift, nil
+}
+
+// adoptAgentConfig replaces the pipeline's graph with the agent's effective
+// config and sends it to every agent of the pipeline. Configs using
+// components missing from the catalog aren't adopted, as they'd be dropped.
+// Secrets the backend filled in become ${secret:name} references again;
+// configs with other secrets aren't adopted, as they'd be stored in the graph.
+func (f *FrontendPipelineService) adoptAgentConfig(pipelineId int, agent models.AgentInfoHome) error {
+	config, err := f.fetchEffectiveConfig(agent)
+	if err != nil {
+		return err
+	}
+
+	imported, err := f.ImportPipeline(config)
+	if err != nil {
+		return err
+	}
+	// Extensions are never part of a graph, such as the status extension
+	// agents enable themselves, so only dropped pipeline components count
+	var names []string
+	for _, component := range imported.Unmapped {
+		if component.Role != "extension" {
+			names = append(names, component.ID)
+		}
+	}
+	if len(names) > 0 {
+		return fmt.Errorf("agent config uses components missing from the catalog: %s", strings.Join(names, ", "))
+	}
+
+	stored, err := f.GetPipelineGraph(pipelineId)
+	if err != nil {
+		return err
+	}
+	graph, err := f.unresolveSecrets(imported.PipelineGraph, *stored)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.SyncPipelineGraph(pipelineId, graph)
+	return err
+}
+
+// unresolveSecrets turns the secrets of the stored graph that the backend
+// filled into an agent's config back into ${secret:name} references. It fails
+// if the graph still has secret env references or sensitive plaintext the
+// stored graph doesn't have.
+func (f *FrontendPipelineService) unresolveSecrets(graph models.PipelineGraph, stored models.PipelineGraph) (models.PipelineGraph, error) {
+	values := make(map[string]string)
+	for _, name := range secrets.References(stored) {
+		values[name] = ""
+		if constants.SECRETS_RESOLUTION == secrets.ResolveOnAgent || f.SecretResolver == nil {
+			continue
+		}
+		value, err := f.SecretResolver.ResolveSecret(name)
+		if err != nil {
+			return models.PipelineGraph{}, fmt.Errorf("cannot resolve secret %s: %w", name, err)
+		}
+		values[name] = value
+	}
+	graph = secrets.UnresolveGraph(graph, values)
+
+	plaintext := make(map[string]bool)
+	for _, node := range stored.Nodes {
+		for _, value := range secrets.SensitivePlaintext(node.Config) {
+			plaintext[value] = true
+		}
+	}
+	var unknown []string
+	for _, node := range graph.Nodes {
+		if len(secrets.EnvReferences(node.Config)) > 0 || slices.ContainsFunc(secrets.SensitivePlaintext(node.Config), func(value string) bool { return !plaintext[value] }) {
+			unknown = append(unknown, node.Name)
+		}
+	}
+	if len(unknown) > 0 {
+		return models.PipelineGraph{}, fmt.Errorf("agent config has secrets the pipeline doesn't reference, in %s; add them to the secrets store and reference them in the pipeline instead", strings.Join(unknown, ", "))
+	}
+	return graph, nil
+}
+
+// GetConfigDrift returns the agent's last drift check, or nil if the agent
+// hasn't reported its config since it joined its pipeline.
+func (f *FrontendPipelineService) GetConfigDrift(agentId int) (*models.ConfigDrift, error) {
+	if _, err := f.FrontendPipelineRepository.GetAgentInfo(agentId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.ErrAgentDoesNotExists
+		}
+		return nil, err
+	}
+	return f.FrontendPipelineRepository.GetConfigDrift(agentId)
+}
+
+// GetPipelineConfigDrift returns the last drift check of the pipeline's agents.
+func (f *FrontendPipelineService) GetPipelineConfigDrift(pipelineId int) ([]models.ConfigDrift, error) {
+	if !f.FrontendPipelineRepository.PipelineExists(pipelineId) {
+		return nil, utils.ErrPipelineDoesNotExists
+	}
+
+	drifts, err := f.FrontendPipelineRepository.GetPipelineConfigDrift(pipelineId)
+	if err != nil {
+		return nil, err
+	}
+	if drifts == nil {
+		drifts = []models.ConfigDrift{}
+	}
+	return drifts, nil
+}
+
+// GetEffectiveConfig asks the agent for the config it runs.
+func (f *FrontendPipelineService) GetEffectiveConfig(agentId int) (map[string]any, error) {
+	agent, err := f.FrontendPipelineRepository.GetAgentInfo(agentId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.ErrAgentDoesNotExists
+		}
+		return nil, err
+	}
+	return f.fetchEffectiveConfig(*agent)
+}
+
+// failedToApply tells whether the agent's last config apply was of the given
+// config version and failed.
+func (f *FrontendPipelineService) failedToApply(agentId int, version string) bool {
+	applies, err := f.FrontendPipelineRepository.GetConfigApplies(agentId)
+	if err != nil {
+		utils.Logger.Sugar().Errorf("Failed to read config applies of agent [ID:%v]: %v", agentId, err)
+		return false
+	}
+	return len(applies) > 0 && applies[0].ConfigVersion == version && applies[0].Status == models.ConfigApplyFailed
+}
+
+func (f *FrontendPipelineService) saveConfigDrift(drift models.ConfigDrift) {
+	if err := f.FrontendPipelineRepository.SaveConfigDrift(drift); err != nil {
+		utils.Logger.Sugar().Errorf("Failed to record config drift of agent [ID:%v]: %v", drift.AgentID, err)
+	}
+}
+
+func (f *FrontendPipelineService) fetchEffectiveConfig(agent models.AgentInfoHome) (map[string]any, error) {
+	resp, err := agentclient.Do(agent, http.MethodGet, "/agent/v1/config", nil)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.OK() {
+		return nil, fmt.Errorf("request failed with status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Config map[string]any `json:"config"`
+	}
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return nil, fmt.Errorf("error decoding agent config: %v", err)
+	}
+	if body.Config == nil {
+		return nil, fmt.Errorf("agent has no config")
+	}
+	return body.Config, nil
+}
@@ -0,0 +1,65 @@
+package frontendpipeline
+
+import (
+	"testing"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/constants"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/secrets"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type secretValues map[string]string
+
+func (s secretValues) ResolveSecret(name string) (string, error) {
+	value, ok := s[name]
+	if !ok {
+		return "", utils.ErrSecretDoesNotExists
+	}
+	return value, nil
+}
+
+func kafkaExporterGraph(password string) models.PipelineGraph {
+	return models.PipelineGraph{Nodes: []models.PipelineNodes{
+		{ComponentID: 1, Name: "Kafka", ComponentName: "kafka_exporter", Config: map[string]any{
+			"brokers": []any{"kafka:9092"},
+			"auth":    map[string]any{"sasl": map[string]any{"username": "ingest", "password": password}},
+		}},
+	}}
+}
+
+func TestUnresolveSecrets_Compile(t *testing.T) {
+	service := &FrontendPipelineService{SecretResolver: secretValues{"kafka": "hunter2"}}
+	stored := kafkaExporterGraph("${secret:kafka}")
+
+	graph, err := service.unresolveSecrets(kafkaExporterGraph("hunter2"), stored)
+	require.NoError(t, err)
+	assert.Equal(t, stored, graph)
+
+	// A password the pipeline doesn't know isn't stored in the graph
+	_, err = service.unresolveSecrets(kafkaExporterGraph("changed-on-agent"), stored)
+	assert.ErrorContains(t, err, "in Kafka")
+
+	// Plaintext the pipeline already stores is kept
+	graph, err = service.unresolveSecrets(kafkaExporterGraph("plaintext"), kafkaExporterGraph("plaintext"))
+	require.NoError(t, err)
+	assert.Equal(t, kafkaExporterGraph("plaintext"), graph)
+}
+
+func TestUnresolveSecrets_Env(t *testing.T) {
+	resolution := constants.SECRETS_RESOLUTION
+	constants.SECRETS_RESOLUTION = secrets.ResolveOnAgent
+	defer func() { constants.SECRETS_RESOLUTION = resolution }()
+
+	service := &FrontendPipelineService{}
+	stored := kafkaExporterGraph("${secret:kafka}")
+
+	graph, err := service.unresolveSecrets(kafkaExporterGraph("${env:CTRLB_SECRET_KAFKA}"), stored)
+	require.NoError(t, err)
+	assert.Equal(t, stored, graph)
+
+	_, err = service.unresolveSecrets(kafkaExporterGraph("${env:CTRLB_SECRET_OTHER}"), stored)
+	assert.Error(t, err)
+}
@@ -1,11 +1,18 @@
 package frontendpipeline
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/configcompiler"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/configexport"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/labelselector"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/secrets"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
 	"github.com/gorilla/mux"
 )
@@ -53,6 +60,9 @@ func (f *FrontendPipelineHandler) CreatePipeline(w http.ResponseWriter, r *http.
 	pipelineId, err := f.FrontendPipelineService.CreatePipeline(req)
 	if err != nil {
 		utils.Logger.Error(fmt.Sprintf("Error creating pipeline: %v", err))
+		if SendGraphValidationError(w, err) {
+			return
+		}
 		utils.SendJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Error creating pipeline: %v", err))
 		return
 	}
@@ -95,6 +105,9 @@ func (f *FrontendPipelineHandler) GetPipelineOverview(w http.ResponseWriter, r *
 		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if response.Config != nil {
+		response.Config = secrets.RedactConfig(response.Config)
+	}
 	utils.WriteJSONResponse(w, http.StatusOK, response)
 }
 
@@ -183,13 +196,16 @@ func (f *FrontendPipelineHandler) AttachAgentToPipeline(w http.ResponseWriter, r
 
 	utils.Logger.Info(fmt.Sprintf("Request received to attach agent [ID: %s] to pipeline with ID: %s", agentId, pipelineId))
 
-	err = f.FrontendPipelineService.AttachAgentToPipeline(pipelineIdInt, agentIdInt)
+	jobIds, err := f.FrontendPipelineService.AttachAgentToPipeline(pipelineIdInt, agentIdInt)
 	if err != nil {
 		utils.Logger.Error(fmt.Sprintf("Error attach agent [ID: %s] to pipeline [ID: %s]: %v", agentId, pipelineId, err))
+		if SendGraphValidationError(w, err) {
+			return
+		}
 		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Agent [ID: " + agentId + "] attached successfully to pipeline [ID: " + pipelineId + "]"})
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]any{"message": "Agent [ID: " + agentId + "] attached successfully to pipeline [ID: " + pipelineId + "]", "job_ids": jobIds})
 }
 
 func (f *FrontendPipelineHandler) GetPipelineGraph(w http.ResponseWriter, r *http.Request) {
@@ -208,7 +224,7 @@ func (f *FrontendPipelineHandler) GetPipelineGraph(w http.ResponseWriter, r *htt
 		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	utils.WriteJSONResponse(w, http.StatusOK, response)
+	utils.WriteJSONResponse(w, http.StatusOK, secrets.RedactGraph(*response))
 }
 
 func (f *FrontendPipelineHandler) SyncPipelineGraph(w http.ResponseWriter, r *http.Request) {
@@ -229,11 +245,652 @@ func (f *FrontendPipelineHandler) SyncPipelineGraph(w http.ResponseWriter, r *ht
 		return
 	}
 
-	err = f.FrontendPipelineService.SyncPipelineGraph(pipelineIdInt, graph)
+	if err := utils.ValidatePipelineTelemetry(graph.Telemetry); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid telemetry settings: %v", err))
+		return
+	}
+
+	jobIds, err := f.FrontendPipelineService.SyncPipelineGraph(pipelineIdInt, graph)
 	if err != nil {
 		utils.Logger.Error(fmt.Sprintf("Error syncing graph for pipeline [ID: %s]: %v", pipelineId, err))
+		if SendGraphValidationError(w, err) {
+			return
+		}
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]any{"message": "Pipeline graph synced successfully", "job_ids": jobIds})
+}
+
+func (f *FrontendPipelineHandler) GetPipelineTelemetry(w http.ResponseWriter, r *http.Request) {
+	pipelineId := mux.Vars(r)["id"]
+	pipelineIdInt, err := strconv.Atoi(pipelineId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid pipeline ID format")
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to get telemetry settings for pipeline with ID: %s", pipelineId))
+
+	response, err := f.FrontendPipelineService.GetPipelineTelemetry(pipelineIdInt)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error getting telemetry settings for pipeline [ID: %s]: %v", pipelineId, err))
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (f *FrontendPipelineHandler) UpdatePipelineTelemetry(w http.ResponseWriter, r *http.Request) {
+	pipelineId := mux.Vars(r)["id"]
+	pipelineIdInt, err := strconv.Atoi(pipelineId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid pipeline ID format")
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to update telemetry settings for pipeline with ID: %s", pipelineId))
+
+	var telemetry models.PipelineTelemetry
+	if err := utils.UnmarshalJSONRequest(r, &telemetry); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+		return
+	}
+
+	if err := utils.ValidatePipelineTelemetry(&telemetry); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid telemetry settings: %v", err))
+		return
+	}
+
+	err = f.FrontendPipelineService.UpdatePipelineTelemetry(pipelineIdInt, telemetry)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error updating telemetry settings for pipeline [ID: %s]: %v", pipelineId, err))
+		if SendGraphValidationError(w, err) {
+			return
+		}
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Pipeline telemetry settings updated successfully"})
+}
+
+func (f *FrontendPipelineHandler) LintPipeline(w http.ResponseWriter, r *http.Request) {
+	pipelineId := mux.Vars(r)["id"]
+	pipelineIdInt, err := strconv.Atoi(pipelineId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid pipeline ID format")
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to lint pipeline with ID: %s", pipelineId))
+
+	response, err := f.FrontendPipelineService.LintPipeline(pipelineIdInt)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error linting pipeline [ID: %s]: %v", pipelineId, err))
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (f *FrontendPipelineHandler) ImportPipeline(w http.ResponseWriter, r *http.Request) {
+	utils.Logger.Info("Request received to import a collector config")
+
+	var collectorConfig map[string]any
+	if err := utils.UnmarshalYAMLRequest(r, &collectorConfig); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid collector config: %v", err))
+		return
+	}
+
+	response, err := f.FrontendPipelineService.ImportPipeline(collectorConfig)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error importing collector config: %v", err))
+		utils.SendJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (f *FrontendPipelineHandler) ExportPipeline(w http.ResponseWriter, r *http.Request) {
+	pipelineId := mux.Vars(r)["id"]
+	pipelineIdInt, err := strconv.Atoi(pipelineId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid pipeline ID format")
+		return
+	}
+
+	query := r.URL.Query()
+	format := query.Get("format")
+	if !configexport.IsSupportedFormat(format) {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Unsupported export format: %s", format))
+		return
+	}
+	if format == "" {
+		format = configexport.FormatYAML
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to export pipeline with ID: %s as %s", pipelineId, format))
+
+	output, err := f.FrontendPipelineService.ExportPipeline(pipelineIdInt, format, configexport.Options{
+		Namespace: query.Get("namespace"),
+		Image:     query.Get("image"),
+	})
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error exporting pipeline [ID: %s]: %v", pipelineId, err))
+		if SendGraphValidationError(w, err) {
+			return
+		}
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"pipeline-%d-%s.yaml\"", pipelineIdInt, format))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(output); err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error writing export for pipeline [ID: %s]: %v", pipelineId, err))
+	}
+}
+
+func (f *FrontendPipelineHandler) SetPipelineEnvironment(w http.ResponseWriter, r *http.Request) {
+	pipelineId := mux.Vars(r)["id"]
+	pipelineIdInt, err := strconv.Atoi(pipelineId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid pipeline ID format")
+		return
+	}
+
+	var req struct {
+		Environment string `json:"environment"`
+	}
+	if err := utils.UnmarshalJSONRequest(r, &req); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to set environment of pipeline with ID: %s to %q", pipelineId, req.Environment))
+
+	if err := f.FrontendPipelineService.SetPipelineEnvironment(pipelineIdInt, req.Environment); err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error setting environment of pipeline [ID: %s]: %v", pipelineId, err))
+		sendPromotionError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Pipeline environment updated successfully"})
+}
+
+func (f *FrontendPipelineHandler) ClonePipeline(w http.ResponseWriter, r *http.Request) {
+	pipelineId := mux.Vars(r)["id"]
+	pipelineIdInt, err := strconv.Atoi(pipelineId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid pipeline ID format")
+		return
+	}
+
+	var req models.ClonePipelineRequest
+	if r.ContentLength != 0 {
+		if err := utils.UnmarshalJSONRequest(r, &req); err != nil {
+			utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+			return
+		}
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to clone pipeline with ID: %s", pipelineId))
+
+	newPipelineId, err := f.FrontendPipelineService.ClonePipeline(pipelineIdInt, req)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error cloning pipeline [ID: %s]: %v", pipelineId, err))
+		sendPromotionError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Pipeline cloned successfully", "id": newPipelineId})
+}
+
+func (f *FrontendPipelineHandler) PreviewPromotion(w http.ResponseWriter, r *http.Request) {
+	pipelineId := mux.Vars(r)["id"]
+	pipelineIdInt, err := strconv.Atoi(pipelineId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid pipeline ID format")
+		return
+	}
+
+	to := r.URL.Query().Get("to")
+	utils.Logger.Info(fmt.Sprintf("Request received to preview promotion of pipeline with ID: %s to %q", pipelineId, to))
+
+	response, err := f.FrontendPipelineService.PreviewPromotion(pipelineIdInt, to)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error previewing promotion of pipeline [ID: %s]: %v", pipelineId, err))
+		sendPromotionError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, redactPromotion(response))
+}
+
+func (f *FrontendPipelineHandler) PromotePipeline(w http.ResponseWriter, r *http.Request) {
+	pipelineId := mux.Vars(r)["id"]
+	pipelineIdInt, err := strconv.Atoi(pipelineId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid pipeline ID format")
+		return
+	}
+
+	var req models.PromotePipelineRequest
+	if r.ContentLength != 0 {
+		if err := utils.UnmarshalJSONRequest(r, &req); err != nil {
+			utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+			return
+		}
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to promote pipeline with ID: %s to %q", pipelineId, req.To))
+
+	response, err := f.FrontendPipelineService.PromotePipeline(pipelineIdInt, req)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error promoting pipeline [ID: %s]: %v", pipelineId, err))
+		sendPromotionError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, redactPromotion(response))
+}
+
+// redactPromotion hides sensitive values in the node configs of a promotion diff.
+func redactPromotion(promotion *models.PipelinePromotion) *models.PipelinePromotion {
+	redacted := *promotion
+	redacted.Diff.NodesAdded = secrets.RedactGraph(models.PipelineGraph{Nodes: promotion.Diff.NodesAdded}).Nodes
+	redacted.Diff.NodesRemoved = secrets.RedactGraph(models.PipelineGraph{Nodes: promotion.Diff.NodesRemoved}).Nodes
+	redacted.Diff.NodesChanged = make([]models.PipelineNodeChange, len(promotion.Diff.NodesChanged))
+	for i, change := range promotion.Diff.NodesChanged {
+		if change.Before != nil {
+			change.Before = secrets.RedactConfig(change.Before)
+		}
+		if change.After != nil {
+			change.After = secrets.RedactConfig(change.After)
+		}
+		redacted.Diff.NodesChanged[i] = change
+	}
+	return &redacted
+}
+
+// sendPromotionError maps clone and promotion failures to a status code.
+func (f *FrontendPipelineHandler) GetPipelineSelector(w http.ResponseWriter, r *http.Request) {
+	pipelineId := mux.Vars(r)["id"]
+	pipelineIdInt, err := strconv.Atoi(pipelineId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid pipeline ID format")
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to get label selector of pipeline with ID: %s", pipelineId))
+
+	status, err := f.FrontendPipelineService.GetSelectorStatus(pipelineIdInt)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error getting label selector of pipeline [ID: %s]: %v", pipelineId, err))
+		sendSelectorError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, status)
+}
+
+func (f *FrontendPipelineHandler) SetPipelineSelector(w http.ResponseWriter, r *http.Request) {
+	pipelineId := mux.Vars(r)["id"]
+	pipelineIdInt, err := strconv.Atoi(pipelineId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid pipeline ID format")
+		return
+	}
+
+	var req struct {
+		Selector string `json:"selector"`
+	}
+	if err := utils.UnmarshalJSONRequest(r, &req); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to set label selector of pipeline with ID: %s to %q", pipelineId, req.Selector))
+
+	status, err := f.FrontendPipelineService.SetPipelineSelector(pipelineIdInt, req.Selector)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error setting label selector of pipeline [ID: %s]: %v", pipelineId, err))
+		sendSelectorError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, status)
+}
+
+func (f *FrontendPipelineHandler) SetPipelineDriftPolicy(w http.ResponseWriter, r *http.Request) {
+	pipelineId := mux.Vars(r)["id"]
+	pipelineIdInt, err := strconv.Atoi(pipelineId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid pipeline ID format")
+		return
+	}
+
+	var req struct {
+		DriftPolicy string `json:"drift_policy"`
+	}
+	if err := utils.UnmarshalJSONRequest(r, &req); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to set drift policy of pipeline with ID: %s to %q", pipelineId, req.DriftPolicy))
+
+	if err := f.FrontendPipelineService.SetPipelineDriftPolicy(pipelineIdInt, req.DriftPolicy); err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error setting drift policy of pipeline [ID: %s]: %v", pipelineId, err))
+		sendDriftError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Pipeline drift policy updated successfully"})
+}
+
+func (f *FrontendPipelineHandler) GetPipelineConfigDrift(w http.ResponseWriter, r *http.Request) {
+	pipelineId := mux.Vars(r)["id"]
+	pipelineIdInt, err := strconv.Atoi(pipelineId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid pipeline ID format")
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to get config drift of pipeline with ID: %s", pipelineId))
+
+	drifts, err := f.FrontendPipelineService.GetPipelineConfigDrift(pipelineIdInt)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error getting config drift of pipeline [ID: %s]: %v", pipelineId, err))
+		sendDriftError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, drifts)
+}
+
+func (f *FrontendPipelineHandler) GetConfigDrift(w http.ResponseWriter, r *http.Request) {
+	agentId := mux.Vars(r)["id"]
+	agentIdInt, err := strconv.Atoi(agentId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid agent ID format")
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to get config drift of agent with ID: %s", agentId))
+
+	drift, err := f.FrontendPipelineService.GetConfigDrift(agentIdInt)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error getting config drift of agent [ID: %s]: %v", agentId, err))
+		sendDriftError(w, err)
+		return
+	}
+	if drift == nil {
+		utils.SendJSONError(w, http.StatusNotFound, "agent hasn't reported its config yet")
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, drift)
+}
+
+// GetEffectiveConfig returns the config the agent reports running, with
+// sensitive values redacted.
+func (f *FrontendPipelineHandler) GetEffectiveConfig(w http.ResponseWriter, r *http.Request) {
+	agentId := mux.Vars(r)["id"]
+	agentIdInt, err := strconv.Atoi(agentId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid agent ID format")
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to get effective config of agent with ID: %s", agentId))
+
+	config, err := f.FrontendPipelineService.GetEffectiveConfig(agentIdInt)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error getting effective config of agent [ID: %s]: %v", agentId, err))
+		sendDriftError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, secrets.RedactConfig(config))
+}
+
+// GetConfigApplies returns the agent's recent config applies with the
+// collector's component statuses, newest first.
+func (f *FrontendPipelineHandler) GetConfigApplies(w http.ResponseWriter, r *http.Request) {
+	agentId := mux.Vars(r)["id"]
+	agentIdInt, err := strconv.Atoi(agentId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid agent ID format")
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to get config status of agent with ID: %s", agentId))
+
+	applies, err := f.FrontendPipelineService.GetConfigApplies(agentIdInt)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error getting config status of agent [ID: %s]: %v", agentId, err))
+		sendDriftError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, applies)
+}
+
+// GetAgentComponents returns the collector components built into the agent,
+// with their stability levels.
+func (f *FrontendPipelineHandler) GetAgentComponents(w http.ResponseWriter, r *http.Request) {
+	agentId := mux.Vars(r)["id"]
+	agentIdInt, err := strconv.Atoi(agentId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid agent ID format")
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to get components of agent with ID: %s", agentId))
+
+	components, err := f.FrontendPipelineService.GetAgentComponents(agentIdInt)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error getting components of agent [ID: %s]: %v", agentId, err))
+		sendDriftError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, components)
+}
+
+func (f *FrontendPipelineHandler) GetPipelineRollout(w http.ResponseWriter, r *http.Request) {
+	pipelineId := mux.Vars(r)["id"]
+	pipelineIdInt, err := strconv.Atoi(pipelineId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid pipeline ID format")
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to get config rollout of pipeline with ID: %s", pipelineId))
+
+	rollout, err := f.FrontendPipelineService.GetPipelineRollout(pipelineIdInt)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error getting config rollout of pipeline [ID: %s]: %v", pipelineId, err))
+		sendDriftError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, rollout)
+}
+
+func sendDriftError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, utils.ErrPipelineDoesNotExists), errors.Is(err, utils.ErrAgentDoesNotExists):
+		utils.SendJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, utils.ErrInvalidDriftPolicy):
+		utils.SendJSONError(w, http.StatusBadRequest, err.Error())
+	default:
 		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func sendSelectorError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, utils.ErrPipelineDoesNotExists):
+		utils.SendJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, labelselector.ErrInvalidSelector):
+		utils.SendJSONError(w, http.StatusBadRequest, err.Error())
+	default:
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func sendPromotionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, utils.ErrPipelineDoesNotExists):
+		utils.SendJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, utils.ErrInvalidPromotion):
+		utils.SendJSONError(w, http.StatusBadRequest, err.Error())
+	default:
+		if SendGraphValidationError(w, err) {
+			return
+		}
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// SendGraphValidationError answers with 400 and the per-node errors or lint
+// issues when err is a graph validation or lint failure. It reports whether a response was written.
+func SendGraphValidationError(w http.ResponseWriter, err error) bool {
+	var lintErr *configcompiler.LintError
+	if errors.As(err, &lintErr) {
+		utils.WriteJSONResponse(w, http.StatusBadRequest, map[string]any{
+			"error":  "Pipeline graph failed lint",
+			"issues": lintErr.Issues,
+		})
+		return true
+	}
+
+	var validationErr *models.GraphValidationError
+	if !errors.As(err, &validationErr) {
+		return false
+	}
+
+	utils.WriteJSONResponse(w, http.StatusBadRequest, map[string]any{
+		"error": "Pipeline graph failed validation",
+		"nodes": validationErr.Nodes,
+	})
+	return true
+}
+
+// tapGracePeriod is how long a tap stream waits past the tap's duration for
+// the agents' last samples
+const tapGracePeriod = 10 * time.Second
+
+// TapPipelineNode streams a sample of the telemetry leaving a receiver or
+// processor of the pipeline as server-sent events: a start event with the
+// agents that started the tap, a sample event for each batch an agent sends,
+// and a done event once every agent's tap ended or the duration ran out.
+func (f *FrontendPipelineHandler) TapPipelineNode(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	pipelineIdInt, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid pipeline ID format")
 		return
 	}
-	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Pipeline graph synced successfully"})
+	nodeIdInt, err := strconv.Atoi(vars["node"])
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid node ID format")
+		return
+	}
+
+	query := r.URL.Query()
+	opts := models.TapOptions{Signal: query.Get("signal")}
+	for _, param := range []struct {
+		name  string
+		value *int
+	}{{"limit", &opts.Limit}, {"duration_sec", &opts.DurationSec}} {
+		if raw := query.Get(param.name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n <= 0 {
+				utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("%s must be a positive integer", param.name))
+				return
+			}
+			*param.value = n
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.SendJSONError(w, http.StatusInternalServerError, "Streaming is not supported")
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to tap node %d of pipeline with ID: %d", nodeIdInt, pipelineIdInt))
+
+	tap, err := f.FrontendPipelineService.StartTap(pipelineIdInt, nodeIdInt, opts)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error tapping node %d of pipeline [ID: %d]: %v", nodeIdInt, pipelineIdInt, err))
+		sendTapError(w, err)
+		return
+	}
+	defer tap.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	writeEvent(w, "start", tap)
+	flusher.Flush()
+
+	deadline := time.NewTimer(time.Duration(tap.Options.DurationSec)*time.Second + tapGracePeriod)
+	defer deadline.Stop()
+
+	pending := len(tap.Agents)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-deadline.C:
+			writeEvent(w, "done", map[string]string{"reason": "timeout"})
+			flusher.Flush()
+			return
+		case sample := <-tap.Samples:
+			writeEvent(w, "sample", sample)
+			if sample.Done {
+				pending--
+			}
+			if pending == 0 {
+				writeEvent(w, "done", map[string]string{"reason": "complete"})
+				flusher.Flush()
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// ReceiveTapSample takes a sample an agent caught for an open tap. A closed
+// tap answers 404.
+func (f *FrontendPipelineHandler) ReceiveTapSample(w http.ResponseWriter, r *http.Request) {
+	tapId := mux.Vars(r)["id"]
+
+	var sample models.TapSample
+	if err := utils.UnmarshalJSONRequest(r, &sample); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+		return
+	}
+
+	if err := f.FrontendPipelineService.PublishTapSample(tapId, sample); err != nil {
+		sendTapError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Sample received"})
+}
+
+// writeEvent writes a server-sent event with data as JSON.
+func writeEvent(w http.ResponseWriter, event string, data any) {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error encoding %s event: %v", event, err))
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, jsonData)
+}
+
+func sendTapError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, utils.ErrPipelineDoesNotExists), errors.Is(err, utils.ErrNodeDoesNotExists), errors.Is(err, utils.ErrTapDoesNotExists):
+		utils.SendJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, utils.ErrInvalidTap):
+		utils.SendJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, utils.ErrTapUnavailable):
+		utils.SendJSONError(w, http.StatusConflict, err.Error())
+	default:
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+	}
 }
@@ -3,12 +3,17 @@ package frontendpipeline_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	frontendpipeline "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/pipeline"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/configexport"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/labelselector"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -46,22 +51,191 @@ func (m *MockService) DetachAgentFromPipeline(pipelineId, agentId int) error {
 	args := m.Called(pipelineId, agentId)
 	return args.Error(0)
 }
-func (m *MockService) AttachAgentToPipeline(pipelineId, agentId int) error {
+func (m *MockService) AttachAgentToPipeline(pipelineId, agentId int) ([]string, error) {
 	args := m.Called(pipelineId, agentId)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
 }
 func (m *MockService) GetPipelineGraph(id int) (*models.PipelineGraph, error) {
 	args := m.Called(id)
 	return args.Get(0).(*models.PipelineGraph), args.Error(1)
 }
-func (m *MockService) SyncPipelineGraph(id int, graph models.PipelineGraph) error {
+func (m *MockService) SyncPipelineGraph(id int, graph models.PipelineGraph) ([]string, error) {
 	args := m.Called(id, graph)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+func (m *MockService) GetPipelineTelemetry(id int) (*models.PipelineTelemetry, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.PipelineTelemetry), args.Error(1)
+}
+func (m *MockService) UpdatePipelineTelemetry(id int, telemetry models.PipelineTelemetry) error {
+	args := m.Called(id, telemetry)
+	return args.Error(0)
+}
+func (m *MockService) LintPipeline(id int) (*models.LintReport, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LintReport), args.Error(1)
+}
+func (m *MockService) ImportPipeline(collectorConfig map[string]any) (*models.ImportPipelineResponse, error) {
+	args := m.Called(collectorConfig)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImportPipelineResponse), args.Error(1)
+}
+func (m *MockService) ExportPipeline(id int, format string, opts configexport.Options) ([]byte, error) {
+	args := m.Called(id, format, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+func (m *MockService) SetPipelineEnvironment(id int, environment string) error {
+	args := m.Called(id, environment)
 	return args.Error(0)
 }
+func (m *MockService) ClonePipeline(id int, req models.ClonePipelineRequest) (string, error) {
+	args := m.Called(id, req)
+	return args.String(0), args.Error(1)
+}
+func (m *MockService) PreviewPromotion(id int, to string) (*models.PipelinePromotion, error) {
+	args := m.Called(id, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PipelinePromotion), args.Error(1)
+}
+func (m *MockService) PromotePipeline(id int, req models.PromotePipelineRequest) (*models.PipelinePromotion, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PipelinePromotion), args.Error(1)
+}
+func (m *MockService) SetPipelineSelector(id int, selector string) (*models.PipelineSelectorStatus, error) {
+	args := m.Called(id, selector)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PipelineSelectorStatus), args.Error(1)
+}
+func (m *MockService) GetSelectorStatus(id int) (*models.PipelineSelectorStatus, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PipelineSelectorStatus), args.Error(1)
+}
+func (m *MockService) AssignAgentBySelector(agentId int) (*int, error) {
+	args := m.Called(agentId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*int), args.Error(1)
+}
+func (m *MockService) GetPipelineConfig(id int) (map[string]any, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]any), args.Error(1)
+}
 func (m *MockService) SyncConfig(agentId string) error {
 	args := m.Called(agentId)
 	return args.Error(0)
 }
+func (m *MockService) RedeliverConfig(agentId string) error {
+	args := m.Called(agentId)
+	return args.Error(0)
+}
+func (m *MockService) RetryConfigDeliveries() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockService) SetPipelineDriftPolicy(pipelineId int, policy string) error {
+	args := m.Called(pipelineId, policy)
+	return args.Error(0)
+}
+
+func (m *MockService) ReportEffectiveConfig(agentId string, effectiveHash string) (*models.ConfigDrift, error) {
+	args := m.Called(agentId, effectiveHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigDrift), args.Error(1)
+}
+
+func (m *MockService) GetConfigDrift(agentId int) (*models.ConfigDrift, error) {
+	args := m.Called(agentId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigDrift), args.Error(1)
+}
+
+func (m *MockService) GetPipelineConfigDrift(pipelineId int) ([]models.ConfigDrift, error) {
+	args := m.Called(pipelineId)
+	return args.Get(0).([]models.ConfigDrift), args.Error(1)
+}
+
+func (m *MockService) GetEffectiveConfig(agentId int) (map[string]any, error) {
+	args := m.Called(agentId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]any), args.Error(1)
+}
+
+func (m *MockService) RecordConfigApply(agentId string, req models.ConfigApplyRequest) error {
+	args := m.Called(agentId, req)
+	return args.Error(0)
+}
+
+func (m *MockService) GetConfigApplies(agentId int) ([]models.ConfigApply, error) {
+	args := m.Called(agentId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ConfigApply), args.Error(1)
+}
+
+func (m *MockService) GetPipelineRollout(pipelineId int) (*models.ConfigRollout, error) {
+	args := m.Called(pipelineId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigRollout), args.Error(1)
+}
+
+func (m *MockService) GetAgentComponents(agentId int) ([]models.AgentComponent, error) {
+	args := m.Called(agentId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AgentComponent), args.Error(1)
+}
+
+func (m *MockService) StartTap(pipelineId int, nodeId int, opts models.TapOptions) (*frontendpipeline.NodeTap, error) {
+	args := m.Called(pipelineId, nodeId, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*frontendpipeline.NodeTap), args.Error(1)
+}
+
+func (m *MockService) PublishTapSample(tapId string, sample models.TapSample) error {
+	args := m.Called(tapId, sample)
+	return args.Error(0)
+}
 
 func TestGetAllPipelinesHandler(t *testing.T) {
 	mockSvc := new(MockService)
@@ -209,7 +383,7 @@ func TestAttachAgentToPipelineHandler(t *testing.T) {
 	mockSvc := new(MockService)
 	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
 
-	mockSvc.On("AttachAgentToPipeline", 1, 2).Return(nil)
+	mockSvc.On("AttachAgentToPipeline", 1, 2).Return([]string{"job-1"}, nil)
 
 	req := httptest.NewRequest("POST", "/pipelines/1/agents/2", nil)
 	req = mux.SetURLVars(req, map[string]string{"id": "1", "agent_id": "2"})
@@ -217,6 +391,26 @@ func TestAttachAgentToPipelineHandler(t *testing.T) {
 
 	handler.AttachAgentToPipeline(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"job_ids":["job-1"]`)
+}
+
+func TestAttachAgentToPipelineHandler_UnsupportedComponent(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
+
+	mockSvc.On("AttachAgentToPipeline", 1, 2).Return(nil, &models.GraphValidationError{
+		Nodes: []models.NodeValidationError{{ComponentID: 1, Name: "Kafka", ComponentName: "kafka_receiver", Errors: []models.FieldError{
+			{Reason: `component "kafka_receiver" isn't built into agent 2`},
+		}}},
+	})
+
+	req := httptest.NewRequest("POST", "/pipelines/1/agents/2", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1", "agent_id": "2"})
+	w := httptest.NewRecorder()
+
+	handler.AttachAgentToPipeline(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "isn't built into agent 2")
 }
 
 func TestGetPipelineGraphHandler(t *testing.T) {
@@ -233,12 +427,112 @@ func TestGetPipelineGraphHandler(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 }
 
+func TestGetPipelineGraphHandler_RedactsSecrets(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
+
+	mockSvc.On("GetPipelineGraph", 1).Return(&models.PipelineGraph{
+		Nodes: []models.PipelineNodes{{ComponentID: 1, Name: "Kafka", ComponentName: "kafka_exporter", Config: map[string]any{
+			"auth": map[string]any{"sasl": map[string]any{"username": "ingest", "password": "hunter2"}},
+		}}},
+	}, nil)
+
+	req := httptest.NewRequest("GET", "/pipelines/1/graph", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+
+	handler.GetPipelineGraph(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "hunter2")
+	assert.Contains(t, w.Body.String(), "ingest")
+}
+
 func TestSyncPipelineGraphHandler(t *testing.T) {
 	mockSvc := new(MockService)
 	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
 
 	graph := models.PipelineGraph{}
-	mockSvc.On("SyncPipelineGraph", 1, graph).Return(nil)
+	mockSvc.On("SyncPipelineGraph", 1, graph).Return([]string{"job-1", "job-2"}, nil)
+
+	body, _ := json.Marshal(graph)
+	req := httptest.NewRequest("POST", "/pipelines/1/graph", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+
+	handler.SyncPipelineGraph(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"job_ids":["job-1","job-2"]`)
+}
+
+func TestGetPipelineTelemetryHandler(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
+
+	mockSvc.On("GetPipelineTelemetry", 1).Return(&models.PipelineTelemetry{
+		Metrics: models.TelemetryMetrics{Level: "detailed", Port: 8888},
+	}, nil)
+
+	req := httptest.NewRequest("GET", "/pipelines/1/telemetry", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+
+	handler.GetPipelineTelemetry(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"port":8888`)
+}
+
+func TestUpdatePipelineTelemetryHandler(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
+
+	telemetry := models.PipelineTelemetry{
+		Logs:    models.TelemetryLogs{Level: "debug"},
+		Metrics: models.TelemetryMetrics{Level: "normal", Host: "0.0.0.0", Port: 9999},
+	}
+	mockSvc.On("UpdatePipelineTelemetry", 1, telemetry).Return(nil)
+
+	body, _ := json.Marshal(telemetry)
+	req := httptest.NewRequest("PUT", "/pipelines/1/telemetry", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+
+	handler.UpdatePipelineTelemetry(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestUpdatePipelineTelemetryHandler_InvalidSettings(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
+
+	body, _ := json.Marshal(models.PipelineTelemetry{
+		Metrics: models.TelemetryMetrics{Level: "none"},
+	})
+	req := httptest.NewRequest("PUT", "/pipelines/1/telemetry", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+
+	handler.UpdatePipelineTelemetry(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockSvc.AssertNotCalled(t, "UpdatePipelineTelemetry", mock.Anything, mock.Anything)
+}
+
+func TestSyncPipelineGraphHandler_ValidationError(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
+
+	graph := models.PipelineGraph{}
+	mockSvc.On("SyncPipelineGraph", 1, graph).Return(nil, &models.GraphValidationError{
+		Nodes: []models.NodeValidationError{{
+			ComponentID:   1,
+			Name:          "Batch",
+			ComponentName: "batch_processor",
+			Errors:        []models.FieldError{{Pointer: "/timeout", Reason: "required property is missing"}},
+		}},
+	})
 
 	body, _ := json.Marshal(graph)
 	req := httptest.NewRequest("POST", "/pipelines/1/graph", bytes.NewReader(body))
@@ -247,5 +541,273 @@ func TestSyncPipelineGraphHandler(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	handler.SyncPipelineGraph(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"pointer":"/timeout"`)
+}
+
+func TestLintPipelineHandler(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
+
+	mockSvc.On("LintPipeline", 1).Return(&models.LintReport{
+		Issues: []models.LintIssue{{
+			Rule:         "batch-last",
+			Severity:     models.LintSeverityWarning,
+			Message:      "batch processor batch should be the last processor in its pipeline",
+			ComponentIDs: []int{2},
+		}},
+		Warnings: 1,
+	}, nil)
+
+	req := httptest.NewRequest("GET", "/pipelines/1/lint", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+
+	handler.LintPipeline(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"rule":"batch-last"`)
+	assert.Contains(t, w.Body.String(), `"warnings":1`)
+}
+
+func TestImportPipelineHandler(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
+
+	collectorConfig := map[string]any{
+		"receivers": map[string]any{"otlp": nil},
+		"exporters": map[string]any{"debug": nil},
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"traces": map[string]any{"receivers": []any{"otlp"}, "exporters": []any{"debug"}},
+			},
+		},
+	}
+	mockSvc.On("ImportPipeline", collectorConfig).Return(&models.ImportPipelineResponse{}, nil)
+
+	body := `
+receivers:
+  otlp:
+exporters:
+  debug:
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [debug]
+`
+	req := httptest.NewRequest("POST", "/pipelines/import", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.ImportPipeline(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestImportPipelineHandler_InvalidYAML(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
+
+	req := httptest.NewRequest("POST", "/pipelines/import", strings.NewReader("receivers: [otlp"))
+	w := httptest.NewRecorder()
+
+	handler.ImportPipeline(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockSvc.AssertNotCalled(t, "ImportPipeline", mock.Anything)
+}
+
+func TestExportPipelineHandler(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
+
+	opts := configexport.Options{Namespace: "monitoring"}
+	mockSvc.On("ExportPipeline", 1, "kubernetes", opts).Return([]byte("kind: ConfigMap\n"), nil)
+
+	req := httptest.NewRequest("GET", "/pipelines/1/export?format=kubernetes&namespace=monitoring", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+
+	handler.ExportPipeline(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/yaml", w.Header().Get("Content-Type"))
+	assert.Equal(t, "kind: ConfigMap\n", w.Body.String())
+}
+
+func TestExportPipelineHandler_UnsupportedFormat(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
+
+	req := httptest.NewRequest("GET", "/pipelines/1/export?format=toml", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+
+	handler.ExportPipeline(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockSvc.AssertNotCalled(t, "ExportPipeline", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClonePipelineHandler(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
+
+	mockSvc.On("ClonePipeline", 1, models.ClonePipelineRequest{Name: "edge-copy", Environment: "dev"}).Return("2", nil)
+
+	req := httptest.NewRequest("POST", "/pipelines/1/clone", strings.NewReader(`{"name": "edge-copy", "environment": "dev"}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+
+	handler.ClonePipeline(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"2"`)
+}
+
+func TestPromotePipelineHandler_InvalidPromotion(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
+
+	mockSvc.On("PromotePipeline", 1, models.PromotePipelineRequest{To: "dev"}).
+		Return(nil, fmt.Errorf("%w: cannot promote from prod to dev", utils.ErrInvalidPromotion))
+
+	req := httptest.NewRequest("POST", "/pipelines/1/promote", strings.NewReader(`{"to": "dev"}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+
+	handler.PromotePipeline(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSetPipelineSelectorHandler_InvalidSelector(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
+
+	mockSvc.On("SetPipelineSelector", 1, "env=").
+		Return(nil, fmt.Errorf("%w: bad value in \"env=\"", labelselector.ErrInvalidSelector))
+
+	req := httptest.NewRequest("PUT", "/pipelines/1/selector", strings.NewReader(`{"selector": "env="}`))
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+
+	handler.SetPipelineSelector(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetPipelineSelectorHandler(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
+
+	mockSvc.On("GetSelectorStatus", 1).Return(&models.PipelineSelectorStatus{
+		Selector: "env=prod",
+		Agents:   []models.SelectorMatch{{AgentID: 2, Matched: true}},
+	}, nil)
+
+	req := httptest.NewRequest("GET", "/pipelines/1/selector", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+
+	handler.GetPipelineSelector(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"selector":"env=prod"`)
+}
+
+func TestGetAgentComponentsHandler(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
+
+	mockSvc.On("GetAgentComponents", 2).Return([]models.AgentComponent{
+		{Kind: "receiver", Type: "otlp", Name: "otlp_receiver", Stability: map[string]string{"logs": "stable"}},
+	}, nil)
+	mockSvc.On("GetAgentComponents", 404).Return(nil, utils.ErrAgentDoesNotExists)
+
+	req := httptest.NewRequest("GET", "/agents/2/components", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "2"})
+	w := httptest.NewRecorder()
+	handler.GetAgentComponents(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"otlp_receiver"`)
+
+	req = httptest.NewRequest("GET", "/agents/404/components", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "404"})
+	w = httptest.NewRecorder()
+	handler.GetAgentComponents(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestTapPipelineNodeHandler_Streams(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
+
+	samples := make(chan models.TapSample, 2)
+	samples <- models.TapSample{TapID: "ab12", AgentID: 2, Signal: "logs", Count: 1, Data: json.RawMessage(`{"resourceLogs":[]}`)}
+	samples <- models.TapSample{TapID: "ab12", AgentID: 2, Signal: "logs", Count: 1, Done: true, Reason: "limit"}
+	closed := false
+	tap := &frontendpipeline.NodeTap{
+		ID:      "ab12",
+		Options: models.TapOptions{Signal: "logs", Limit: 1, DurationSec: 60},
+		Agents:  []int64{2},
+		Samples: samples,
+		Close:   func() { closed = true },
+	}
+	mockSvc.On("StartTap", 1, 3, models.TapOptions{Signal: "logs", Limit: 1}).Return(tap, nil)
+
+	req := httptest.NewRequest("GET", "/pipelines/1/nodes/3/tap?signal=logs&limit=1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1", "node": "3"})
+	w := httptest.NewRecorder()
+
+	handler.TapPipelineNode(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	body := w.Body.String()
+	assert.Contains(t, body, "event: start\ndata: {\"tap_id\":\"ab12\"")
+	assert.Contains(t, body, "event: sample\ndata: {\"tap_id\":\"ab12\",\"agent_id\":2,\"signal\":\"logs\",\"count\":1,\"data\":{\"resourceLogs\":[]}")
+	assert.True(t, strings.HasSuffix(body, "event: done\ndata: {\"reason\":\"complete\"}\n\n"))
+	assert.True(t, closed)
+}
+
+func TestTapPipelineNodeHandler_Errors(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
+
+	mockSvc.On("StartTap", 1, 4, models.TapOptions{}).Return(nil, fmt.Errorf("%w: only receivers and processors can be tapped", utils.ErrInvalidTap))
+	mockSvc.On("StartTap", 1, 9, models.TapOptions{}).Return(nil, utils.ErrNodeDoesNotExists)
+	mockSvc.On("StartTap", 2, 3, models.TapOptions{}).Return(nil, utils.ErrTapUnavailable)
+
+	for _, tt := range []struct {
+		url        string
+		vars       map[string]string
+		expectCode int
+	}{
+		{"/pipelines/1/nodes/4/tap", map[string]string{"id": "1", "node": "4"}, http.StatusBadRequest},
+		{"/pipelines/1/nodes/9/tap", map[string]string{"id": "1", "node": "9"}, http.StatusNotFound},
+		{"/pipelines/2/nodes/3/tap", map[string]string{"id": "2", "node": "3"}, http.StatusConflict},
+		{"/pipelines/1/nodes/x/tap", map[string]string{"id": "1", "node": "x"}, http.StatusBadRequest},
+		{"/pipelines/1/nodes/3/tap?limit=0", map[string]string{"id": "1", "node": "3"}, http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest("GET", tt.url, nil)
+		req = mux.SetURLVars(req, tt.vars)
+		w := httptest.NewRecorder()
+		handler.TapPipelineNode(w, req)
+		assert.Equal(t, tt.expectCode, w.Code, tt.url)
+	}
+}
+
+func TestReceiveTapSampleHandler(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendpipeline.NewFrontendPipelineHandler(mockSvc)
+
+	sample := models.TapSample{AgentID: 2, Signal: "logs", Count: 3, Done: true, Reason: "limit"}
+	mockSvc.On("PublishTapSample", "ab12", sample).Return(nil)
+	mockSvc.On("PublishTapSample", "gone", sample).Return(utils.ErrTapDoesNotExists)
+
+	jsonData, _ := json.Marshal(sample)
+	req := httptest.NewRequest("POST", "/taps/ab12/samples", bytes.NewReader(jsonData))
+	req = mux.SetURLVars(req, map[string]string{"id": "ab12"})
+	w := httptest.NewRecorder()
+	handler.ReceiveTapSample(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest("POST", "/taps/gone/samples", bytes.NewReader(jsonData))
+	req = mux.SetURLVars(req, map[string]string{"id": "gone"})
+	w = httptest.NewRecorder()
+	handler.ReceiveTapSample(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockSvc.AssertExpectations(t)
 }
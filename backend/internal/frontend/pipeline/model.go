@@ -1,5 +1,7 @@
 package frontendpipeline
 
+import "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+
 type Pipeline struct {
 	ID            int    `json:"id"`
 	Name          string `json:"name"`
@@ -10,11 +12,14 @@ type Pipeline struct {
 }
 
 type PipelineInfo struct {
-	ID        int    `json:"id"`
-	Name      string `json:"name"`
-	CreatedBy string `json:"created_by"`
-	CreatedAt int    `json:"created_at"`
-	UpdatedAt int    `json:"updated_at"`
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	Environment   string `json:"environment"`
+	LabelSelector string `json:"label_selector"`
+	DriftPolicy   string `json:"drift_policy"`
+	CreatedBy     string `json:"created_by"`
+	CreatedAt     int    `json:"created_at"`
+	UpdatedAt     int    `json:"updated_at"`
 }
 
 type PipelineInfoWithAgent struct {
@@ -32,3 +37,14 @@ type PipelineInfoWithAgent struct {
 	Labels       map[string]string `json:"labels"`
 	Config       map[string]any    `json:"config"`
 }
+
+// NodeTap is a tap open on a pipeline node. Samples of every agent in
+// Agents arrive on Samples until Close.
+type NodeTap struct {
+	ID      string                  `json:"tap_id"`
+	Options models.TapOptions       `json:"options"`
+	Agents  []int64                 `json:"agents"`           // Agents that started the tap
+	Failed  map[int64]string        `json:"failed,omitempty"` // Agents that couldn't, with why
+	Samples <-chan models.TapSample `json:"-"`
+	Close   func()                  `json:"-"`
+}
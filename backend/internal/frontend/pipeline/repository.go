@@ -72,8 +72,8 @@ func (f *FrontendPipelineRepository) GetPipelineInfo(pipelineId int) (*PipelineI
 	pipelineInfo := &PipelineInfo{}
 
 	// Query the database for the pipeline info
-	query := `SELECT pipeline_id, name, created_by, created_at, updated_at FROM pipelines WHERE pipeline_id = ?`
-	err := f.db.QueryRow(query, pipelineId).Scan(&pipelineInfo.ID, &pipelineInfo.Name, &pipelineInfo.CreatedBy, &pipelineInfo.CreatedAt, &pipelineInfo.UpdatedAt)
+	query := `SELECT pipeline_id, name, COALESCE(environment, ''), COALESCE(label_selector, ''), COALESCE(drift_policy, ?), created_by, created_at, updated_at FROM pipelines WHERE pipeline_id = ?`
+	err := f.db.QueryRow(query, models.DriftPolicyAutoRemediate, pipelineId).Scan(&pipelineInfo.ID, &pipelineInfo.Name, &pipelineInfo.Environment, &pipelineInfo.LabelSelector, &pipelineInfo.DriftPolicy, &pipelineInfo.CreatedBy, &pipelineInfo.CreatedAt, &pipelineInfo.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -161,9 +161,10 @@ func (f *FrontendPipelineRepository) CreatePipeline(createPipelineRequest models
 
 	// Insert the pipeline
 	res, err := tx.Exec(
-		"INSERT INTO pipelines (name, created_by) VALUES (?, ?)",
+		"INSERT INTO pipelines (name, created_by, environment) VALUES (?, ?, NULLIF(?, ''))",
 		createPipelineRequest.Name,
 		createPipelineRequest.CreatedBy,
+		createPipelineRequest.Environment,
 	)
 	if err != nil {
 		_ = tx.Rollback()
@@ -271,12 +272,45 @@ func (f *FrontendPipelineRepository) GetPipelineGraph(pipelineId int) (*models.P
 		return nil, fmt.Errorf("failed to get pipeline dependencies: %w", err)
 	}
 
+	telemetry, err := f.getPipelineTelemetry(f.db, pipelineId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pipeline telemetry: %w", err)
+	}
+
 	return &models.PipelineGraph{
-		Nodes: nodes,
-		Edges: edges,
+		Nodes:     nodes,
+		Edges:     edges,
+		Telemetry: telemetry,
 	}, nil
 }
 
+// queryRower is satisfied by both *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// getPipelineTelemetry returns the stored telemetry settings, or nil when the
+// pipeline uses the defaults.
+func (f *FrontendPipelineRepository) getPipelineTelemetry(q queryRower, pipelineId int) (*models.PipelineTelemetry, error) {
+	var telemetryJSON sql.NullString
+	err := q.QueryRow(`SELECT telemetry_json FROM pipelines WHERE pipeline_id = ?`, pipelineId).Scan(&telemetryJSON)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !telemetryJSON.Valid || telemetryJSON.String == "" {
+		return nil, nil
+	}
+
+	var telemetry models.PipelineTelemetry
+	if err := json.Unmarshal([]byte(telemetryJSON.String), &telemetry); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal telemetry settings: %w", err)
+	}
+	return &telemetry, nil
+}
+
 func (f *FrontendPipelineRepository) getPipelineComponents(pipelineId int) ([]models.PipelineNodes, error) {
 	rows, err := f.db.Query(`
 		SELECT component_id, name, component_role, component_name, config, supported_signals
@@ -370,8 +404,8 @@ func (f *FrontendPipelineRepository) SyncPipelineGraph(tx *sql.Tx, pipelineID in
 
 	// Prepare statement for component insertion
 	insertComponentStmt, err := tx.Prepare(`
-        INSERT INTO pipeline_components (pipeline_id, component_role, component_name, name, config, supported_signals)
-        VALUES (?, ?, ?, ?, ?,?)
+        INSERT INTO pipeline_components (pipeline_id, component_role, component_name, name, config, supported_signals, schema_version)
+        VALUES (?, ?, ?, ?, ?, ?, COALESCE((SELECT version FROM component_schemas WHERE name = ?), 1))
     `)
 	if err != nil {
 		if shouldCommit {
@@ -404,7 +438,7 @@ func (f *FrontendPipelineRepository) SyncPipelineGraph(tx *sql.Tx, pipelineID in
 		}
 
 		// Insert the component
-		res, err := insertComponentStmt.Exec(pipelineID, comp.ComponentRole, comp.ComponentName, comp.Name, string(configBytes), supportedSignals)
+		res, err := insertComponentStmt.Exec(pipelineID, comp.ComponentRole, comp.ComponentName, comp.Name, string(configBytes), supportedSignals, comp.ComponentName)
 		if err != nil {
 			if shouldCommit {
 				_ = tx.Rollback()
@@ -459,6 +493,28 @@ func (f *FrontendPipelineRepository) SyncPipelineGraph(tx *sql.Tx, pipelineID in
 		}
 	}
 
+	// Graphs synced without telemetry settings keep whatever the pipeline already has
+	var telemetryJSON sql.NullString
+	if graph.Telemetry == nil {
+		graph.Telemetry, err = f.getPipelineTelemetry(tx, pipelineID)
+		if err != nil {
+			if shouldCommit {
+				_ = tx.Rollback()
+			}
+			return fmt.Errorf("failed to get pipeline telemetry: %w", err)
+		}
+	}
+	if graph.Telemetry != nil {
+		telemetryBytes, err := json.Marshal(graph.Telemetry)
+		if err != nil {
+			if shouldCommit {
+				_ = tx.Rollback()
+			}
+			return fmt.Errorf("failed to marshal telemetry settings: %w", err)
+		}
+		telemetryJSON = sql.NullString{String: string(telemetryBytes), Valid: true}
+	}
+
 	jsonConfig, err := configcompiler.CompileGraphToJSON(graph)
 	if err != nil {
 		if shouldCommit {
@@ -478,9 +534,9 @@ func (f *FrontendPipelineRepository) SyncPipelineGraph(tx *sql.Tx, pipelineID in
 
 	_, err = tx.Exec(`
 		UPDATE pipelines
-		SET updated_at = ?, config_json = ?
+		SET updated_at = ?, config_json = ?, telemetry_json = ?
 		WHERE pipeline_id = ?
-	`, updatedAt, configBytes, pipelineID)
+	`, updatedAt, configBytes, telemetryJSON, pipelineID)
 	if err != nil {
 		if shouldCommit {
 			_ = tx.Rollback()
@@ -535,3 +591,464 @@ func (f *FrontendPipelineRepository) GetAgentPipelineId(agentId string) (*int, e
 	}
 	return &pipelineId, nil
 }
+
+// GetComponentSchema returns the raw JSON Schema registered for a component.
+func (f *FrontendPipelineRepository) GetComponentSchema(componentName string) ([]byte, error) {
+	var rawSchema string
+	err := f.db.QueryRow("SELECT schema_json FROM component_schemas WHERE name = ?", componentName).Scan(&rawSchema)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(rawSchema), nil
+}
+
+// GetComponentCatalog returns every component in component_schemas keyed by
+// its catalog name.
+func (f *FrontendPipelineRepository) GetComponentCatalog() (map[string]models.CatalogComponent, error) {
+	rows, err := f.db.Query("SELECT name, type, supported_signals FROM component_schemas")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	catalog := make(map[string]models.CatalogComponent)
+	for rows.Next() {
+		var name, role, supportedSignalsRaw string
+		if err := rows.Scan(&name, &role, &supportedSignalsRaw); err != nil {
+			return nil, err
+		}
+
+		supportedSignals := strings.Split(supportedSignalsRaw, ",")
+		for i := range supportedSignals {
+			supportedSignals[i] = strings.TrimSpace(supportedSignals[i])
+		}
+		catalog[name] = models.CatalogComponent{Name: name, Role: role, SupportedSignals: supportedSignals}
+	}
+
+	return catalog, rows.Err()
+}
+
+// SetPipelineEnvironment sets or, with an empty string, clears the pipeline's
+// environment label.
+func (f *FrontendPipelineRepository) SetPipelineEnvironment(pipelineId int, environment string) error {
+	_, err := f.db.Exec("UPDATE pipelines SET environment = NULLIF(?, ''), updated_at = ? WHERE pipeline_id = ?", environment, utils.GetCurrentTime(), pipelineId)
+	if err != nil {
+		return fmt.Errorf("failed to set pipeline environment: %w", err)
+	}
+	return nil
+}
+
+// GetPipelineIdByEnvironment finds the pipeline with the given name in an
+// environment. It returns nil when there is none.
+func (f *FrontendPipelineRepository) GetPipelineIdByEnvironment(name string, environment string) (*int, error) {
+	var pipelineId int
+	err := f.db.QueryRow("SELECT pipeline_id FROM pipelines WHERE name = ? AND environment = ? ORDER BY pipeline_id LIMIT 1", name, environment).Scan(&pipelineId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pipeline %s in %s: %w", name, environment, err)
+	}
+	return &pipelineId, nil
+}
+
+// SetPipelineSelector sets or, with an empty string, clears the pipeline's
+// agent label selector.
+func (f *FrontendPipelineRepository) SetPipelineSelector(pipelineId int, selector string) error {
+	_, err := f.db.Exec("UPDATE pipelines SET label_selector = NULLIF(?, ''), updated_at = ? WHERE pipeline_id = ?", selector, utils.GetCurrentTime(), pipelineId)
+	if err != nil {
+		return fmt.Errorf("failed to set pipeline label selector: %w", err)
+	}
+	return nil
+}
+
+// SetPipelineDriftPolicy sets what happens when an agent of the pipeline runs
+// a config that differs from the pipeline's.
+func (f *FrontendPipelineRepository) SetPipelineDriftPolicy(pipelineId int, policy string) error {
+	_, err := f.db.Exec("UPDATE pipelines SET drift_policy = ?, updated_at = ? WHERE pipeline_id = ?", policy, utils.GetCurrentTime(), pipelineId)
+	if err != nil {
+		return fmt.Errorf("failed to set pipeline drift policy: %w", err)
+	}
+	return nil
+}
+
+// GetPipelineSelectors returns every pipeline that has a label selector,
+// oldest first.
+func (f *FrontendPipelineRepository) GetPipelineSelectors() ([]models.PipelineSelector, error) {
+	rows, err := f.db.Query("SELECT pipeline_id, name, label_selector FROM pipelines WHERE label_selector IS NOT NULL AND label_selector != '' ORDER BY pipeline_id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pipeline label selectors: %w", err)
+	}
+	defer rows.Close()
+
+	var selectors []models.PipelineSelector
+	for rows.Next() {
+		var selector models.PipelineSelector
+		if err := rows.Scan(&selector.PipelineID, &selector.Name, &selector.Selector); err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+	}
+	return selectors, rows.Err()
+}
+
+// GetAgentsWithLabels returns every agent with its labels. With agentId > 0
+// only that agent is returned.
+func (f *FrontendPipelineRepository) GetAgentsWithLabels(agentId int) ([]models.AgentLabels, error) {
+	query := "SELECT id, name, pipeline_id FROM agents"
+	var args []any
+	if agentId > 0 {
+		query += " WHERE id = ?"
+		args = append(args, agentId)
+	}
+	rows, err := f.db.Query(query+" ORDER BY id", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agents: %w", err)
+	}
+
+	var agents []models.AgentLabels
+	index := make(map[int64]int)
+	for rows.Next() {
+		var agent models.AgentLabels
+		var pipelineId sql.NullInt64
+		if err := rows.Scan(&agent.ID, &agent.Name, &pipelineId); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if pipelineId.Valid {
+			id := int(pipelineId.Int64)
+			agent.PipelineID = &id
+		}
+		agent.Labels = map[string]string{}
+		index[agent.ID] = len(agents)
+		agents = append(agents, agent)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	labelRows, err := f.db.Query("SELECT agent_id, key, value FROM agents_labels")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agent labels: %w", err)
+	}
+	defer labelRows.Close()
+	for labelRows.Next() {
+		var id int64
+		var key, value string
+		if err := labelRows.Scan(&id, &key, &value); err != nil {
+			return nil, err
+		}
+		if i, ok := index[id]; ok {
+			agents[i].Labels[key] = value
+		}
+	}
+	return agents, labelRows.Err()
+}
+
+// GetConfigDelivery returns the agent's config delivery, or nil when nothing
+// was ever sent to it.
+func (f *FrontendPipelineRepository) GetConfigDelivery(agentId int) (*models.ConfigDelivery, error) {
+	rows, err := f.queryConfigDeliveries("WHERE agent_id = ?", agentId)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0], nil
+}
+
+// GetDueConfigDeliveries returns the pending deliveries whose next attempt is
+// due at now.
+func (f *FrontendPipelineRepository) GetDueConfigDeliveries(now int64) ([]models.ConfigDelivery, error) {
+	return f.queryConfigDeliveries("WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at", models.ConfigDeliveryPending, now)
+}
+
+// SaveConfigDelivery inserts or replaces the agent's config delivery.
+func (f *FrontendPipelineRepository) SaveConfigDelivery(delivery models.ConfigDelivery) error {
+	var nextAttemptAt, deliveredAt any
+	if delivery.NextAttemptAt != 0 {
+		nextAttemptAt = delivery.NextAttemptAt
+	}
+	if delivery.DeliveredAt != 0 {
+		deliveredAt = delivery.DeliveredAt
+	}
+
+	_, err := f.db.Exec(`
+		INSERT INTO config_deliveries
+		(agent_id, pipeline_id, config_version, status, attempts, last_error, next_attempt_at, updated_at, delivered_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(agent_id) DO UPDATE SET
+			pipeline_id = EXCLUDED.pipeline_id,
+			config_version = EXCLUDED.config_version,
+			status = EXCLUDED.status,
+			attempts = EXCLUDED.attempts,
+			last_error = EXCLUDED.last_error,
+			next_attempt_at = EXCLUDED.next_attempt_at,
+			updated_at = EXCLUDED.updated_at,
+			delivered_at = EXCLUDED.delivered_at
+	`, delivery.AgentID, delivery.PipelineID, delivery.ConfigVersion, delivery.Status, delivery.Attempts, delivery.LastError,
+		nextAttemptAt, delivery.UpdatedAt, deliveredAt)
+	if err != nil {
+		return fmt.Errorf("failed to save config delivery: %w", err)
+	}
+	return nil
+}
+
+// DeleteConfigDelivery drops the agent's config delivery, e.g. once it left
+// its pipeline.
+func (f *FrontendPipelineRepository) DeleteConfigDelivery(agentId int) error {
+	if _, err := f.db.Exec("DELETE FROM config_deliveries WHERE agent_id = ?", agentId); err != nil {
+		return fmt.Errorf("failed to delete config delivery: %w", err)
+	}
+	return nil
+}
+
+func (f *FrontendPipelineRepository) queryConfigDeliveries(where string, args ...any) ([]models.ConfigDelivery, error) {
+	rows, err := f.db.Query(`
+		SELECT agent_id, pipeline_id, config_version, status, attempts, last_error,
+			COALESCE(next_attempt_at, 0), updated_at, COALESCE(delivered_at, 0)
+		FROM config_deliveries `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query config deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.ConfigDelivery
+	for rows.Next() {
+		var delivery models.ConfigDelivery
+		if err := rows.Scan(&delivery.AgentID, &delivery.PipelineID, &delivery.ConfigVersion, &delivery.Status, &delivery.Attempts,
+			&delivery.LastError, &delivery.NextAttemptAt, &delivery.UpdatedAt, &delivery.DeliveredAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// GetConfigDrift returns the agent's last drift check, or nil when it never
+// reported its config or has left the pipeline it was checked against.
+func (f *FrontendPipelineRepository) GetConfigDrift(agentId int) (*models.ConfigDrift, error) {
+	drifts, err := f.queryConfigDrift("WHERE d.agent_id = ?", agentId)
+	if err != nil {
+		return nil, err
+	}
+	if len(drifts) == 0 {
+		return nil, nil
+	}
+	return &drifts[0], nil
+}
+
+// GetPipelineConfigDrift returns the last drift check of every agent attached
+// to the pipeline that reported its config.
+func (f *FrontendPipelineRepository) GetPipelineConfigDrift(pipelineId int) ([]models.ConfigDrift, error) {
+	return f.queryConfigDrift("WHERE d.pipeline_id = ? ORDER BY d.agent_id", pipelineId)
+}
+
+// SaveConfigDrift inserts or replaces the agent's drift check.
+func (f *FrontendPipelineRepository) SaveConfigDrift(drift models.ConfigDrift) error {
+	var driftedSince any
+	if drift.DriftedSince != 0 {
+		driftedSince = drift.DriftedSince
+	}
+
+	_, err := f.db.Exec(`
+		INSERT INTO config_drift
+		(agent_id, pipeline_id, status, desired_hash, effective_hash, action, error, drifted_since, checked_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(agent_id) DO UPDATE SET
+			pipeline_id = EXCLUDED.pipeline_id,
+			status = EXCLUDED.status,
+			desired_hash = EXCLUDED.desired_hash,
+			effective_hash = EXCLUDED.effective_hash,
+			action = EXCLUDED.action,
+			error = EXCLUDED.error,
+			drifted_since = EXCLUDED.drifted_since,
+			checked_at = EXCLUDED.checked_at
+	`, drift.AgentID, drift.PipelineID, drift.Status, drift.DesiredHash, drift.EffectiveHash, drift.Action, drift.Error,
+		driftedSince, drift.CheckedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save config drift: %w", err)
+	}
+	return nil
+}
+
+// queryConfigDrift only returns checks made against the pipeline the agent is
+// still attached to.
+func (f *FrontendPipelineRepository) queryConfigDrift(where string, args ...any) ([]models.ConfigDrift, error) {
+	rows, err := f.db.Query(`
+		SELECT d.agent_id, d.pipeline_id, d.status, d.desired_hash, d.effective_hash, d.action, d.error,
+			COALESCE(d.drifted_since, 0), d.checked_at
+		FROM config_drift d
+		JOIN agents a ON a.id = d.agent_id AND a.pipeline_id = d.pipeline_id `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query config drift: %w", err)
+	}
+	defer rows.Close()
+
+	var drifts []models.ConfigDrift
+	for rows.Next() {
+		var drift models.ConfigDrift
+		if err := rows.Scan(&drift.AgentID, &drift.PipelineID, &drift.Status, &drift.DesiredHash, &drift.EffectiveHash,
+			&drift.Action, &drift.Error, &drift.DriftedSince, &drift.CheckedAt); err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, drift)
+	}
+	return drifts, rows.Err()
+}
+
+// configApplyHistory is how many config apply reports are kept per agent.
+const configApplyHistory = 20
+
+// GetPipelineConfigDeliveries returns the config deliveries of the pipeline's
+// agents.
+func (f *FrontendPipelineRepository) GetPipelineConfigDeliveries(pipelineId int) ([]models.ConfigDelivery, error) {
+	return f.queryConfigDeliveries("WHERE pipeline_id = ? ORDER BY agent_id", pipelineId)
+}
+
+// SaveConfigApply records a config apply report of an agent, dropping its
+// reports beyond the newest configApplyHistory.
+func (f *FrontendPipelineRepository) SaveConfigApply(apply models.ConfigApply) error {
+	componentsJSON, err := json.Marshal(apply.Components)
+	if err != nil {
+		return fmt.Errorf("failed to marshal component statuses: %w", err)
+	}
+
+	tx, err := f.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO config_applies
+		(agent_id, pipeline_id, config_version, status, error, rolled_back, components_json, reported_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, apply.AgentID, apply.PipelineID, apply.ConfigVersion, apply.Status, apply.Error, apply.RolledBack, string(componentsJSON),
+		apply.ReportedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save config apply: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM config_applies
+		WHERE agent_id = ? AND apply_id NOT IN (
+			SELECT apply_id FROM config_applies WHERE agent_id = ? ORDER BY apply_id DESC LIMIT ?
+		)
+	`, apply.AgentID, apply.AgentID, configApplyHistory)
+	if err != nil {
+		return fmt.Errorf("failed to prune config applies: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetConfigApplies returns the agent's config apply reports, newest first.
+func (f *FrontendPipelineRepository) GetConfigApplies(agentId int) ([]models.ConfigApply, error) {
+	return f.queryConfigApplies("WHERE c.agent_id = ? ORDER BY c.apply_id DESC", agentId)
+}
+
+// GetPipelineConfigApplies returns the last config apply report of every
+// agent attached to the pipeline that reported one.
+func (f *FrontendPipelineRepository) GetPipelineConfigApplies(pipelineId int) ([]models.ConfigApply, error) {
+	return f.queryConfigApplies(`
+		JOIN agents a ON a.id = c.agent_id
+		WHERE a.pipeline_id = ?
+			AND c.apply_id = (SELECT MAX(apply_id) FROM config_applies WHERE agent_id = c.agent_id)
+		ORDER BY c.agent_id`, pipelineId)
+}
+
+func (f *FrontendPipelineRepository) queryConfigApplies(where string, args ...any) ([]models.ConfigApply, error) {
+	rows, err := f.db.Query(`
+		SELECT c.apply_id, c.agent_id, c.pipeline_id, c.config_version, c.status, c.error, c.rolled_back, c.components_json,
+			c.reported_at
+		FROM config_applies c `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query config applies: %w", err)
+	}
+	defer rows.Close()
+
+	var applies []models.ConfigApply
+	for rows.Next() {
+		var apply models.ConfigApply
+		var pipelineId sql.NullInt64
+		var componentsJSON string
+		if err := rows.Scan(&apply.ID, &apply.AgentID, &pipelineId, &apply.ConfigVersion, &apply.Status, &apply.Error,
+			&apply.RolledBack, &componentsJSON, &apply.ReportedAt); err != nil {
+			return nil, err
+		}
+		if pipelineId.Valid {
+			id := int(pipelineId.Int64)
+			apply.PipelineID = &id
+		}
+		if err := json.Unmarshal([]byte(componentsJSON), &apply.Components); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal component statuses: %w", err)
+		}
+		applies = append(applies, apply)
+	}
+	return applies, rows.Err()
+}
+
+// GetAgentComponents returns the collector components the agent reported at
+// registration, ordered by kind and type.
+func (f *FrontendPipelineRepository) GetAgentComponents(agentId int) ([]models.AgentComponent, error) {
+	rows, err := f.db.Query(`
+		SELECT kind, type, name, stability_json FROM agent_components
+		WHERE agent_id = ? ORDER BY kind, type`, agentId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agent components: %w", err)
+	}
+	defer rows.Close()
+
+	components := []models.AgentComponent{}
+	for rows.Next() {
+		var component models.AgentComponent
+		var stabilityJSON string
+		if err := rows.Scan(&component.Kind, &component.Type, &component.Name, &stabilityJSON); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(stabilityJSON), &component.Stability); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal component stability: %w", err)
+		}
+		components = append(components, component)
+	}
+	return components, rows.Err()
+}
+
+// GetAgentComponentNames returns the catalog names of the components each of
+// the agents reported. Agents that reported no components are left out.
+func (f *FrontendPipelineRepository) GetAgentComponentNames(agentIds []int) (map[int]map[string]bool, error) {
+	names := make(map[int]map[string]bool)
+	if len(agentIds) == 0 {
+		return names, nil
+	}
+
+	args := make([]any, len(agentIds))
+	for i, id := range agentIds {
+		args[i] = id
+	}
+	rows, err := f.db.Query(`
+		SELECT agent_id, kind, type FROM agent_components
+		WHERE agent_id IN (?`+strings.Repeat(", ?", len(agentIds)-1)+`)`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agent components: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var agentId int
+		var kind, componentType string
+		if err := rows.Scan(&agentId, &kind, &componentType); err != nil {
+			return nil, err
+		}
+		if names[agentId] == nil {
+			names[agentId] = make(map[string]bool)
+		}
+		for _, name := range configcompiler.CatalogComponentNames(kind, componentType) {
+			names[agentId][name] = true
+		}
+	}
+	return names, rows.Err()
+}
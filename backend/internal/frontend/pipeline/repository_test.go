@@ -6,6 +6,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	frontendpipeline "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/pipeline"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -51,14 +52,17 @@ func TestGetPipelineInfo(t *testing.T) {
 	createdAt := int64(1704067200) // 2024-01-01T00:00:00Z
 	updatedAt := int64(1704153600) // 2024-01-02T00:00:00Z
 
-	mock.ExpectQuery("SELECT pipeline_id, name, created_by, created_at, updated_at FROM pipelines WHERE pipeline_id = \\?").
-		WithArgs(1).
-		WillReturnRows(sqlmock.NewRows([]string{"pipeline_id", "name", "created_by", "created_at", "updated_at"}).
-			AddRow(1, "PipelineX", "admin", createdAt, updatedAt))
+	mock.ExpectQuery("SELECT pipeline_id, name, COALESCE\\(environment, ''\\), COALESCE\\(label_selector, ''\\), COALESCE\\(drift_policy, \\?\\), created_by, created_at, updated_at FROM pipelines WHERE pipeline_id = \\?").
+		WithArgs(models.DriftPolicyAutoRemediate, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"pipeline_id", "name", "environment", "label_selector", "drift_policy", "created_by", "created_at", "updated_at"}).
+			AddRow(1, "PipelineX", "dev", "env=prod", "alert", "admin", createdAt, updatedAt))
 
 	info, err := repo.GetPipelineInfo(1)
 	assert.NoError(t, err)
 	assert.Equal(t, "PipelineX", info.Name)
+	assert.Equal(t, "dev", info.Environment)
+	assert.Equal(t, "env=prod", info.LabelSelector)
+	assert.Equal(t, "alert", info.DriftPolicy)
 	assert.Equal(t, 1, info.ID)
 	assert.Equal(t, int(createdAt), info.CreatedAt)
 	assert.Equal(t, int(updatedAt), info.UpdatedAt)
@@ -152,3 +156,105 @@ func TestGetAgentInfo(t *testing.T) {
 	assert.Equal(t, "agentX", info.Name)
 	assert.Equal(t, "running", info.Status)
 }
+
+func TestGetPipelineIdByEnvironment(t *testing.T) {
+	repo, mock, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT pipeline_id FROM pipelines WHERE name = \\? AND environment = \\?").
+		WithArgs("edge", "staging").WillReturnRows(sqlmock.NewRows([]string{"pipeline_id"}).AddRow(7))
+
+	id, err := repo.GetPipelineIdByEnvironment("edge", "staging")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, *id)
+
+	mock.ExpectQuery("SELECT pipeline_id FROM pipelines WHERE name = \\? AND environment = \\?").
+		WithArgs("edge", "prod").WillReturnError(sql.ErrNoRows)
+
+	id, err = repo.GetPipelineIdByEnvironment("edge", "prod")
+	assert.NoError(t, err)
+	assert.Nil(t, id)
+}
+
+func TestGetAgentsWithLabels(t *testing.T) {
+	repo, mock, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT id, name, pipeline_id FROM agents WHERE id = \\? ORDER BY id").
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "pipeline_id"}).AddRow(3, "agentX", nil))
+	mock.ExpectQuery("SELECT agent_id, key, value FROM agents_labels").
+		WillReturnRows(sqlmock.NewRows([]string{"agent_id", "key", "value"}).
+			AddRow(3, "env", "prod").
+			AddRow(8, "env", "dev"))
+
+	agents, err := repo.GetAgentsWithLabels(3)
+	assert.NoError(t, err)
+	assert.Len(t, agents, 1)
+	assert.Nil(t, agents[0].PipelineID)
+	assert.Equal(t, map[string]string{"env": "prod"}, agents[0].Labels)
+}
+
+func TestSaveConfigApply(t *testing.T) {
+	repo, mock, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO config_applies").
+		WithArgs(5, nil, "abc123", models.ConfigApplied, "", false, `[{"id":"otlp","kind":"receiver","status":"ok"}]`, int64(100)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM config_applies").
+		WithArgs(5, 5, 20).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := repo.SaveConfigApply(models.ConfigApply{
+		AgentID:       5,
+		ConfigVersion: "abc123",
+		Status:        models.ConfigApplied,
+		Components:    []models.ComponentStatus{{ID: "otlp", Kind: "receiver", Status: "ok"}},
+		ReportedAt:    100,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetConfigApplies(t *testing.T) {
+	repo, mock, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery("FROM config_applies c WHERE c.agent_id = \\? ORDER BY c.apply_id DESC").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"apply_id", "agent_id", "pipeline_id", "config_version", "status", "error", "rolled_back", "components_json", "reported_at"}).
+			AddRow(2, 5, 4, "abc123", models.ConfigApplyFailed, "port in use", true, `[{"id":"otlp","kind":"receiver","status":"permanent_error"}]`, 200).
+			AddRow(1, 5, nil, "old", models.ConfigApplied, "", false, `[]`, 100))
+
+	applies, err := repo.GetConfigApplies(5)
+	assert.NoError(t, err)
+	assert.Len(t, applies, 2)
+	assert.Equal(t, 4, *applies[0].PipelineID)
+	assert.True(t, applies[0].RolledBack)
+	assert.Equal(t, []models.ComponentStatus{{ID: "otlp", Kind: "receiver", Status: "permanent_error"}}, applies[0].Components)
+	assert.Nil(t, applies[1].PipelineID)
+}
+
+func TestGetAgentComponentNames(t *testing.T) {
+	repo, mock, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT agent_id, kind, type FROM agent_components WHERE agent_id IN \\(\\?, \\?\\)").
+		WithArgs(5, 6).
+		WillReturnRows(sqlmock.NewRows([]string{"agent_id", "kind", "type"}).
+			AddRow(5, "receiver", "otlp").
+			AddRow(5, "exporter", "debug").
+			AddRow(6, "processor", "acme_sampling"))
+
+	names, err := repo.GetAgentComponentNames([]int{5, 6})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]map[string]bool{
+		5: {"otlp_receiver": true, "debug_exporter": true},
+		// A custom component keeps the underscores of its type
+		6: {"acmesampling_processor": true, "acme_sampling_processor": true},
+	}, names)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,149 @@
+package frontendpipeline
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+)
+
+// RecordConfigApply records an agent's report of loading a config into its
+// collector.
+func (f *FrontendPipelineService) RecordConfigApply(agentId string, req models.ConfigApplyRequest) error {
+	if req.Status != models.ConfigApplied && req.Status != models.ConfigApplyFailed {
+		return fmt.Errorf("%w %q: must be %s or %s", utils.ErrInvalidConfigApplyStatus, req.Status,
+			models.ConfigApplied, models.ConfigApplyFailed)
+	}
+
+	agentIDInt, err := strconv.Atoi(agentId)
+	if err != nil {
+		return fmt.Errorf("error converting agent ID to int: %v", err)
+	}
+	if _, err := f.FrontendPipelineRepository.GetAgentInfo(agentIDInt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return utils.ErrAgentDoesNotExists
+		}
+		return err
+	}
+
+	pipelineId, err := f.FrontendPipelineRepository.GetAgentPipelineId(agentId)
+	if err != nil {
+		return err
+	}
+
+	apply := models.ConfigApply{
+		AgentID:       agentIDInt,
+		PipelineID:    pipelineId,
+		ConfigVersion: req.ConfigHash,
+		Status:        req.Status,
+		Error:         req.Error,
+		RolledBack:    req.RolledBack,
+		Components:    req.Components,
+		ReportedAt:    utils.GetCurrentTime(),
+	}
+	if apply.Components == nil {
+		apply.Components = []models.ComponentStatus{}
+	}
+	if apply.Status == models.ConfigApplyFailed {
+		utils.Logger.Sugar().Warnf("Agent [ID:%v] failed to apply config %s (rolled back: %v): %s", agentId, req.ConfigHash, req.RolledBack, req.Error)
+	}
+
+	return f.FrontendPipelineRepository.SaveConfigApply(apply)
+}
+
+// GetConfigApplies returns the agent's recent config applies, newest first.
+func (f *FrontendPipelineService) GetConfigApplies(agentId int) ([]models.ConfigApply, error) {
+	if _, err := f.FrontendPipelineRepository.GetAgentInfo(agentId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.ErrAgentDoesNotExists
+		}
+		return nil, err
+	}
+
+	applies, err := f.FrontendPipelineRepository.GetConfigApplies(agentId)
+	if err != nil {
+		return nil, err
+	}
+	if applies == nil {
+		applies = []models.ConfigApply{}
+	}
+	return applies, nil
+}
+
+// GetPipelineRollout reports how far each agent of the pipeline got with the
+// config the pipeline currently compiles to: delivered to the agent, then
+// applied by its collector or failed.
+func (f *FrontendPipelineService) GetPipelineRollout(pipelineId int) (*models.ConfigRollout, error) {
+	if !f.FrontendPipelineRepository.PipelineExists(pipelineId) {
+		return nil, utils.ErrPipelineDoesNotExists
+	}
+
+	config, err := f.GetPipelineConfig(pipelineId)
+	if err != nil {
+		return nil, err
+	}
+	version, err := utils.EffectiveConfigHash(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash pipeline config: %w", err)
+	}
+
+	agents, err := f.FrontendPipelineRepository.GetAllAgentsAttachedToPipeline(pipelineId)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := f.FrontendPipelineRepository.GetPipelineConfigDeliveries(pipelineId)
+	if err != nil {
+		return nil, err
+	}
+	applies, err := f.FrontendPipelineRepository.GetPipelineConfigApplies(pipelineId)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveryByAgent := make(map[int]*models.ConfigDelivery, len(deliveries))
+	for i := range deliveries {
+		deliveryByAgent[deliveries[i].AgentID] = &deliveries[i]
+	}
+	applyByAgent := make(map[int]*models.ConfigApply, len(applies))
+	for i := range applies {
+		applyByAgent[applies[i].AgentID] = &applies[i]
+	}
+
+	rollout := &models.ConfigRollout{
+		PipelineID:    pipelineId,
+		ConfigVersion: version,
+		Summary:       map[string]int{},
+		Agents:        []models.RolloutAgent{},
+	}
+	for _, agent := range agents {
+		rolloutAgent := models.RolloutAgent{
+			AgentID:  int(agent.ID),
+			Name:     agent.Name,
+			Delivery: deliveryByAgent[int(agent.ID)],
+			Apply:    applyByAgent[int(agent.ID)],
+		}
+		rolloutAgent.Status = rolloutStatus(version, rolloutAgent.Delivery, rolloutAgent.Apply)
+		rollout.Summary[rolloutAgent.Status]++
+		rollout.Agents = append(rollout.Agents, rolloutAgent)
+	}
+	return rollout, nil
+}
+
+// rolloutStatus tells how far an agent got with the config version. An apply
+// of the version outranks its delivery, as agents also apply configs they
+// read from disk at startup.
+func rolloutStatus(version string, delivery *models.ConfigDelivery, apply *models.ConfigApply) string {
+	if apply != nil && apply.ConfigVersion == version {
+		if apply.RolledBack {
+			return models.RolloutRolledBack
+		}
+		return apply.Status
+	}
+	if delivery != nil && delivery.ConfigVersion == version {
+		return delivery.Status
+	}
+	return models.RolloutOutdated
+}
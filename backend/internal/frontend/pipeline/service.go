@@ -1,17 +1,26 @@
 package frontendpipeline
 
 import (
-	"bytes"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
-	"time"
+	"strings"
+	"sync"
 
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/constants"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/agentclient"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/configcompiler"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/configexport"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/jobs"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/labelselector"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/schemavalidator"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/secrets"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/taps"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
 )
 
@@ -29,6 +38,27 @@ type FrontendPipelineRepositoryInterface interface {
 	SyncPipelineGraph(tx *sql.Tx, pipelineID int, graph models.PipelineGraph) error
 	GetAgentInfo(agentId int) (*models.AgentInfoHome, error)
 	GetAgentPipelineId(agentId string) (*int, error)
+	GetComponentSchema(componentName string) ([]byte, error)
+	GetComponentCatalog() (map[string]models.CatalogComponent, error)
+	SetPipelineEnvironment(pipelineId int, environment string) error
+	GetPipelineIdByEnvironment(name string, environment string) (*int, error)
+	SetPipelineSelector(pipelineId int, selector string) error
+	GetPipelineSelectors() ([]models.PipelineSelector, error)
+	GetAgentsWithLabels(agentId int) ([]models.AgentLabels, error)
+	GetConfigDelivery(agentId int) (*models.ConfigDelivery, error)
+	GetDueConfigDeliveries(now int64) ([]models.ConfigDelivery, error)
+	SaveConfigDelivery(delivery models.ConfigDelivery) error
+	DeleteConfigDelivery(agentId int) error
+	SetPipelineDriftPolicy(pipelineId int, policy string) error
+	GetConfigDrift(agentId int) (*models.ConfigDrift, error)
+	GetPipelineConfigDrift(pipelineId int) ([]models.ConfigDrift, error)
+	SaveConfigDrift(drift models.ConfigDrift) error
+	GetPipelineConfigDeliveries(pipelineId int) ([]models.ConfigDelivery, error)
+	SaveConfigApply(apply models.ConfigApply) error
+	GetConfigApplies(agentId int) ([]models.ConfigApply, error)
+	GetPipelineConfigApplies(pipelineId int) ([]models.ConfigApply, error)
+	GetAgentComponents(agentId int) ([]models.AgentComponent, error)
+	GetAgentComponentNames(agentIds []int) (map[int]map[string]bool, error)
 }
 
 type FrontendPipelineServiceInterface interface {
@@ -39,21 +69,68 @@ type FrontendPipelineServiceInterface interface {
 	DeletePipeline(pipelineId int) error
 	GetAllAgentsAttachedToPipeline(pipelineId int) ([]models.AgentInfoHome, error)
 	DetachAgentFromPipeline(pipelineId int, agentId int) error
-	AttachAgentToPipeline(pipelineId int, agentId int) error
+	AttachAgentToPipeline(pipelineId int, agentId int) ([]string, error)
 	GetPipelineGraph(pipelineId int) (*models.PipelineGraph, error)
-	SyncPipelineGraph(pipelineId int, pipelineGraph models.PipelineGraph) error
+	SyncPipelineGraph(pipelineId int, pipelineGraph models.PipelineGraph) ([]string, error)
+	GetPipelineTelemetry(pipelineId int) (*models.PipelineTelemetry, error)
+	UpdatePipelineTelemetry(pipelineId int, telemetry models.PipelineTelemetry) error
+	LintPipeline(pipelineId int) (*models.LintReport, error)
+	ImportPipeline(collectorConfig map[string]any) (*models.ImportPipelineResponse, error)
+	ExportPipeline(pipelineId int, format string, opts configexport.Options) ([]byte, error)
+	SetPipelineEnvironment(pipelineId int, environment string) error
+	ClonePipeline(pipelineId int, req models.ClonePipelineRequest) (string, error)
+	PreviewPromotion(pipelineId int, to string) (*models.PipelinePromotion, error)
+	PromotePipeline(pipelineId int, req models.PromotePipelineRequest) (*models.PipelinePromotion, error)
+	SetPipelineSelector(pipelineId int, selector string) (*models.PipelineSelectorStatus, error)
+	GetSelectorStatus(pipelineId int) (*models.PipelineSelectorStatus, error)
+	AssignAgentBySelector(agentId int) (*int, error)
+	GetPipelineConfig(pipelineId int) (map[string]any, error)
 	SyncConfig(agentId string) error
+	RedeliverConfig(agentId string) error
+	RetryConfigDeliveries() error
+	SetPipelineDriftPolicy(pipelineId int, policy string) error
+	ReportEffectiveConfig(agentId string, effectiveHash string) (*models.ConfigDrift, error)
+	GetConfigDrift(agentId int) (*models.ConfigDrift, error)
+	GetPipelineConfigDrift(pipelineId int) ([]models.ConfigDrift, error)
+	GetEffectiveConfig(agentId int) (map[string]any, error)
+	RecordConfigApply(agentId string, req models.ConfigApplyRequest) error
+	GetConfigApplies(agentId int) ([]models.ConfigApply, error)
+	GetPipelineRollout(pipelineId int) (*models.ConfigRollout, error)
+	GetAgentComponents(agentId int) ([]models.AgentComponent, error)
+	StartTap(pipelineId int, nodeId int, opts models.TapOptions) (*NodeTap, error)
+	PublishTapSample(tapId string, sample models.TapSample) error
 }
 
 type FrontendPipelineService struct {
 	FrontendPipelineRepository FrontendPipelineRepositoryInterface
+	SecretResolver             secrets.Resolver
+	// Taps routes samples agents send to the open taps
+	Taps *taps.Broker
+	Jobs *jobs.Manager
+}
+
+// configPushJobType is the job type of config pushes; each job pushes the
+// config of one pipeline to one agent.
+const configPushJobType = "pipeline.config_push"
+
+type configPushParams struct {
+	PipelineID int `json:"pipeline_id"`
 }
 
-// NewFrontendPipelineService creates a new FrontendPipelineService
-func NewFrontendPipelineService(frontendPipelineRepository FrontendPipelineRepositoryInterface) FrontendPipelineServiceInterface {
-	return &FrontendPipelineService{
+// NewFrontendPipelineService creates a new FrontendPipelineService. The secret
+// resolver fills in ${secret:name} references; with a nil resolver, graphs
+// that use them can't be saved or sent when secrets resolve at compile time.
+// Configs are pushed to agents as jobs of the job manager, which the service
+// registers its executor with.
+func NewFrontendPipelineService(frontendPipelineRepository FrontendPipelineRepositoryInterface, secretResolver secrets.Resolver, jobManager *jobs.Manager) FrontendPipelineServiceInterface {
+	f := &FrontendPipelineService{
 		FrontendPipelineRepository: frontendPipelineRepository,
+		SecretResolver:             secretResolver,
+		Taps:                       taps.NewBroker(),
+		Jobs:                       jobManager,
 	}
+	jobManager.Register(configPushJobType, f.runConfigPushTarget)
+	return f
 }
 
 func (f *FrontendPipelineService) GetAllPipelines() ([]*Pipeline, error) {
@@ -77,6 +154,10 @@ func (f *FrontendPipelineService) GetPipelineOverview(pipelineId int) (*Pipeline
 }
 
 func (f *FrontendPipelineService) CreatePipeline(createPipelineRequest models.CreatePipelineRequest) (string, error) {
+	if err := f.validateNodeConfigs(createPipelineRequest.PipelineGraph, createPipelineRequest.AgentIDs); err != nil {
+		return "", err
+	}
+
 	return f.FrontendPipelineRepository.CreatePipeline(createPipelineRequest)
 }
 
@@ -101,33 +182,41 @@ func (f *FrontendPipelineService) DetachAgentFromPipeline(pipelineId int, agentI
 		return utils.ErrPipelineDoesNotExists
 	}
 
-	return f.FrontendPipelineRepository.DetachAgentFromPipeline(pipelineId, agentId)
+	if err := f.FrontendPipelineRepository.DetachAgentFromPipeline(pipelineId, agentId); err != nil {
+		return err
+	}
+
+	// The pipeline's config is no longer owed to the agent
+	return f.FrontendPipelineRepository.DeleteConfigDelivery(agentId)
 }
 
-func (f *FrontendPipelineService) AttachAgentToPipeline(pipelineId int, agentId int) error {
+// AttachAgentToPipeline attaches the agent and starts a job pushing the
+// pipeline's config to it. It returns the ID of the job. The config is
+// compiled first, so an agent is only attached to a pipeline it can be sent.
+func (f *FrontendPipelineService) AttachAgentToPipeline(pipelineId int, agentId int) ([]string, error) {
 	if !f.FrontendPipelineRepository.PipelineExists(pipelineId) {
-		return utils.ErrPipelineDoesNotExists
+		return nil, utils.ErrPipelineDoesNotExists
 	}
 
-	err := f.FrontendPipelineRepository.AttachAgentToPipeline(pipelineId, agentId)
+	graph, err := f.FrontendPipelineRepository.GetPipelineGraph(pipelineId)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	graph, err := f.GetPipelineGraph(pipelineId)
-	if err != nil {
-		return err
+	if err := f.checkAgentSupport(*graph, []int{agentId}); err != nil {
+		return nil, err
 	}
 
-	agent, err := f.FrontendPipelineRepository.GetAgentInfo(agentId)
-	if err != nil {
-		return err
+	if _, _, err := f.compileConfig(*graph); err != nil {
+		return nil, err
 	}
 
-	var agents []models.AgentInfoHome
-	agents = append(agents, *agent)
+	err = f.FrontendPipelineRepository.AttachAgentToPipeline(pipelineId, agentId)
+	if err != nil {
+		return nil, err
+	}
 
-	return f.sendConfigToAgents(agents, *graph)
+	return f.enqueueConfigPushes(pipelineId, []int{agentId})
 }
 
 func (f *FrontendPipelineService) GetPipelineGraph(pipelineId int) (*models.PipelineGraph, error) {
@@ -138,24 +227,596 @@ func (f *FrontendPipelineService) GetPipelineGraph(pipelineId int) (*models.Pipe
 	return f.FrontendPipelineRepository.GetPipelineGraph(pipelineId)
 }
 
-func (f *FrontendPipelineService) SyncPipelineGraph(pipelineId int, pipelineGraph models.PipelineGraph) error {
+// SyncPipelineGraph stores the pipeline's graph and starts a job per attached
+// agent pushing the new config to it. It returns the IDs of the jobs.
+func (f *FrontendPipelineService) SyncPipelineGraph(pipelineId int, pipelineGraph models.PipelineGraph) ([]string, error) {
+	if !f.FrontendPipelineRepository.PipelineExists(pipelineId) {
+		return nil, utils.ErrPipelineDoesNotExists
+	}
+
+	// Graphs fetched from the API have sensitive values redacted; keep the
+	// stored values wherever they come back unchanged
+	storedGraph, err := f.FrontendPipelineRepository.GetPipelineGraph(pipelineId)
+	if err != nil {
+		return nil, err
+	}
+	pipelineGraph = secrets.RestoreRedacted(pipelineGraph, *storedGraph)
+
+	attachedAgent, err := f.FrontendPipelineRepository.GetAllAgentsAttachedToPipeline(pipelineId)
+	if err != nil {
+		return nil, err
+	}
+
+	agentIds := make([]int, 0, len(attachedAgent))
+	for _, agent := range attachedAgent {
+		agentIds = append(agentIds, int(agent.ID))
+	}
+	if err := f.validateNodeConfigs(pipelineGraph, agentIds); err != nil {
+		return nil, err
+	}
+
+	err = f.FrontendPipelineRepository.SyncPipelineGraph(nil, pipelineId, pipelineGraph)
+	if err != nil {
+		return nil, err
+	}
+
+	// Graphs synced without telemetry keep the stored settings, so compile what was persisted
+	if pipelineGraph.Telemetry == nil {
+		pipelineGraph.Telemetry = storedGraph.Telemetry
+	}
+	if _, _, err := f.compileConfig(pipelineGraph); err != nil {
+		return nil, err
+	}
+
+	return f.enqueueConfigPushes(pipelineId, agentIds)
+}
+
+// GetPipelineTelemetry returns the pipeline's collector telemetry settings,
+// falling back to the defaults when none were stored.
+func (f *FrontendPipelineService) GetPipelineTelemetry(pipelineId int) (*models.PipelineTelemetry, error) {
+	graph, err := f.GetPipelineGraph(pipelineId)
+	if err != nil {
+		return nil, err
+	}
+
+	if graph.Telemetry == nil {
+		telemetry := constants.DefaultPipelineTelemetry
+		return &telemetry, nil
+	}
+	return graph.Telemetry, nil
+}
+
+// LintPipeline runs the semantic lint rules against the stored pipeline graph.
+func (f *FrontendPipelineService) LintPipeline(pipelineId int) (*models.LintReport, error) {
+	graph, err := f.GetPipelineGraph(pipelineId)
+	if err != nil {
+		return nil, err
+	}
+
+	report := configcompiler.LintGraph(*graph)
+	return &report, nil
+}
+
+// ImportPipeline reverse-compiles a hand-written collector config into a
+// pipeline graph using the component catalog. Nothing is stored.
+func (f *FrontendPipelineService) ImportPipeline(collectorConfig map[string]any) (*models.ImportPipelineResponse, error) {
+	catalog, err := f.FrontendPipelineRepository.GetComponentCatalog()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load component catalog: %w", err)
+	}
+
+	return configcompiler.DecompileConfig(collectorConfig, catalog)
+}
+
+// ExportPipeline compiles the pipeline graph and renders it for running
+// outside the agent: plain YAML, Helm values or a Kubernetes manifest.
+func (f *FrontendPipelineService) ExportPipeline(pipelineId int, format string, opts configexport.Options) ([]byte, error) {
+	info, err := f.GetPipelineInfo(pipelineId)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := f.FrontendPipelineRepository.GetPipelineGraph(pipelineId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Exported files are downloaded and shared, so secrets are always left
+	// for the collector to read from its environment
+	config, err := configcompiler.CompileGraphToJSON(secrets.EnvGraph(*graph))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile pipeline graph: %w", err)
+	}
+
+	if opts.Name == "" {
+		opts.Name = info.Name
+	}
+	return configexport.Export(*config, format, opts)
+}
+
+// UpdatePipelineTelemetry stores new telemetry settings and pushes the
+// recompiled config to every attached agent.
+func (f *FrontendPipelineService) UpdatePipelineTelemetry(pipelineId int, telemetry models.PipelineTelemetry) error {
+	if err := utils.ValidatePipelineTelemetry(&telemetry); err != nil {
+		return err
+	}
+
+	graph, err := f.GetPipelineGraph(pipelineId)
+	if err != nil {
+		return err
+	}
+	graph.Telemetry = &telemetry
+
+	_, err = f.SyncPipelineGraph(pipelineId, *graph)
+	return err
+}
+
+func (f *FrontendPipelineService) SetPipelineEnvironment(pipelineId int, environment string) error {
 	if !f.FrontendPipelineRepository.PipelineExists(pipelineId) {
 		return utils.ErrPipelineDoesNotExists
 	}
 
-	err := f.FrontendPipelineRepository.SyncPipelineGraph(nil, pipelineId, pipelineGraph)
+	return f.FrontendPipelineRepository.SetPipelineEnvironment(pipelineId, environment)
+}
+
+// ClonePipeline creates a new pipeline with a copy of the source pipeline's
+// graph and telemetry settings.
+func (f *FrontendPipelineService) ClonePipeline(pipelineId int, req models.ClonePipelineRequest) (string, error) {
+	info, err := f.GetPipelineInfo(pipelineId)
+	if err != nil {
+		return "", err
+	}
+
+	graph, err := f.FrontendPipelineRepository.GetPipelineGraph(pipelineId)
+	if err != nil {
+		return "", err
+	}
+
+	if req.Name == "" {
+		req.Name = info.Name + " (copy)"
+	}
+	if req.CreatedBy == "" {
+		req.CreatedBy = info.CreatedBy
+	}
+
+	return f.CreatePipeline(models.CreatePipelineRequest{
+		Name:          req.Name,
+		CreatedBy:     req.CreatedBy,
+		Environment:   req.Environment,
+		AgentIDs:      req.AgentIDs,
+		PipelineGraph: *graph,
+	})
+}
+
+// PreviewPromotion shows what promoting the pipeline to the target
+// environment would change, without changing anything.
+func (f *FrontendPipelineService) PreviewPromotion(pipelineId int, to string) (*models.PipelinePromotion, error) {
+	promotion, _, err := f.planPromotion(pipelineId, to)
+	return promotion, err
+}
+
+// PromotePipeline copies the pipeline's graph to the pipeline with the same
+// name in the target environment. An existing target is synced, which pushes
+// the new config to its agents; otherwise the target pipeline is created.
+func (f *FrontendPipelineService) PromotePipeline(pipelineId int, req models.PromotePipelineRequest) (*models.PipelinePromotion, error) {
+	promotion, graph, err := f.planPromotion(pipelineId, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	if promotion.TargetID != nil {
+		if _, err := f.SyncPipelineGraph(*promotion.TargetID, *graph); err != nil {
+			return nil, err
+		}
+		return promotion, nil
+	}
+
+	info, err := f.FrontendPipelineRepository.GetPipelineInfo(pipelineId)
+	if err != nil {
+		return nil, err
+	}
+	if req.CreatedBy == "" {
+		req.CreatedBy = info.CreatedBy
+	}
+
+	id, err := f.CreatePipeline(models.CreatePipelineRequest{
+		Name:          info.Name,
+		CreatedBy:     req.CreatedBy,
+		Environment:   promotion.TargetEnvironment,
+		PipelineGraph: *graph,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	targetId, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("error converting pipeline ID to int: %v", err)
+	}
+	promotion.TargetID = &targetId
+	return promotion, nil
+}
+
+// planPromotion resolves the target environment and pipeline and diffs the
+// target's current graph against the source graph. to defaults to the
+// environment after the source's; promotions only move forward.
+func (f *FrontendPipelineService) planPromotion(pipelineId int, to string) (*models.PipelinePromotion, *models.PipelineGraph, error) {
+	info, err := f.GetPipelineInfo(pipelineId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	from := environmentIndex(info.Environment)
+	if from < 0 {
+		return nil, nil, fmt.Errorf("%w: pipeline %q has no promotable environment (got %q, want one of %v)",
+			utils.ErrInvalidPromotion, info.Name, info.Environment, constants.PromotionEnvironments)
+	}
+
+	if to == "" {
+		if from == len(constants.PromotionEnvironments)-1 {
+			return nil, nil, fmt.Errorf("%w: %s is the last environment", utils.ErrInvalidPromotion, info.Environment)
+		}
+		to = constants.PromotionEnvironments[from+1]
+	}
+	target := environmentIndex(to)
+	if target < 0 {
+		return nil, nil, fmt.Errorf("%w: unknown environment %q", utils.ErrInvalidPromotion, to)
+	}
+	if target <= from {
+		return nil, nil, fmt.Errorf("%w: cannot promote from %s to %s", utils.ErrInvalidPromotion, info.Environment, to)
+	}
+
+	graph, err := f.FrontendPipelineRepository.GetPipelineGraph(pipelineId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targetId, err := f.FrontendPipelineRepository.GetPipelineIdByEnvironment(info.Name, to)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current := &models.PipelineGraph{}
+	if targetId != nil {
+		current, err = f.FrontendPipelineRepository.GetPipelineGraph(*targetId)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return &models.PipelinePromotion{
+		SourceID:          pipelineId,
+		SourceEnvironment: info.Environment,
+		TargetEnvironment: to,
+		TargetID:          targetId,
+		Diff:              DiffPipelineGraphs(*current, *graph),
+	}, graph, nil
+}
+
+func environmentIndex(environment string) int {
+	for i, env := range constants.PromotionEnvironments {
+		if env == environment {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateNodeConfigs checks every node config against the JSON Schema of its
+// component, and every component against the ones the agents reported, and
+// reports all problems at once as a *models.GraphValidationError.
+func (f *FrontendPipelineService) validateNodeConfigs(graph models.PipelineGraph, agentIds []int) error {
+	var nodeErrors []models.NodeValidationError
+	schemas := make(map[string][]byte)
+
+	unsupported, err := f.unsupportedComponents(graph, agentIds)
 	if err != nil {
 		return err
 	}
 
-	attachedAgent, err := f.FrontendPipelineRepository.GetAllAgentsAttachedToPipeline(pipelineId)
+	for _, node := range graph.Nodes {
+		nodeErr := models.NodeValidationError{
+			ComponentID:   node.ComponentID,
+			Name:          node.Name,
+			ComponentName: node.ComponentName,
+		}
+
+		schema, cached := schemas[node.ComponentName]
+		if !cached {
+			var err error
+			schema, err = f.FrontendPipelineRepository.GetComponentSchema(node.ComponentName)
+			if errors.Is(err, sql.ErrNoRows) {
+				nodeErr.Errors = []models.FieldError{{Reason: fmt.Sprintf("unknown component %q", node.ComponentName)}}
+				nodeErrors = append(nodeErrors, nodeErr)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to load schema for component %s: %w", node.ComponentName, err)
+			}
+			schemas[node.ComponentName] = schema
+		}
+
+		var config any = node.Config
+		if node.Config == nil {
+			config = map[string]any{}
+		}
+
+		fieldErrors, err := schemavalidator.ValidateJSON(schema, config)
+		if err != nil {
+			return fmt.Errorf("failed to validate config of node %s: %w", node.Name, err)
+		}
+		fieldErrors = append(fieldErrors, f.checkSecretReferences(node)...)
+		fieldErrors = append(fieldErrors, unsupportedComponentErrors(node, unsupported)...)
+		if len(fieldErrors) > 0 {
+			nodeErr.Errors = fieldErrors
+			nodeErrors = append(nodeErrors, nodeErr)
+		}
+	}
+
+	if len(nodeErrors) > 0 {
+		return &models.GraphValidationError{Nodes: nodeErrors}
+	}
+	return nil
+}
+
+// GetAgentComponents returns the collector components the agent reported at
+// registration. It's empty for agents that don't report them.
+func (f *FrontendPipelineService) GetAgentComponents(agentId int) ([]models.AgentComponent, error) {
+	if _, err := f.FrontendPipelineRepository.GetAgentInfo(agentId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.ErrAgentDoesNotExists
+		}
+		return nil, err
+	}
+
+	return f.FrontendPipelineRepository.GetAgentComponents(agentId)
+}
+
+// checkAgentSupport reports the graph's components that any of the agents
+// can't run as a *models.GraphValidationError.
+func (f *FrontendPipelineService) checkAgentSupport(graph models.PipelineGraph, agentIds []int) error {
+	unsupported, err := f.unsupportedComponents(graph, agentIds)
 	if err != nil {
 		return err
 	}
 
-	return f.sendConfigToAgents(attachedAgent, pipelineGraph)
+	var nodeErrors []models.NodeValidationError
+	for _, node := range graph.Nodes {
+		if fieldErrors := unsupportedComponentErrors(node, unsupported); len(fieldErrors) > 0 {
+			nodeErrors = append(nodeErrors, models.NodeValidationError{
+				ComponentID:   node.ComponentID,
+				Name:          node.Name,
+				ComponentName: node.ComponentName,
+				Errors:        fieldErrors,
+			})
+		}
+	}
+
+	if len(nodeErrors) > 0 {
+		return &models.GraphValidationError{Nodes: nodeErrors}
+	}
+	return nil
+}
+
+// unsupportedComponents maps each component of the graph that some of the
+// agents don't have to the IDs of those agents. Agents that never reported
+// their components are assumed to support everything.
+func (f *FrontendPipelineService) unsupportedComponents(graph models.PipelineGraph, agentIds []int) (map[string][]int, error) {
+	if len(agentIds) == 0 || len(graph.Nodes) == 0 {
+		return nil, nil
+	}
+
+	supported, err := f.FrontendPipelineRepository.GetAgentComponentNames(agentIds)
+	if err != nil {
+		return nil, err
+	}
+
+	unsupported := make(map[string][]int)
+	for _, agentId := range agentIds {
+		names, reported := supported[agentId]
+		if !reported {
+			continue
+		}
+		for _, node := range graph.Nodes {
+			if names[node.ComponentName] || slices.Contains(unsupported[node.ComponentName], agentId) {
+				continue
+			}
+			unsupported[node.ComponentName] = append(unsupported[node.ComponentName], agentId)
+		}
+	}
+	return unsupported, nil
+}
+
+func unsupportedComponentErrors(node models.PipelineNodes, unsupported map[string][]int) []models.FieldError {
+	agentIds := unsupported[node.ComponentName]
+	if len(agentIds) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(agentIds))
+	for i, id := range agentIds {
+		ids[i] = strconv.Itoa(id)
+	}
+	noun := "agent"
+	if len(ids) > 1 {
+		noun = "agents"
+	}
+	return []models.FieldError{{Reason: fmt.Sprintf("component %q isn't built into %s %s", node.ComponentName, noun, strings.Join(ids, ", "))}}
 }
 
+// checkSecretReferences reports references to secrets that can't be resolved.
+// When secrets resolve on the agent the store isn't involved, so nothing is
+// checked.
+func (f *FrontendPipelineService) checkSecretReferences(node models.PipelineNodes) []models.FieldError {
+	if constants.SECRETS_RESOLUTION == secrets.ResolveOnAgent {
+		return nil
+	}
+
+	var fieldErrors []models.FieldError
+	for _, name := range secrets.References(models.PipelineGraph{Nodes: []models.PipelineNodes{node}}) {
+		if f.SecretResolver == nil {
+			fieldErrors = append(fieldErrors, models.FieldError{Reason: fmt.Sprintf("secret %q can't be resolved: the secrets store is disabled", name)})
+			continue
+		}
+		if _, err := f.SecretResolver.ResolveSecret(name); err != nil {
+			reason := fmt.Sprintf("secret %q can't be resolved: %v", name, err)
+			if errors.Is(err, utils.ErrSecretDoesNotExists) {
+				reason = fmt.Sprintf("unknown secret %q", name)
+			}
+			fieldErrors = append(fieldErrors, models.FieldError{Reason: reason})
+		}
+	}
+	return fieldErrors
+}
+
+// SetPipelineSelector stores the pipeline's label selector in canonical form
+// and attaches every matching agent that isn't on a pipeline yet. Agents
+// already on a pipeline are never moved, and clearing or narrowing the
+// selector doesn't detach anyone.
+func (f *FrontendPipelineService) SetPipelineSelector(pipelineId int, selector string) (*models.PipelineSelectorStatus, error) {
+	if !f.FrontendPipelineRepository.PipelineExists(pipelineId) {
+		return nil, utils.ErrPipelineDoesNotExists
+	}
+
+	parsed, err := labelselector.Parse(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.FrontendPipelineRepository.SetPipelineSelector(pipelineId, parsed.String()); err != nil {
+		return nil, err
+	}
+
+	status, err := f.GetSelectorStatus(pipelineId)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, match := range status.Agents {
+		if !match.Matched || match.PipelineID != nil {
+			continue
+		}
+		// The config is pushed by a job, so an error means the agent wasn't attached
+		if _, err := f.AttachAgentToPipeline(pipelineId, int(match.AgentID)); err != nil {
+			utils.Logger.Sugar().Warnf("Not attaching agent [ID:%v] to pipeline [ID:%v] by selector: %v", match.AgentID, pipelineId, err)
+			status.Agents[i].Error = err.Error()
+			continue
+		}
+		status.Agents[i].Attached = true
+		status.Agents[i].PipelineID = &pipelineId
+		status.Attached = append(status.Attached, match.AgentID)
+	}
+
+	return status, nil
+}
+
+// GetSelectorStatus evaluates the pipeline's label selector against every
+// agent and explains why each one does or doesn't match.
+func (f *FrontendPipelineService) GetSelectorStatus(pipelineId int) (*models.PipelineSelectorStatus, error) {
+	info, err := f.GetPipelineInfo(pipelineId)
+	if err != nil {
+		return nil, err
+	}
+
+	selector, err := labelselector.Parse(info.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	agents, err := f.FrontendPipelineRepository.GetAgentsWithLabels(0)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.PipelineSelectorStatus{
+		Selector: selector.String(),
+		Agents:   make([]models.SelectorMatch, 0, len(agents)),
+	}
+	for _, agent := range agents {
+		matched, requirements := selector.Explain(agent.Labels)
+		status.Agents = append(status.Agents, models.SelectorMatch{
+			AgentID:      agent.ID,
+			AgentName:    agent.Name,
+			Labels:       agent.Labels,
+			Matched:      matched,
+			Attached:     agent.PipelineID != nil && *agent.PipelineID == pipelineId,
+			PipelineID:   agent.PipelineID,
+			Requirements: requirements,
+		})
+	}
+	return status, nil
+}
+
+// AssignAgentBySelector attaches an agent that isn't on a pipeline to the
+// oldest pipeline whose label selector matches its labels and whose components
+// the agent has. It returns the
+// pipeline ID, or nil when the agent is already assigned or nothing matches.
+// The config isn't pushed; callers send it through registration or SyncConfig.
+func (f *FrontendPipelineService) AssignAgentBySelector(agentId int) (*int, error) {
+	agents, err := f.FrontendPipelineRepository.GetAgentsWithLabels(agentId)
+	if err != nil {
+		return nil, err
+	}
+	if len(agents) == 0 || agents[0].PipelineID != nil {
+		return nil, nil
+	}
+
+	selectors, err := f.FrontendPipelineRepository.GetPipelineSelectors()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range selectors {
+		selector, err := labelselector.Parse(candidate.Selector)
+		if err != nil {
+			utils.Logger.Sugar().Warnf("Skipping invalid label selector of pipeline [ID:%v]: %v", candidate.PipelineID, err)
+			continue
+		}
+		if !selector.Matches(agents[0].Labels) {
+			continue
+		}
+		graph, err := f.FrontendPipelineRepository.GetPipelineGraph(candidate.PipelineID)
+		if err != nil {
+			return nil, err
+		}
+		if err := f.checkAgentSupport(*graph, []int{agentId}); err != nil {
+			var validationErr *models.GraphValidationError
+			if !errors.As(err, &validationErr) {
+				return nil, err
+			}
+			utils.Logger.Sugar().Warnf("Skipping pipeline [ID:%v] for agent [ID:%v]: %v", candidate.PipelineID, agentId, err)
+			continue
+		}
+		if err := f.FrontendPipelineRepository.AttachAgentToPipeline(candidate.PipelineID, agentId); err != nil {
+			return nil, err
+		}
+		pipelineId := candidate.PipelineID
+		return &pipelineId, nil
+	}
+	return nil, nil
+}
+
+// GetPipelineConfig compiles the pipeline's graph into the collector config
+// sent to agents, with secret references resolved.
+func (f *FrontendPipelineService) GetPipelineConfig(pipelineId int) (map[string]any, error) {
+	graph, err := f.GetPipelineGraph(pipelineId)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := f.resolveSecrets(*graph)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := configcompiler.CompileGraphToJSON(resolved)
+	if err != nil {
+		return nil, err
+	}
+	return *config, nil
+}
+
+// SyncConfig starts a job pushing the config of the agent's pipeline to it.
 func (f *FrontendPipelineService) SyncConfig(agentId string) error {
 	pipelineId, err := f.FrontendPipelineRepository.GetAgentPipelineId(agentId)
 	if err != nil {
@@ -172,33 +833,163 @@ func (f *FrontendPipelineService) SyncConfig(agentId string) error {
 		return fmt.Errorf("error converting agent ID to int: %v", err)
 	}
 
+	if _, _, err := f.compileConfig(*graph); err != nil {
+		return err
+	}
+
+	_, err = f.enqueueConfigPushes(*pipelineId, []int{agentIDInt})
+	return err
+}
+
+// enqueueConfigPushes starts one config push job per agent and returns the
+// job IDs.
+func (f *FrontendPipelineService) enqueueConfigPushes(pipelineId int, agentIds []int) ([]string, error) {
+	jobIds := make([]string, 0, len(agentIds))
+	for _, agentId := range agentIds {
+		job, err := f.Jobs.Enqueue(configPushJobType, []string{strconv.Itoa(agentId)}, configPushParams{PipelineID: pipelineId})
+		if err != nil {
+			return jobIds, fmt.Errorf("failed to start config push to agent [ID:%v]: %w", agentId, err)
+		}
+		jobIds = append(jobIds, job.ID)
+	}
+	return jobIds, nil
+}
+
+// runConfigPushTarget is the job executor of config pushes. It sends the
+// pipeline's current config, so a job resumed after a restart doesn't push
+// a stale one. Agents or pipelines deleted since the job started and
+// configs that can't be built or that the agent rejects fail without
+// retries.
+func (f *FrontendPipelineService) runConfigPushTarget(_, agentId string, params json.RawMessage) error {
+	var push configPushParams
+	if err := json.Unmarshal(params, &push); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid config push params: %w", err))
+	}
+	agentIDInt, err := strconv.Atoi(agentId)
+	if err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid agent ID %q", agentId))
+	}
+
 	agent, err := f.FrontendPipelineRepository.GetAgentInfo(agentIDInt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return jobs.Permanent(utils.ErrAgentDoesNotExists)
+	}
 	if err != nil {
 		return err
 	}
 
-	var agents []models.AgentInfoHome
-	agents = append(agents, *agent)
+	graph, err := f.GetPipelineGraph(push.PipelineID)
+	if errors.Is(err, utils.ErrPipelineDoesNotExists) {
+		return jobs.Permanent(err)
+	}
+	if err != nil {
+		return err
+	}
+
+	version, jsonData, err := f.compileConfig(*graph)
+	if err != nil {
+		return jobs.Permanent(err)
+	}
 
-	return f.sendConfigToAgents(agents, *graph)
+	err = f.deliverConfig(push.PipelineID, *agent, version, jsonData)
+	if errors.Is(err, utils.ErrInvalidConfig) {
+		return jobs.Permanent(err)
+	}
+	return err
 }
 
-func (f *FrontendPipelineService) sendConfigToAgents(agents []models.AgentInfoHome, pipelineGraph models.PipelineGraph) error {
-	config, err := configcompiler.CompileGraphToJSON(pipelineGraph)
+// RedeliverConfig resends the config of the agent's pipeline right away if
+// the last delivery to the agent is still pending, e.g. once it reconnects.
+func (f *FrontendPipelineService) RedeliverConfig(agentId string) error {
+	agentIDInt, err := strconv.Atoi(agentId)
+	if err != nil {
+		return fmt.Errorf("error converting agent ID to int: %v", err)
+	}
+
+	delivery, err := f.FrontendPipelineRepository.GetConfigDelivery(agentIDInt)
 	if err != nil {
 		return err
 	}
+	if delivery == nil || delivery.Status != models.ConfigDeliveryPending {
+		return nil
+	}
+	return f.redeliverConfig(*delivery)
+}
 
-	jsonData, err := json.Marshal(config)
+// RetryConfigDeliveries resends the config of the pending deliveries whose
+// retry is due, the most overdue first. Up to constants.CONFIG_RETRY_BATCH are
+// sent per call, constants.BULK_CONCURRENCY at a time, so agents that time out
+// don't hold up the others; the rest wait for the next call.
+func (f *FrontendPipelineService) RetryConfigDeliveries() error {
+	deliveries, err := f.FrontendPipelineRepository.GetDueConfigDeliveries(utils.GetCurrentTime())
+	if err != nil {
+		return err
+	}
+	if len(deliveries) > constants.CONFIG_RETRY_BATCH {
+		deliveries = deliveries[:constants.CONFIG_RETRY_BATCH]
+	}
+
+	pending := make(chan models.ConfigDelivery)
+	var wg sync.WaitGroup
+	for w := 0; w < max(1, min(constants.BULK_CONCURRENCY, len(deliveries))); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for delivery := range pending {
+				if err := f.redeliverConfig(delivery); err != nil {
+					utils.Logger.Sugar().Warnf("Retry %d of config delivery to agent [ID:%v] failed: %v", delivery.Attempts, delivery.AgentID, err)
+				}
+			}
+		}()
+	}
+	for _, delivery := range deliveries {
+		pending <- delivery
+	}
+	close(pending)
+	wg.Wait()
+	return nil
+}
+
+// redeliverConfig sends the current config of the delivery's pipeline, so an
+// agent that missed several updates gets the latest one.
+func (f *FrontendPipelineService) redeliverConfig(delivery models.ConfigDelivery) error {
+	agent, err := f.FrontendPipelineRepository.GetAgentInfo(delivery.AgentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return f.FrontendPipelineRepository.DeleteConfigDelivery(delivery.AgentID)
+	}
+	if err != nil {
+		return err
+	}
+
+	graph, err := f.GetPipelineGraph(delivery.PipelineID)
+	if errors.Is(err, utils.ErrPipelineDoesNotExists) {
+		return f.FrontendPipelineRepository.DeleteConfigDelivery(delivery.AgentID)
+	}
+	if err == nil {
+		err = f.sendConfigToAgents(delivery.PipelineID, []models.AgentInfoHome{*agent}, *graph)
+	}
+	if err != nil && !errors.Is(err, errConfigNotSent) {
+		// The config couldn't be built, so nothing recorded the attempt
+		f.saveConfigDelivery(failedDelivery(delivery, err))
+	}
+	return err
+}
+
+// errConfigNotSent wraps the errors of agents that didn't take the config;
+// their deliveries already record the failure.
+var errConfigNotSent = errors.New("config not delivered")
+
+func (f *FrontendPipelineService) sendConfigToAgents(pipelineId int, agents []models.AgentInfoHome, pipelineGraph models.PipelineGraph) error {
+	version, jsonData, err := f.compileConfig(pipelineGraph)
 	if err != nil {
-		return fmt.Errorf("error marshaling config: %v", err)
+		return err
 	}
 
 	var failedAgents []string
 	var lastErr error
 
 	for _, agent := range agents {
-		if err := f.sendConfigToSingleAgent(agent, jsonData); err != nil {
+		if err := f.deliverConfig(pipelineId, agent, version, jsonData); err != nil {
 			failedAgents = append(failedAgents, fmt.Sprintf("Agent[ID:%v]", agent.ID))
 			lastErr = err
 			utils.Logger.Sugar().Errorf("Failed to send config to agent [ID:%v]: %v", agent.ID, err)
@@ -206,46 +997,133 @@ func (f *FrontendPipelineService) sendConfigToAgents(agents []models.AgentInfoHo
 	}
 
 	if len(failedAgents) == len(agents) {
-		return fmt.Errorf("failed to send configuration to all %d agent(s): last error: %v", len(agents), lastErr)
+		return fmt.Errorf("%w: failed to send configuration to all %d agent(s): last error: %v", errConfigNotSent, len(agents), lastErr)
 	} else if len(failedAgents) > 0 {
-		return fmt.Errorf("partial failure: configuration failed for %d out of %d agent(s) [%v]; last error: %v",
-			len(failedAgents), len(agents), failedAgents, lastErr)
+		return fmt.Errorf("%w: partial failure: configuration failed for %d out of %d agent(s) [%v]; last error: %v",
+			errConfigNotSent, len(failedAgents), len(agents), failedAgents, lastErr)
 	}
 
 	return nil
 }
 
-func (f *FrontendPipelineService) sendConfigToSingleAgent(agent models.AgentInfoHome, jsonData []byte) error {
-	// create a client with 10s timeout
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+// compileConfig builds the config agents are sent for the graph and returns
+// its version along with the encoded config.
+func (f *FrontendPipelineService) compileConfig(pipelineGraph models.PipelineGraph) (string, []byte, error) {
+	pipelineGraph, err := f.resolveSecrets(pipelineGraph)
+	if err != nil {
+		return "", nil, err
 	}
 
-	trySend := func(endpoint string) error {
-		url := fmt.Sprintf("http://%s:3421/agent/v1/config", endpoint)
-		resp, err := client.Post(url, "application/json", bytes.NewBuffer(jsonData))
-		if err != nil {
-			return err
-		}
-		defer resp.Body.Close()
+	config, err := configcompiler.CompileGraphToJSON(pipelineGraph)
+	if err != nil {
+		return "", nil, err
+	}
 
-		switch {
-		case resp.StatusCode >= 200 && resp.StatusCode < 300:
-			return nil
-		case resp.StatusCode == 500:
-			return utils.ErrInvalidConfig
-		default:
-			return fmt.Errorf("request failed with status %d", resp.StatusCode)
-		}
+	jsonData, err := json.Marshal(config)
+	if err != nil {
+		return "", nil, fmt.Errorf("error marshaling config: %v", err)
+	}
+	// Versions are hashed like agents hash the config they apply
+	version, err := utils.EffectiveConfigHash(*config)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to hash config: %w", err)
+	}
+	return version, jsonData, nil
+}
+
+// deliverConfig sends the config to one agent through the delivery outbox.
+// The delivery is recorded as pending before sending, so a backend that stops
+// mid-send retries it later. Failed sends are retried with exponential
+// backoff; configs the agent rejects as invalid aren't.
+func (f *FrontendPipelineService) deliverConfig(pipelineId int, agent models.AgentInfoHome, version string, jsonData []byte) error {
+	delivery := models.ConfigDelivery{
+		AgentID:       int(agent.ID),
+		PipelineID:    pipelineId,
+		ConfigVersion: version,
+		Status:        models.ConfigDeliveryPending,
+	}
+	// Attempts only add up while the same config keeps failing
+	previous, err := f.FrontendPipelineRepository.GetConfigDelivery(delivery.AgentID)
+	if err != nil {
+		utils.Logger.Sugar().Errorf("Failed to read config delivery of agent [ID:%v]: %v", agent.ID, err)
+	} else if previous != nil && previous.ConfigVersion == version && previous.Status == models.ConfigDeliveryPending {
+		delivery.Attempts = previous.Attempts
+		delivery.LastError = previous.LastError
 	}
+	delivery.UpdatedAt = utils.GetCurrentTime()
+	delivery.NextAttemptAt = delivery.UpdatedAt + configRetryDelay(delivery.Attempts+1)
+	f.saveConfigDelivery(delivery)
 
-	// Try with hostname first
-	err := trySend(agent.Hostname)
-	if err == nil || errors.Is(err, utils.ErrInvalidConfig) {
+	sendErr := f.sendConfigToSingleAgent(agent, jsonData)
+	switch {
+	case sendErr == nil:
+		delivery.Attempts++
+		delivery.Status = models.ConfigDeliveryDelivered
+		delivery.LastError = ""
+		delivery.UpdatedAt = utils.GetCurrentTime()
+		delivery.NextAttemptAt = 0
+		delivery.DeliveredAt = delivery.UpdatedAt
+	case errors.Is(sendErr, utils.ErrInvalidConfig):
+		delivery.Attempts++
+		delivery.Status = models.ConfigDeliveryRejected
+		delivery.LastError = sendErr.Error()
+		delivery.UpdatedAt = utils.GetCurrentTime()
+		delivery.NextAttemptAt = 0
+	default:
+		delivery = failedDelivery(delivery, sendErr)
+	}
+	f.saveConfigDelivery(delivery)
+	return sendErr
+}
+
+// failedDelivery counts a failed attempt and schedules the next one.
+func failedDelivery(delivery models.ConfigDelivery, err error) models.ConfigDelivery {
+	delivery.Attempts++
+	delivery.Status = models.ConfigDeliveryPending
+	delivery.LastError = err.Error()
+	delivery.UpdatedAt = utils.GetCurrentTime()
+	delivery.NextAttemptAt = delivery.UpdatedAt + configRetryDelay(delivery.Attempts)
+	return delivery
+}
+
+// configRetryDelay is the wait in seconds after the given number of failed
+// attempts: constants.CONFIG_RETRY_BACKOFF_SEC, doubled per further attempt
+// up to constants.CONFIG_RETRY_MAX_BACKOFF_SEC.
+func configRetryDelay(attempts int) int64 {
+	delay := int64(constants.CONFIG_RETRY_BACKOFF_SEC)
+	for i := 1; i < attempts && delay < int64(constants.CONFIG_RETRY_MAX_BACKOFF_SEC); i++ {
+		delay *= 2
+	}
+	return min(delay, int64(constants.CONFIG_RETRY_MAX_BACKOFF_SEC))
+}
+
+func (f *FrontendPipelineService) saveConfigDelivery(delivery models.ConfigDelivery) {
+	if err := f.FrontendPipelineRepository.SaveConfigDelivery(delivery); err != nil {
+		utils.Logger.Sugar().Errorf("Failed to record config delivery to agent [ID:%v]: %v", delivery.AgentID, err)
+	}
+}
+
+// resolveSecrets prepares ${secret:name} references for agents according to
+// constants.SECRETS_RESOLUTION.
+func (f *FrontendPipelineService) resolveSecrets(graph models.PipelineGraph) (models.PipelineGraph, error) {
+	if constants.SECRETS_RESOLUTION == secrets.ResolveOnAgent {
+		return secrets.EnvGraph(graph), nil
+	}
+	return secrets.ResolveGraph(graph, f.SecretResolver)
+}
+
+func (f *FrontendPipelineService) sendConfigToSingleAgent(agent models.AgentInfoHome, jsonData []byte) error {
+	resp, err := agentclient.Do(agent, http.MethodPost, "/agent/v1/config", jsonData)
+	if err != nil {
 		return err
 	}
 
-	// Retry with IP if hostname failed due to network error
-	utils.Logger.Sugar().Warnf("Hostname failed for agent [ID:%v], retrying with IP: %v", agent.ID, err)
-	return trySend(agent.IP)
+	switch {
+	case resp.OK():
+		return nil
+	case resp.StatusCode == http.StatusInternalServerError:
+		return utils.ErrInvalidConfig
+	default:
+		return fmt.Errorf("request failed with status %d", resp.StatusCode)
+	}
 }
@@ -2,10 +2,16 @@ package frontendpipeline_test
 
 import (
 	"database/sql"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/constants"
+	database "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/db"
 	frontendpipeline "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/pipeline"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/jobs"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/labelselector"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -70,11 +76,152 @@ func (m *MockRepo) GetAgentPipelineId(agentId string) (*int, error) {
 	return args.Get(0).(*int), args.Error(1)
 }
 
+func (m *MockRepo) GetComponentSchema(componentName string) ([]byte, error) {
+	args := m.Called(componentName)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockRepo) GetComponentCatalog() (map[string]models.CatalogComponent, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]models.CatalogComponent), args.Error(1)
+}
+
+func (m *MockRepo) SetPipelineEnvironment(pipelineId int, environment string) error {
+	args := m.Called(pipelineId, environment)
+	return args.Error(0)
+}
+
+func (m *MockRepo) SetPipelineSelector(pipelineId int, selector string) error {
+	args := m.Called(pipelineId, selector)
+	return args.Error(0)
+}
+
+func (m *MockRepo) GetPipelineSelectors() ([]models.PipelineSelector, error) {
+	args := m.Called()
+	return args.Get(0).([]models.PipelineSelector), args.Error(1)
+}
+
+func (m *MockRepo) GetAgentsWithLabels(agentId int) ([]models.AgentLabels, error) {
+	args := m.Called(agentId)
+	return args.Get(0).([]models.AgentLabels), args.Error(1)
+}
+
+func (m *MockRepo) GetPipelineIdByEnvironment(name string, environment string) (*int, error) {
+	args := m.Called(name, environment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*int), args.Error(1)
+}
+
+func (m *MockRepo) GetConfigDelivery(agentId int) (*models.ConfigDelivery, error) {
+	args := m.Called(agentId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigDelivery), args.Error(1)
+}
+
+func (m *MockRepo) GetDueConfigDeliveries(now int64) ([]models.ConfigDelivery, error) {
+	args := m.Called(now)
+	return args.Get(0).([]models.ConfigDelivery), args.Error(1)
+}
+
+func (m *MockRepo) SaveConfigDelivery(delivery models.ConfigDelivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
+
+func (m *MockRepo) DeleteConfigDelivery(agentId int) error {
+	args := m.Called(agentId)
+	return args.Error(0)
+}
+
+func (m *MockRepo) SetPipelineDriftPolicy(pipelineId int, policy string) error {
+	args := m.Called(pipelineId, policy)
+	return args.Error(0)
+}
+
+func (m *MockRepo) GetConfigDrift(agentId int) (*models.ConfigDrift, error) {
+	args := m.Called(agentId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigDrift), args.Error(1)
+}
+
+func (m *MockRepo) GetPipelineConfigDrift(pipelineId int) ([]models.ConfigDrift, error) {
+	args := m.Called(pipelineId)
+	return args.Get(0).([]models.ConfigDrift), args.Error(1)
+}
+
+func (m *MockRepo) SaveConfigDrift(drift models.ConfigDrift) error {
+	args := m.Called(drift)
+	return args.Error(0)
+}
+
+func (m *MockRepo) GetPipelineConfigDeliveries(pipelineId int) ([]models.ConfigDelivery, error) {
+	args := m.Called(pipelineId)
+	return args.Get(0).([]models.ConfigDelivery), args.Error(1)
+}
+
+func (m *MockRepo) SaveConfigApply(apply models.ConfigApply) error {
+	args := m.Called(apply)
+	return args.Error(0)
+}
+
+func (m *MockRepo) GetConfigApplies(agentId int) ([]models.ConfigApply, error) {
+	args := m.Called(agentId)
+	return args.Get(0).([]models.ConfigApply), args.Error(1)
+}
+
+func (m *MockRepo) GetPipelineConfigApplies(pipelineId int) ([]models.ConfigApply, error) {
+	args := m.Called(pipelineId)
+	return args.Get(0).([]models.ConfigApply), args.Error(1)
+}
+
+func (m *MockRepo) GetAgentComponents(agentId int) ([]models.AgentComponent, error) {
+	args := m.Called(agentId)
+	return args.Get(0).([]models.AgentComponent), args.Error(1)
+}
+
+func (m *MockRepo) GetAgentComponentNames(agentIds []int) (map[int]map[string]bool, error) {
+	args := m.Called(agentIds)
+	return args.Get(0).(map[int]map[string]bool), args.Error(1)
+}
+
+// newJobManager returns a job manager storing its jobs in an in-memory
+// database, so config pushes run like they do in the backend.
+func newJobManager(t *testing.T) *jobs.Manager {
+	db, err := database.DBInit(":memory:")
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+
+	jobManager := jobs.NewManager(jobs.NewJobRepository(db))
+	jobManager.Backoff = time.Millisecond
+	jobManager.MaxAttempts = 1
+	return jobManager
+}
+
+func waitForJob(t *testing.T, service frontendpipeline.FrontendPipelineServiceInterface, id string) *models.Job {
+	t.Helper()
+	var job *models.Job
+	assert.Eventually(t, func() bool {
+		job, _ = service.(*frontendpipeline.FrontendPipelineService).Jobs.GetJob(id)
+		return job != nil && job.Status != models.JobRunning
+	}, 5*time.Second, 5*time.Millisecond)
+	return job
+}
+
 // --- Tests ---
 
 func TestGetAllPipelines_Service(t *testing.T) {
 	mockRepo := new(MockRepo)
-	service := frontendpipeline.NewFrontendPipelineService(mockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
 
 	expected := []*frontendpipeline.Pipeline{{ID: 1, Name: "TestPipeline"}}
 	mockRepo.On("GetAllPipelines").Return(expected, nil)
@@ -86,7 +233,7 @@ func TestGetAllPipelines_Service(t *testing.T) {
 
 func TestGetPipelineInfo_Service_Exists(t *testing.T) {
 	mockRepo := new(MockRepo)
-	service := frontendpipeline.NewFrontendPipelineService(mockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
 
 	mockRepo.On("PipelineExists", 1).Return(true)
 	expected := &frontendpipeline.PipelineInfo{ID: 1, Name: "TestPipeline"}
@@ -99,7 +246,7 @@ func TestGetPipelineInfo_Service_Exists(t *testing.T) {
 
 func TestGetPipelineInfo_Service_NotExists(t *testing.T) {
 	mockRepo := new(MockRepo)
-	service := frontendpipeline.NewFrontendPipelineService(mockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
 
 	mockRepo.On("PipelineExists", 404).Return(false)
 
@@ -108,3 +255,766 @@ func TestGetPipelineInfo_Service_NotExists(t *testing.T) {
 	assert.Nil(t, info)
 	assert.Equal(t, utils.ErrPipelineDoesNotExists, err)
 }
+
+func TestCreatePipeline_Service_InvalidNodeConfig(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	mockRepo.On("GetComponentSchema", "debug_exporter").Return([]byte(`{
+		"type": "object",
+		"properties": {"verbosity": {"type": "string", "enum": ["basic", "normal", "detailed"]}}
+	}`), nil)
+	mockRepo.On("GetComponentSchema", "made_up_receiver").Return([]byte(nil), sql.ErrNoRows)
+
+	req := models.CreatePipelineRequest{
+		Name:      "invalid",
+		CreatedBy: "admin",
+		PipelineGraph: models.PipelineGraph{
+			Nodes: []models.PipelineNodes{
+				{ComponentID: 1, Name: "Debug", ComponentName: "debug_exporter", ComponentRole: "exporter", Config: map[string]any{"verbosity": "loud"}},
+				{ComponentID: 2, Name: "Mystery", ComponentName: "made_up_receiver", ComponentRole: "receiver"},
+			},
+		},
+	}
+
+	id, err := service.CreatePipeline(req)
+	assert.Empty(t, id)
+
+	var validationErr *models.GraphValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Len(t, validationErr.Nodes, 2)
+	assert.Equal(t, "/verbosity", validationErr.Nodes[0].Errors[0].Pointer)
+	assert.Contains(t, validationErr.Nodes[1].Errors[0].Reason, "unknown component")
+	mockRepo.AssertNotCalled(t, "CreatePipeline", mock.Anything)
+}
+
+func TestImportPipeline_Service(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	mockRepo.On("GetComponentCatalog").Return(map[string]models.CatalogComponent{
+		"otlp_receiver":  {Name: "otlp_receiver", Role: "receiver", SupportedSignals: []string{"traces", "metrics", "logs"}},
+		"debug_exporter": {Name: "debug_exporter", Role: "exporter", SupportedSignals: []string{"traces", "metrics", "logs"}},
+	}, nil)
+
+	response, err := service.ImportPipeline(map[string]any{
+		"receivers": map[string]any{"otlp": map[string]any{}},
+		"exporters": map[string]any{"debug": map[string]any{}},
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"logs": map[string]any{"receivers": []any{"otlp"}, "exporters": []any{"debug"}},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, response.PipelineGraph.Nodes, 2)
+	assert.Equal(t, []models.PipelineEdges{{Source: "1", Target: "2"}}, response.PipelineGraph.Edges)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPreviewPromotion_Service(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	targetId := 7
+	source := &models.PipelineGraph{
+		Nodes: []models.PipelineNodes{
+			{ComponentID: 1, Name: "OTLP", ComponentName: "otlp_receiver"},
+			{ComponentID: 2, Name: "Debug", ComponentName: "debug_exporter", Config: map[string]any{"verbosity": "detailed"}},
+		},
+		Edges: []models.PipelineEdges{{Source: "1", Target: "2"}},
+	}
+	target := &models.PipelineGraph{
+		Nodes: []models.PipelineNodes{
+			{ComponentID: 8, Name: "OTLP", ComponentName: "otlp_receiver"},
+			{ComponentID: 9, Name: "Debug", ComponentName: "debug_exporter", Config: map[string]any{"verbosity": "basic"}},
+		},
+		Edges: []models.PipelineEdges{{Source: "8", Target: "9"}},
+	}
+
+	mockRepo.On("PipelineExists", 1).Return(true)
+	mockRepo.On("GetPipelineInfo", 1).Return(&frontendpipeline.PipelineInfo{ID: 1, Name: "edge", Environment: "dev"}, nil)
+	mockRepo.On("GetPipelineGraph", 1).Return(source, nil)
+	mockRepo.On("GetPipelineIdByEnvironment", "edge", "staging").Return(&targetId, nil)
+	mockRepo.On("GetPipelineGraph", 7).Return(target, nil)
+
+	promotion, err := service.PreviewPromotion(1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "staging", promotion.TargetEnvironment)
+	assert.Equal(t, &targetId, promotion.TargetID)
+	assert.Empty(t, promotion.Diff.NodesAdded)
+	assert.Len(t, promotion.Diff.NodesChanged, 1)
+	assert.Equal(t, "Debug", promotion.Diff.NodesChanged[0].Name)
+	mockRepo.AssertNotCalled(t, "SyncPipelineGraph", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPromotePipeline_Service_Backwards(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	mockRepo.On("PipelineExists", 1).Return(true)
+	mockRepo.On("GetPipelineInfo", 1).Return(&frontendpipeline.PipelineInfo{ID: 1, Name: "edge", Environment: "prod"}, nil)
+
+	promotion, err := service.PromotePipeline(1, models.PromotePipelineRequest{To: "dev"})
+	assert.Nil(t, promotion)
+	assert.ErrorIs(t, err, utils.ErrInvalidPromotion)
+}
+
+func TestPromotePipeline_Service_CreatesTarget(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	source := &models.PipelineGraph{
+		Nodes: []models.PipelineNodes{{ComponentID: 1, Name: "OTLP", ComponentName: "otlp_receiver"}},
+		Edges: []models.PipelineEdges{},
+	}
+
+	mockRepo.On("PipelineExists", 1).Return(true)
+	mockRepo.On("GetPipelineInfo", 1).Return(&frontendpipeline.PipelineInfo{ID: 1, Name: "edge", Environment: "staging", CreatedBy: "admin"}, nil)
+	mockRepo.On("GetPipelineGraph", 1).Return(source, nil)
+	mockRepo.On("GetPipelineIdByEnvironment", "edge", "prod").Return(nil, nil)
+	mockRepo.On("GetComponentSchema", "otlp_receiver").Return([]byte(`{"type": "object"}`), nil)
+	mockRepo.On("CreatePipeline", models.CreatePipelineRequest{
+		Name:          "edge",
+		CreatedBy:     "admin",
+		Environment:   "prod",
+		PipelineGraph: *source,
+	}).Return("12", nil)
+
+	promotion, err := service.PromotePipeline(1, models.PromotePipelineRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, 12, *promotion.TargetID)
+	assert.Len(t, promotion.Diff.NodesAdded, 1)
+	mockRepo.AssertExpectations(t)
+}
+
+type mockSecretResolver map[string]string
+
+func (m mockSecretResolver) ResolveSecret(name string) (string, error) {
+	value, ok := m[name]
+	if !ok {
+		return "", utils.ErrSecretDoesNotExists
+	}
+	return value, nil
+}
+
+func TestCreatePipeline_Service_UnknownSecret(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, mockSecretResolver{"kafka.password": "hunter2"}, newJobManager(t))
+
+	mockRepo.On("GetComponentSchema", "kafka_exporter").Return([]byte(`{"type": "object"}`), nil)
+
+	req := models.CreatePipelineRequest{
+		Name:      "kafka",
+		CreatedBy: "admin",
+		PipelineGraph: models.PipelineGraph{
+			Nodes: []models.PipelineNodes{
+				{ComponentID: 1, Name: "Kafka", ComponentName: "kafka_exporter", ComponentRole: "exporter", Config: map[string]any{
+					"auth": map[string]any{"sasl": map[string]any{
+						"username": "${secret:kafka.username}",
+						"password": "${secret:kafka.password}",
+					}},
+				}},
+			},
+		},
+	}
+
+	_, err := service.CreatePipeline(req)
+
+	var validationErr *models.GraphValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Len(t, validationErr.Nodes[0].Errors, 1)
+	assert.Equal(t, `unknown secret "kafka.username"`, validationErr.Nodes[0].Errors[0].Reason)
+	mockRepo.AssertNotCalled(t, "CreatePipeline", mock.Anything)
+}
+
+func TestSetPipelineSelector_Service_Invalid(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	mockRepo.On("PipelineExists", 1).Return(true)
+
+	_, err := service.SetPipelineSelector(1, "region in (eu")
+	assert.ErrorIs(t, err, labelselector.ErrInvalidSelector)
+	mockRepo.AssertNotCalled(t, "SetPipelineSelector", mock.Anything, mock.Anything)
+}
+
+func TestSetPipelineSelector_Service_ExplainsAgents(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	otherPipeline := 2
+	mockRepo.On("PipelineExists", 1).Return(true)
+	mockRepo.On("SetPipelineSelector", 1, "env=prod").Return(nil)
+	mockRepo.On("GetPipelineInfo", 1).Return(&frontendpipeline.PipelineInfo{ID: 1, LabelSelector: "env=prod"}, nil)
+	mockRepo.On("GetAgentsWithLabels", 0).Return([]models.AgentLabels{
+		{ID: 1, Name: "a", PipelineID: &otherPipeline, Labels: map[string]string{"env": "prod"}},
+		{ID: 2, Name: "b", Labels: map[string]string{"env": "dev"}},
+	}, nil)
+
+	status, err := service.SetPipelineSelector(1, " env == prod ")
+	assert.NoError(t, err)
+	assert.Equal(t, "env=prod", status.Selector)
+	assert.Empty(t, status.Attached)
+	assert.True(t, status.Agents[0].Matched)
+	assert.False(t, status.Agents[0].Attached)
+	assert.False(t, status.Agents[1].Matched)
+	assert.Equal(t, "agent has env=dev", status.Agents[1].Requirements[0].Reason)
+	mockRepo.AssertNotCalled(t, "AttachAgentToPipeline", mock.Anything, mock.Anything)
+}
+
+func TestSetPipelineSelector_Service_ReportsFailedAttaches(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	prod := map[string]string{"env": "prod"}
+	mockRepo.On("PipelineExists", 1).Return(true)
+	mockRepo.On("SetPipelineSelector", 1, "env=prod").Return(nil)
+	mockRepo.On("GetPipelineInfo", 1).Return(&frontendpipeline.PipelineInfo{ID: 1, LabelSelector: "env=prod"}, nil)
+	mockRepo.On("GetAgentsWithLabels", 0).Return([]models.AgentLabels{
+		{ID: 1, Name: "a", Labels: prod},
+		{ID: 2, Name: "b", Labels: prod},
+		{ID: 3, Name: "c", Labels: prod},
+	}, nil)
+	mockRepo.On("GetPipelineGraph", 1).Return(otlpToDebugGraph(), nil)
+	mockRepo.On("GetAgentComponentNames", []int{1}).Return(map[int]map[string]bool{}, nil)
+	mockRepo.On("GetAgentComponentNames", []int{2}).Return(map[int]map[string]bool{2: {"otlp_receiver": true}}, nil)
+	mockRepo.On("GetAgentComponentNames", []int{3}).Return(map[int]map[string]bool{}, nil)
+	// Agent 1 fails to attach and agent 2 can't run the graph; agent 3 is
+	// attached even though nothing listens on its port, as pushes run as jobs
+	mockRepo.On("AttachAgentToPipeline", 1, 1).Return(errors.New("database is locked"))
+	mockRepo.On("AttachAgentToPipeline", 1, 3).Return(nil)
+	mockRepo.On("GetAgentInfo", 3).Return(&models.AgentInfoHome{ID: 3, Hostname: "127.0.0.1", IP: "127.0.0.1"}, nil)
+	mockRepo.On("GetConfigDelivery", 3).Return(nil, nil)
+	mockRepo.On("SaveConfigDelivery", mock.Anything).Return(nil)
+
+	status, err := service.SetPipelineSelector(1, "env=prod")
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3}, status.Attached)
+	assert.False(t, status.Agents[0].Attached)
+	assert.Contains(t, status.Agents[0].Error, "database is locked")
+	assert.False(t, status.Agents[1].Attached)
+	assert.Contains(t, status.Agents[1].Error, "isn't built into agent 2")
+	assert.True(t, status.Agents[2].Attached)
+	assert.Empty(t, status.Agents[2].Error)
+}
+
+func TestAssignAgentBySelector_Service(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	mockRepo.On("GetAgentsWithLabels", 5).Return([]models.AgentLabels{
+		{ID: 5, Labels: map[string]string{"env": "prod", "region": "eu"}},
+	}, nil)
+	mockRepo.On("GetPipelineSelectors").Return([]models.PipelineSelector{
+		{PipelineID: 3, Selector: "env=dev"},
+		{PipelineID: 4, Selector: "env=prod,region in (eu,us)"},
+		{PipelineID: 6, Selector: "env=prod"},
+	}, nil)
+	mockRepo.On("GetPipelineGraph", 4).Return(otlpToDebugGraph(), nil)
+	mockRepo.On("GetAgentComponentNames", []int{5}).Return(map[int]map[string]bool{}, nil)
+	mockRepo.On("AttachAgentToPipeline", 4, 5).Return(nil)
+
+	pipelineId, err := service.AssignAgentBySelector(5)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, *pipelineId)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAssignAgentBySelector_Service_SkipsUnsupportedPipeline(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	mockRepo.On("GetAgentsWithLabels", 5).Return([]models.AgentLabels{
+		{ID: 5, Labels: map[string]string{"env": "prod"}},
+	}, nil)
+	mockRepo.On("GetPipelineSelectors").Return([]models.PipelineSelector{
+		{PipelineID: 4, Selector: "env=prod"},
+		{PipelineID: 6, Selector: "env=prod"},
+	}, nil)
+	kafkaGraph := otlpToDebugGraph()
+	kafkaGraph.Nodes[1].ComponentName = "kafka_exporter"
+	mockRepo.On("GetPipelineGraph", 4).Return(kafkaGraph, nil)
+	mockRepo.On("GetPipelineGraph", 6).Return(otlpToDebugGraph(), nil)
+	mockRepo.On("GetAgentComponentNames", []int{5}).Return(map[int]map[string]bool{
+		5: {"otlp_receiver": true, "debug_exporter": true},
+	}, nil)
+	mockRepo.On("AttachAgentToPipeline", 6, 5).Return(nil)
+
+	pipelineId, err := service.AssignAgentBySelector(5)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, *pipelineId)
+	mockRepo.AssertNotCalled(t, "AttachAgentToPipeline", 4, 5)
+}
+
+func TestAssignAgentBySelector_Service_AlreadyAssigned(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	current := 1
+	mockRepo.On("GetAgentsWithLabels", 5).Return([]models.AgentLabels{
+		{ID: 5, PipelineID: &current, Labels: map[string]string{"env": "prod"}},
+	}, nil)
+
+	pipelineId, err := service.AssignAgentBySelector(5)
+	assert.NoError(t, err)
+	assert.Nil(t, pipelineId)
+	mockRepo.AssertNotCalled(t, "GetPipelineSelectors")
+}
+
+func TestAttachAgentToPipeline_Service_RecordsFailedDelivery(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	// Nothing listens on the agent port, so the push fails
+	agent := &models.AgentInfoHome{ID: 5, Hostname: "127.0.0.1", IP: "127.0.0.1"}
+	mockRepo.On("PipelineExists", 4).Return(true)
+	mockRepo.On("AttachAgentToPipeline", 4, 5).Return(nil)
+	mockRepo.On("GetPipelineGraph", 4).Return(&models.PipelineGraph{
+		Nodes: []models.PipelineNodes{
+			{ComponentID: 1, Name: "OTLP", ComponentName: "otlp_receiver", ComponentRole: "receiver", SupportedSignals: []string{"logs"}},
+			{ComponentID: 2, Name: "Debug", ComponentName: "debug_exporter", ComponentRole: "exporter", SupportedSignals: []string{"logs"}},
+		},
+		Edges: []models.PipelineEdges{{Source: "1", Target: "2"}},
+	}, nil)
+	mockRepo.On("GetAgentComponentNames", []int{5}).Return(map[int]map[string]bool{}, nil)
+	mockRepo.On("GetAgentInfo", 5).Return(agent, nil)
+	mockRepo.On("GetConfigDelivery", 5).Return(nil, nil)
+	mockRepo.On("SaveConfigDelivery", mock.Anything).Return(nil)
+
+	jobIds, err := service.AttachAgentToPipeline(4, 5)
+	assert.NoError(t, err)
+	assert.Len(t, jobIds, 1)
+	job := waitForJob(t, service, jobIds[0])
+	assert.Equal(t, models.JobFailed, job.Status)
+	assert.Equal(t, "5", job.Results[0].Target)
+
+	var saves []mock.Call
+	for _, call := range mockRepo.Calls {
+		if call.Method == "SaveConfigDelivery" {
+			saves = append(saves, call)
+		}
+	}
+	assert.Len(t, saves, 2)
+	recorded := saves[0].Arguments.Get(0).(models.ConfigDelivery)
+	assert.Equal(t, models.ConfigDeliveryPending, recorded.Status)
+	assert.Equal(t, 0, recorded.Attempts)
+
+	failed := saves[1].Arguments.Get(0).(models.ConfigDelivery)
+	assert.Equal(t, 4, failed.PipelineID)
+	assert.Equal(t, recorded.ConfigVersion, failed.ConfigVersion)
+	assert.Equal(t, models.ConfigDeliveryPending, failed.Status)
+	assert.Equal(t, 1, failed.Attempts)
+	assert.NotEmpty(t, failed.LastError)
+	assert.Equal(t, failed.UpdatedAt+5, failed.NextAttemptAt)
+}
+
+func TestAttachAgentToPipeline_Service_UnsupportedComponent(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	mockRepo.On("PipelineExists", 4).Return(true)
+	mockRepo.On("GetPipelineGraph", 4).Return(otlpToDebugGraph(), nil)
+	mockRepo.On("GetAgentComponentNames", []int{5}).Return(map[int]map[string]bool{
+		5: {"otlp_receiver": true, "otlphttp_exporter": true},
+	}, nil)
+
+	_, err := service.AttachAgentToPipeline(4, 5)
+	var validationErr *models.GraphValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Len(t, validationErr.Nodes, 1)
+	assert.Equal(t, "debug_exporter", validationErr.Nodes[0].ComponentName)
+	assert.Equal(t, `component "debug_exporter" isn't built into agent 5`, validationErr.Nodes[0].Errors[0].Reason)
+	mockRepo.AssertNotCalled(t, "AttachAgentToPipeline", mock.Anything, mock.Anything)
+}
+
+func TestSyncPipelineGraph_Service_UnsupportedComponent(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	graph := otlpToDebugGraph()
+	mockRepo.On("PipelineExists", 4).Return(true)
+	mockRepo.On("GetPipelineGraph", 4).Return(otlpToDebugGraph(), nil)
+	mockRepo.On("GetAllAgentsAttachedToPipeline", 4).Return([]models.AgentInfoHome{{ID: 5}, {ID: 6}, {ID: 7}}, nil)
+	// Agent 7 never reported its components, so it isn't checked
+	mockRepo.On("GetAgentComponentNames", []int{5, 6, 7}).Return(map[int]map[string]bool{
+		5: {"otlp_receiver": true},
+		6: {"otlp_receiver": true},
+	}, nil)
+	mockRepo.On("GetComponentSchema", mock.Anything).Return([]byte(`{"type": "object"}`), nil)
+
+	_, err := service.SyncPipelineGraph(4, *graph)
+	var validationErr *models.GraphValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Len(t, validationErr.Nodes, 1)
+	assert.Equal(t, `component "debug_exporter" isn't built into agents 5, 6`, validationErr.Nodes[0].Errors[0].Reason)
+	mockRepo.AssertNotCalled(t, "SyncPipelineGraph", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSyncPipelineGraph_Service_PushesThroughJobs(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	graph := otlpToDebugGraph()
+	mockRepo.On("PipelineExists", 4).Return(true)
+	mockRepo.On("GetPipelineGraph", 4).Return(otlpToDebugGraph(), nil)
+	mockRepo.On("GetAllAgentsAttachedToPipeline", 4).Return([]models.AgentInfoHome{{ID: 5}, {ID: 6}}, nil)
+	mockRepo.On("GetAgentComponentNames", []int{5, 6}).Return(map[int]map[string]bool{}, nil)
+	mockRepo.On("GetComponentSchema", mock.Anything).Return([]byte(`{"type": "object"}`), nil)
+	mockRepo.On("SyncPipelineGraph", mock.Anything, 4, mock.Anything).Return(nil)
+	// Agent 6 was deleted before its push ran
+	mockRepo.On("GetAgentInfo", 5).Return(&models.AgentInfoHome{ID: 5, Hostname: "127.0.0.1", IP: "127.0.0.1"}, nil)
+	mockRepo.On("GetAgentInfo", 6).Return((*models.AgentInfoHome)(nil), sql.ErrNoRows)
+	mockRepo.On("GetConfigDelivery", 5).Return(nil, nil)
+	mockRepo.On("SaveConfigDelivery", mock.Anything).Return(nil)
+
+	jobIds, err := service.SyncPipelineGraph(4, *graph)
+	assert.NoError(t, err)
+	assert.Len(t, jobIds, 2)
+
+	for i, target := range []string{"5", "6"} {
+		job := waitForJob(t, service, jobIds[i])
+		assert.Equal(t, "pipeline.config_push", job.Type)
+		assert.Equal(t, target, job.Results[0].Target)
+		assert.Equal(t, models.JobFailed, job.Status)
+	}
+	mockRepo.AssertCalled(t, "SaveConfigDelivery", mock.Anything)
+	mockRepo.AssertNotCalled(t, "GetConfigDelivery", 6)
+}
+
+func TestCreatePipeline_Service_AgentWithoutComponents(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	req := models.CreatePipelineRequest{Name: "p", AgentIDs: []int{5}, PipelineGraph: *otlpToDebugGraph()}
+	mockRepo.On("GetAgentComponentNames", []int{5}).Return(map[int]map[string]bool{}, nil)
+	mockRepo.On("GetComponentSchema", mock.Anything).Return([]byte(`{"type": "object"}`), nil)
+	mockRepo.On("CreatePipeline", req).Return("1", nil)
+
+	id, err := service.CreatePipeline(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", id)
+}
+
+func TestRetryConfigDeliveries_Service_DropsDeletedPipeline(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	mockRepo.On("GetDueConfigDeliveries", mock.Anything).Return([]models.ConfigDelivery{
+		{AgentID: 5, PipelineID: 9, Status: models.ConfigDeliveryPending, Attempts: 2},
+	}, nil)
+	mockRepo.On("GetAgentInfo", 5).Return(&models.AgentInfoHome{ID: 5}, nil)
+	mockRepo.On("PipelineExists", 9).Return(false)
+	mockRepo.On("DeleteConfigDelivery", 5).Return(nil)
+
+	assert.NoError(t, service.RetryConfigDeliveries())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRedeliverConfig_Service_SkipsDelivered(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	mockRepo.On("GetConfigDelivery", 5).Return(&models.ConfigDelivery{AgentID: 5, PipelineID: 4, Status: models.ConfigDeliveryDelivered}, nil)
+
+	assert.NoError(t, service.RedeliverConfig("5"))
+	mockRepo.AssertNotCalled(t, "GetAgentInfo", mock.Anything)
+}
+
+func otlpToDebugGraph() *models.PipelineGraph {
+	return &models.PipelineGraph{
+		Nodes: []models.PipelineNodes{
+			{ComponentID: 1, Name: "OTLP", ComponentName: "otlp_receiver", ComponentRole: "receiver", SupportedSignals: []string{"logs"}},
+			{ComponentID: 2, Name: "Debug", ComponentName: "debug_exporter", ComponentRole: "exporter", SupportedSignals: []string{"logs"}},
+		},
+		Edges: []models.PipelineEdges{{Source: "1", Target: "2"}},
+	}
+}
+
+func TestReportEffectiveConfig_Service_InSync(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	pipelineId := 4
+	mockRepo.On("GetAgentPipelineId", "5").Return(&pipelineId, nil)
+	mockRepo.On("PipelineExists", 4).Return(true)
+	mockRepo.On("GetPipelineGraph", 4).Return(otlpToDebugGraph(), nil)
+	mockRepo.On("SaveConfigDrift", mock.Anything).Return(nil)
+
+	config, err := service.GetPipelineConfig(4)
+	assert.NoError(t, err)
+	hash, err := utils.EffectiveConfigHash(config)
+	assert.NoError(t, err)
+
+	drift, err := service.ReportEffectiveConfig("5", hash)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ConfigInSync, drift.Status)
+	assert.Equal(t, hash, drift.DesiredHash)
+	assert.Empty(t, drift.Action)
+	mockRepo.AssertCalled(t, "SaveConfigDrift", *drift)
+	mockRepo.AssertNotCalled(t, "GetPipelineInfo", mock.Anything)
+}
+
+func TestReportEffectiveConfig_Service_AlertOnly(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	pipelineId := 4
+	mockRepo.On("GetAgentPipelineId", "5").Return(&pipelineId, nil)
+	mockRepo.On("PipelineExists", 4).Return(true)
+	mockRepo.On("GetPipelineGraph", 4).Return(otlpToDebugGraph(), nil)
+	mockRepo.On("GetConfigDrift", 5).Return(&models.ConfigDrift{AgentID: 5, PipelineID: 4, Status: models.ConfigDrifted, DriftedSince: 100}, nil)
+	mockRepo.On("GetPipelineInfo", 4).Return(&frontendpipeline.PipelineInfo{ID: 4, DriftPolicy: models.DriftPolicyAlert}, nil)
+	mockRepo.On("GetAgentInfo", 5).Return(&models.AgentInfoHome{ID: 5, Hostname: "127.0.0.1", IP: "127.0.0.1"}, nil)
+	mockRepo.On("SaveConfigDrift", mock.Anything).Return(nil)
+
+	drift, err := service.ReportEffectiveConfig("5", "edited-locally")
+	assert.NoError(t, err)
+	assert.Equal(t, models.ConfigDrifted, drift.Status)
+	assert.Equal(t, models.DriftAlerted, drift.Action)
+	assert.Equal(t, "edited-locally", drift.EffectiveHash)
+	assert.Equal(t, int64(100), drift.DriftedSince)
+	mockRepo.AssertCalled(t, "SaveConfigDrift", *drift)
+	// Nothing is pushed to the agent
+	mockRepo.AssertNotCalled(t, "GetConfigDelivery", mock.Anything)
+}
+
+func TestReportEffectiveConfig_Service_NoPipeline(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	mockRepo.On("GetAgentPipelineId", "5").Return((*int)(nil), nil)
+
+	drift, err := service.ReportEffectiveConfig("5", "abc")
+	assert.NoError(t, err)
+	assert.Nil(t, drift)
+	mockRepo.AssertNotCalled(t, "SaveConfigDrift", mock.Anything)
+}
+
+func TestSetPipelineDriftPolicy_Service(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	mockRepo.On("PipelineExists", 4).Return(true)
+	mockRepo.On("SetPipelineDriftPolicy", 4, models.DriftPolicyAdopt).Return(nil)
+
+	assert.NoError(t, service.SetPipelineDriftPolicy(4, models.DriftPolicyAdopt))
+	assert.ErrorIs(t, service.SetPipelineDriftPolicy(4, "ignore"), utils.ErrInvalidDriftPolicy)
+	mockRepo.AssertNumberOfCalls(t, "SetPipelineDriftPolicy", 1)
+}
+
+func TestRecordConfigApply_Service(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	pipelineId := 4
+	mockRepo.On("GetAgentInfo", 5).Return(&models.AgentInfoHome{ID: 5}, nil)
+	mockRepo.On("GetAgentPipelineId", "5").Return(&pipelineId, nil)
+	mockRepo.On("SaveConfigApply", mock.Anything).Return(nil)
+
+	err := service.RecordConfigApply("5", models.ConfigApplyRequest{
+		ConfigHash: "abc123",
+		Status:     models.ConfigApplyFailed,
+		Error:      "port in use",
+	})
+	assert.NoError(t, err)
+
+	apply := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(0).(models.ConfigApply)
+	assert.Equal(t, 5, apply.AgentID)
+	assert.Equal(t, &pipelineId, apply.PipelineID)
+	assert.Equal(t, "abc123", apply.ConfigVersion)
+	assert.Equal(t, models.ConfigApplyFailed, apply.Status)
+	assert.Equal(t, "port in use", apply.Error)
+	assert.Equal(t, []models.ComponentStatus{}, apply.Components)
+	assert.NotZero(t, apply.ReportedAt)
+}
+
+func TestRecordConfigApply_Service_InvalidStatus(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	err := service.RecordConfigApply("5", models.ConfigApplyRequest{Status: "maybe"})
+	assert.ErrorIs(t, err, utils.ErrInvalidConfigApplyStatus)
+	mockRepo.AssertNotCalled(t, "SaveConfigApply", mock.Anything)
+}
+
+func TestGetPipelineRollout_Service(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	mockRepo.On("PipelineExists", 4).Return(true)
+	mockRepo.On("GetPipelineGraph", 4).Return(otlpToDebugGraph(), nil)
+
+	config, err := service.GetPipelineConfig(4)
+	assert.NoError(t, err)
+	version, err := utils.EffectiveConfigHash(config)
+	assert.NoError(t, err)
+
+	mockRepo.On("GetAllAgentsAttachedToPipeline", 4).Return([]models.AgentInfoHome{
+		{ID: 1, Name: "applied"}, {ID: 2, Name: "failed"}, {ID: 3, Name: "delivered"}, {ID: 4, Name: "outdated"}, {ID: 5, Name: "new"},
+		{ID: 6, Name: "rolled back"},
+	}, nil)
+	mockRepo.On("GetPipelineConfigDeliveries", 4).Return([]models.ConfigDelivery{
+		{AgentID: 1, ConfigVersion: version, Status: models.ConfigDeliveryDelivered},
+		{AgentID: 2, ConfigVersion: version, Status: models.ConfigDeliveryDelivered},
+		{AgentID: 3, ConfigVersion: version, Status: models.ConfigDeliveryDelivered},
+		{AgentID: 4, ConfigVersion: "old", Status: models.ConfigDeliveryDelivered},
+	}, nil)
+	mockRepo.On("GetPipelineConfigApplies", 4).Return([]models.ConfigApply{
+		{AgentID: 1, ConfigVersion: version, Status: models.ConfigApplied},
+		{AgentID: 2, ConfigVersion: version, Status: models.ConfigApplyFailed, Error: "port in use"},
+		{AgentID: 3, ConfigVersion: "old", Status: models.ConfigApplied},
+		{AgentID: 4, ConfigVersion: "old", Status: models.ConfigApplied},
+		{AgentID: 6, ConfigVersion: version, Status: models.ConfigApplyFailed, RolledBack: true},
+	}, nil)
+
+	rollout, err := service.GetPipelineRollout(4)
+	assert.NoError(t, err)
+	assert.Equal(t, version, rollout.ConfigVersion)
+
+	statuses := map[string]string{}
+	for _, agent := range rollout.Agents {
+		statuses[agent.Name] = agent.Status
+	}
+	assert.Equal(t, map[string]string{
+		"applied":     models.RolloutApplied,
+		"failed":      models.RolloutFailed,
+		"delivered":   models.RolloutDelivered,
+		"outdated":    models.RolloutOutdated,
+		"new":         models.RolloutOutdated,
+		"rolled back": models.RolloutRolledBack,
+	}, statuses)
+	assert.Equal(t, map[string]int{
+		models.RolloutApplied:    1,
+		models.RolloutFailed:     1,
+		models.RolloutDelivered:  1,
+		models.RolloutOutdated:   2,
+		models.RolloutRolledBack: 1,
+	}, rollout.Summary)
+}
+
+func TestReportEffectiveConfig_Service_SkipsRolledBackConfig(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	pipelineId := 4
+	mockRepo.On("GetAgentPipelineId", "5").Return(&pipelineId, nil)
+	mockRepo.On("PipelineExists", 4).Return(true)
+	mockRepo.On("GetPipelineGraph", 4).Return(otlpToDebugGraph(), nil)
+
+	config, err := service.GetPipelineConfig(4)
+	assert.NoError(t, err)
+	desiredHash, err := utils.EffectiveConfigHash(config)
+	assert.NoError(t, err)
+
+	mockRepo.On("GetConfigDrift", 5).Return(nil, nil)
+	mockRepo.On("GetPipelineInfo", 4).Return(&frontendpipeline.PipelineInfo{ID: 4, DriftPolicy: models.DriftPolicyAutoRemediate}, nil)
+	mockRepo.On("GetAgentInfo", 5).Return(&models.AgentInfoHome{ID: 5}, nil)
+	mockRepo.On("GetConfigApplies", 5).Return([]models.ConfigApply{
+		{AgentID: 5, ConfigVersion: desiredHash, Status: models.ConfigApplyFailed, RolledBack: true},
+	}, nil)
+	mockRepo.On("SaveConfigDrift", mock.Anything).Return(nil)
+
+	drift, err := service.ReportEffectiveConfig("5", "last-known-good")
+	assert.NoError(t, err)
+	assert.Equal(t, models.ConfigDrifted, drift.Status)
+	assert.Equal(t, models.DriftAlerted, drift.Action)
+	assert.NotEmpty(t, drift.Error)
+	// The config the agent rolled back from isn't pushed again
+	mockRepo.AssertNotCalled(t, "GetConfigDelivery", mock.Anything)
+}
+
+func tapGraph() *models.PipelineGraph {
+	return &models.PipelineGraph{
+		Nodes: []models.PipelineNodes{
+			{ComponentID: 1, Name: "OTLP", ComponentName: "otlp_receiver", ComponentRole: "receiver", SupportedSignals: []string{"logs", "traces"}},
+			{ComponentID: 2, Name: "Batch", ComponentName: "batch_processor", ComponentRole: "processor", SupportedSignals: []string{"logs"}},
+			{ComponentID: 3, Name: "Debug", ComponentName: "debug_exporter", ComponentRole: "exporter", SupportedSignals: []string{"logs"}},
+		},
+		Edges: []models.PipelineEdges{{Source: "1", Target: "2"}, {Source: "2", Target: "3"}},
+	}
+}
+
+func TestStartTap_Service_InvalidRequest(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	mockRepo.On("PipelineExists", 4).Return(true)
+	mockRepo.On("PipelineExists", 5).Return(false)
+	mockRepo.On("GetPipelineGraph", 4).Return(tapGraph(), nil)
+
+	_, err := service.StartTap(5, 1, models.TapOptions{})
+	assert.ErrorIs(t, err, utils.ErrPipelineDoesNotExists)
+
+	_, err = service.StartTap(4, 9, models.TapOptions{})
+	assert.ErrorIs(t, err, utils.ErrNodeDoesNotExists)
+
+	for _, tt := range []struct {
+		nodeId int
+		opts   models.TapOptions
+	}{
+		{3, models.TapOptions{Signal: "logs"}},                // exporter
+		{1, models.TapOptions{}},                              // signal is ambiguous
+		{2, models.TapOptions{Signal: "traces"}},              // unsupported signal
+		{2, models.TapOptions{Limit: models.TapMaxLimit + 1}}, // limit too high
+		{2, models.TapOptions{DurationSec: 3600}},             // duration too long
+	} {
+		_, err = service.StartTap(4, tt.nodeId, tt.opts)
+		assert.ErrorIs(t, err, utils.ErrInvalidTap, "node %d %+v", tt.nodeId, tt.opts)
+	}
+}
+
+func TestStartTap_Service_Unavailable(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	mockRepo.On("PipelineExists", 4).Return(true)
+	mockRepo.On("GetPipelineGraph", 4).Return(tapGraph(), nil)
+	mockRepo.On("GetAllAgentsAttachedToPipeline", 4).Return([]models.AgentInfoHome{}, nil).Once()
+
+	_, err := service.StartTap(4, 2, models.TapOptions{})
+	assert.ErrorIs(t, err, utils.ErrTapUnavailable)
+
+	// Nothing listens on the agent port, so no agent starts the tap
+	mockRepo.On("GetAllAgentsAttachedToPipeline", 4).Return([]models.AgentInfoHome{{ID: 5, Hostname: "127.0.0.1", IP: "127.0.0.1"}}, nil)
+	_, err = service.StartTap(4, 2, models.TapOptions{})
+	assert.ErrorIs(t, err, utils.ErrTapUnavailable)
+	assert.Contains(t, err.Error(), "agent 5:")
+}
+
+func TestPublishTapSample_Service(t *testing.T) {
+	service := frontendpipeline.NewFrontendPipelineService(new(MockRepo), nil, newJobManager(t))
+
+	err := service.PublishTapSample("ab12", models.TapSample{AgentID: 5, Count: 1})
+	assert.ErrorIs(t, err, utils.ErrTapDoesNotExists)
+
+	samples, closeTap := service.(*frontendpipeline.FrontendPipelineService).Taps.Subscribe("ab12", 1, 0)
+	assert.NoError(t, service.PublishTapSample("ab12", models.TapSample{AgentID: 5, Count: 1}))
+	assert.Equal(t, models.TapSample{TapID: "ab12", AgentID: 5, Count: 1}, <-samples)
+
+	closeTap()
+	assert.ErrorIs(t, service.PublishTapSample("ab12", models.TapSample{AgentID: 5}), utils.ErrTapDoesNotExists)
+}
+
+func TestRetryConfigDeliveries_Service_LimitsBatch(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := frontendpipeline.NewFrontendPipelineService(mockRepo, nil, newJobManager(t))
+
+	batch := constants.CONFIG_RETRY_BATCH
+	constants.CONFIG_RETRY_BATCH = 2
+	defer func() { constants.CONFIG_RETRY_BATCH = batch }()
+
+	var deliveries []models.ConfigDelivery
+	for id := 5; id < 8; id++ {
+		deliveries = append(deliveries, models.ConfigDelivery{AgentID: id, PipelineID: 9, Status: models.ConfigDeliveryPending})
+		mockRepo.On("GetAgentInfo", id).Return(&models.AgentInfoHome{ID: int64(id)}, nil)
+		mockRepo.On("DeleteConfigDelivery", id).Return(nil)
+	}
+	mockRepo.On("GetDueConfigDeliveries", mock.Anything).Return(deliveries, nil)
+	mockRepo.On("PipelineExists", 9).Return(false)
+
+	assert.NoError(t, service.RetryConfigDeliveries())
+	mockRepo.AssertCalled(t, "DeleteConfigDelivery", 5)
+	mockRepo.AssertCalled(t, "DeleteConfigDelivery", 6)
+	mockRepo.AssertNotCalled(t, "GetAgentInfo", 7)
+}
@@ -0,0 +1,179 @@
+package frontendpipeline
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/agentclient"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/configcompiler"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+)
+
+// errAgentNotFound is an agent answering 404, such as for a tap that has
+// already ended or from an agent too old to tap
+var errAgentNotFound = errors.New("agent returned 404")
+
+// tapBuffer is how many samples per agent wait for a slow stream before
+// further ones are dropped
+const tapBuffer = 32
+
+// StartTap asks every agent of the pipeline to tap the node, a receiver or
+// processor, and returns where their samples arrive. Agents end their taps
+// at the limit or duration; closing the tap ends them early.
+func (f *FrontendPipelineService) StartTap(pipelineId int, nodeId int, opts models.TapOptions) (*NodeTap, error) {
+	if !f.FrontendPipelineRepository.PipelineExists(pipelineId) {
+		return nil, utils.ErrPipelineDoesNotExists
+	}
+	graph, err := f.GetPipelineGraph(pipelineId)
+	if err != nil {
+		return nil, err
+	}
+	index := slices.IndexFunc(graph.Nodes, func(node models.PipelineNodes) bool { return node.ComponentID == nodeId })
+	if index < 0 {
+		return nil, fmt.Errorf("%w: %d", utils.ErrNodeDoesNotExists, nodeId)
+	}
+	node := graph.Nodes[index]
+
+	if node.ComponentRole != "receiver" && node.ComponentRole != "processor" {
+		return nil, fmt.Errorf("%w: only receivers and processors can be tapped, not the %s %s", utils.ErrInvalidTap, node.ComponentRole, node.Name)
+	}
+	if opts.Signal == "" && len(node.SupportedSignals) == 1 {
+		opts.Signal = node.SupportedSignals[0]
+	}
+	if !slices.Contains(node.SupportedSignals, opts.Signal) {
+		return nil, fmt.Errorf("%w: signal must be one of %s, the signals %s handles", utils.ErrInvalidTap, strings.Join(node.SupportedSignals, ", "), node.Name)
+	}
+	if opts.Limit == 0 {
+		opts.Limit = models.TapDefaultLimit
+	}
+	if opts.Limit < 0 || opts.Limit > models.TapMaxLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", utils.ErrInvalidTap, models.TapMaxLimit)
+	}
+	if opts.DurationSec == 0 {
+		opts.DurationSec = models.TapDefaultDurationSec
+	}
+	if opts.DurationSec < 0 || opts.DurationSec > models.TapMaxDurationSec {
+		return nil, fmt.Errorf("%w: duration_sec must be between 1 and %d", utils.ErrInvalidTap, models.TapMaxDurationSec)
+	}
+
+	// Agents know the node by the collector ID it compiles to, which hashes
+	// its config as sent to them
+	resolved, err := f.resolveSecrets(*graph)
+	if err != nil {
+		return nil, err
+	}
+	component := configcompiler.ComponentAlias(resolved.Nodes[index])
+
+	agents, err := f.FrontendPipelineRepository.GetAllAgentsAttachedToPipeline(pipelineId)
+	if err != nil {
+		return nil, err
+	}
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("%w: pipeline has no agents", utils.ErrTapUnavailable)
+	}
+
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	request := models.TapRequest{
+		ID:          hex.EncodeToString(id),
+		Component:   component,
+		Role:        node.ComponentRole,
+		Signal:      opts.Signal,
+		Limit:       opts.Limit,
+		DurationSec: opts.DurationSec,
+	}
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling tap request: %v", err)
+	}
+
+	// Listen before the agents start sending
+	samples, closeTap := f.Taps.Subscribe(request.ID, tapBuffer*len(agents), len(agents))
+
+	tap := &NodeTap{ID: request.ID, Options: opts, Agents: []int64{}, Failed: map[int64]string{}, Samples: samples}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var started []models.AgentInfoHome
+	for _, agent := range agents {
+		wg.Add(1)
+		go func(agent models.AgentInfoHome) {
+			defer wg.Done()
+			err := f.sendTapCommand(agent, http.MethodPost, "/agent/v1/taps", jsonData)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				utils.Logger.Sugar().Warnf("Agent [ID:%v] couldn't start tap %s: %v", agent.ID, request.ID, err)
+				tap.Failed[agent.ID] = err.Error()
+				return
+			}
+			started = append(started, agent)
+			tap.Agents = append(tap.Agents, agent.ID)
+		}(agent)
+	}
+	wg.Wait()
+	slices.Sort(tap.Agents)
+
+	if len(started) == 0 {
+		closeTap()
+		reasons := make([]string, 0, len(tap.Failed))
+		for _, agent := range agents {
+			reasons = append(reasons, fmt.Sprintf("agent %d: %s", agent.ID, tap.Failed[agent.ID]))
+		}
+		return nil, fmt.Errorf("%w: %s", utils.ErrTapUnavailable, strings.Join(reasons, "; "))
+	}
+
+	utils.Logger.Sugar().Infof("Started tap %s on %s of pipeline [ID:%d] with %d agents", request.ID, component, pipelineId, len(started))
+	var once sync.Once
+	tap.Close = func() {
+		once.Do(func() {
+			closeTap()
+			go f.stopTap(request.ID, started)
+		})
+	}
+	return tap, nil
+}
+
+// PublishTapSample hands a sample an agent caught to the open tap.
+func (f *FrontendPipelineService) PublishTapSample(tapId string, sample models.TapSample) error {
+	sample.TapID = tapId
+	if !f.Taps.Publish(sample) {
+		return utils.ErrTapDoesNotExists
+	}
+	return nil
+}
+
+// stopTap ends the tap on agents that may still be running it. Agents whose
+// tap already ended answer 404.
+func (f *FrontendPipelineService) stopTap(tapId string, agents []models.AgentInfoHome) {
+	for _, agent := range agents {
+		if err := f.sendTapCommand(agent, http.MethodDelete, "/agent/v1/taps/"+tapId, nil); err != nil && !errors.Is(err, errAgentNotFound) {
+			utils.Logger.Sugar().Warnf("Failed to stop tap %s on agent [ID:%v]: %v", tapId, agent.ID, err)
+		}
+	}
+}
+
+func (f *FrontendPipelineService) sendTapCommand(agent models.AgentInfoHome, method string, path string, jsonData []byte) error {
+	resp, err := agentclient.Do(agent, method, path, jsonData)
+	if err != nil {
+		return err
+	}
+
+	body := strings.TrimSpace(string(resp.Body))
+	switch {
+	case resp.OK():
+		return nil
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: %s", errAgentNotFound, body)
+	default:
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, body)
+	}
+}
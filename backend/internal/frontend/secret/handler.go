@@ -0,0 +1,103 @@
+package frontendsecret
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+	"github.com/gorilla/mux"
+)
+
+// FrontendSecretHandler handles secrets store operations
+type FrontendSecretHandler struct {
+	FrontendSecretService FrontendSecretServiceInterface
+}
+
+// NewFrontendSecretHandler initializes the handler
+func NewFrontendSecretHandler(frontendSecretService FrontendSecretServiceInterface) *FrontendSecretHandler {
+	return &FrontendSecretHandler{
+		FrontendSecretService: frontendSecretService,
+	}
+}
+
+func (f *FrontendSecretHandler) GetAllSecrets(w http.ResponseWriter, r *http.Request) {
+	utils.Logger.Info("Request received to get all secrets")
+
+	response, err := f.FrontendSecretService.GetAllSecrets()
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error while getting all secrets: %v", err))
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (f *FrontendSecretHandler) CreateSecret(w http.ResponseWriter, r *http.Request) {
+	var req models.SecretRequest
+	if err := utils.UnmarshalJSONRequest(r, &req); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to create secret: %s", req.Name))
+
+	secretId, err := f.FrontendSecretService.CreateSecret(req)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error creating secret %s: %v", req.Name, err))
+		sendSecretError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]any{"message": "Secret created successfully", "id": secretId})
+}
+
+func (f *FrontendSecretHandler) UpdateSecret(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	var req models.SecretRequest
+	if err := utils.UnmarshalJSONRequest(r, &req); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to update secret: %s", name))
+
+	if err := f.FrontendSecretService.UpdateSecret(name, req); err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error updating secret %s: %v", name, err))
+		sendSecretError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Secret updated successfully"})
+}
+
+func (f *FrontendSecretHandler) DeleteSecret(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	utils.Logger.Info(fmt.Sprintf("Request received to delete secret: %s", name))
+
+	if err := f.FrontendSecretService.DeleteSecret(name); err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error deleting secret %s: %v", name, err))
+		sendSecretError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Secret deleted successfully"})
+}
+
+// sendSecretError maps service errors to status codes: 404 for a missing
+// secret, 400 for invalid requests, 409 for secrets still in use, 503 when no
+// master key is configured and 500 otherwise.
+func sendSecretError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, utils.ErrSecretDoesNotExists):
+		utils.SendJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidSecret):
+		utils.SendJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrSecretInUse):
+		utils.SendJSONError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrSecretsDisabled):
+		utils.SendJSONError(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+	}
+}
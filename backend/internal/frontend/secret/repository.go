@@ -0,0 +1,116 @@
+package frontendsecret
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+)
+
+type FrontendSecretRepository struct {
+	db *sql.DB
+}
+
+// NewFrontendSecretRepository creates a new FrontendSecretRepository
+func NewFrontendSecretRepository(db *sql.DB) *FrontendSecretRepository {
+	return &FrontendSecretRepository{db: db}
+}
+
+func (f *FrontendSecretRepository) SecretExists(name string) bool {
+	var verifyId int
+	err := f.db.QueryRow("SELECT secret_id FROM secrets WHERE name = ? LIMIT 1", name).Scan(&verifyId)
+	return err == nil
+}
+
+func (f *FrontendSecretRepository) GetAllSecrets() ([]models.Secret, error) {
+	rows, err := f.db.Query("SELECT secret_id, name, description, created_by, created_at, updated_at FROM secrets ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query secrets: %w", err)
+	}
+	defer rows.Close()
+
+	secrets := []models.Secret{}
+	for rows.Next() {
+		var secret models.Secret
+		if err := rows.Scan(&secret.ID, &secret.Name, &secret.Description, &secret.CreatedBy, &secret.CreatedAt, &secret.UpdatedAt); err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
+	}
+	return secrets, rows.Err()
+}
+
+// GetSecretValue returns the encrypted value of a secret.
+func (f *FrontendSecretRepository) GetSecretValue(name string) ([]byte, error) {
+	var value []byte
+	err := f.db.QueryRow("SELECT value FROM secrets WHERE name = ?", name).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrSecretDoesNotExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query secret %s: %w", name, err)
+	}
+	return value, nil
+}
+
+func (f *FrontendSecretRepository) CreateSecret(secret models.Secret, value []byte) (int, error) {
+	now := utils.GetCurrentTime()
+	result, err := f.db.Exec(
+		"INSERT INTO secrets (name, description, value, created_by, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+		secret.Name, secret.Description, value, secret.CreatedBy, now, now,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert secret: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get secret ID: %w", err)
+	}
+	return int(id), nil
+}
+
+func (f *FrontendSecretRepository) UpdateSecret(secret models.Secret, value []byte) error {
+	_, err := f.db.Exec(
+		"UPDATE secrets SET description = ?, value = ?, updated_at = ? WHERE name = ?",
+		secret.Description, value, utils.GetCurrentTime(), secret.Name,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update secret: %w", err)
+	}
+	return nil
+}
+
+func (f *FrontendSecretRepository) DeleteSecret(name string) error {
+	_, err := f.db.Exec("DELETE FROM secrets WHERE name = ?", name)
+	if err != nil {
+		return fmt.Errorf("failed to delete secret: %w", err)
+	}
+	return nil
+}
+
+// GetPipelinesReferencingSecret lists the names of pipelines whose node
+// configs contain ${secret:name}.
+func (f *FrontendSecretRepository) GetPipelinesReferencingSecret(name string) ([]string, error) {
+	rows, err := f.db.Query(`
+		SELECT DISTINCT p.name
+		FROM pipeline_components pc
+		JOIN pipelines p ON p.pipeline_id = pc.pipeline_id
+		WHERE instr(pc.config, ?) > 0
+		ORDER BY p.name`, "${secret:"+name+"}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query secret references: %w", err)
+	}
+	defer rows.Close()
+
+	pipelines := []string{}
+	for rows.Next() {
+		var pipelineName string
+		if err := rows.Scan(&pipelineName); err != nil {
+			return nil, err
+		}
+		pipelines = append(pipelines, pipelineName)
+	}
+	return pipelines, rows.Err()
+}
@@ -0,0 +1,148 @@
+package frontendsecret
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/secrets"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+)
+
+// ErrInvalidSecret is returned for secret requests that fail validation.
+var ErrInvalidSecret = errors.New("invalid secret")
+
+// ErrSecretsDisabled is returned when no master key is configured.
+var ErrSecretsDisabled = errors.New("the secrets store is disabled: SECRETS_MASTER_KEY is not set")
+
+// ErrSecretInUse is returned when deleting a secret that pipelines still reference.
+var ErrSecretInUse = errors.New("secret is in use")
+
+type FrontendSecretRepositoryInterface interface {
+	SecretExists(name string) bool
+	GetAllSecrets() ([]models.Secret, error)
+	GetSecretValue(name string) ([]byte, error)
+	CreateSecret(secret models.Secret, value []byte) (int, error)
+	UpdateSecret(secret models.Secret, value []byte) error
+	DeleteSecret(name string) error
+	GetPipelinesReferencingSecret(name string) ([]string, error)
+}
+
+type FrontendSecretServiceInterface interface {
+	GetAllSecrets() ([]models.Secret, error)
+	CreateSecret(req models.SecretRequest) (int, error)
+	UpdateSecret(name string, req models.SecretRequest) error
+	DeleteSecret(name string) error
+	ResolveSecret(name string) (string, error)
+}
+
+type FrontendSecretService struct {
+	FrontendSecretRepository FrontendSecretRepositoryInterface
+	Cipher                   *secrets.Cipher
+}
+
+// NewFrontendSecretService creates a new FrontendSecretService. A nil cipher
+// disables storing and resolving secrets; listing and deleting still work.
+func NewFrontendSecretService(frontendSecretRepository FrontendSecretRepositoryInterface, cipher *secrets.Cipher) FrontendSecretServiceInterface {
+	return &FrontendSecretService{
+		FrontendSecretRepository: frontendSecretRepository,
+		Cipher:                   cipher,
+	}
+}
+
+func (f *FrontendSecretService) GetAllSecrets() ([]models.Secret, error) {
+	return f.FrontendSecretRepository.GetAllSecrets()
+}
+
+func (f *FrontendSecretService) CreateSecret(req models.SecretRequest) (int, error) {
+	if f.Cipher == nil {
+		return 0, ErrSecretsDisabled
+	}
+	if err := validateSecretRequest(req); err != nil {
+		return 0, err
+	}
+	if req.CreatedBy == "" {
+		return 0, fmt.Errorf("%w: created_by is required", ErrInvalidSecret)
+	}
+
+	value, err := f.Cipher.Encrypt(req.Name, []byte(req.Value))
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := f.FrontendSecretRepository.CreateSecret(models.Secret{
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedBy:   req.CreatedBy,
+	}, value)
+	if utils.IsUniqueViolation(err) {
+		return 0, fmt.Errorf("%w: a secret named %s already exists", ErrInvalidSecret, req.Name)
+	}
+	return id, err
+}
+
+// UpdateSecret replaces the value and description of an existing secret.
+// Pipelines pick up the new value the next time their config is sent.
+func (f *FrontendSecretService) UpdateSecret(name string, req models.SecretRequest) error {
+	if f.Cipher == nil {
+		return ErrSecretsDisabled
+	}
+	if !f.FrontendSecretRepository.SecretExists(name) {
+		return utils.ErrSecretDoesNotExists
+	}
+	req.Name = name
+	if err := validateSecretRequest(req); err != nil {
+		return err
+	}
+
+	value, err := f.Cipher.Encrypt(name, []byte(req.Value))
+	if err != nil {
+		return err
+	}
+	return f.FrontendSecretRepository.UpdateSecret(models.Secret{Name: name, Description: req.Description}, value)
+}
+
+// DeleteSecret removes a secret unless a pipeline still references it.
+func (f *FrontendSecretService) DeleteSecret(name string) error {
+	if !f.FrontendSecretRepository.SecretExists(name) {
+		return utils.ErrSecretDoesNotExists
+	}
+
+	pipelines, err := f.FrontendSecretRepository.GetPipelinesReferencingSecret(name)
+	if err != nil {
+		return err
+	}
+	if len(pipelines) > 0 {
+		return fmt.Errorf("%w: referenced by pipelines %s", ErrSecretInUse, strings.Join(pipelines, ", "))
+	}
+	return f.FrontendSecretRepository.DeleteSecret(name)
+}
+
+// ResolveSecret decrypts a secret for compiling configs. It implements
+// secrets.Resolver.
+func (f *FrontendSecretService) ResolveSecret(name string) (string, error) {
+	if f.Cipher == nil {
+		return "", ErrSecretsDisabled
+	}
+
+	value, err := f.FrontendSecretRepository.GetSecretValue(name)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := f.Cipher.Decrypt(name, value)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func validateSecretRequest(req models.SecretRequest) error {
+	if !secrets.ValidName(req.Name) {
+		return fmt.Errorf("%w: name %q may only contain letters, digits, '.', '_' and '-'", ErrInvalidSecret, req.Name)
+	}
+	if req.Value == "" {
+		return fmt.Errorf("%w: value is required", ErrInvalidSecret)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package frontendsecret
+
+import (
+	"encoding/base64"
+	"testing"
+
+	database "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/db"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/secrets"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupSecretService(t *testing.T) (FrontendSecretServiceInterface, *FrontendSecretRepository) {
+	db, err := database.DBInit(":memory:")
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+
+	cipher, err := secrets.NewCipher(base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef")))
+	assert.NoError(t, err)
+
+	repo := NewFrontendSecretRepository(db)
+	return NewFrontendSecretService(repo, cipher), repo
+}
+
+func TestSecretService_Lifecycle(t *testing.T) {
+	service, repo := setupSecretService(t)
+
+	id, err := service.CreateSecret(models.SecretRequest{Name: "kafka.password", Value: "hunter2", CreatedBy: "admin"})
+	assert.NoError(t, err)
+	assert.NotZero(t, id)
+
+	// Only the ciphertext is stored
+	stored, err := repo.GetSecretValue("kafka.password")
+	assert.NoError(t, err)
+	assert.NotContains(t, string(stored), "hunter2")
+
+	value, err := service.ResolveSecret("kafka.password")
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", value)
+
+	assert.NoError(t, service.UpdateSecret("kafka.password", models.SecretRequest{Value: "correct-horse", Description: "SASL"}))
+	value, err = service.ResolveSecret("kafka.password")
+	assert.NoError(t, err)
+	assert.Equal(t, "correct-horse", value)
+
+	all, err := service.GetAllSecrets()
+	assert.NoError(t, err)
+	assert.Len(t, all, 1)
+	assert.Equal(t, "SASL", all[0].Description)
+
+	_, err = service.CreateSecret(models.SecretRequest{Name: "kafka.password", Value: "x", CreatedBy: "admin"})
+	assert.ErrorIs(t, err, ErrInvalidSecret)
+
+	assert.NoError(t, service.DeleteSecret("kafka.password"))
+	_, err = service.ResolveSecret("kafka.password")
+	assert.ErrorIs(t, err, utils.ErrSecretDoesNotExists)
+}
+
+func TestSecretService_Validation(t *testing.T) {
+	service, _ := setupSecretService(t)
+
+	_, err := service.CreateSecret(models.SecretRequest{Name: "has space", Value: "x", CreatedBy: "admin"})
+	assert.ErrorIs(t, err, ErrInvalidSecret)
+
+	_, err = service.CreateSecret(models.SecretRequest{Name: "empty", CreatedBy: "admin"})
+	assert.ErrorIs(t, err, ErrInvalidSecret)
+
+	err = service.UpdateSecret("missing", models.SecretRequest{Value: "x"})
+	assert.ErrorIs(t, err, utils.ErrSecretDoesNotExists)
+}
+
+func TestSecretService_DeleteInUse(t *testing.T) {
+	service, repo := setupSecretService(t)
+
+	_, err := service.CreateSecret(models.SecretRequest{Name: "token", Value: "abc", CreatedBy: "admin"})
+	assert.NoError(t, err)
+
+	_, err = repo.db.Exec("INSERT INTO pipelines (pipeline_id, name, created_by) VALUES (1, 'edge', 'admin')")
+	assert.NoError(t, err)
+	_, err = repo.db.Exec(`INSERT INTO pipeline_components (pipeline_id, component_role, component_name, name, config, supported_signals)
+		VALUES (1, 'exporter', 'otlphttp_exporter', 'OTLP', '{"headers":{"Authorization":"Bearer ${secret:token}"}}', 'logs')`)
+	assert.NoError(t, err)
+
+	err = service.DeleteSecret("token")
+	assert.ErrorIs(t, err, ErrSecretInUse)
+	assert.ErrorContains(t, err, "edge")
+}
+
+func TestSecretService_Disabled(t *testing.T) {
+	_, repo := setupSecretService(t)
+	service := NewFrontendSecretService(repo, nil)
+
+	_, err := service.CreateSecret(models.SecretRequest{Name: "token", Value: "abc", CreatedBy: "admin"})
+	assert.ErrorIs(t, err, ErrSecretsDisabled)
+	_, err = service.ResolveSecret("token")
+	assert.ErrorIs(t, err, ErrSecretsDisabled)
+}
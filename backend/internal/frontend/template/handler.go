@@ -0,0 +1,161 @@
+package frontendtemplate
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	frontendpipeline "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/pipeline"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+	"github.com/gorilla/mux"
+)
+
+// FrontendTemplateHandler handles pipeline template operations
+type FrontendTemplateHandler struct {
+	FrontendTemplateService FrontendTemplateServiceInterface
+}
+
+// NewFrontendTemplateHandler initializes the handler
+func NewFrontendTemplateHandler(frontendTemplateService FrontendTemplateServiceInterface) *FrontendTemplateHandler {
+	return &FrontendTemplateHandler{
+		FrontendTemplateService: frontendTemplateService,
+	}
+}
+
+func (f *FrontendTemplateHandler) GetAllTemplates(w http.ResponseWriter, r *http.Request) {
+	utils.Logger.Info("Request received to get all pipeline templates")
+
+	response, err := f.FrontendTemplateService.GetAllTemplates()
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error while getting all pipeline templates: %v", err))
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for i := range response {
+		response[i] = redactTemplate(response[i])
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (f *FrontendTemplateHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	templateId, ok := templateIdFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to get pipeline template with ID: %d", templateId))
+
+	response, err := f.FrontendTemplateService.GetTemplate(templateId)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error getting pipeline template [ID: %d]: %v", templateId, err))
+		sendTemplateError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, redactTemplate(*response))
+}
+
+func (f *FrontendTemplateHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var template models.PipelineTemplate
+	if err := utils.UnmarshalJSONRequest(r, &template); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to create pipeline template: %s", template.Name))
+
+	templateId, err := f.FrontendTemplateService.CreateTemplate(template)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error creating pipeline template: %v", err))
+		sendTemplateError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]any{"message": "Template created successfully", "id": templateId})
+}
+
+func (f *FrontendTemplateHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	templateId, ok := templateIdFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var template models.PipelineTemplate
+	if err := utils.UnmarshalJSONRequest(r, &template); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to update pipeline template with ID: %d", templateId))
+
+	if err := f.FrontendTemplateService.UpdateTemplate(templateId, template); err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error updating pipeline template [ID: %d]: %v", templateId, err))
+		sendTemplateError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Template updated successfully"})
+}
+
+func (f *FrontendTemplateHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	templateId, ok := templateIdFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to delete pipeline template with ID: %d", templateId))
+
+	if err := f.FrontendTemplateService.DeleteTemplate(templateId); err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error deleting pipeline template [ID: %d]: %v", templateId, err))
+		sendTemplateError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Template deleted successfully"})
+}
+
+func (f *FrontendTemplateHandler) InstantiateTemplate(w http.ResponseWriter, r *http.Request) {
+	templateId, ok := templateIdFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.InstantiateTemplateRequest
+	if err := utils.UnmarshalJSONRequest(r, &req); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to instantiate pipeline %s from template with ID: %d", req.Name, templateId))
+
+	pipelineId, err := f.FrontendTemplateService.InstantiateTemplate(templateId, req)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error instantiating pipeline template [ID: %d]: %v", templateId, err))
+		if frontendpipeline.SendGraphValidationError(w, err) {
+			return
+		}
+		sendTemplateError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Pipeline created successfully", "id": pipelineId})
+}
+
+func templateIdFromRequest(w http.ResponseWriter, r *http.Request) (int, bool) {
+	templateId, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid template ID format")
+		return 0, false
+	}
+	return templateId, true
+}
+
+// sendTemplateError maps service errors to status codes: 404 for a missing
+// template, 400 for invalid templates or parameters and 500 otherwise.
+func sendTemplateError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, utils.ErrTemplateDoesNotExists):
+		utils.SendJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrInvalidTemplate):
+		utils.SendJSONError(w, http.StatusBadRequest, err.Error())
+	default:
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,146 @@
+package frontendtemplate_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	frontendtemplate "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/template"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) GetAllTemplates() ([]models.PipelineTemplate, error) {
+	args := m.Called()
+	return args.Get(0).([]models.PipelineTemplate), args.Error(1)
+}
+func (m *MockService) GetTemplate(templateId int) (*models.PipelineTemplate, error) {
+	args := m.Called(templateId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PipelineTemplate), args.Error(1)
+}
+func (m *MockService) CreateTemplate(template models.PipelineTemplate) (int, error) {
+	args := m.Called(template)
+	return args.Int(0), args.Error(1)
+}
+func (m *MockService) UpdateTemplate(templateId int, template models.PipelineTemplate) error {
+	return m.Called(templateId, template).Error(0)
+}
+func (m *MockService) DeleteTemplate(templateId int) error {
+	return m.Called(templateId).Error(0)
+}
+func (m *MockService) InstantiateTemplate(templateId int, req models.InstantiateTemplateRequest) (string, error) {
+	args := m.Called(templateId, req)
+	return args.String(0), args.Error(1)
+}
+func (m *MockService) RenderTemplateByName(name string, params map[string]any) (*models.PipelineGraph, error) {
+	args := m.Called(name, params)
+	return args.Get(0).(*models.PipelineGraph), args.Error(1)
+}
+
+func TestGetTemplateHandler_NotFound(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendtemplate.NewFrontendTemplateHandler(mockSvc)
+
+	mockSvc.On("GetTemplate", 3).Return(nil, utils.ErrTemplateDoesNotExists)
+
+	req := httptest.NewRequest("GET", "/templates/3", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "3"})
+	w := httptest.NewRecorder()
+
+	handler.GetTemplate(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func secretTemplate() *models.PipelineTemplate {
+	return &models.PipelineTemplate{
+		ID:   1,
+		Name: "kafka-logs",
+		PipelineGraph: models.PipelineGraph{Nodes: []models.PipelineNodes{{ComponentID: 1, Name: "Kafka", ComponentName: "kafka_exporter", Config: map[string]any{
+			"auth": map[string]any{"sasl": map[string]any{"username": "ingest", "password": "hunter2"}},
+			"tls":  map[string]any{"key": "${tls_key}"},
+		}}}},
+	}
+}
+
+func TestGetTemplateHandler_RedactsSecrets(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendtemplate.NewFrontendTemplateHandler(mockSvc)
+
+	mockSvc.On("GetTemplate", 1).Return(secretTemplate(), nil)
+
+	req := httptest.NewRequest("GET", "/templates/1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+
+	handler.GetTemplate(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "hunter2")
+	assert.Contains(t, w.Body.String(), "ingest")
+	// Placeholders aren't secrets until the template is instantiated
+	assert.Contains(t, w.Body.String(), "${tls_key}")
+}
+
+func TestGetAllTemplatesHandler_RedactsSecrets(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendtemplate.NewFrontendTemplateHandler(mockSvc)
+
+	mockSvc.On("GetAllTemplates").Return([]models.PipelineTemplate{*secretTemplate()}, nil)
+
+	req := httptest.NewRequest("GET", "/templates", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetAllTemplates(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "hunter2")
+	assert.Contains(t, w.Body.String(), "${tls_key}")
+}
+
+func TestCreateTemplateHandler_Invalid(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendtemplate.NewFrontendTemplateHandler(mockSvc)
+
+	template := models.PipelineTemplate{Name: "kafka-logs"}
+	mockSvc.On("CreateTemplate", template).Return(0, fmt.Errorf("%w: created_by is required", frontendtemplate.ErrInvalidTemplate))
+
+	body, _ := json.Marshal(template)
+	req := httptest.NewRequest("POST", "/templates", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.CreateTemplate(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "created_by is required")
+}
+
+func TestInstantiateTemplateHandler(t *testing.T) {
+	mockSvc := new(MockService)
+	handler := frontendtemplate.NewFrontendTemplateHandler(mockSvc)
+
+	instantiate := models.InstantiateTemplateRequest{
+		Name:       "edge-logs",
+		CreatedBy:  "admin",
+		Parameters: map[string]any{"kafka_brokers": "k:9092"},
+	}
+	mockSvc.On("InstantiateTemplate", 1, instantiate).Return("5", nil)
+
+	body, _ := json.Marshal(instantiate)
+	req := httptest.NewRequest("POST", "/templates/1/instantiate", bytes.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+
+	handler.InstantiateTemplate(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"5"`)
+}
@@ -0,0 +1,296 @@
+package frontendtemplate
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/secrets"
+)
+
+// ErrInvalidTemplate is wrapped by every template or parameter validation
+// error so handlers can answer with 400.
+var ErrInvalidTemplate = errors.New("invalid template")
+
+// placeholderPattern matches ${name}. Collector references such as
+// ${env:HOME} contain a colon and are left alone.
+var placeholderPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+var parameterNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var parameterTypes = map[string]bool{
+	models.TemplateParamString:     true,
+	models.TemplateParamInt:        true,
+	models.TemplateParamNumber:     true,
+	models.TemplateParamBool:       true,
+	models.TemplateParamStringList: true,
+}
+
+// ValidateTemplate checks the template's metadata and parameter declarations
+// and that every placeholder in its node configs is declared.
+func ValidateTemplate(template *models.PipelineTemplate) error {
+	if template.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTemplate)
+	}
+	if template.CreatedBy == "" {
+		return fmt.Errorf("%w: created_by is required", ErrInvalidTemplate)
+	}
+	if len(template.PipelineGraph.Nodes) == 0 {
+		return fmt.Errorf("%w: pipeline graph has no nodes", ErrInvalidTemplate)
+	}
+
+	declared := make(map[string]bool)
+	for _, param := range template.Parameters {
+		if !parameterNamePattern.MatchString(param.Name) {
+			return fmt.Errorf("%w: invalid parameter name %q", ErrInvalidTemplate, param.Name)
+		}
+		if declared[param.Name] {
+			return fmt.Errorf("%w: parameter %s is declared twice", ErrInvalidTemplate, param.Name)
+		}
+		declared[param.Name] = true
+
+		if !parameterTypes[param.Type] {
+			return fmt.Errorf("%w: parameter %s has unknown type %q", ErrInvalidTemplate, param.Name, param.Type)
+		}
+		if param.Default != nil {
+			if _, err := coerceParameter(param.Type, param.Default); err != nil {
+				return fmt.Errorf("%w: default for %s: %v", ErrInvalidTemplate, param.Name, err)
+			}
+		}
+	}
+
+	for _, node := range template.PipelineGraph.Nodes {
+		for _, name := range placeholdersIn(node.Config) {
+			if !declared[name] {
+				return fmt.Errorf("%w: node %s uses undeclared parameter ${%s}", ErrInvalidTemplate, node.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// RenderTemplate substitutes parameter values into a copy of the template's
+// graph. A config value that is exactly "${name}" takes the parameter's typed
+// value; placeholders inside longer strings are interpolated as text. Optional
+// parameters with no value and no default drop the config key they fill.
+func RenderTemplate(template *models.PipelineTemplate, values map[string]any) (*models.PipelineGraph, error) {
+	params := make(map[string]models.TemplateParameter)
+	for _, param := range template.Parameters {
+		params[param.Name] = param
+	}
+	for name := range values {
+		if _, ok := params[name]; !ok {
+			return nil, fmt.Errorf("%w: unknown parameter %s", ErrInvalidTemplate, name)
+		}
+	}
+
+	resolved := make(map[string]any)
+	for _, param := range template.Parameters {
+		value, given := values[param.Name]
+		if !given || value == nil {
+			if param.Default == nil {
+				if param.Required {
+					return nil, fmt.Errorf("%w: missing required parameter %s", ErrInvalidTemplate, param.Name)
+				}
+				continue
+			}
+			value = param.Default
+		}
+
+		typed, err := coerceParameter(param.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: parameter %s: %v", ErrInvalidTemplate, param.Name, err)
+		}
+		resolved[param.Name] = typed
+	}
+
+	graph := models.PipelineGraph{
+		Nodes:     make([]models.PipelineNodes, len(template.PipelineGraph.Nodes)),
+		Edges:     append([]models.PipelineEdges{}, template.PipelineGraph.Edges...),
+		Telemetry: template.PipelineGraph.Telemetry,
+	}
+	for i, node := range template.PipelineGraph.Nodes {
+		config, _ := substitute(node.Config, resolved)
+		node.Config, _ = config.(map[string]any)
+		if node.Config == nil {
+			node.Config = map[string]any{}
+		}
+		graph.Nodes[i] = node
+	}
+	return &graph, nil
+}
+
+// substitute returns a copy of value with placeholders replaced. The boolean
+// is false when value was a lone placeholder for an unset optional parameter.
+func substitute(value any, resolved map[string]any) (any, bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			if replaced, keep := substitute(item, resolved); keep {
+				out[key] = replaced
+			}
+		}
+		return out, true
+	case []any:
+		out := make([]any, 0, len(v))
+		for _, item := range v {
+			if replaced, keep := substitute(item, resolved); keep {
+				out = append(out, replaced)
+			}
+		}
+		return out, true
+	case string:
+		if match := placeholderPattern.FindStringSubmatch(v); match != nil && match[0] == v {
+			typed, ok := resolved[match[1]]
+			return typed, ok
+		}
+		return placeholderPattern.ReplaceAllStringFunc(v, func(placeholder string) string {
+			typed, ok := resolved[placeholder[2:len(placeholder)-1]]
+			if !ok {
+				return ""
+			}
+			if list, isList := typed.([]string); isList {
+				return strings.Join(list, ",")
+			}
+			return fmt.Sprint(typed)
+		}), true
+	default:
+		return value, true
+	}
+}
+
+func placeholdersIn(value any) []string {
+	var names []string
+	switch v := value.(type) {
+	case map[string]any:
+		for _, item := range v {
+			names = append(names, placeholdersIn(item)...)
+		}
+	case []any:
+		for _, item := range v {
+			names = append(names, placeholdersIn(item)...)
+		}
+	case string:
+		for _, match := range placeholderPattern.FindAllStringSubmatch(v, -1) {
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// redactTemplate returns a copy of the template with the sensitive values of
+// its node configs redacted like pipeline graphs are. Values holding a ${name}
+// placeholder stay visible, as they aren't secrets until the template is
+// instantiated.
+func redactTemplate(template models.PipelineTemplate) models.PipelineTemplate {
+	redacted := secrets.RedactGraph(template.PipelineGraph)
+	for i, node := range template.PipelineGraph.Nodes {
+		config, _ := keepPlaceholders(node.Config, redacted.Nodes[i].Config).(map[string]any)
+		redacted.Nodes[i].Config = config
+	}
+	template.PipelineGraph = redacted
+	return template
+}
+
+// keepPlaceholders puts the strings of original that hold a placeholder back
+// into its redacted copy.
+func keepPlaceholders(original, redacted any) any {
+	switch v := original.(type) {
+	case map[string]any:
+		out, _ := redacted.(map[string]any)
+		for key, item := range v {
+			out[key] = keepPlaceholders(item, out[key])
+		}
+		return out
+	case []any:
+		out, _ := redacted.([]any)
+		for i, item := range v {
+			out[i] = keepPlaceholders(item, out[i])
+		}
+		return out
+	case string:
+		if placeholderPattern.MatchString(v) {
+			return v
+		}
+	}
+	return redacted
+}
+
+// coerceParameter converts a JSON-decoded value to the parameter's type.
+// Strings are parsed for the scalar types so values can come from
+// environment variables.
+func coerceParameter(paramType string, value any) (any, error) {
+	switch paramType {
+	case models.TemplateParamString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case models.TemplateParamInt:
+		switch v := value.(type) {
+		case float64:
+			if v == float64(int64(v)) {
+				return int64(v), nil
+			}
+		case int:
+			return int64(v), nil
+		case int64:
+			return v, nil
+		case string:
+			if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return i, nil
+			}
+		}
+	case models.TemplateParamNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case string:
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return f, nil
+			}
+		}
+	case models.TemplateParamBool:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b, nil
+			}
+		}
+	case models.TemplateParamStringList:
+		switch v := value.(type) {
+		case []string:
+			return v, nil
+		case []any:
+			list := make([]string, 0, len(v))
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("expected a list of strings")
+				}
+				list = append(list, s)
+			}
+			return list, nil
+		case string:
+			var list []string
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+			return list, nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown parameter type %q", paramType)
+	}
+	return nil, fmt.Errorf("expected a value of type %s, got %v", paramType, value)
+}
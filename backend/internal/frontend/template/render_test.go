@@ -0,0 +1,86 @@
+package frontendtemplate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func kafkaTemplate() *models.PipelineTemplate {
+	return &models.PipelineTemplate{
+		Name:      "kafka-logs",
+		CreatedBy: "admin",
+		Parameters: []models.TemplateParameter{
+			{Name: "kafka_brokers", Type: models.TemplateParamStringList, Required: true},
+			{Name: "topic", Type: models.TemplateParamString, Default: "logs"},
+			{Name: "sampling_pct", Type: models.TemplateParamNumber, Default: 10.0},
+			{Name: "client_id", Type: models.TemplateParamString},
+		},
+		PipelineGraph: models.PipelineGraph{
+			Nodes: []models.PipelineNodes{
+				{ComponentID: 1, Name: "sampler", ComponentName: "probabilisticsampler_processor", ComponentRole: "processor",
+					Config: map[string]any{"sampling_percentage": "${sampling_pct}"}},
+				{ComponentID: 2, Name: "kafka", ComponentName: "kafka_exporter", ComponentRole: "exporter",
+					Config: map[string]any{
+						"brokers":   "${kafka_brokers}",
+						"topic":     "otel-${topic}",
+						"client_id": "${client_id}",
+						"auth":      map[string]any{"plain_text": map[string]any{"password": "${env:KAFKA_PASSWORD}"}},
+					}},
+			},
+			Edges: []models.PipelineEdges{{Source: "1", Target: "2"}},
+		},
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	assert.NoError(t, ValidateTemplate(kafkaTemplate()))
+
+	undeclared := kafkaTemplate()
+	undeclared.Parameters = undeclared.Parameters[1:]
+	assert.ErrorIs(t, ValidateTemplate(undeclared), ErrInvalidTemplate)
+
+	badDefault := kafkaTemplate()
+	badDefault.Parameters[2].Default = "ten"
+	assert.ErrorIs(t, ValidateTemplate(badDefault), ErrInvalidTemplate)
+
+	badType := kafkaTemplate()
+	badType.Parameters[0].Type = "map"
+	assert.ErrorIs(t, ValidateTemplate(badType), ErrInvalidTemplate)
+}
+
+func TestRenderTemplate(t *testing.T) {
+	template := kafkaTemplate()
+
+	graph, err := RenderTemplate(template, map[string]any{
+		"kafka_brokers": []any{"a:9092", "b:9092"},
+		"sampling_pct":  "25",
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 25.0, graph.Nodes[0].Config["sampling_percentage"])
+	kafka := graph.Nodes[1].Config
+	assert.Equal(t, []string{"a:9092", "b:9092"}, kafka["brokers"])
+	assert.Equal(t, "otel-logs", kafka["topic"])
+	assert.NotContains(t, kafka, "client_id")
+	assert.Equal(t, "${env:KAFKA_PASSWORD}", kafka["auth"].(map[string]any)["plain_text"].(map[string]any)["password"])
+
+	// The template itself is untouched
+	assert.Equal(t, "${kafka_brokers}", template.PipelineGraph.Nodes[1].Config["brokers"])
+}
+
+func TestRenderTemplate_InvalidParameters(t *testing.T) {
+	tests := map[string]map[string]any{
+		"missing required": {},
+		"unknown":          {"kafka_brokers": []any{"a:9092"}, "partitions": 3},
+		"wrong type":       {"kafka_brokers": []any{"a:9092"}, "sampling_pct": true},
+	}
+	for name, params := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := RenderTemplate(kafkaTemplate(), params)
+			assert.True(t, errors.Is(err, ErrInvalidTemplate), "got %v", err)
+		})
+	}
+}
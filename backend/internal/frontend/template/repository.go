@@ -0,0 +1,144 @@
+package frontendtemplate
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+)
+
+type FrontendTemplateRepository struct {
+	db *sql.DB
+}
+
+// NewFrontendTemplateRepository creates a new FrontendTemplateRepository
+func NewFrontendTemplateRepository(db *sql.DB) *FrontendTemplateRepository {
+	return &FrontendTemplateRepository{db: db}
+}
+
+const selectTemplateColumns = "SELECT template_id, name, description, parameters_json, graph_json, created_by, created_at, updated_at FROM pipeline_templates"
+
+func (f *FrontendTemplateRepository) TemplateExists(templateId int) bool {
+	var verifyId int
+	err := f.db.QueryRow("SELECT template_id FROM pipeline_templates WHERE template_id = ? LIMIT 1", templateId).Scan(&verifyId)
+	return err == nil
+}
+
+func (f *FrontendTemplateRepository) GetAllTemplates() ([]models.PipelineTemplate, error) {
+	rows, err := f.db.Query(selectTemplateColumns + " ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []models.PipelineTemplate{}
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+	return templates, rows.Err()
+}
+
+func (f *FrontendTemplateRepository) GetTemplate(templateId int) (*models.PipelineTemplate, error) {
+	template, err := scanTemplate(f.db.QueryRow(selectTemplateColumns+" WHERE template_id = ?", templateId))
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrTemplateDoesNotExists
+	}
+	return template, err
+}
+
+func (f *FrontendTemplateRepository) GetTemplateByName(name string) (*models.PipelineTemplate, error) {
+	template, err := scanTemplate(f.db.QueryRow(selectTemplateColumns+" WHERE name = ?", name))
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrTemplateDoesNotExists
+	}
+	return template, err
+}
+
+func (f *FrontendTemplateRepository) CreateTemplate(template models.PipelineTemplate) (int, error) {
+	parametersJSON, graphJSON, err := marshalTemplate(template)
+	if err != nil {
+		return 0, err
+	}
+
+	now := utils.GetCurrentTime()
+	result, err := f.db.Exec(
+		"INSERT INTO pipeline_templates (name, description, parameters_json, graph_json, created_by, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		template.Name, template.Description, parametersJSON, graphJSON, template.CreatedBy, now, now,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert template: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get template ID: %w", err)
+	}
+	return int(id), nil
+}
+
+func (f *FrontendTemplateRepository) UpdateTemplate(template models.PipelineTemplate) error {
+	parametersJSON, graphJSON, err := marshalTemplate(template)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.db.Exec(
+		"UPDATE pipeline_templates SET name = ?, description = ?, parameters_json = ?, graph_json = ?, updated_at = ? WHERE template_id = ?",
+		template.Name, template.Description, parametersJSON, graphJSON, utils.GetCurrentTime(), template.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update template: %w", err)
+	}
+	return nil
+}
+
+func (f *FrontendTemplateRepository) DeleteTemplate(templateId int) error {
+	_, err := f.db.Exec("DELETE FROM pipeline_templates WHERE template_id = ?", templateId)
+	if err != nil {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTemplate(row rowScanner) (*models.PipelineTemplate, error) {
+	var template models.PipelineTemplate
+	var parametersJSON, graphJSON string
+	err := row.Scan(&template.ID, &template.Name, &template.Description, &parametersJSON, &graphJSON,
+		&template.CreatedBy, &template.CreatedAt, &template.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(parametersJSON), &template.Parameters); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal template parameters: %w", err)
+	}
+	if err := json.Unmarshal([]byte(graphJSON), &template.PipelineGraph); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal template graph: %w", err)
+	}
+	return &template, nil
+}
+
+func marshalTemplate(template models.PipelineTemplate) (string, string, error) {
+	if template.Parameters == nil {
+		template.Parameters = []models.TemplateParameter{}
+	}
+	parametersJSON, err := json.Marshal(template.Parameters)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal template parameters: %w", err)
+	}
+	graphJSON, err := json.Marshal(template.PipelineGraph)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal template graph: %w", err)
+	}
+	return string(parametersJSON), string(graphJSON), nil
+}
@@ -0,0 +1,43 @@
+package frontendtemplate
+
+import (
+	"testing"
+
+	database "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/db"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateRepository_CRUD(t *testing.T) {
+	db, err := database.DBInit(":memory:")
+	assert.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	repo := NewFrontendTemplateRepository(db)
+
+	id, err := repo.CreateTemplate(*kafkaTemplate())
+	assert.NoError(t, err)
+	assert.True(t, repo.TemplateExists(id))
+
+	stored, err := repo.GetTemplateByName("kafka-logs")
+	assert.NoError(t, err)
+	assert.Equal(t, id, stored.ID)
+	assert.Len(t, stored.Parameters, 4)
+	assert.Equal(t, "${kafka_brokers}", stored.PipelineGraph.Nodes[1].Config["brokers"])
+
+	stored.Description = "Ship logs to Kafka"
+	assert.NoError(t, repo.UpdateTemplate(*stored))
+
+	templates, err := repo.GetAllTemplates()
+	assert.NoError(t, err)
+	assert.Len(t, templates, 1)
+	assert.Equal(t, "Ship logs to Kafka", templates[0].Description)
+
+	_, err = repo.CreateTemplate(*kafkaTemplate())
+	assert.True(t, utils.IsUniqueViolation(err))
+
+	assert.NoError(t, repo.DeleteTemplate(id))
+	_, err = repo.GetTemplate(id)
+	assert.ErrorIs(t, err, utils.ErrTemplateDoesNotExists)
+}
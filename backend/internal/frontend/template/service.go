@@ -0,0 +1,130 @@
+package frontendtemplate
+
+import (
+	"fmt"
+
+	frontendpipeline "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/pipeline"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/secrets"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+)
+
+type FrontendTemplateRepositoryInterface interface {
+	TemplateExists(templateId int) bool
+	GetAllTemplates() ([]models.PipelineTemplate, error)
+	GetTemplate(templateId int) (*models.PipelineTemplate, error)
+	GetTemplateByName(name string) (*models.PipelineTemplate, error)
+	CreateTemplate(template models.PipelineTemplate) (int, error)
+	UpdateTemplate(template models.PipelineTemplate) error
+	DeleteTemplate(templateId int) error
+}
+
+type FrontendTemplateServiceInterface interface {
+	GetAllTemplates() ([]models.PipelineTemplate, error)
+	GetTemplate(templateId int) (*models.PipelineTemplate, error)
+	CreateTemplate(template models.PipelineTemplate) (int, error)
+	UpdateTemplate(templateId int, template models.PipelineTemplate) error
+	DeleteTemplate(templateId int) error
+	InstantiateTemplate(templateId int, req models.InstantiateTemplateRequest) (string, error)
+	RenderTemplateByName(name string, params map[string]any) (*models.PipelineGraph, error)
+}
+
+type FrontendTemplateService struct {
+	FrontendTemplateRepository FrontendTemplateRepositoryInterface
+	FrontendPipelineService    frontendpipeline.FrontendPipelineServiceInterface
+}
+
+// NewFrontendTemplateService creates a new FrontendTemplateService
+func NewFrontendTemplateService(frontendTemplateRepository FrontendTemplateRepositoryInterface, frontendPipelineService frontendpipeline.FrontendPipelineServiceInterface) FrontendTemplateServiceInterface {
+	return &FrontendTemplateService{
+		FrontendTemplateRepository: frontendTemplateRepository,
+		FrontendPipelineService:    frontendPipelineService,
+	}
+}
+
+func (f *FrontendTemplateService) GetAllTemplates() ([]models.PipelineTemplate, error) {
+	return f.FrontendTemplateRepository.GetAllTemplates()
+}
+
+func (f *FrontendTemplateService) GetTemplate(templateId int) (*models.PipelineTemplate, error) {
+	return f.FrontendTemplateRepository.GetTemplate(templateId)
+}
+
+func (f *FrontendTemplateService) CreateTemplate(template models.PipelineTemplate) (int, error) {
+	if err := ValidateTemplate(&template); err != nil {
+		return 0, err
+	}
+
+	id, err := f.FrontendTemplateRepository.CreateTemplate(template)
+	if utils.IsUniqueViolation(err) {
+		return 0, fmt.Errorf("%w: a template named %s already exists", ErrInvalidTemplate, template.Name)
+	}
+	return id, err
+}
+
+func (f *FrontendTemplateService) UpdateTemplate(templateId int, template models.PipelineTemplate) error {
+	if !f.FrontendTemplateRepository.TemplateExists(templateId) {
+		return utils.ErrTemplateDoesNotExists
+	}
+
+	// Templates fetched from the API have sensitive values redacted; keep the
+	// stored values wherever they come back unchanged
+	stored, err := f.FrontendTemplateRepository.GetTemplate(templateId)
+	if err != nil {
+		return err
+	}
+	template.PipelineGraph = secrets.RestoreRedacted(template.PipelineGraph, stored.PipelineGraph)
+
+	if err := ValidateTemplate(&template); err != nil {
+		return err
+	}
+
+	template.ID = templateId
+	err = f.FrontendTemplateRepository.UpdateTemplate(template)
+	if utils.IsUniqueViolation(err) {
+		return fmt.Errorf("%w: a template named %s already exists", ErrInvalidTemplate, template.Name)
+	}
+	return err
+}
+
+func (f *FrontendTemplateService) DeleteTemplate(templateId int) error {
+	if !f.FrontendTemplateRepository.TemplateExists(templateId) {
+		return utils.ErrTemplateDoesNotExists
+	}
+	return f.FrontendTemplateRepository.DeleteTemplate(templateId)
+}
+
+// InstantiateTemplate renders the template with the given parameters and
+// creates a pipeline from the result.
+func (f *FrontendTemplateService) InstantiateTemplate(templateId int, req models.InstantiateTemplateRequest) (string, error) {
+	if req.Name == "" || req.CreatedBy == "" {
+		return "", fmt.Errorf("%w: name and created_by are required", ErrInvalidTemplate)
+	}
+
+	template, err := f.FrontendTemplateRepository.GetTemplate(templateId)
+	if err != nil {
+		return "", err
+	}
+
+	graph, err := RenderTemplate(template, req.Parameters)
+	if err != nil {
+		return "", err
+	}
+
+	return f.FrontendPipelineService.CreatePipeline(models.CreatePipelineRequest{
+		Name:          req.Name,
+		CreatedBy:     req.CreatedBy,
+		AgentIDs:      req.AgentIDs,
+		PipelineGraph: *graph,
+	})
+}
+
+// RenderTemplateByName renders a template looked up by name. Agents use it to
+// pick a template when they register with a pipeline name.
+func (f *FrontendTemplateService) RenderTemplateByName(name string, params map[string]any) (*models.PipelineGraph, error) {
+	template, err := f.FrontendTemplateRepository.GetTemplateByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return RenderTemplate(template, params)
+}
@@ -0,0 +1,123 @@
+package frontendtemplate
+
+import (
+	"testing"
+
+	frontendpipeline "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/pipeline"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/secrets"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepo struct {
+	mock.Mock
+}
+
+func (m *MockRepo) TemplateExists(templateId int) bool {
+	return m.Called(templateId).Bool(0)
+}
+func (m *MockRepo) GetAllTemplates() ([]models.PipelineTemplate, error) {
+	args := m.Called()
+	return args.Get(0).([]models.PipelineTemplate), args.Error(1)
+}
+func (m *MockRepo) GetTemplate(templateId int) (*models.PipelineTemplate, error) {
+	args := m.Called(templateId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PipelineTemplate), args.Error(1)
+}
+func (m *MockRepo) GetTemplateByName(name string) (*models.PipelineTemplate, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PipelineTemplate), args.Error(1)
+}
+func (m *MockRepo) CreateTemplate(template models.PipelineTemplate) (int, error) {
+	args := m.Called(template)
+	return args.Int(0), args.Error(1)
+}
+func (m *MockRepo) UpdateTemplate(template models.PipelineTemplate) error {
+	return m.Called(template).Error(0)
+}
+func (m *MockRepo) DeleteTemplate(templateId int) error {
+	return m.Called(templateId).Error(0)
+}
+
+// MockPipelineService only implements CreatePipeline; the embedded interface
+// panics if anything else is called.
+type MockPipelineService struct {
+	frontendpipeline.FrontendPipelineServiceInterface
+	mock.Mock
+}
+
+func (m *MockPipelineService) CreatePipeline(req models.CreatePipelineRequest) (string, error) {
+	args := m.Called(req)
+	return args.String(0), args.Error(1)
+}
+
+func TestCreateTemplate_Service_Invalid(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := NewFrontendTemplateService(mockRepo, nil)
+
+	template := kafkaTemplate()
+	template.Name = ""
+	_, err := service.CreateTemplate(*template)
+	assert.ErrorIs(t, err, ErrInvalidTemplate)
+	mockRepo.AssertNotCalled(t, "CreateTemplate", mock.Anything)
+}
+
+func TestUpdateTemplate_Service_NotFound(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := NewFrontendTemplateService(mockRepo, nil)
+
+	mockRepo.On("TemplateExists", 9).Return(false)
+	err := service.UpdateTemplate(9, *kafkaTemplate())
+	assert.ErrorIs(t, err, utils.ErrTemplateDoesNotExists)
+}
+
+func TestUpdateTemplate_Service_KeepsRedactedSecrets(t *testing.T) {
+	mockRepo := new(MockRepo)
+	service := NewFrontendTemplateService(mockRepo, nil)
+
+	stored := kafkaTemplate()
+	stored.PipelineGraph.Nodes[1].Config["auth"] = map[string]any{"plain_text": map[string]any{"password": "hunter2"}}
+	fetched := redactTemplate(*stored)
+	assert.Equal(t, secrets.RedactedValue, fetched.PipelineGraph.Nodes[1].Config["auth"].(map[string]any)["plain_text"].(map[string]any)["password"])
+	fetched.Description = "Kafka logs"
+
+	mockRepo.On("TemplateExists", 1).Return(true)
+	mockRepo.On("GetTemplate", 1).Return(stored, nil)
+	mockRepo.On("UpdateTemplate", mock.MatchedBy(func(template models.PipelineTemplate) bool {
+		password := template.PipelineGraph.Nodes[1].Config["auth"].(map[string]any)["plain_text"].(map[string]any)["password"]
+		return template.ID == 1 && template.Description == "Kafka logs" && password == "hunter2"
+	})).Return(nil)
+
+	assert.NoError(t, service.UpdateTemplate(1, fetched))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestInstantiateTemplate_Service(t *testing.T) {
+	mockRepo := new(MockRepo)
+	mockPipelines := new(MockPipelineService)
+	service := NewFrontendTemplateService(mockRepo, mockPipelines)
+
+	mockRepo.On("GetTemplate", 1).Return(kafkaTemplate(), nil)
+	mockPipelines.On("CreatePipeline", mock.MatchedBy(func(req models.CreatePipelineRequest) bool {
+		return req.Name == "edge-logs" &&
+			req.CreatedBy == "admin" &&
+			assert.ObjectsAreEqual([]string{"k:9092"}, req.PipelineGraph.Nodes[1].Config["brokers"])
+	})).Return("5", nil)
+
+	id, err := service.InstantiateTemplate(1, models.InstantiateTemplateRequest{
+		Name:       "edge-logs",
+		CreatedBy:  "admin",
+		Parameters: map[string]any{"kafka_brokers": "k:9092"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "5", id)
+	mockPipelines.AssertExpectations(t)
+}
@@ -0,0 +1,165 @@
+package frontendupgrade
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+	"github.com/gorilla/mux"
+)
+
+// FrontendUpgradeHandler handles agent artifacts, fleet versions and upgrades
+type FrontendUpgradeHandler struct {
+	FrontendUpgradeService FrontendUpgradeServiceInterface
+}
+
+// NewFrontendUpgradeHandler initializes the handler
+func NewFrontendUpgradeHandler(frontendUpgradeService FrontendUpgradeServiceInterface) *FrontendUpgradeHandler {
+	return &FrontendUpgradeHandler{
+		FrontendUpgradeService: frontendUpgradeService,
+	}
+}
+
+func (f *FrontendUpgradeHandler) GetAllArtifacts(w http.ResponseWriter, r *http.Request) {
+	utils.Logger.Info("Request received to get all agent artifacts")
+
+	response, err := f.FrontendUpgradeService.GetAllArtifacts()
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error while getting all agent artifacts: %v", err))
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (f *FrontendUpgradeHandler) CreateArtifact(w http.ResponseWriter, r *http.Request) {
+	var req models.AgentArtifact
+	if err := utils.UnmarshalJSONRequest(r, &req); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to create agent artifact %s for %s/%s", req.Version, req.OS, req.Arch))
+
+	artifactId, err := f.FrontendUpgradeService.CreateArtifact(req)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error creating agent artifact %s: %v", req.Version, err))
+		sendUpgradeError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]any{"message": "Agent artifact created successfully", "id": artifactId})
+}
+
+func (f *FrontendUpgradeHandler) DeleteArtifact(w http.ResponseWriter, r *http.Request) {
+	artifactId := mux.Vars(r)["id"]
+	artifactIdInt, err := strconv.Atoi(artifactId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid artifact ID format")
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to delete agent artifact with ID: %s", artifactId))
+
+	if err := f.FrontendUpgradeService.DeleteArtifact(artifactIdInt); err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error deleting agent artifact [ID: %s]: %v", artifactId, err))
+		sendUpgradeError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Agent artifact deleted successfully"})
+}
+
+func (f *FrontendUpgradeHandler) GetFleetVersions(w http.ResponseWriter, r *http.Request) {
+	utils.Logger.Info("Request received to get agent versions")
+
+	response, err := f.FrontendUpgradeService.GetFleetVersions()
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error while getting agent versions: %v", err))
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (f *FrontendUpgradeHandler) StartUpgrade(w http.ResponseWriter, r *http.Request) {
+	var req models.AgentUpgradeRequest
+	if err := utils.UnmarshalJSONRequest(r, &req); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Request received to upgrade agents to version %s", req.Version))
+
+	job, err := f.FrontendUpgradeService.StartUpgrade(req)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error upgrading agents to version %s: %v", req.Version, err))
+		sendUpgradeError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusAccepted, job)
+}
+
+// GetUpgrades lists the latest upgrade attempts, of one agent if the
+// agent_id query parameter is set.
+func (f *FrontendUpgradeHandler) GetUpgrades(w http.ResponseWriter, r *http.Request) {
+	agentId := 0
+	if param := r.URL.Query().Get("agent_id"); param != "" {
+		var err error
+		if agentId, err = strconv.Atoi(param); err != nil {
+			utils.SendJSONError(w, http.StatusBadRequest, "Invalid agent ID format")
+			return
+		}
+	}
+
+	utils.Logger.Info("Request received to get agent upgrades")
+
+	response, err := f.FrontendUpgradeService.GetUpgrades(agentId)
+	if err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error while getting agent upgrades: %v", err))
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+// ReportUpgrade receives an agent's report of how an upgrade ended.
+func (f *FrontendUpgradeHandler) ReportUpgrade(w http.ResponseWriter, r *http.Request) {
+	upgradeId := mux.Vars(r)["id"]
+	upgradeIdInt, err := strconv.Atoi(upgradeId)
+	if err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, "Invalid upgrade ID format")
+		return
+	}
+
+	var req models.AgentUpgradeReport
+	if err := utils.UnmarshalJSONRequest(r, &req); err != nil {
+		utils.SendJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid payload: %v", err))
+		return
+	}
+
+	utils.Logger.Info(fmt.Sprintf("Agent reported upgrade [ID: %s] %s", upgradeId, req.Status))
+
+	if err := f.FrontendUpgradeService.ReportUpgrade(upgradeIdInt, req); err != nil {
+		utils.Logger.Error(fmt.Sprintf("Error recording upgrade [ID: %s]: %v", upgradeId, err))
+		sendUpgradeError(w, err)
+		return
+	}
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Upgrade status recorded"})
+}
+
+// sendUpgradeError maps service errors to status codes: 404 for a missing
+// artifact, upgrade, agent or group, 400 for invalid requests and 500
+// otherwise.
+func sendUpgradeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, utils.ErrArtifactDoesNotExists), errors.Is(err, utils.ErrUpgradeDoesNotExists),
+		errors.Is(err, utils.ErrAgentDoesNotExists), errors.Is(err, utils.ErrGroupDoesNotExists):
+		utils.SendJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, utils.ErrInvalidUpgrade):
+		utils.SendJSONError(w, http.StatusBadRequest, err.Error())
+	default:
+		utils.SendJSONError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,196 @@
+package frontendupgrade
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+)
+
+// upgradeHistory is how many upgrade attempts are listed by default.
+const upgradeHistory = 100
+
+type FrontendUpgradeRepository struct {
+	db *sql.DB
+}
+
+// NewFrontendUpgradeRepository creates a new FrontendUpgradeRepository
+func NewFrontendUpgradeRepository(db *sql.DB) *FrontendUpgradeRepository {
+	return &FrontendUpgradeRepository{db: db}
+}
+
+func (f *FrontendUpgradeRepository) GetAllArtifacts() ([]models.AgentArtifact, error) {
+	rows, err := f.db.Query("SELECT artifact_id, version, os, arch, url, sha256, signature, created_at FROM agent_artifacts ORDER BY created_at DESC, artifact_id DESC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agent artifacts: %w", err)
+	}
+	defer rows.Close()
+
+	artifacts := []models.AgentArtifact{}
+	for rows.Next() {
+		var artifact models.AgentArtifact
+		if err := rows.Scan(&artifact.ID, &artifact.Version, &artifact.OS, &artifact.Arch, &artifact.URL, &artifact.SHA256, &artifact.Signature, &artifact.CreatedAt); err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, artifact)
+	}
+	return artifacts, rows.Err()
+}
+
+// GetArtifact returns the artifact of a version for a platform.
+func (f *FrontendUpgradeRepository) GetArtifact(version, os, arch string) (*models.AgentArtifact, error) {
+	var artifact models.AgentArtifact
+	err := f.db.QueryRow(
+		"SELECT artifact_id, version, os, arch, url, sha256, signature, created_at FROM agent_artifacts WHERE version = ? AND os = ? AND arch = ?",
+		version, os, arch,
+	).Scan(&artifact.ID, &artifact.Version, &artifact.OS, &artifact.Arch, &artifact.URL, &artifact.SHA256, &artifact.Signature, &artifact.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrArtifactDoesNotExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agent artifact: %w", err)
+	}
+	return &artifact, nil
+}
+
+func (f *FrontendUpgradeRepository) VersionExists(version string) bool {
+	var exists bool
+	err := f.db.QueryRow("SELECT EXISTS(SELECT 1 FROM agent_artifacts WHERE version = ?)", version).Scan(&exists)
+	return err == nil && exists
+}
+
+func (f *FrontendUpgradeRepository) CreateArtifact(artifact models.AgentArtifact) (int, error) {
+	result, err := f.db.Exec(
+		"INSERT INTO agent_artifacts (version, os, arch, url, sha256, signature, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		artifact.Version, artifact.OS, artifact.Arch, artifact.URL, artifact.SHA256, artifact.Signature, artifact.CreatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+func (f *FrontendUpgradeRepository) DeleteArtifact(artifactId int) error {
+	result, err := f.db.Exec("DELETE FROM agent_artifacts WHERE artifact_id = ?", artifactId)
+	if err != nil {
+		return fmt.Errorf("failed to delete agent artifact: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return utils.ErrArtifactDoesNotExists
+	}
+	return nil
+}
+
+// GetAgentVersions returns the version of every agent by agent ID.
+func (f *FrontendUpgradeRepository) GetAgentVersions() (map[int]string, error) {
+	rows, err := f.db.Query("SELECT id, COALESCE(version, '') FROM agents")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agent versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := map[int]string{}
+	for rows.Next() {
+		var id int
+		var version string
+		if err := rows.Scan(&id, &version); err != nil {
+			return nil, err
+		}
+		versions[id] = version
+	}
+	return versions, rows.Err()
+}
+
+// GetAgent returns what an upgrade needs to know about an agent.
+func (f *FrontendUpgradeRepository) GetAgent(agentId int) (*models.AgentInfoHome, string, error) {
+	var agent models.AgentInfoHome
+	var platform string
+	err := f.db.QueryRow(
+		"SELECT id, name, COALESCE(version, ''), COALESCE(hostname, ''), COALESCE(ip, ''), COALESCE(platform, '') FROM agents WHERE id = ?", agentId,
+	).Scan(&agent.ID, &agent.Name, &agent.Version, &agent.Hostname, &agent.IP, &platform)
+	if err == sql.ErrNoRows {
+		return nil, "", utils.ErrAgentDoesNotExists
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query agent: %w", err)
+	}
+	return &agent, platform, nil
+}
+
+func (f *FrontendUpgradeRepository) SetAgentVersion(agentId int, version string) error {
+	_, err := f.db.Exec("UPDATE agents SET version = ? WHERE id = ?", version, agentId)
+	return err
+}
+
+func (f *FrontendUpgradeRepository) CreateUpgrade(upgrade models.AgentUpgrade) (int, error) {
+	result, err := f.db.Exec(
+		"INSERT INTO agent_upgrades (agent_id, job_id, from_version, to_version, status, error, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		upgrade.AgentID, upgrade.JobID, upgrade.FromVersion, upgrade.ToVersion, upgrade.Status, upgrade.Error, upgrade.CreatedAt, upgrade.UpdatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert agent upgrade: %w", err)
+	}
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+func (f *FrontendUpgradeRepository) GetUpgrade(upgradeId int) (*models.AgentUpgrade, error) {
+	upgrades, err := f.queryUpgrades("WHERE upgrade_id = ?", upgradeId)
+	if err != nil {
+		return nil, err
+	}
+	if len(upgrades) == 0 {
+		return nil, utils.ErrUpgradeDoesNotExists
+	}
+	return &upgrades[0], nil
+}
+
+// GetInProgressUpgrade returns the upgrade a job started on an agent that is
+// still waiting for the agent's report, or nil if there is none.
+func (f *FrontendUpgradeRepository) GetInProgressUpgrade(agentId int, jobId string) (*models.AgentUpgrade, error) {
+	upgrades, err := f.queryUpgrades("WHERE agent_id = ? AND job_id = ? AND status = ? ORDER BY upgrade_id DESC LIMIT 1", agentId, jobId, models.UpgradeInProgress)
+	if err != nil || len(upgrades) == 0 {
+		return nil, err
+	}
+	return &upgrades[0], nil
+}
+
+// GetUpgrades returns the latest upgrade attempts, newest first, of one agent
+// or of all agents when agentId is 0.
+func (f *FrontendUpgradeRepository) GetUpgrades(agentId int) ([]models.AgentUpgrade, error) {
+	if agentId != 0 {
+		return f.queryUpgrades("WHERE agent_id = ? ORDER BY upgrade_id DESC LIMIT ?", agentId, upgradeHistory)
+	}
+	return f.queryUpgrades("ORDER BY upgrade_id DESC LIMIT ?", upgradeHistory)
+}
+
+func (f *FrontendUpgradeRepository) UpdateUpgradeStatus(upgradeId int, status, errMsg string, updatedAt int64) error {
+	_, err := f.db.Exec(
+		"UPDATE agent_upgrades SET status = ?, error = ?, updated_at = ? WHERE upgrade_id = ?",
+		status, errMsg, updatedAt, upgradeId,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update agent upgrade: %w", err)
+	}
+	return nil
+}
+
+func (f *FrontendUpgradeRepository) queryUpgrades(clause string, args ...any) ([]models.AgentUpgrade, error) {
+	rows, err := f.db.Query("SELECT upgrade_id, agent_id, job_id, from_version, to_version, status, error, created_at, updated_at FROM agent_upgrades "+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agent upgrades: %w", err)
+	}
+	defer rows.Close()
+
+	upgrades := []models.AgentUpgrade{}
+	for rows.Next() {
+		var upgrade models.AgentUpgrade
+		if err := rows.Scan(&upgrade.ID, &upgrade.AgentID, &upgrade.JobID, &upgrade.FromVersion, &upgrade.ToVersion, &upgrade.Status, &upgrade.Error, &upgrade.CreatedAt, &upgrade.UpdatedAt); err != nil {
+			return nil, err
+		}
+		upgrades = append(upgrades, upgrade)
+	}
+	return upgrades, rows.Err()
+}
@@ -0,0 +1,407 @@
+package frontendupgrade
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/constants"
+	frontendgroup "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/group"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/agentclient"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/jobs"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+)
+
+// upgradeJobType is the job type of agent upgrade rollouts.
+const upgradeJobType = "agent_upgrade"
+
+type FrontendUpgradeRepositoryInterface interface {
+	GetAllArtifacts() ([]models.AgentArtifact, error)
+	GetArtifact(version, os, arch string) (*models.AgentArtifact, error)
+	VersionExists(version string) bool
+	CreateArtifact(artifact models.AgentArtifact) (int, error)
+	DeleteArtifact(artifactId int) error
+	GetAgentVersions() (map[int]string, error)
+	GetAgent(agentId int) (*models.AgentInfoHome, string, error)
+	SetAgentVersion(agentId int, version string) error
+	CreateUpgrade(upgrade models.AgentUpgrade) (int, error)
+	GetUpgrade(upgradeId int) (*models.AgentUpgrade, error)
+	GetInProgressUpgrade(agentId int, jobId string) (*models.AgentUpgrade, error)
+	GetUpgrades(agentId int) ([]models.AgentUpgrade, error)
+	UpdateUpgradeStatus(upgradeId int, status, errMsg string, updatedAt int64) error
+}
+
+type FrontendUpgradeServiceInterface interface {
+	GetAllArtifacts() ([]models.AgentArtifact, error)
+	CreateArtifact(artifact models.AgentArtifact) (int, error)
+	DeleteArtifact(artifactId int) error
+	GetFleetVersions() ([]models.FleetVersion, error)
+	StartUpgrade(req models.AgentUpgradeRequest) (*models.Job, error)
+	GetUpgrades(agentId int) ([]models.AgentUpgrade, error)
+	ReportUpgrade(upgradeId int, report models.AgentUpgradeReport) error
+}
+
+type FrontendUpgradeService struct {
+	FrontendUpgradeRepository FrontendUpgradeRepositoryInterface
+	FrontendGroupService      frontendgroup.FrontendGroupServiceInterface
+	Jobs                      *jobs.Manager
+	// SendUpgrade tells an agent to upgrade
+	SendUpgrade func(agent models.AgentInfoHome, command models.AgentUpgradeCommand) error
+	// PollInterval is how often an upgrade in progress is checked for the
+	// agent's report, which has to arrive within Timeout.
+	PollInterval time.Duration
+	Timeout      time.Duration
+}
+
+// NewFrontendUpgradeService creates a new FrontendUpgradeService. Upgrades run
+// as jobs of the job manager, which the service registers its executor with.
+func NewFrontendUpgradeService(frontendUpgradeRepository FrontendUpgradeRepositoryInterface, frontendGroupService frontendgroup.FrontendGroupServiceInterface, jobManager *jobs.Manager) FrontendUpgradeServiceInterface {
+	f := &FrontendUpgradeService{
+		FrontendUpgradeRepository: frontendUpgradeRepository,
+		FrontendGroupService:      frontendGroupService,
+		Jobs:                      jobManager,
+		SendUpgrade:               sendUpgradeToAgent,
+		PollInterval:              time.Second,
+		Timeout:                   time.Duration(constants.UPGRADE_TIMEOUT_SEC) * time.Second,
+	}
+	jobManager.Register(upgradeJobType, f.runUpgradeTarget)
+	return f
+}
+
+func (f *FrontendUpgradeService) GetAllArtifacts() ([]models.AgentArtifact, error) {
+	return f.FrontendUpgradeRepository.GetAllArtifacts()
+}
+
+// CreateArtifact registers a released agent binary. The checksum and
+// signature are only checked for their format here; agents verify them
+// against the binary they download.
+func (f *FrontendUpgradeService) CreateArtifact(artifact models.AgentArtifact) (int, error) {
+	if artifact.Version == "" || artifact.OS == "" || artifact.Arch == "" {
+		return 0, fmt.Errorf("%w: version, os and arch are required", utils.ErrInvalidUpgrade)
+	}
+	if u, err := url.Parse(artifact.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return 0, fmt.Errorf("%w: url must be an http(s) URL", utils.ErrInvalidUpgrade)
+	}
+	if digest, err := hex.DecodeString(artifact.SHA256); err != nil || len(digest) != 32 {
+		return 0, fmt.Errorf("%w: sha256 must be a hex SHA-256 digest", utils.ErrInvalidUpgrade)
+	}
+	if signature, err := base64.StdEncoding.DecodeString(artifact.Signature); err != nil || len(signature) != ed25519.SignatureSize {
+		return 0, fmt.Errorf("%w: signature must be a base64 Ed25519 signature", utils.ErrInvalidUpgrade)
+	}
+	artifact.SHA256 = strings.ToLower(artifact.SHA256)
+	artifact.Version = strings.TrimPrefix(artifact.Version, "v")
+	artifact.CreatedAt = utils.GetCurrentTime()
+
+	id, err := f.FrontendUpgradeRepository.CreateArtifact(artifact)
+	if utils.IsUniqueViolation(err) {
+		return 0, fmt.Errorf("%w: an artifact of version %s for %s/%s already exists", utils.ErrInvalidUpgrade, artifact.Version, artifact.OS, artifact.Arch)
+	}
+	return id, err
+}
+
+func (f *FrontendUpgradeService) DeleteArtifact(artifactId int) error {
+	return f.FrontendUpgradeRepository.DeleteArtifact(artifactId)
+}
+
+// GetFleetVersions counts the agents per version, newest version first.
+func (f *FrontendUpgradeService) GetFleetVersions() ([]models.FleetVersion, error) {
+	versions, err := f.FrontendUpgradeRepository.GetAgentVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[string]*models.FleetVersion{}
+	for agentId, version := range versions {
+		if byVersion[version] == nil {
+			byVersion[version] = &models.FleetVersion{Version: version, AgentIDs: []int{}}
+		}
+		byVersion[version].Agents++
+		byVersion[version].AgentIDs = append(byVersion[version].AgentIDs, agentId)
+	}
+
+	fleet := []models.FleetVersion{}
+	for _, version := range byVersion {
+		sort.Ints(version.AgentIDs)
+		fleet = append(fleet, *version)
+	}
+	sort.Slice(fleet, func(i, j int) bool { return compareVersions(fleet[i].Version, fleet[j].Version) > 0 })
+	return fleet, nil
+}
+
+// StartUpgrade starts a job upgrading the agents to the requested version
+// and returns it. BatchSize agents upgrade at a time, one if unset, and the
+// job halts once an upgrade fails so a bad version doesn't reach the rest of
+// the fleet.
+func (f *FrontendUpgradeService) StartUpgrade(req models.AgentUpgradeRequest) (*models.Job, error) {
+	req.Version = strings.TrimPrefix(req.Version, "v")
+	if req.Version == "" {
+		return nil, fmt.Errorf("%w: version is required", utils.ErrInvalidUpgrade)
+	}
+	if !f.FrontendUpgradeRepository.VersionExists(req.Version) {
+		return nil, fmt.Errorf("%w: no artifact of version %s", utils.ErrArtifactDoesNotExists, req.Version)
+	}
+	if req.BatchSize < 0 {
+		return nil, fmt.Errorf("%w: batch_size must not be negative", utils.ErrInvalidUpgrade)
+	}
+	if req.BatchSize == 0 {
+		req.BatchSize = 1
+	}
+
+	agentIds, err := f.upgradeTargets(req)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]string, len(agentIds))
+	for i, agentId := range agentIds {
+		targets[i] = strconv.Itoa(agentId)
+	}
+	return f.Jobs.EnqueueWithOptions(upgradeJobType, targets, req, models.JobOptions{Concurrency: req.BatchSize, HaltOnFailure: true})
+}
+
+// upgradeTargets resolves the agents of an upgrade request.
+func (f *FrontendUpgradeService) upgradeTargets(req models.AgentUpgradeRequest) ([]int, error) {
+	if (len(req.AgentIDs) == 0) == (req.GroupID == 0) {
+		return nil, fmt.Errorf("%w: set either agent_ids or group_id", utils.ErrInvalidUpgrade)
+	}
+
+	if req.GroupID != 0 {
+		agents, err := f.FrontendGroupService.GetGroupAgents(req.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		if len(agents) == 0 {
+			return nil, fmt.Errorf("%w: the group has no agents", utils.ErrInvalidUpgrade)
+		}
+		agentIds := make([]int, len(agents))
+		for i, agent := range agents {
+			agentIds[i] = int(agent.ID)
+		}
+		return agentIds, nil
+	}
+
+	versions, err := f.FrontendUpgradeRepository.GetAgentVersions()
+	if err != nil {
+		return nil, err
+	}
+	seen := map[int]bool{}
+	var agentIds []int
+	for _, agentId := range req.AgentIDs {
+		if _, ok := versions[agentId]; !ok {
+			return nil, fmt.Errorf("%w: %d", utils.ErrAgentDoesNotExists, agentId)
+		}
+		if !seen[agentId] {
+			seen[agentId] = true
+			agentIds = append(agentIds, agentId)
+		}
+	}
+	return agentIds, nil
+}
+
+func (f *FrontendUpgradeService) GetUpgrades(agentId int) ([]models.AgentUpgrade, error) {
+	return f.FrontendUpgradeRepository.GetUpgrades(agentId)
+}
+
+// ReportUpgrade records how an upgrade ended, as reported by the agent. The
+// agent reports by upgrade ID because it registers anew after restarting.
+func (f *FrontendUpgradeService) ReportUpgrade(upgradeId int, report models.AgentUpgradeReport) error {
+	switch report.Status {
+	case models.UpgradeSucceeded, models.UpgradeFailed, models.UpgradeRolledBack:
+	default:
+		return fmt.Errorf("%w: status %q must be %s, %s or %s", utils.ErrInvalidUpgrade, report.Status,
+			models.UpgradeSucceeded, models.UpgradeFailed, models.UpgradeRolledBack)
+	}
+
+	upgrade, err := f.FrontendUpgradeRepository.GetUpgrade(upgradeId)
+	if err != nil {
+		return err
+	}
+	if upgrade.Status != models.UpgradeInProgress {
+		utils.Logger.Sugar().Warnf("Agent upgrade [ID:%d] reported %s after it was marked %s", upgradeId, report.Status, upgrade.Status)
+	}
+
+	if err := f.FrontendUpgradeRepository.UpdateUpgradeStatus(upgradeId, report.Status, report.Error, utils.GetCurrentTime()); err != nil {
+		return err
+	}
+	if report.Status == models.UpgradeSucceeded {
+		version := upgrade.ToVersion
+		if report.Version != "" {
+			version = strings.TrimPrefix(report.Version, "v")
+		}
+		return f.FrontendUpgradeRepository.SetAgentVersion(upgrade.AgentID, version)
+	}
+	utils.Logger.Sugar().Warnf("Agent [ID:%d] upgrade to %s %s: %s", upgrade.AgentID, upgrade.ToVersion, report.Status, report.Error)
+	return nil
+}
+
+// runUpgradeTarget is the job executor of upgrades. It tells the agent to
+// upgrade and waits for its report. Only failing to reach the agent is
+// retried; a failed upgrade isn't. An upgrade the job already started, e.g.
+// before a backend restart, is waited on instead of being sent again.
+func (f *FrontendUpgradeService) runUpgradeTarget(jobId, target string, params json.RawMessage) error {
+	var req models.AgentUpgradeRequest
+	if err := json.Unmarshal(params, &req); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid upgrade params: %w", err))
+	}
+	agentId, err := strconv.Atoi(target)
+	if err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid agent ID %q", target))
+	}
+
+	started, err := f.FrontendUpgradeRepository.GetInProgressUpgrade(agentId, jobId)
+	if err != nil {
+		return err
+	}
+	if started != nil {
+		return f.awaitUpgrade(started.ID, req.Version, f.Timeout-time.Since(time.Unix(started.CreatedAt, 0)))
+	}
+
+	agent, platform, err := f.FrontendUpgradeRepository.GetAgent(agentId)
+	if err != nil {
+		if errors.Is(err, utils.ErrAgentDoesNotExists) {
+			return jobs.Permanent(err)
+		}
+		return err
+	}
+	if strings.TrimPrefix(agent.Version, "v") == req.Version {
+		return nil
+	}
+
+	os, arch, ok := strings.Cut(platform, "/")
+	if !ok {
+		return jobs.Permanent(fmt.Errorf("agent platform %q doesn't name an OS and architecture", platform))
+	}
+	artifact, err := f.FrontendUpgradeRepository.GetArtifact(req.Version, os, arch)
+	if err != nil {
+		if errors.Is(err, utils.ErrArtifactDoesNotExists) {
+			return jobs.Permanent(fmt.Errorf("%w: no artifact of version %s for %s", err, req.Version, platform))
+		}
+		return err
+	}
+
+	now := utils.GetCurrentTime()
+	upgradeId, err := f.FrontendUpgradeRepository.CreateUpgrade(models.AgentUpgrade{
+		AgentID:     agentId,
+		JobID:       jobId,
+		FromVersion: agent.Version,
+		ToVersion:   req.Version,
+		Status:      models.UpgradeInProgress,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = f.SendUpgrade(*agent, models.AgentUpgradeCommand{
+		UpgradeID: upgradeId,
+		Version:   artifact.Version,
+		URL:       artifact.URL,
+		SHA256:    artifact.SHA256,
+		Signature: artifact.Signature,
+	})
+	if err != nil {
+		f.finishUpgrade(upgradeId, models.UpgradeFailed, err.Error())
+		return err
+	}
+	return f.awaitUpgrade(upgradeId, req.Version, f.Timeout)
+}
+
+// awaitUpgrade waits up to timeout for the agent's report and fails the
+// target unless the upgrade succeeded.
+func (f *FrontendUpgradeService) awaitUpgrade(upgradeId int, version string, timeout time.Duration) error {
+	upgrade, err := f.waitForUpgrade(upgradeId, timeout)
+	if err != nil {
+		f.finishUpgrade(upgradeId, models.UpgradeFailed, err.Error())
+		return jobs.Permanent(err)
+	}
+	if upgrade.Status != models.UpgradeSucceeded {
+		return jobs.Permanent(fmt.Errorf("upgrade to %s %s: %s", version, upgrade.Status, upgrade.Error))
+	}
+	return nil
+}
+
+// waitForUpgrade waits for the agent to report how the upgrade ended.
+func (f *FrontendUpgradeService) waitForUpgrade(upgradeId int, wait time.Duration) (*models.AgentUpgrade, error) {
+	ticker := time.NewTicker(f.PollInterval)
+	defer ticker.Stop()
+	timeout := time.After(wait)
+
+	for {
+		upgrade, err := f.FrontendUpgradeRepository.GetUpgrade(upgradeId)
+		if err != nil {
+			return nil, err
+		}
+		if upgrade.Status != models.UpgradeInProgress {
+			return upgrade, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-timeout:
+			return nil, fmt.Errorf("agent didn't report the upgrade within %s", f.Timeout)
+		}
+	}
+}
+
+func (f *FrontendUpgradeService) finishUpgrade(upgradeId int, status, errMsg string) {
+	if err := f.FrontendUpgradeRepository.UpdateUpgradeStatus(upgradeId, status, errMsg, utils.GetCurrentTime()); err != nil {
+		utils.Logger.Sugar().Errorf("Failed to update agent upgrade [ID:%d]: %v", upgradeId, err)
+	}
+}
+
+// sendUpgradeToAgent posts the upgrade command to the agent, by hostname and
+// then by IP.
+func sendUpgradeToAgent(agent models.AgentInfoHome, command models.AgentUpgradeCommand) error {
+	body, err := json.Marshal(command)
+	if err != nil {
+		return err
+	}
+
+	resp, err := agentclient.Do(agent, http.MethodPost, "/agent/v1/upgrade", body)
+	if err != nil {
+		return err
+	}
+	if !resp.OK() {
+		return fmt.Errorf("agent rejected upgrade with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// compareVersions compares dotted version numbers numerically, falling back
+// to comparing the text of parts that aren't numbers.
+func compareVersions(a, b string) int {
+	aParts := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bParts := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < max(len(aParts), len(bParts)); i++ {
+		var aPart, bPart string
+		if i < len(aParts) {
+			aPart = aParts[i]
+		}
+		if i < len(bParts) {
+			bPart = bParts[i]
+		}
+		aNum, aErr := strconv.Atoi(aPart)
+		bNum, bErr := strconv.Atoi(bPart)
+		switch {
+		case aErr == nil && bErr == nil && aNum != bNum:
+			if aNum < bNum {
+				return -1
+			}
+			return 1
+		case (aErr != nil || bErr != nil) && aPart != bPart:
+			return strings.Compare(aPart, bPart)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,243 @@
+package frontendupgrade
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"sync"
+	"testing"
+	"time"
+
+	database "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/db"
+	frontendgroup "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/frontend/group"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/pkg/jobs"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupUpgradeService(t *testing.T) (*FrontendUpgradeService, *jobs.Manager) {
+	db, err := database.DBInit(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+
+	for id, platform := range map[int]string{1: "linux/amd64", 2: "linux/amd64", 3: "linux", 4: "linux/amd64"} {
+		_, err := db.Exec("INSERT INTO agents (id, name, type, version, hostname, platform, registered_at, ip) VALUES (?, ?, 'OTEL', '1.0.0', 'host', ?, 0, '127.0.0.1')", id, "agent", platform)
+		require.NoError(t, err)
+	}
+
+	jobManager := jobs.NewManager(jobs.NewJobRepository(db))
+	jobManager.Backoff = time.Millisecond
+	groupService := frontendgroup.NewFrontendGroupService(frontendgroup.NewFrontendGroupRepository(db), nil, jobManager)
+	service := NewFrontendUpgradeService(NewFrontendUpgradeRepository(db), groupService, jobManager).(*FrontendUpgradeService)
+	service.PollInterval = time.Millisecond
+	service.Timeout = time.Second
+	return service, jobManager
+}
+
+func signedArtifact(version string) models.AgentArtifact {
+	_, key, _ := ed25519.GenerateKey(nil)
+	digest := sha256.Sum256([]byte("agent " + version))
+	return models.AgentArtifact{
+		Version:   version,
+		OS:        "linux",
+		Arch:      "amd64",
+		URL:       "https://downloads.example.com/agent-" + version,
+		SHA256:    hex.EncodeToString(digest[:]),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, digest[:])),
+	}
+}
+
+func waitForJob(t *testing.T, jobManager *jobs.Manager, id string) *models.Job {
+	t.Helper()
+	var job *models.Job
+	assert.Eventually(t, func() bool {
+		job, _ = jobManager.GetJob(id)
+		return job != nil && job.Status != models.JobRunning
+	}, 5*time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestCreateArtifact(t *testing.T) {
+	service, _ := setupUpgradeService(t)
+
+	artifact := signedArtifact("v1.1.0")
+	id, err := service.CreateArtifact(artifact)
+	assert.NoError(t, err)
+	assert.NotZero(t, id)
+
+	_, err = service.CreateArtifact(artifact)
+	assert.ErrorIs(t, err, utils.ErrInvalidUpgrade)
+
+	invalid := signedArtifact("1.2.0")
+	invalid.SHA256 = "abc"
+	_, err = service.CreateArtifact(invalid)
+	assert.ErrorIs(t, err, utils.ErrInvalidUpgrade)
+
+	invalid = signedArtifact("1.2.0")
+	invalid.Signature = base64.StdEncoding.EncodeToString([]byte("short"))
+	_, err = service.CreateArtifact(invalid)
+	assert.ErrorIs(t, err, utils.ErrInvalidUpgrade)
+
+	invalid = signedArtifact("1.2.0")
+	invalid.URL = "file:///tmp/agent"
+	_, err = service.CreateArtifact(invalid)
+	assert.ErrorIs(t, err, utils.ErrInvalidUpgrade)
+
+	artifacts, err := service.GetAllArtifacts()
+	assert.NoError(t, err)
+	require.Len(t, artifacts, 1)
+	assert.Equal(t, "1.1.0", artifacts[0].Version)
+
+	assert.NoError(t, service.DeleteArtifact(id))
+	assert.ErrorIs(t, service.DeleteArtifact(id), utils.ErrArtifactDoesNotExists)
+}
+
+func TestStartUpgradeValidation(t *testing.T) {
+	service, _ := setupUpgradeService(t)
+	_, err := service.CreateArtifact(signedArtifact("1.1.0"))
+	require.NoError(t, err)
+
+	_, err = service.StartUpgrade(models.AgentUpgradeRequest{Version: "9.9.9", AgentIDs: []int{1}})
+	assert.ErrorIs(t, err, utils.ErrArtifactDoesNotExists)
+
+	_, err = service.StartUpgrade(models.AgentUpgradeRequest{Version: "1.1.0"})
+	assert.ErrorIs(t, err, utils.ErrInvalidUpgrade)
+
+	_, err = service.StartUpgrade(models.AgentUpgradeRequest{Version: "1.1.0", AgentIDs: []int{1}, GroupID: 1})
+	assert.ErrorIs(t, err, utils.ErrInvalidUpgrade)
+
+	_, err = service.StartUpgrade(models.AgentUpgradeRequest{Version: "1.1.0", AgentIDs: []int{42}})
+	assert.ErrorIs(t, err, utils.ErrAgentDoesNotExists)
+
+	_, err = service.StartUpgrade(models.AgentUpgradeRequest{Version: "1.1.0", GroupID: 42})
+	assert.ErrorIs(t, err, utils.ErrGroupDoesNotExists)
+}
+
+func TestUpgradeRolloutHaltsOnFailure(t *testing.T) {
+	service, jobManager := setupUpgradeService(t)
+	artifact := signedArtifact("1.1.0")
+	_, err := service.CreateArtifact(artifact)
+	require.NoError(t, err)
+
+	// Agent 2 fails its health checks after upgrading and rolls back
+	var mu sync.Mutex
+	var upgraded []int64
+	service.SendUpgrade = func(agent models.AgentInfoHome, command models.AgentUpgradeCommand) error {
+		mu.Lock()
+		upgraded = append(upgraded, agent.ID)
+		mu.Unlock()
+		assert.Equal(t, artifact.URL, command.URL)
+		assert.Equal(t, artifact.Signature, command.Signature)
+
+		report := models.AgentUpgradeReport{Status: models.UpgradeSucceeded, Version: "1.1.0"}
+		if agent.ID == 2 {
+			report = models.AgentUpgradeReport{Status: models.UpgradeRolledBack, Version: "1.0.0", Error: "collector didn't start"}
+		}
+		go func() { assert.NoError(t, service.ReportUpgrade(command.UpgradeID, report)) }()
+		return nil
+	}
+
+	job, err := service.StartUpgrade(models.AgentUpgradeRequest{Version: "v1.1.0", AgentIDs: []int{1, 2, 1, 4}})
+	require.NoError(t, err)
+	assert.Equal(t, models.JobOptions{Concurrency: 1, HaltOnFailure: true}, job.Options)
+	assert.Equal(t, 3, job.Total)
+
+	finished := waitForJob(t, jobManager, job.ID)
+	assert.Equal(t, models.JobFailed, finished.Status)
+	assert.Equal(t, models.JobCompleted, finished.Results[0].Status)
+	assert.Equal(t, models.JobFailed, finished.Results[1].Status)
+	assert.Equal(t, "upgrade to 1.1.0 rolled_back: collector didn't start", finished.Results[1].Error)
+	assert.Equal(t, models.JobCancelled, finished.Results[2].Status)
+	assert.Equal(t, []int64{1, 2}, upgraded)
+
+	upgrades, err := service.GetUpgrades(2)
+	assert.NoError(t, err)
+	require.Len(t, upgrades, 1)
+	assert.Equal(t, models.UpgradeRolledBack, upgrades[0].Status)
+	assert.Equal(t, "1.0.0", upgrades[0].FromVersion)
+
+	fleet, err := service.GetFleetVersions()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.FleetVersion{
+		{Version: "1.1.0", Agents: 1, AgentIDs: []int{1}},
+		{Version: "1.0.0", Agents: 3, AgentIDs: []int{2, 3, 4}},
+	}, fleet)
+
+	// Agents already on the version are skipped, and an agent whose platform
+	// has no artifact fails
+	job, err = service.StartUpgrade(models.AgentUpgradeRequest{Version: "1.1.0", AgentIDs: []int{1, 3}, BatchSize: 2})
+	require.NoError(t, err)
+	finished = waitForJob(t, jobManager, job.ID)
+	assert.Equal(t, models.JobCompleted, finished.Results[0].Status)
+	assert.Equal(t, models.JobFailed, finished.Results[1].Status)
+	assert.Equal(t, 1, finished.Results[1].Attempts)
+	assert.Equal(t, []int64{1, 2}, upgraded)
+}
+
+func TestUpgradeTimesOut(t *testing.T) {
+	service, jobManager := setupUpgradeService(t)
+	_, err := service.CreateArtifact(signedArtifact("1.1.0"))
+	require.NoError(t, err)
+	service.Timeout = 20 * time.Millisecond
+	service.SendUpgrade = func(models.AgentInfoHome, models.AgentUpgradeCommand) error { return nil }
+
+	job, err := service.StartUpgrade(models.AgentUpgradeRequest{Version: "1.1.0", AgentIDs: []int{1}})
+	require.NoError(t, err)
+	finished := waitForJob(t, jobManager, job.ID)
+	assert.Equal(t, models.JobFailed, finished.Status)
+
+	upgrades, err := service.GetUpgrades(0)
+	assert.NoError(t, err)
+	require.Len(t, upgrades, 1)
+	assert.Equal(t, models.UpgradeFailed, upgrades[0].Status)
+	assert.Contains(t, upgrades[0].Error, "didn't report the upgrade")
+}
+
+func TestUpgradeResumesInProgressUpgrade(t *testing.T) {
+	service, _ := setupUpgradeService(t)
+	_, err := service.CreateArtifact(signedArtifact("1.1.0"))
+	require.NoError(t, err)
+	sent := 0
+	service.SendUpgrade = func(models.AgentInfoHome, models.AgentUpgradeCommand) error {
+		sent++
+		return nil
+	}
+
+	// The job sent the upgrade before the backend restarted
+	now := utils.GetCurrentTime()
+	upgradeId, err := service.FrontendUpgradeRepository.CreateUpgrade(models.AgentUpgrade{
+		AgentID: 1, JobID: "job-1", FromVersion: "1.0.0", ToVersion: "1.1.0",
+		Status: models.UpgradeInProgress, CreatedAt: now, UpdatedAt: now,
+	})
+	require.NoError(t, err)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		assert.NoError(t, service.ReportUpgrade(upgradeId, models.AgentUpgradeReport{Status: models.UpgradeSucceeded, Version: "1.1.0"}))
+	}()
+
+	assert.NoError(t, service.runUpgradeTarget("job-1", "1", []byte(`{"version":"1.1.0"}`)))
+	assert.Equal(t, 0, sent)
+	upgrades, err := service.GetUpgrades(1)
+	assert.NoError(t, err)
+	require.Len(t, upgrades, 1)
+	assert.Equal(t, models.UpgradeSucceeded, upgrades[0].Status)
+	assert.Equal(t, "job-1", upgrades[0].JobID)
+}
+
+func TestReportUpgrade(t *testing.T) {
+	service, _ := setupUpgradeService(t)
+
+	assert.ErrorIs(t, service.ReportUpgrade(1, models.AgentUpgradeReport{Status: "done"}), utils.ErrInvalidUpgrade)
+	assert.ErrorIs(t, service.ReportUpgrade(1, models.AgentUpgradeReport{Status: models.UpgradeSucceeded}), utils.ErrUpgradeDoesNotExists)
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, 1, compareVersions("1.10.0", "1.9.2"))
+	assert.Equal(t, -1, compareVersions("v1.2", "1.2.1"))
+	assert.Equal(t, 0, compareVersions("v3.1.5", "3.1.5"))
+	assert.Equal(t, 1, compareVersions("1.0.0-rc2", "1.0.0-rc1"))
+}
@@ -45,3 +45,25 @@ func AuthMiddleware() func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// RequireRole only lets users with the given role through. It runs behind
+// AuthMiddleware, which puts the user's email in the request context, and
+// looks up their role with userRole.
+func RequireRole(role string, userRole func(email string) (string, error)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			email, _ := r.Context().Value(EmailContextKey).(string)
+			if email != "" && userRole != nil {
+				actual, err := userRole(email)
+				if err != nil {
+					utils.Logger.Sugar().Errorf("Failed to look up role of %s: %v", email, err)
+				}
+				if err == nil && actual == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			utils.SendJSONError(w, http.StatusForbidden, "Requires the "+role+" role")
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package middleware_test
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -104,3 +105,32 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 		t.Errorf("expected status 200, got %d", recorder.Code)
 	}
 }
+
+func TestRequireRole(t *testing.T) {
+	roles := map[string]string{"admin@example.com": "admin", "user@example.com": "user"}
+	userRole := func(email string) (string, error) {
+		if role, ok := roles[email]; ok {
+			return role, nil
+		}
+		return "", errors.New("user not found")
+	}
+
+	for email, expectCode := range map[string]int{
+		"admin@example.com":   http.StatusOK,
+		"user@example.com":    http.StatusForbidden,
+		"unknown@example.com": http.StatusForbidden,
+		"":                    http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/protected", nil)
+		if email != "" {
+			req = req.WithContext(context.WithValue(req.Context(), middleware.EmailContextKey, email))
+		}
+		recorder := httptest.NewRecorder()
+
+		middleware.RequireRole("admin", userRole)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(recorder, req)
+
+		if recorder.Code != expectCode {
+			t.Errorf("%q: expected status %d, got %d", email, expectCode, recorder.Code)
+		}
+	}
+}
@@ -3,15 +3,28 @@ package models
 import "time"
 
 type AgentRegisterRequest struct {
-	Name         string `json:"_"`             // The name of the agent
-	Version      string `json:"version"`       // The version of the agent
-	Hostname     string `json:"hostname"`      // The hostname of the machine running the agent
-	IP           string `json:"ip"`            // IP address of machine running the agent
-	Platform     string `json:"platform"`      // The platform (e.g., OS) the agent is running on
-	Type         string `json:"type"`          // The type of agent
-	PipelineName string `json:"pipeline_name"` // The name of the pipeline the agent is associated with
-	StartedBy    string `json:"started_by"`    // The user who started the agent
-	RegisteredAt int64  `json:"registered_at"` // The Unix timestamp when the agent was registered
+	Name             string            `json:"_"`                           // The name of the agent
+	Version          string            `json:"version"`                     // The version of the agent
+	Hostname         string            `json:"hostname"`                    // The hostname of the machine running the agent
+	IP               string            `json:"ip"`                          // IP address of machine running the agent
+	Platform         string            `json:"platform"`                    // The platform (e.g., OS) the agent is running on
+	Type             string            `json:"type"`                        // The type of agent
+	PipelineName     string            `json:"pipeline_name"`               // The name of the pipeline the agent is associated with
+	PipelineTemplate string            `json:"pipeline_template,omitempty"` // Template to build that pipeline from, instead of the default graph
+	TemplateParams   map[string]any    `json:"template_params,omitempty"`   // Parameters for PipelineTemplate
+	StartedBy        string            `json:"started_by"`                  // The user who started the agent
+	Labels           map[string]string `json:"labels,omitempty"`            // Labels matched against pipeline label selectors
+	Components       []AgentComponent  `json:"components,omitempty"`        // Collector components built into the agent
+	RegisteredAt     int64             `json:"registered_at"`               // The Unix timestamp when the agent was registered
+}
+
+// AgentComponent is a collector component built into an agent. Agents that
+// don't report their components are assumed to support the whole catalog.
+type AgentComponent struct {
+	Kind      string            `json:"kind"`           // receiver, processor, exporter, connector or extension
+	Type      string            `json:"type"`           // Collector component type, e.g. otlp
+	Name      string            `json:"name,omitempty"` // Catalog component name, e.g. otlp_receiver
+	Stability map[string]string `json:"stability"`      // Stability level per supported signal, e.g. {"traces": "stable"}
 }
 
 // AgentMetrics represents metrics related to an agent's performance.
@@ -0,0 +1,91 @@
+package models
+
+// Config delivery states.
+const (
+	ConfigDeliveryPending   = "pending"
+	ConfigDeliveryDelivered = "delivered"
+	ConfigDeliveryRejected  = "rejected" // the agent refused the config as invalid; not retried
+)
+
+// ConfigDelivery is the outbox entry for the config an agent should run.
+// ConfigVersion identifies the compiled config; pending deliveries are
+// resent from NextAttemptAt on.
+type ConfigDelivery struct {
+	AgentID       int    `json:"agent_id"`
+	PipelineID    int    `json:"pipeline_id"`
+	ConfigVersion string `json:"config_version"`
+	Status        string `json:"status"`
+	Attempts      int    `json:"attempts"`
+	LastError     string `json:"last_error,omitempty"`
+	NextAttemptAt int64  `json:"next_attempt_at,omitempty"`
+	UpdatedAt     int64  `json:"updated_at"`
+	DeliveredAt   int64  `json:"delivered_at,omitempty"`
+}
+
+// Outcomes of a config apply, as reported by agents.
+const (
+	ConfigApplied     = "applied"
+	ConfigApplyFailed = "failed"
+)
+
+// ComponentStatus is the status the collector reported for a component of an
+// applied config.
+type ComponentStatus struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ConfigApplyRequest is how an agent reports loading a config into its
+// collector.
+type ConfigApplyRequest struct {
+	ConfigHash string            `json:"config_hash"`
+	Status     string            `json:"status"`
+	Error      string            `json:"error"`
+	RolledBack bool              `json:"rolled_back"`
+	Components []ComponentStatus `json:"components"`
+}
+
+// ConfigApply records an agent's report of loading a config. ConfigVersion is
+// the hash of the config file, comparable with delivery config versions.
+type ConfigApply struct {
+	ID            int               `json:"id"`
+	AgentID       int               `json:"agent_id"`
+	PipelineID    *int              `json:"pipeline_id"` // the agent's pipeline when it reported
+	ConfigVersion string            `json:"config_version"`
+	Status        string            `json:"status"`
+	Error         string            `json:"error,omitempty"`
+	RolledBack    bool              `json:"rolled_back"` // a failed config was replaced by the agent's last-known-good config
+	Components    []ComponentStatus `json:"components"`
+	ReportedAt    int64             `json:"reported_at"`
+}
+
+// Rollout states of an agent, for the config its pipeline currently compiles
+// to. Agents whose delivery or apply is for an older config are outdated.
+const (
+	RolloutPending    = ConfigDeliveryPending
+	RolloutDelivered  = ConfigDeliveryDelivered // sent, but the agent hasn't reported applying it yet
+	RolloutRejected   = ConfigDeliveryRejected
+	RolloutApplied    = ConfigApplied
+	RolloutFailed     = ConfigApplyFailed
+	RolloutRolledBack = "rolled_back" // failed, and the agent went back to its last-known-good config
+	RolloutOutdated   = "outdated"
+)
+
+// RolloutAgent is how far an agent of a pipeline got with its config.
+type RolloutAgent struct {
+	AgentID  int             `json:"agent_id"`
+	Name     string          `json:"name"`
+	Status   string          `json:"status"`
+	Delivery *ConfigDelivery `json:"delivery"`
+	Apply    *ConfigApply    `json:"apply"` // the agent's last apply
+}
+
+// ConfigRollout is the progress of a pipeline's config across its agents.
+type ConfigRollout struct {
+	PipelineID    int            `json:"pipeline_id"`
+	ConfigVersion string         `json:"config_version"`
+	Summary       map[string]int `json:"summary"` // agents per rollout status
+	Agents        []RolloutAgent `json:"agents"`
+}
@@ -0,0 +1,43 @@
+package models
+
+// Drift policies, chosen per pipeline. They decide what happens when an agent
+// reports an effective config that differs from the pipeline's.
+const (
+	DriftPolicyAutoRemediate = "auto_remediate" // push the pipeline's config back to the agent; the default
+	DriftPolicyAlert         = "alert"          // only record the drift
+	DriftPolicyAdopt         = "adopt"          // make the agent's config the pipeline's new graph
+)
+
+// Config drift states.
+const (
+	ConfigInSync  = "in_sync"
+	ConfigDrifted = "drifted"
+)
+
+// Actions taken on a drifted agent.
+const (
+	DriftRemediated = "remediated"
+	DriftAlerted    = "alerted"
+	DriftAdopted    = "adopted"
+)
+
+// ConfigDrift compares the config an agent should run with the one it
+// reported running. Both are identified by their effective config hash.
+type ConfigDrift struct {
+	AgentID       int    `json:"agent_id"`
+	PipelineID    int    `json:"pipeline_id"`
+	Status        string `json:"status"`
+	DesiredHash   string `json:"desired_hash"`
+	EffectiveHash string `json:"effective_hash"`
+	Action        string `json:"action,omitempty"` // what the drift policy did about the last drift
+	Error         string `json:"error,omitempty"`  // why the action failed
+	DriftedSince  int64  `json:"drifted_since,omitempty"`
+	CheckedAt     int64  `json:"checked_at"`
+}
+
+// ConfigChangedRequest is what agents send when their config file changes.
+// ConfigHash is the effective config hash, or empty when the file is gone;
+// agents that predate drift detection don't send it.
+type ConfigChangedRequest struct {
+	ConfigHash *string `json:"config_hash"`
+}
@@ -0,0 +1,32 @@
+package models
+
+// AgentGroup is a named set of agents for bulk operations. Static groups list
+// their agents in AgentIDs; label groups have a Selector and contain every
+// agent whose labels match it.
+type AgentGroup struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Selector    string `json:"selector,omitempty"`
+	AgentIDs    []int  `json:"agent_ids,omitempty"`
+	CreatedBy   string `json:"created_by"`
+	CreatedAt   int64  `json:"created_at"`
+	UpdatedAt   int64  `json:"updated_at"`
+}
+
+// AgentGroupRequest creates or replaces an agent group. Set either Selector
+// or AgentIDs.
+type AgentGroupRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Selector    string `json:"selector"`
+	AgentIDs    []int  `json:"agent_ids"`
+	CreatedBy   string `json:"created_by"`
+}
+
+// BulkActionRequest runs an agent operation on every agent in a group.
+// Labels is required for the add_labels action.
+type BulkActionRequest struct {
+	Action string            `json:"action"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
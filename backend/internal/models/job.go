@@ -0,0 +1,45 @@
+package models
+
+// Job and job target states.
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job is a long-running operation over a set of targets, such as a bulk
+// action on an agent group. Progress is the percentage of targets that are
+// done, whether they succeeded, failed or were cancelled.
+type Job struct {
+	ID         string            `json:"id"`
+	Type       string            `json:"type"`
+	Status     string            `json:"status"`
+	Total      int               `json:"total"`
+	Succeeded  int               `json:"succeeded"`
+	Failed     int               `json:"failed"`
+	Progress   int               `json:"progress"`
+	Options    JobOptions        `json:"options"`
+	Results    []JobTargetResult `json:"results,omitempty"`
+	CreatedAt  int64             `json:"created_at"`
+	UpdatedAt  int64             `json:"updated_at"`
+	FinishedAt int64             `json:"finished_at,omitempty"`
+}
+
+// JobTargetResult is the outcome of a job for one target. Error holds the
+// last failure, also while the target waits for a retry.
+type JobTargetResult struct {
+	Target   string `json:"target"`
+	Status   string `json:"status"`
+	Attempts int    `json:"attempts"`
+	Error    string `json:"error,omitempty"`
+}
+
+// JobOptions changes how a job runs its targets. Concurrency overrides the
+// job manager's limit of targets in flight when set. A job that halts on
+// failure cancels its remaining targets once one fails.
+type JobOptions struct {
+	Concurrency   int  `json:"concurrency,omitempty"`
+	HaltOnFailure bool `json:"halt_on_failure,omitempty"`
+}
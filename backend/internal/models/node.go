@@ -11,7 +11,42 @@ type ComponentSchema struct {
 	Name       string          `db:"name"`        // e.g. otlp, batch, logging
 	Subtype    string          `db:"subtype"`     // Optional: grpc, http, etc.
 	SchemaJSON json.RawMessage `db:"schema_json"` // The full JSON schema
-	Version    string          `db:"version"`     // Optional version tracking
+	Version    int             `db:"version"`     // Bumped when existing configs need migrating
 	CreatedAt  time.Time       `db:"created_at"`
 	UpdatedAt  time.Time       `db:"updated_at"`
 }
+
+// CatalogComponent is a component_schemas entry: the catalog name, its role
+// (receiver, processor or exporter) and the signals it supports.
+type CatalogComponent struct {
+	Name             string
+	Role             string
+	SupportedSignals []string
+}
+
+// CustomComponentRequest registers a component_schemas entry for a component
+// of a custom-built collector. Type is the role: receiver, processor or
+// exporter.
+type CustomComponentRequest struct {
+	Name             string          `json:"name"`
+	Type             string          `json:"type"`
+	DisplayName      string          `json:"display_name"`
+	SupportedSignals []string        `json:"supported_signals"`
+	Schema           json.RawMessage `json:"schema"`
+	UISchema         json.RawMessage `json:"ui_schema"`
+}
+
+// ComponentMigration reports a node config upgraded to a newer version of
+// its component's schema. Error is set when the config was left as it was.
+type ComponentMigration struct {
+	ID            int64    `json:"id"`
+	PipelineID    int      `json:"pipeline_id"`
+	ComponentID   int      `json:"component_id"`
+	ComponentName string   `json:"component_name"`
+	Name          string   `json:"name"`
+	FromVersion   int      `json:"from_version"`
+	ToVersion     int      `json:"to_version"`
+	Changes       []string `json:"changes"`
+	Error         string   `json:"error,omitempty"`
+	MigratedAt    int64    `json:"migrated_at"`
+}
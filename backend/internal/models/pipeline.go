@@ -3,6 +3,7 @@ package models
 type CreatePipelineRequest struct {
 	Name          string        `json:"name"`
 	CreatedBy     string        `json:"created_by"`
+	Environment   string        `json:"environment,omitempty"` // Optional environment label, e.g. dev, staging or prod
 	AgentIDs      []int         `json:"agent_ids"`
 	PipelineGraph PipelineGraph `json:"pipeline_graph"`
 }
@@ -25,6 +26,146 @@ type PipelineEdges struct {
 
 // Struct for API response
 type PipelineGraph struct {
-	Nodes []PipelineNodes `json:"nodes"`
-	Edges []PipelineEdges `json:"edges"`
+	Nodes     []PipelineNodes    `json:"nodes"`
+	Edges     []PipelineEdges    `json:"edges"`
+	Telemetry *PipelineTelemetry `json:"telemetry,omitempty"`
+}
+
+// PipelineTelemetry controls the collector's own telemetry (service.telemetry)
+// for every agent attached to a pipeline.
+type PipelineTelemetry struct {
+	Logs    TelemetryLogs    `json:"logs"`
+	Metrics TelemetryMetrics `json:"metrics"`
+	Traces  TelemetryTraces  `json:"traces"`
+}
+
+type TelemetryLogs struct {
+	Level    string                `json:"level"` // debug, info, warn or error
+	Sampling *TelemetryLogSampling `json:"sampling,omitempty"`
+}
+
+type TelemetryLogSampling struct {
+	Enabled    bool   `json:"enabled"`
+	Tick       string `json:"tick"`       // e.g. 10s
+	Initial    int    `json:"initial"`    // entries logged per tick before sampling kicks in
+	Thereafter int    `json:"thereafter"` // afterwards, log every Nth entry
+}
+
+type TelemetryMetrics struct {
+	Level string `json:"level"` // basic, normal or detailed
+	Host  string `json:"host"`  // bind address of the Prometheus pull reader
+	Port  int    `json:"port"`  // port of the Prometheus pull reader, polled by the control plane
+	// Optional OTLP push reader for shipping collector metrics elsewhere
+	OTLPEndpoint string `json:"otlp_endpoint,omitempty"`
+	OTLPProtocol string `json:"otlp_protocol,omitempty"` // grpc or http/protobuf
+}
+
+type TelemetryTraces struct {
+	Level        string `json:"level,omitempty"` // none, basic, normal or detailed
+	OTLPEndpoint string `json:"otlp_endpoint,omitempty"`
+	OTLPProtocol string `json:"otlp_protocol,omitempty"` // grpc or http/protobuf
+}
+
+// ImportPipelineResponse is a collector config reverse-compiled into a graph,
+// ready to be submitted to POST /pipelines.
+type ImportPipelineResponse struct {
+	PipelineGraph PipelineGraph       `json:"pipeline_graph"`
+	Unmapped      []UnmappedComponent `json:"unmapped"`
+	Warnings      []string            `json:"warnings"`
+}
+
+// UnmappedComponent is a collector component that has no catalog entry, or
+// no place in a graph, and was left out of the imported graph.
+type UnmappedComponent struct {
+	ID     string `json:"id"`   // component ID as written in the config, e.g. otlp/in
+	Role   string `json:"role"` // receiver, processor, exporter, extension or connector
+	Reason string `json:"reason"`
+}
+
+// ClonePipelineRequest copies a pipeline's graph into a new pipeline.
+type ClonePipelineRequest struct {
+	Name        string `json:"name"`
+	CreatedBy   string `json:"created_by"`
+	Environment string `json:"environment,omitempty"`
+	AgentIDs    []int  `json:"agent_ids"`
+}
+
+// PromotePipelineRequest copies a pipeline's graph to the pipeline with the
+// same name in the target environment, creating it if needed.
+type PromotePipelineRequest struct {
+	To        string `json:"to"` // target environment; defaults to the next one after the source
+	CreatedBy string `json:"created_by"`
+}
+
+// PipelinePromotion describes a promotion: the pipelines involved and what
+// would change (preview) or changed (apply) in the target.
+type PipelinePromotion struct {
+	SourceID          int          `json:"source_id"`
+	SourceEnvironment string       `json:"source_environment"`
+	TargetEnvironment string       `json:"target_environment"`
+	TargetID          *int         `json:"target_id"` // nil when the target pipeline doesn't exist yet
+	Diff              PipelineDiff `json:"diff"`
+}
+
+// PipelineDiff compares two graphs. Nodes are matched by component name and
+// node name because their IDs differ between pipelines; edges are given as
+// node names.
+type PipelineDiff struct {
+	NodesAdded       []PipelineNodes      `json:"nodes_added"`
+	NodesRemoved     []PipelineNodes      `json:"nodes_removed"`
+	NodesChanged     []PipelineNodeChange `json:"nodes_changed"`
+	EdgesAdded       []PipelineEdges      `json:"edges_added"`
+	EdgesRemoved     []PipelineEdges      `json:"edges_removed"`
+	TelemetryChanged bool                 `json:"telemetry_changed"`
+}
+
+type PipelineNodeChange struct {
+	Name          string         `json:"name"`
+	ComponentName string         `json:"component_name"`
+	Before        map[string]any `json:"before"`
+	After         map[string]any `json:"after"`
+}
+
+// PipelineSelector is a pipeline's agent label selector.
+type PipelineSelector struct {
+	PipelineID int    `json:"pipeline_id"`
+	Name       string `json:"name"`
+	Selector   string `json:"selector"`
+}
+
+// AgentLabels is an agent with its labels and current pipeline.
+type AgentLabels struct {
+	ID         int64             `json:"id"`
+	Name       string            `json:"name"`
+	PipelineID *int              `json:"pipeline_id"`
+	Labels     map[string]string `json:"labels"`
+}
+
+// SelectorMatch explains whether an agent matches a pipeline's selector and
+// whether it is attached.
+type SelectorMatch struct {
+	AgentID      int64               `json:"agent_id"`
+	AgentName    string              `json:"agent_name"`
+	Labels       map[string]string   `json:"labels"`
+	Matched      bool                `json:"matched"`
+	Attached     bool                `json:"attached"`
+	PipelineID   *int                `json:"pipeline_id"`     // pipeline the agent is attached to, if any
+	Error        string              `json:"error,omitempty"` // why the last selector update couldn't attach the agent
+	Requirements []RequirementResult `json:"requirements"`
+}
+
+// RequirementResult explains how one selector requirement evaluated against
+// an agent's labels.
+type RequirementResult struct {
+	Requirement string `json:"requirement"`
+	Matched     bool   `json:"matched"`
+	Reason      string `json:"reason"`
+}
+
+// PipelineSelectorStatus is a pipeline's selector with the match result of
+// every agent.
+type PipelineSelectorStatus struct {
+	Selector string          `json:"selector"`
+	Attached []int64         `json:"attached,omitempty"` // agents attached by the last selector update
+	Agents   []SelectorMatch `json:"agents"`
 }
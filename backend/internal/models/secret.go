@@ -0,0 +1,20 @@
+package models
+
+// Secret is a stored secret's metadata. The value is never returned.
+type Secret struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	CreatedBy   string `json:"created_by"`
+	CreatedAt   int64  `json:"created_at"`
+	UpdatedAt   int64  `json:"updated_at"`
+}
+
+// SecretRequest creates or replaces a secret. Node configs reference it as
+// ${secret:name}.
+type SecretRequest struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Description string `json:"description"`
+	CreatedBy   string `json:"created_by"`
+}
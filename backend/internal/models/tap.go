@@ -0,0 +1,43 @@
+package models
+
+import "encoding/json"
+
+// Tap limits. A tap stops at whichever comes first.
+const (
+	TapDefaultLimit       = 100
+	TapMaxLimit           = 1000
+	TapDefaultDurationSec = 60
+	TapMaxDurationSec     = 600
+)
+
+// TapOptions says what to sample from a pipeline node: items of Signal (log
+// records, spans or metrics), at most Limit per agent, for DurationSec.
+type TapOptions struct {
+	Signal      string `json:"signal"`
+	Limit       int    `json:"limit"`
+	DurationSec int    `json:"duration_sec"`
+}
+
+// TapRequest asks an agent to tap a receiver or processor of its config.
+// Component is the collector ID the node compiles to.
+type TapRequest struct {
+	ID          string `json:"id"`
+	Component   string `json:"component"`
+	Role        string `json:"role"`
+	Signal      string `json:"signal"`
+	Limit       int    `json:"limit"`
+	DurationSec int    `json:"duration_sec"`
+}
+
+// TapSample is telemetry an agent caught with a tap, as OTLP/JSON. The last
+// sample of each agent is Done and says why its tap ended: limit, timeout,
+// stopped or config_replaced.
+type TapSample struct {
+	TapID   string          `json:"tap_id"`
+	AgentID int64           `json:"agent_id"`
+	Signal  string          `json:"signal"`
+	Count   int             `json:"count"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Done    bool            `json:"done"`
+	Reason  string          `json:"reason,omitempty"`
+}
@@ -0,0 +1,40 @@
+package models
+
+// Template parameter types
+const (
+	TemplateParamString     = "string"
+	TemplateParamInt        = "int"
+	TemplateParamNumber     = "number"
+	TemplateParamBool       = "bool"
+	TemplateParamStringList = "string_list"
+)
+
+// TemplateParameter declares a ${name} placeholder used in a template's node
+// configs.
+type TemplateParameter struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"` // string, int, number, bool or string_list
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
+	Default     any    `json:"default,omitempty"`
+}
+
+// PipelineTemplate is a reusable pipeline graph with typed parameters.
+type PipelineTemplate struct {
+	ID            int                 `json:"id"`
+	Name          string              `json:"name"`
+	Description   string              `json:"description"`
+	Parameters    []TemplateParameter `json:"parameters"`
+	PipelineGraph PipelineGraph       `json:"pipeline_graph"`
+	CreatedBy     string              `json:"created_by"`
+	CreatedAt     int64               `json:"created_at"`
+	UpdatedAt     int64               `json:"updated_at"`
+}
+
+// InstantiateTemplateRequest creates a pipeline from a template.
+type InstantiateTemplateRequest struct {
+	Name       string         `json:"name"`
+	CreatedBy  string         `json:"created_by"`
+	AgentIDs   []int          `json:"agent_ids"`
+	Parameters map[string]any `json:"parameters"`
+}
@@ -0,0 +1,71 @@
+package models
+
+// Agent upgrade states. An upgrade is in progress from when the agent is told
+// to upgrade until it reports back; rolled_back means the new version failed
+// its health checks and the agent went back to its previous binary.
+const (
+	UpgradeInProgress = "in_progress"
+	UpgradeSucceeded  = "succeeded"
+	UpgradeFailed     = "failed"
+	UpgradeRolledBack = "rolled_back"
+)
+
+// AgentArtifact is a released agent binary for one platform. The backend
+// points agents to its URL; agents check the download against SHA256 and
+// the Ed25519 Signature of the SHA-256 digest before running it.
+type AgentArtifact struct {
+	ID        int    `json:"id"`
+	Version   string `json:"version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	URL       string `json:"url"`
+	SHA256    string `json:"sha256"`
+	Signature string `json:"signature"` // base64
+	CreatedAt int64  `json:"created_at"`
+}
+
+// AgentUpgradeRequest upgrades the listed agents, or the agents of a group,
+// to Version. BatchSize agents are upgraded at a time; the rollout stops at
+// the first agent whose upgrade fails.
+type AgentUpgradeRequest struct {
+	Version   string `json:"version"`
+	AgentIDs  []int  `json:"agent_ids,omitempty"`
+	GroupID   int    `json:"group_id,omitempty"`
+	BatchSize int    `json:"batch_size,omitempty"`
+}
+
+// AgentUpgrade is one attempt to upgrade an agent.
+type AgentUpgrade struct {
+	ID          int    `json:"id"`
+	AgentID     int    `json:"agent_id"`
+	JobID       string `json:"job_id,omitempty"`
+	FromVersion string `json:"from_version"`
+	ToVersion   string `json:"to_version"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+	CreatedAt   int64  `json:"created_at"`
+	UpdatedAt   int64  `json:"updated_at"`
+}
+
+// AgentUpgradeCommand tells an agent which artifact to upgrade to.
+type AgentUpgradeCommand struct {
+	UpgradeID int    `json:"upgrade_id"`
+	Version   string `json:"version"`
+	URL       string `json:"url"`
+	SHA256    string `json:"sha256"`
+	Signature string `json:"signature"`
+}
+
+// AgentUpgradeReport is an agent's report of how an upgrade ended.
+type AgentUpgradeReport struct {
+	Status  string `json:"status"`
+	Version string `json:"version"`
+	Error   string `json:"error,omitempty"`
+}
+
+// FleetVersion counts the agents running a version.
+type FleetVersion struct {
+	Version  string `json:"version"`
+	Agents   int    `json:"agents"`
+	AgentIDs []int  `json:"agent_ids"`
+}
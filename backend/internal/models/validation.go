@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldError describes a single problem with a node config.
+type FieldError struct {
+	Pointer string `json:"pointer"` // JSON pointer into the node config, e.g. /protocols/grpc/endpoint
+	Reason  string `json:"reason"`
+}
+
+// NodeValidationError collects every problem found in one pipeline node.
+type NodeValidationError struct {
+	ComponentID   int          `json:"component_id"`
+	Name          string       `json:"name"`
+	ComponentName string       `json:"component_name"`
+	Errors        []FieldError `json:"errors"`
+}
+
+// GraphValidationError is returned when one or more nodes of a pipeline graph
+// fail validation. Handlers send Nodes back to the client as-is.
+type GraphValidationError struct {
+	Nodes []NodeValidationError `json:"nodes"`
+}
+
+func (e *GraphValidationError) Error() string {
+	var parts []string
+	for _, node := range e.Nodes {
+		for _, fieldErr := range node.Errors {
+			pointer := fieldErr.Pointer
+			if pointer == "" {
+				pointer = "/"
+			}
+			parts = append(parts, fmt.Sprintf("%s (%s) %s: %s", node.Name, node.ComponentName, pointer, fieldErr.Reason))
+		}
+	}
+	return fmt.Sprintf("invalid node configuration: %s", strings.Join(parts, "; "))
+}
+
+// Lint severities, from most to least serious.
+const (
+	LintSeverityError   = "error"
+	LintSeverityWarning = "warning"
+	LintSeverityInfo    = "info"
+)
+
+// LintIssue is a semantic problem found in a pipeline graph, such as a
+// processor in the wrong position or two receivers bound to the same port.
+type LintIssue struct {
+	Rule         string `json:"rule"`
+	Severity     string `json:"severity"`
+	Message      string `json:"message"`
+	ComponentIDs []int  `json:"component_ids,omitempty"`
+}
+
+// LintReport is the result of linting a pipeline graph.
+type LintReport struct {
+	Issues   []LintIssue `json:"issues"`
+	Errors   int         `json:"errors"`
+	Warnings int         `json:"warnings"`
+}
@@ -0,0 +1,72 @@
+// Package agentclient sends requests to the API agents serve, reaching each
+// agent by hostname and falling back to its IP.
+package agentclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+)
+
+// Port is the port agents serve their API on.
+var Port = 3421
+
+// Timeout bounds each attempt to reach an agent.
+const Timeout = 10 * time.Second
+
+// Response is an agent's answer, with its body already read.
+type Response struct {
+	StatusCode int
+	Body       []byte
+}
+
+// OK tells whether the agent answered with a 2xx status.
+func (r *Response) OK() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// Do sends a request to the agent, by hostname first and by IP if the
+// hostname can't be reached. Once an agent answers, its response is returned
+// whatever the status, as asking again by IP would reach the same agent. A
+// nil body sends no JSON.
+func Do(agent models.AgentInfoHome, method, path string, body []byte) (*Response, error) {
+	client := &http.Client{
+		Timeout: Timeout,
+	}
+
+	resp, err := send(client, agent.Hostname, method, path, body)
+	if err == nil {
+		return resp, nil
+	}
+
+	utils.Logger.Sugar().Warnf("Hostname failed for agent [ID:%v], retrying with IP: %v", agent.ID, err)
+	return send(client, agent.IP, method, path, body)
+}
+
+func send(client *http.Client, endpoint, method, path string, body []byte) (*Response, error) {
+	url := fmt.Sprintf("http://%s:%d%s", endpoint, Port, path)
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading agent response: %v", err)
+	}
+	return &Response{StatusCode: resp.StatusCode, Body: respBody}, nil
+}
@@ -0,0 +1,67 @@
+package agentclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveAgent starts an agent API on a local port and points Port at it.
+func serveAgent(t *testing.T, handler http.HandlerFunc) *int {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	serverURL, _ := url.Parse(server.URL)
+	previous := Port
+	Port, _ = strconv.Atoi(serverURL.Port())
+	t.Cleanup(func() { Port = previous })
+	return &calls
+}
+
+func TestDo_FallsBackToIP(t *testing.T) {
+	calls := serveAgent(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/agent/v1/config", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.JSONEq(t, `{"receivers": {}}`, string(body))
+		w.Write([]byte("applied"))
+	})
+
+	agent := models.AgentInfoHome{ID: 5, Hostname: "agent.invalid", IP: "127.0.0.1"}
+	resp, err := Do(agent, http.MethodPost, "/agent/v1/config", []byte(`{"receivers": {}}`))
+	assert.NoError(t, err)
+	assert.True(t, resp.OK())
+	assert.Equal(t, "applied", string(resp.Body))
+	assert.Equal(t, 1, *calls)
+}
+
+func TestDo_ReturnsAnswerWithoutRetrying(t *testing.T) {
+	calls := serveAgent(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "tap not found", http.StatusNotFound)
+	})
+
+	agent := models.AgentInfoHome{ID: 5, Hostname: "127.0.0.1", IP: "127.0.0.1"}
+	resp, err := Do(agent, http.MethodDelete, "/agent/v1/taps/ab12", nil)
+	assert.NoError(t, err)
+	assert.False(t, resp.OK())
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "tap not found\n", string(resp.Body))
+	assert.Equal(t, 1, *calls)
+}
+
+func TestDo_Unreachable(t *testing.T) {
+	agent := models.AgentInfoHome{ID: 5, Hostname: "agent.invalid", IP: "agent.invalid"}
+	_, err := Do(agent, http.MethodGet, "/agent/v1/config", nil)
+	assert.Error(t, err)
+}
@@ -2,15 +2,29 @@ package configcompiler
 
 import (
 	"fmt"
-	"strconv"
 
-	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/constants"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
 )
 
 func CompileGraphToJSON(graph models.PipelineGraph) (*map[string]any, error) {
 	utils.Logger.Info("Starting pipeline graph compilation")
+
+	report := LintGraph(graph)
+	var lintErrors []models.LintIssue
+	for _, issue := range report.Issues {
+		if issue.Severity == models.LintSeverityError {
+			lintErrors = append(lintErrors, issue)
+			continue
+		}
+		utils.Logger.Warn(fmt.Sprintf("Pipeline lint [%s] %s: %s", issue.Severity, issue.Rule, issue.Message))
+	}
+	if len(lintErrors) > 0 {
+		err := &LintError{Issues: lintErrors}
+		utils.Logger.Error(err.Error())
+		return nil, err
+	}
+
 	receivers, processors, exporters, pipelines, err := buildPipelines(graph)
 	if err != nil {
 		utils.Logger.Error(fmt.Sprintf("Failed to build pipelines: %v", err))
@@ -27,7 +41,7 @@ func CompileGraphToJSON(graph models.PipelineGraph) (*map[string]any, error) {
 		"exporters":  exporters,
 		"service": map[string]any{
 			"pipelines": pipelines,
-			"telemetry": constants.TelemetryService,
+			"telemetry": buildTelemetryService(graph.Telemetry),
 		},
 	}
 
@@ -52,6 +66,12 @@ func intersectSupportedSignals(firstSignals, secondSignals []string) []string {
 	return intersection
 }
 
+// ComponentAlias is the collector ID a node compiles to: the collector type of
+// its component and its name in CamelCase, with a hash of its config.
+func ComponentAlias(node models.PipelineNodes) string {
+	return fmt.Sprintf("%s/%s_%s", CollectorComponentType(node.ComponentName), utils.ToCamelCase(node.Name), utils.HashFromConfig(node.Config))
+}
+
 func buildPipelines(graph models.PipelineGraph) (map[string]any, map[string]any, map[string]any, Pipelines, error) {
 	if len(graph.Nodes) == 0 {
 		return nil, nil, nil, nil, fmt.Errorf("empty pipeline graph")
@@ -60,57 +80,19 @@ func buildPipelines(graph models.PipelineGraph) (map[string]any, map[string]any,
 	utils.Logger.Info(fmt.Sprintf("Building pipelines from graph: nodes=%d edges=%d",
 		len(graph.Nodes), len(graph.Edges)))
 
-	// Index nodes by ID
-	nodesByID := make(map[string]models.PipelineNodes)
 	for _, node := range graph.Nodes {
-		nodesByID[strconv.Itoa(node.ComponentID)] = node
-	}
-
-	// Build the adjacency list for graph traversal
-	adjacencyList := make(map[string][]string)
-	for _, edge := range graph.Edges {
-		if _, exists := nodesByID[edge.Source]; !exists {
-			return nil, nil, nil, nil, fmt.Errorf("edge references non-existent source node: %s", edge.Source)
+		switch node.ComponentRole {
+		case "receiver", "processor", "exporter":
+		default:
+			return nil, nil, nil, nil, fmt.Errorf("unknown component role: %s", node.ComponentRole)
 		}
-		if _, exists := nodesByID[edge.Target]; !exists {
-			return nil, nil, nil, nil, fmt.Errorf("edge references non-existent target node: %s", edge.Target)
-		}
-		adjacencyList[edge.Source] = append(adjacencyList[edge.Source], edge.Target)
-		adjacencyList[edge.Target] = append(adjacencyList[edge.Target], edge.Source)
 	}
 
-	// Find connected components using BFS
-	visitedNodes := make(map[string]bool)
-	var connectedComponents [][]models.PipelineNodes
-
-	for nodeID := range nodesByID {
-		if visitedNodes[nodeID] {
-			continue
-		}
-
-		utils.Logger.Debug(fmt.Sprintf("Processing new component: startNodeId=%s", nodeID))
-		nodeQueue := []string{nodeID}
-		var currentComponent []models.PipelineNodes
-		visitedNodes[nodeID] = true
-
-		for len(nodeQueue) > 0 {
-			currentNodeID := nodeQueue[0]
-			nodeQueue = nodeQueue[1:]
-
-			node, exists := nodesByID[currentNodeID]
-			if !exists {
-				return nil, nil, nil, nil, fmt.Errorf("invalid node reference: %s", currentNodeID)
-			}
-			currentComponent = append(currentComponent, node)
-
-			for _, neighborID := range adjacencyList[currentNodeID] {
-				if !visitedNodes[neighborID] {
-					visitedNodes[neighborID] = true
-					nodeQueue = append(nodeQueue, neighborID)
-				}
-			}
-		}
-		connectedComponents = append(connectedComponents, currentComponent)
+	// Split the graph the way lint checks it, so processors compile in the
+	// order the lint rules saw them
+	connectedComponents, err := splitPipelines(graph)
+	if err != nil {
+		return nil, nil, nil, nil, err
 	}
 
 	// Prepare maps for pipeline configurations
@@ -121,23 +103,15 @@ func buildPipelines(graph models.PipelineGraph) (map[string]any, map[string]any,
 	pipelineCounter := 1 // Global counter for unique naming across pipelines
 
 	// Process each connected component to build pipelines.
-	for _, componentNodes := range connectedComponents {
+	for _, component := range connectedComponents {
+		componentNodes := component.nodes()
 		utils.Logger.Debug(fmt.Sprintf("Building pipeline configuration: componentNodeCount=%d", len(componentNodes)))
-
-		// Compute common supported signals for all nodes in this component.
-		var commonSupportedSignals []string
-		if len(componentNodes) > 0 {
-			commonSupportedSignals = componentNodes[0].SupportedSignals
-			for _, node := range componentNodes[1:] {
-				commonSupportedSignals = intersectSupportedSignals(commonSupportedSignals, node.SupportedSignals)
-			}
-		}
+		commonSupportedSignals := component.signals
 
 		// Build role-specific alias lists.
 		var receiverAliases, processorAliases, exporterAliases []string
 		for _, node := range componentNodes {
-			// Use meaningful alias formatting: Trims component name after underscore and converts node name to CamelCase.
-			alias := fmt.Sprintf("%s/%s_%s", utils.TrimAfterUnderscore(node.ComponentName), utils.ToCamelCase(node.Name), utils.HashFromConfig(node.Config))
+			alias := ComponentAlias(node)
 			switch node.ComponentRole {
 			case "receiver":
 				receiverAliases = append(receiverAliases, alias)
@@ -148,8 +122,6 @@ func buildPipelines(graph models.PipelineGraph) (map[string]any, map[string]any,
 			case "exporter":
 				exporterAliases = append(exporterAliases, alias)
 				exportersConfig[alias] = node.Config
-			default:
-				return nil, nil, nil, nil, fmt.Errorf("unknown component role: %s", node.ComponentRole)
 			}
 		}
 
@@ -1,6 +1,7 @@
 package configcompiler
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
@@ -66,3 +67,87 @@ func TestCompileGraphToJSON_EmptyGraph(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, result)
 }
+
+func TestCompileGraphToJSON_CustomTelemetry(t *testing.T) {
+	graph := models.PipelineGraph{
+		Nodes: []models.PipelineNodes{
+			{ComponentID: 1, Name: "rx", ComponentName: "otlp_receiver", ComponentRole: "receiver", SupportedSignals: []string{"logs"}, Config: map[string]any{}},
+			{ComponentID: 2, Name: "tx", ComponentName: "debug_exporter", ComponentRole: "exporter", SupportedSignals: []string{"logs"}, Config: map[string]any{}},
+		},
+		Edges: []models.PipelineEdges{{Source: "1", Target: "2"}},
+		Telemetry: &models.PipelineTelemetry{
+			Logs:    models.TelemetryLogs{Level: "debug", Sampling: &models.TelemetryLogSampling{Enabled: true, Tick: "10s", Initial: 5}},
+			Metrics: models.TelemetryMetrics{Level: "basic", Port: 9464, OTLPEndpoint: "collector:4317"},
+			Traces:  models.TelemetryTraces{OTLPEndpoint: "tempo:4318", OTLPProtocol: "http/protobuf"},
+		},
+	}
+
+	result, err := CompileGraphToJSON(graph)
+	assert.NoError(t, err)
+
+	telemetry := (*result)["service"].(map[string]any)["telemetry"].(map[string]any)
+	logs := telemetry["logs"].(map[string]any)
+	assert.Equal(t, "debug", logs["level"])
+	assert.Equal(t, "10s", logs["sampling"].(map[string]any)["tick"])
+
+	metrics := telemetry["metrics"].(map[string]any)
+	assert.Equal(t, "basic", metrics["level"])
+	readers := metrics["readers"].([]any)
+	assert.Len(t, readers, 2)
+	prometheus := readers[0].(map[string]any)["pull"].(map[string]any)["exporter"].(map[string]any)["prometheus"].(map[string]any)
+	assert.Equal(t, 9464, prometheus["port"])
+	assert.Equal(t, "0.0.0.0", prometheus["host"])
+
+	traces := telemetry["traces"].(map[string]any)
+	otlp := traces["processors"].([]any)[0].(map[string]any)["batch"].(map[string]any)["exporter"].(map[string]any)["otlp"].(map[string]any)
+	assert.Equal(t, "http/protobuf", otlp["protocol"])
+	assert.Equal(t, "tempo:4318", otlp["endpoint"])
+}
+
+func TestComponentAlias_MatchesCompiledID(t *testing.T) {
+	node := models.PipelineNodes{
+		ComponentID:      2,
+		Name:             "processor_batch",
+		ComponentName:    "batch_processor",
+		ComponentRole:    "processor",
+		SupportedSignals: []string{"logs"},
+		Config:           map[string]any{"timeout": "10s"},
+	}
+	graph := models.PipelineGraph{
+		Nodes: []models.PipelineNodes{
+			{ComponentID: 1, Name: "receiver_one", ComponentName: "otlp_receiver", ComponentRole: "receiver", SupportedSignals: []string{"logs"}, Config: map[string]any{}},
+			node,
+			{ComponentID: 3, Name: "exporter_debug", ComponentName: "debug_exporter", ComponentRole: "exporter", SupportedSignals: []string{"logs"}, Config: map[string]any{}},
+		},
+		Edges: []models.PipelineEdges{{Source: "1", Target: "2"}, {Source: "2", Target: "3"}},
+	}
+
+	alias := ComponentAlias(node)
+	assert.Regexp(t, `^batch/`, alias)
+
+	result, err := CompileGraphToJSON(graph)
+	assert.NoError(t, err)
+	assert.Contains(t, (*result)["processors"], alias)
+}
+
+func TestCompileGraphToJSON_UnderscoreCollectorTypes(t *testing.T) {
+	graph := models.PipelineGraph{
+		Nodes: []models.PipelineNodes{
+			{ComponentID: 1, Name: "rx", ComponentName: "otlp_receiver", ComponentRole: "receiver", SupportedSignals: []string{"traces"}, Config: map[string]any{}},
+			{ComponentID: 2, Name: "limiter", ComponentName: "memorylimiter_processor", ComponentRole: "processor", SupportedSignals: []string{"traces"}, Config: map[string]any{}},
+			{ComponentID: 3, Name: "tail", ComponentName: "tailsampling_processor", ComponentRole: "processor", SupportedSignals: []string{"traces"}, Config: map[string]any{}},
+			{ComponentID: 4, Name: "sampler", ComponentName: "probabilisticsampler_processor", ComponentRole: "processor", SupportedSignals: []string{"traces"}, Config: map[string]any{}},
+			{ComponentID: 5, Name: "tx", ComponentName: "debug_exporter", ComponentRole: "exporter", SupportedSignals: []string{"traces"}, Config: map[string]any{}},
+		},
+		Edges: []models.PipelineEdges{{Source: "1", Target: "2"}, {Source: "2", Target: "3"}, {Source: "3", Target: "4"}, {Source: "4", Target: "5"}},
+	}
+
+	result, err := CompileGraphToJSON(graph)
+	assert.NoError(t, err)
+
+	var types []string
+	for alias := range (*result)["processors"].(map[string]any) {
+		types = append(types, strings.Split(alias, "/")[0])
+	}
+	assert.ElementsMatch(t, []string{"memory_limiter", "tail_sampling", "probabilistic_sampler"}, types)
+}
@@ -0,0 +1,301 @@
+package configcompiler
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+)
+
+// componentNameOverrides maps collector component types whose catalog name
+// doesn't follow the "<type without underscores>_<role>" convention.
+var componentNameOverrides = map[string]string{
+	"exporter/otlp":    "otlp_grpc_exporter",
+	"exporter/logging": "debug_exporter",
+}
+
+// collectorTypes maps catalog names that aren't their collector component type
+// followed by the role, mostly because the catalog name drops underscores.
+var collectorTypes = map[string]string{
+	"otlp_grpc_exporter":             "otlp",
+	"memorylimiter_processor":        "memory_limiter",
+	"probabilisticsampler_processor": "probabilistic_sampler",
+	"tailsampling_processor":         "tail_sampling",
+	"k8scluster_receiver":            "k8s_cluster",
+}
+
+// compiledAliasSuffix matches the config hash CompileGraphToJSON appends to
+// node names, so re-importing a compiled config gives back the original names.
+var compiledAliasSuffix = regexp.MustCompile(`_[0-9a-f]{8}$`)
+
+var collectorRoles = []struct {
+	section string
+	role    string
+}{
+	{"receivers", "receiver"},
+	{"processors", "processor"},
+	{"exporters", "exporter"},
+}
+
+// CatalogComponentName returns the component_schemas name for a collector
+// component type, e.g. ("receiver", "otlp") -> "otlp_receiver" and
+// ("processor", "memory_limiter") -> "memorylimiter_processor".
+func CatalogComponentName(role, componentType string) string {
+	if name, ok := componentNameOverrides[role+"/"+componentType]; ok {
+		return name
+	}
+	return strings.ReplaceAll(componentType, "_", "") + "_" + role
+}
+
+// CatalogComponentNames returns every catalog name a collector component can
+// be found under: its built-in name and, when that drops underscores or is
+// overridden, the name of a custom component of the same type, which keeps
+// them.
+func CatalogComponentNames(role, componentType string) []string {
+	name := CatalogComponentName(role, componentType)
+	if custom := componentType + "_" + role; custom != name {
+		return []string{name, custom}
+	}
+	return []string{name}
+}
+
+// CollectorComponentType returns the collector component type for a
+// component_schemas name, e.g. "otlp_grpc_exporter" -> "otlp",
+// "k8scluster_receiver" -> "k8s_cluster" and, for a custom component,
+// "acme_sampling_processor" -> "acme_sampling".
+func CollectorComponentType(componentName string) string {
+	if componentType, ok := collectorTypes[componentName]; ok {
+		return componentType
+	}
+	if idx := strings.LastIndex(componentName, "_"); idx != -1 {
+		return componentName[:idx]
+	}
+	return componentName
+}
+
+// catalogEntry looks up the catalog component of a collector component type.
+// Custom components keep the underscores of their type in their name.
+func catalogEntry(catalog map[string]models.CatalogComponent, role, componentType string) (models.CatalogComponent, bool) {
+	for _, name := range CatalogComponentNames(role, componentType) {
+		if entry, ok := catalog[name]; ok {
+			return entry, true
+		}
+	}
+	return models.CatalogComponent{}, false
+}
+
+type importedComponent struct {
+	id      string
+	role    string
+	config  map[string]any
+	signals map[string]bool
+	nodeID  int
+}
+
+// DecompileConfig reverse-compiles a collector config into a pipeline graph.
+// Every component referenced by service.pipelines becomes a node, and each
+// pipeline is rebuilt as receivers -> processors in order -> exporters.
+// Components missing from the catalog are reported and bridged over.
+func DecompileConfig(config map[string]any, catalog map[string]models.CatalogComponent) (*models.ImportPipelineResponse, error) {
+	service, ok := config["service"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("config has no service section")
+	}
+	pipelines, ok := service["pipelines"].(map[string]any)
+	if !ok || len(pipelines) == 0 {
+		return nil, fmt.Errorf("config has no service.pipelines")
+	}
+
+	response := &models.ImportPipelineResponse{
+		PipelineGraph: models.PipelineGraph{Nodes: []models.PipelineNodes{}, Edges: []models.PipelineEdges{}},
+		Unmapped:      []models.UnmappedComponent{},
+		Warnings:      []string{},
+	}
+
+	// Collect declared components per role
+	components := make(map[string]*importedComponent)
+	for _, r := range collectorRoles {
+		section, _ := config[r.section].(map[string]any)
+		for id, raw := range section {
+			componentConfig, _ := raw.(map[string]any)
+			if componentConfig == nil {
+				componentConfig = map[string]any{}
+			}
+			components[r.role+"/"+id] = &importedComponent{id: id, role: r.role, config: componentConfig, signals: map[string]bool{}}
+		}
+	}
+
+	pipelineNames := make([]string, 0, len(pipelines))
+	for name := range pipelines {
+		pipelineNames = append(pipelineNames, name)
+	}
+	sort.Strings(pipelineNames)
+
+	unmapped := make(map[string]bool)
+	reportUnmapped := func(role, id, reason string) {
+		if !unmapped[role+"/"+id] {
+			unmapped[role+"/"+id] = true
+			response.Unmapped = append(response.Unmapped, models.UnmappedComponent{ID: id, Role: role, Reason: reason})
+		}
+	}
+
+	// Graphs have no extensions or connectors, so every declared one is left out
+	extensions, _ := config["extensions"].(map[string]any)
+	for _, id := range sortedKeys(extensions) {
+		reportUnmapped("extension", id, "extensions aren't part of pipeline graphs")
+	}
+	connectors, _ := config["connectors"].(map[string]any)
+	for _, id := range sortedKeys(connectors) {
+		reportUnmapped("connector", id, "connectors aren't part of pipeline graphs; the pipelines it joins are imported separately")
+	}
+
+	// resolve looks up the components a pipeline lists for one role, skipping
+	// the ones that are undeclared or not in the catalog
+	resolve := func(pipelineName string, pipeline map[string]any, section, role string) []*importedComponent {
+		var resolved []*importedComponent
+		ids, _ := pipeline[section].([]any)
+		for _, raw := range ids {
+			id := fmt.Sprint(raw)
+			component, ok := components[role+"/"+id]
+			if _, isConnector := connectors[id]; !ok && isConnector && role != "processor" {
+				continue
+			}
+			if !ok {
+				response.Warnings = append(response.Warnings, fmt.Sprintf("pipeline %s references undeclared %s %s", pipelineName, role, id))
+				continue
+			}
+			componentType := strings.SplitN(id, "/", 2)[0]
+			entry, ok := catalogEntry(catalog, role, componentType)
+			if !ok || entry.Role != role {
+				reportUnmapped(role, id, fmt.Sprintf("no catalog component %s", CatalogComponentName(role, componentType)))
+				continue
+			}
+			resolved = append(resolved, component)
+		}
+		return resolved
+	}
+
+	type importedPipeline struct {
+		signal     string
+		receivers  []*importedComponent
+		processors []*importedComponent
+		exporters  []*importedComponent
+	}
+
+	var resolvedPipelines []importedPipeline
+	for _, name := range pipelineNames {
+		pipeline, ok := pipelines[name].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("pipeline %s is not a map", name)
+		}
+		signal := strings.SplitN(name, "/", 2)[0]
+
+		p := importedPipeline{
+			signal:     signal,
+			receivers:  resolve(name, pipeline, "receivers", "receiver"),
+			processors: resolve(name, pipeline, "processors", "processor"),
+			exporters:  resolve(name, pipeline, "exporters", "exporter"),
+		}
+		if len(p.receivers) == 0 || len(p.exporters) == 0 {
+			response.Warnings = append(response.Warnings, fmt.Sprintf("pipeline %s has no mappable receivers or exporters and was skipped", name))
+			continue
+		}
+		for _, list := range [][]*importedComponent{p.receivers, p.processors, p.exporters} {
+			for _, component := range list {
+				component.signals[signal] = true
+			}
+		}
+		resolvedPipelines = append(resolvedPipelines, p)
+	}
+
+	// Turn used components into nodes, receivers first, then by ID
+	var used []*importedComponent
+	for key, component := range components {
+		if len(component.signals) > 0 {
+			used = append(used, component)
+		} else if !unmapped[key] {
+			response.Warnings = append(response.Warnings, fmt.Sprintf("%s %s is not used by any pipeline and was skipped", component.role, component.id))
+		}
+	}
+	roleOrder := map[string]int{"receiver": 0, "processor": 1, "exporter": 2}
+	sort.Slice(used, func(i, j int) bool {
+		if used[i].role != used[j].role {
+			return roleOrder[used[i].role] < roleOrder[used[j].role]
+		}
+		return used[i].id < used[j].id
+	})
+	sort.Strings(response.Warnings)
+
+	for i, component := range used {
+		component.nodeID = i + 1
+		componentType := strings.SplitN(component.id, "/", 2)[0]
+		entry, _ := catalogEntry(catalog, component.role, componentType)
+
+		// Keep only the signals the imported pipelines actually carried
+		var signals []string
+		for _, signal := range entry.SupportedSignals {
+			if component.signals[signal] {
+				signals = append(signals, signal)
+			}
+		}
+
+		response.PipelineGraph.Nodes = append(response.PipelineGraph.Nodes, models.PipelineNodes{
+			ComponentID:      component.nodeID,
+			Name:             importedNodeName(component.id),
+			ComponentRole:    component.role,
+			ComponentName:    entry.Name,
+			Config:           component.config,
+			SupportedSignals: signals,
+		})
+	}
+
+	// Rebuild edges along each pipeline, skipping duplicates
+	seenEdges := make(map[[2]int]bool)
+	addEdges := func(sources, targets []*importedComponent) {
+		for _, source := range sources {
+			for _, target := range targets {
+				key := [2]int{source.nodeID, target.nodeID}
+				if seenEdges[key] {
+					continue
+				}
+				seenEdges[key] = true
+				response.PipelineGraph.Edges = append(response.PipelineGraph.Edges, models.PipelineEdges{
+					Source: strconv.Itoa(source.nodeID),
+					Target: strconv.Itoa(target.nodeID),
+				})
+			}
+		}
+	}
+	for _, p := range resolvedPipelines {
+		previous := p.receivers
+		for _, processor := range p.processors {
+			addEdges(previous, []*importedComponent{processor})
+			previous = []*importedComponent{processor}
+		}
+		addEdges(previous, p.exporters)
+	}
+
+	return response, nil
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// importedNodeName derives a node name from a component ID: the part after
+// the slash with any compiler hash removed, or the type when there is none.
+func importedNodeName(id string) string {
+	parts := strings.SplitN(id, "/", 2)
+	if len(parts) == 1 || parts[1] == "" {
+		return parts[0]
+	}
+	return compiledAliasSuffix.ReplaceAllString(parts[1], "")
+}
@@ -0,0 +1,167 @@
+package configcompiler
+
+import (
+	"testing"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var testCatalog = map[string]models.CatalogComponent{
+	"otlp_receiver":           {Name: "otlp_receiver", Role: "receiver", SupportedSignals: []string{"traces", "metrics", "logs"}},
+	"filelog_receiver":        {Name: "filelog_receiver", Role: "receiver", SupportedSignals: []string{"logs"}},
+	"memorylimiter_processor": {Name: "memorylimiter_processor", Role: "processor", SupportedSignals: []string{"traces", "metrics", "logs"}},
+	"batch_processor":         {Name: "batch_processor", Role: "processor", SupportedSignals: []string{"traces", "metrics", "logs"}},
+	"otlp_grpc_exporter":      {Name: "otlp_grpc_exporter", Role: "exporter", SupportedSignals: []string{"traces", "metrics", "logs"}},
+	"debug_exporter":          {Name: "debug_exporter", Role: "exporter", SupportedSignals: []string{"traces", "metrics", "logs"}},
+}
+
+func TestCatalogComponentName(t *testing.T) {
+	assert.Equal(t, "otlp_receiver", CatalogComponentName("receiver", "otlp"))
+	assert.Equal(t, "memorylimiter_processor", CatalogComponentName("processor", "memory_limiter"))
+	assert.Equal(t, "otlp_grpc_exporter", CatalogComponentName("exporter", "otlp"))
+	assert.Equal(t, "otlphttp_exporter", CatalogComponentName("exporter", "otlphttp"))
+}
+
+func TestCollectorComponentType(t *testing.T) {
+	assert.Equal(t, "otlp", CollectorComponentType("otlp_grpc_exporter"))
+	assert.Equal(t, "memory_limiter", CollectorComponentType("memorylimiter_processor"))
+	assert.Equal(t, "k8s_cluster", CollectorComponentType("k8scluster_receiver"))
+	assert.Equal(t, "k8sattributes", CollectorComponentType("k8sattributes_processor"))
+	assert.Equal(t, "acme_sampling", CollectorComponentType("acme_sampling_processor"))
+}
+
+func TestDecompileConfig(t *testing.T) {
+	config := map[string]any{
+		"receivers": map[string]any{
+			"otlp":        map[string]any{"protocols": map[string]any{"grpc": nil}},
+			"filelog":     map[string]any{"include": []any{"/var/log/*.log"}},
+			"zipkin":      nil,
+			"hostmetrics": map[string]any{},
+		},
+		"processors": map[string]any{
+			"memory_limiter": map[string]any{"limit_mib": 512},
+			"batch/logs":     map[string]any{},
+			"k8sattributes":  nil,
+		},
+		"exporters": map[string]any{
+			"otlp/upstream_1a2b3c4d": map[string]any{"endpoint": "collector:4317"},
+		},
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"logs": map[string]any{
+					"receivers":  []any{"otlp", "filelog"},
+					"processors": []any{"memory_limiter", "k8sattributes", "batch/logs"},
+					"exporters":  []any{"otlp/upstream_1a2b3c4d"},
+				},
+				"traces": map[string]any{
+					"receivers": []any{"zipkin"},
+					"exporters": []any{"otlp/upstream_1a2b3c4d"},
+				},
+			},
+		},
+	}
+
+	response, err := DecompileConfig(config, testCatalog)
+	assert.NoError(t, err)
+
+	names := make(map[string]models.PipelineNodes)
+	for _, node := range response.PipelineGraph.Nodes {
+		names[node.Name] = node
+	}
+	assert.Len(t, response.PipelineGraph.Nodes, 5)
+	assert.Equal(t, "otlp_grpc_exporter", names["upstream"].ComponentName)
+	assert.Equal(t, "batch_processor", names["logs"].ComponentName)
+	assert.Equal(t, []string{"logs"}, names["otlp"].SupportedSignals)
+
+	// Nodes: filelog=1, otlp=2, batch/logs=3, memory_limiter=4, upstream=5.
+	// k8sattributes is bridged over, so memory_limiter feeds batch directly.
+	assert.Equal(t, []models.PipelineEdges{
+		{Source: "2", Target: "4"},
+		{Source: "1", Target: "4"},
+		{Source: "4", Target: "3"},
+		{Source: "3", Target: "5"},
+	}, response.PipelineGraph.Edges)
+
+	assert.ElementsMatch(t, []models.UnmappedComponent{
+		{ID: "k8sattributes", Role: "processor", Reason: "no catalog component k8sattributes_processor"},
+		{ID: "zipkin", Role: "receiver", Reason: "no catalog component zipkin_receiver"},
+	}, response.Unmapped)
+	assert.Contains(t, response.Warnings, "pipeline traces has no mappable receivers or exporters and was skipped")
+	assert.Contains(t, response.Warnings, "receiver hostmetrics is not used by any pipeline and was skipped")
+
+	// The imported graph compiles
+	_, err = CompileGraphToJSON(response.PipelineGraph)
+	assert.NoError(t, err)
+}
+
+func TestDecompileConfig_ExtensionsAndConnectors(t *testing.T) {
+	config := map[string]any{
+		"extensions": map[string]any{"health_check": map[string]any{}, "pprof": nil},
+		"connectors": map[string]any{"spanmetrics": map[string]any{}},
+		"receivers":  map[string]any{"otlp": map[string]any{}},
+		"exporters":  map[string]any{"debug": map[string]any{}},
+		"service": map[string]any{
+			"extensions": []any{"health_check", "pprof"},
+			"pipelines": map[string]any{
+				"traces": map[string]any{
+					"receivers": []any{"otlp"},
+					"exporters": []any{"debug", "spanmetrics"},
+				},
+				"metrics": map[string]any{
+					"receivers": []any{"spanmetrics"},
+					"exporters": []any{"debug"},
+				},
+			},
+		},
+	}
+
+	response, err := DecompileConfig(config, testCatalog)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.UnmappedComponent{
+		{ID: "health_check", Role: "extension", Reason: "extensions aren't part of pipeline graphs"},
+		{ID: "pprof", Role: "extension", Reason: "extensions aren't part of pipeline graphs"},
+		{ID: "spanmetrics", Role: "connector", Reason: "connectors aren't part of pipeline graphs; the pipelines it joins are imported separately"},
+	}, response.Unmapped)
+	// The connector is reported once instead of as an undeclared component
+	assert.Equal(t, []string{"pipeline metrics has no mappable receivers or exporters and was skipped"}, response.Warnings)
+	assert.Len(t, response.PipelineGraph.Nodes, 2)
+}
+
+func TestDecompileConfig_NoPipelines(t *testing.T) {
+	_, err := DecompileConfig(map[string]any{"receivers": map[string]any{}}, testCatalog)
+	assert.Error(t, err)
+}
+
+func TestDecompileConfig_CustomComponent(t *testing.T) {
+	catalog := map[string]models.CatalogComponent{
+		"otlp_receiver":           testCatalog["otlp_receiver"],
+		"debug_exporter":          testCatalog["debug_exporter"],
+		"acme_sampling_processor": {Name: "acme_sampling_processor", Role: "processor", SupportedSignals: []string{"traces"}},
+	}
+	config := map[string]any{
+		"receivers":  map[string]any{"otlp": map[string]any{}},
+		"processors": map[string]any{"acme_sampling/edge": map[string]any{"ratio": 0.5}},
+		"exporters":  map[string]any{"debug": map[string]any{}},
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"traces": map[string]any{
+					"receivers":  []any{"otlp"},
+					"processors": []any{"acme_sampling/edge"},
+					"exporters":  []any{"debug"},
+				},
+			},
+		},
+	}
+
+	response, err := DecompileConfig(config, catalog)
+	assert.NoError(t, err)
+	assert.Empty(t, response.Unmapped)
+	assert.Len(t, response.PipelineGraph.Nodes, 3)
+
+	compiled, err := CompileGraphToJSON(response.PipelineGraph)
+	assert.NoError(t, err)
+	for alias := range (*compiled)["processors"].(map[string]any) {
+		assert.Regexp(t, `^acme_sampling/`, alias)
+	}
+}
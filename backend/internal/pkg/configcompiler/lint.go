@@ -0,0 +1,418 @@
+package configcompiler
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/constants"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+)
+
+// LintError is returned by CompileGraphToJSON when the graph has lint issues
+// of error severity, i.e. the collector would refuse or fail to run it.
+type LintError struct {
+	Issues []models.LintIssue
+}
+
+func (e *LintError) Error() string {
+	messages := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		messages = append(messages, issue.Message)
+	}
+	return fmt.Sprintf("pipeline graph failed lint: %s", strings.Join(messages, "; "))
+}
+
+// graphPipeline is one connected component of the graph, i.e. one set of
+// collector pipelines sharing receivers, processors and exporters.
+type graphPipeline struct {
+	receivers  []models.PipelineNodes
+	processors []models.PipelineNodes // in edge order, receiver side first
+	exporters  []models.PipelineNodes
+	signals    []string
+}
+
+// nodes returns the pipeline's nodes: receivers, then processors in edge
+// order, then exporters.
+func (p graphPipeline) nodes() []models.PipelineNodes {
+	nodes := make([]models.PipelineNodes, 0, len(p.receivers)+len(p.processors)+len(p.exporters))
+	nodes = append(nodes, p.receivers...)
+	nodes = append(nodes, p.processors...)
+	return append(nodes, p.exporters...)
+}
+
+type lintRule func(graph models.PipelineGraph, pipelines []graphPipeline) []models.LintIssue
+
+var lintRules = []lintRule{
+	lintMemoryLimiterFirst,
+	lintBatchLast,
+	lintTailSamplingWithoutTraces,
+	lintExporterWithoutRetry,
+	lintDuplicateReceiverEndpoints,
+}
+
+// networkExporters send data over the network and support retry_on_failure
+// and sending_queue.
+var networkExporters = map[string]bool{
+	"otlp_grpc_exporter": true,
+	"otlphttp_exporter":  true,
+	"kafka_exporter":     true,
+}
+
+// LintGraph runs every lint rule against a pipeline graph. Graphs that can't
+// be split into pipelines (dangling edges) produce no issues; compilation
+// reports those.
+func LintGraph(graph models.PipelineGraph) models.LintReport {
+	report := models.LintReport{Issues: []models.LintIssue{}}
+
+	pipelines, err := splitPipelines(graph)
+	if err != nil {
+		return report
+	}
+
+	for _, rule := range lintRules {
+		report.Issues = append(report.Issues, rule(graph, pipelines)...)
+	}
+
+	for _, issue := range report.Issues {
+		switch issue.Severity {
+		case models.LintSeverityError:
+			report.Errors++
+		case models.LintSeverityWarning:
+			report.Warnings++
+		}
+	}
+	return report
+}
+
+func splitPipelines(graph models.PipelineGraph) ([]graphPipeline, error) {
+	nodesByID := make(map[string]models.PipelineNodes)
+	for _, node := range graph.Nodes {
+		nodesByID[strconv.Itoa(node.ComponentID)] = node
+	}
+
+	undirected := make(map[string][]string)
+	outgoing := make(map[string][]string)
+	inDegree := make(map[string]int)
+	for _, edge := range graph.Edges {
+		if _, ok := nodesByID[edge.Source]; !ok {
+			return nil, fmt.Errorf("edge references non-existent source node: %s", edge.Source)
+		}
+		if _, ok := nodesByID[edge.Target]; !ok {
+			return nil, fmt.Errorf("edge references non-existent target node: %s", edge.Target)
+		}
+		undirected[edge.Source] = append(undirected[edge.Source], edge.Target)
+		undirected[edge.Target] = append(undirected[edge.Target], edge.Source)
+		outgoing[edge.Source] = append(outgoing[edge.Source], edge.Target)
+		inDegree[edge.Target]++
+	}
+
+	// Visit nodes by ID so results are stable across calls
+	ids := make([]string, 0, len(nodesByID))
+	for id := range nodesByID {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return nodesByID[ids[i]].ComponentID < nodesByID[ids[j]].ComponentID
+	})
+
+	visited := make(map[string]bool)
+	var pipelines []graphPipeline
+	for _, start := range ids {
+		if visited[start] {
+			continue
+		}
+
+		members := make(map[string]bool)
+		queue := []string{start}
+		visited[start] = true
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			members[current] = true
+			for _, neighbor := range undirected[current] {
+				if !visited[neighbor] {
+					visited[neighbor] = true
+					queue = append(queue, neighbor)
+				}
+			}
+		}
+
+		pipeline := graphPipeline{}
+		for _, id := range topologicalOrder(ids, members, outgoing, inDegree) {
+			node := nodesByID[id]
+			switch node.ComponentRole {
+			case "receiver":
+				pipeline.receivers = append(pipeline.receivers, node)
+			case "processor":
+				pipeline.processors = append(pipeline.processors, node)
+			case "exporter":
+				pipeline.exporters = append(pipeline.exporters, node)
+			}
+
+			if pipeline.signals == nil {
+				pipeline.signals = node.SupportedSignals
+			} else {
+				pipeline.signals = intersectSupportedSignals(pipeline.signals, node.SupportedSignals)
+			}
+		}
+		pipelines = append(pipelines, pipeline)
+	}
+
+	return pipelines, nil
+}
+
+// topologicalOrder sorts the members of one connected component along the
+// edges (Kahn's algorithm), breaking ties by component ID. Nodes left over by
+// a cycle are appended in ID order.
+func topologicalOrder(ids []string, members map[string]bool, outgoing map[string][]string, inDegree map[string]int) []string {
+	remaining := make(map[string]int)
+	for _, id := range ids {
+		if members[id] {
+			remaining[id] = inDegree[id]
+		}
+	}
+
+	var order []string
+	placed := make(map[string]bool)
+	for len(order) < len(remaining) {
+		progressed := false
+		for _, id := range ids {
+			if !members[id] || placed[id] || remaining[id] > 0 {
+				continue
+			}
+			placed[id] = true
+			order = append(order, id)
+			for _, next := range outgoing[id] {
+				remaining[next]--
+			}
+			progressed = true
+			break
+		}
+		if !progressed {
+			for _, id := range ids {
+				if members[id] && !placed[id] {
+					placed[id] = true
+					order = append(order, id)
+				}
+			}
+		}
+	}
+	return order
+}
+
+func lintMemoryLimiterFirst(_ models.PipelineGraph, pipelines []graphPipeline) []models.LintIssue {
+	var issues []models.LintIssue
+	for _, pipeline := range pipelines {
+		if len(pipeline.receivers) == 0 || len(pipeline.exporters) == 0 {
+			continue
+		}
+
+		position := indexOfComponent(pipeline.processors, "memorylimiter_processor")
+		switch {
+		case position < 0:
+			issues = append(issues, models.LintIssue{
+				Rule:         "memory-limiter-first",
+				Severity:     models.LintSeverityWarning,
+				Message:      fmt.Sprintf("pipeline starting at %s has no memory_limiter processor; the collector can run out of memory under load", pipeline.receivers[0].Name),
+				ComponentIDs: componentIDs(pipeline.receivers),
+			})
+		case position > 0:
+			node := pipeline.processors[position]
+			issues = append(issues, models.LintIssue{
+				Rule:         "memory-limiter-first",
+				Severity:     models.LintSeverityWarning,
+				Message:      fmt.Sprintf("memory_limiter processor %s should be the first processor in its pipeline", node.Name),
+				ComponentIDs: []int{node.ComponentID},
+			})
+		}
+	}
+	return issues
+}
+
+func lintBatchLast(_ models.PipelineGraph, pipelines []graphPipeline) []models.LintIssue {
+	var issues []models.LintIssue
+	for _, pipeline := range pipelines {
+		position := indexOfComponent(pipeline.processors, "batch_processor")
+		if position >= 0 && position != len(pipeline.processors)-1 {
+			node := pipeline.processors[position]
+			issues = append(issues, models.LintIssue{
+				Rule:         "batch-last",
+				Severity:     models.LintSeverityWarning,
+				Message:      fmt.Sprintf("batch processor %s should be the last processor in its pipeline", node.Name),
+				ComponentIDs: []int{node.ComponentID},
+			})
+		}
+	}
+	return issues
+}
+
+func lintTailSamplingWithoutTraces(_ models.PipelineGraph, pipelines []graphPipeline) []models.LintIssue {
+	var issues []models.LintIssue
+	for _, pipeline := range pipelines {
+		if containsSignal(pipeline.signals, "traces") {
+			continue
+		}
+		for _, node := range pipeline.processors {
+			if node.ComponentName == "tailsampling_processor" {
+				issues = append(issues, models.LintIssue{
+					Rule:         "tail-sampling-needs-traces",
+					Severity:     models.LintSeverityError,
+					Message:      fmt.Sprintf("tail sampling processor %s is in a pipeline that carries no traces", node.Name),
+					ComponentIDs: []int{node.ComponentID},
+				})
+			}
+		}
+	}
+	return issues
+}
+
+func lintExporterWithoutRetry(graph models.PipelineGraph, _ []graphPipeline) []models.LintIssue {
+	var issues []models.LintIssue
+	for _, node := range graph.Nodes {
+		if node.ComponentRole != "exporter" || !networkExporters[node.ComponentName] {
+			continue
+		}
+		if !isEnabledSection(node.Config, "retry_on_failure") && !isEnabledSection(node.Config, "sending_queue") {
+			issues = append(issues, models.LintIssue{
+				Rule:         "exporter-retry",
+				Severity:     models.LintSeverityWarning,
+				Message:      fmt.Sprintf("exporter %s has neither retry_on_failure nor sending_queue configured; data is dropped when the destination is unavailable", node.Name),
+				ComponentIDs: []int{node.ComponentID},
+			})
+		}
+	}
+	return issues
+}
+
+func lintDuplicateReceiverEndpoints(graph models.PipelineGraph, _ []graphPipeline) []models.LintIssue {
+	type binding struct {
+		node models.PipelineNodes
+		host string
+		port string
+	}
+
+	var bindings []binding
+	for _, node := range graph.Nodes {
+		if node.ComponentRole != "receiver" {
+			continue
+		}
+		for _, endpoint := range receiverEndpoints(node.Config) {
+			host, port, err := net.SplitHostPort(endpoint)
+			if err != nil {
+				continue
+			}
+			bindings = append(bindings, binding{node: node, host: host, port: port})
+		}
+	}
+
+	var issues []models.LintIssue
+	for i := 0; i < len(bindings); i++ {
+		for j := i + 1; j < len(bindings); j++ {
+			a, b := bindings[i], bindings[j]
+			if a.port != b.port || !hostsOverlap(a.host, b.host) {
+				continue
+			}
+			ids := []int{a.node.ComponentID}
+			if b.node.ComponentID != a.node.ComponentID {
+				ids = append(ids, b.node.ComponentID)
+			}
+			issues = append(issues, models.LintIssue{
+				Rule:         "duplicate-receiver-endpoint",
+				Severity:     models.LintSeverityError,
+				Message:      fmt.Sprintf("receivers %s and %s both bind port %s", a.node.Name, b.node.Name, a.port),
+				ComponentIDs: ids,
+			})
+		}
+	}
+
+	// The collector's own Prometheus reader also holds a port
+	telemetryPort := strconv.Itoa(constants.DefaultTelemetryMetricsPort)
+	if graph.Telemetry != nil && graph.Telemetry.Metrics.Port != 0 {
+		telemetryPort = strconv.Itoa(graph.Telemetry.Metrics.Port)
+	}
+	for _, b := range bindings {
+		if b.port == telemetryPort {
+			issues = append(issues, models.LintIssue{
+				Rule:         "duplicate-receiver-endpoint",
+				Severity:     models.LintSeverityError,
+				Message:      fmt.Sprintf("receiver %s binds port %s, which the collector uses for its own metrics", b.node.Name, b.port),
+				ComponentIDs: []int{b.node.ComponentID},
+			})
+		}
+	}
+
+	return issues
+}
+
+// receiverEndpoints collects "endpoint" values from a receiver config, both at
+// the top level and under protocols (otlp grpc/http).
+func receiverEndpoints(config map[string]any) []string {
+	var endpoints []string
+	if endpoint, ok := config["endpoint"].(string); ok && endpoint != "" {
+		endpoints = append(endpoints, endpoint)
+	}
+	if protocols, ok := config["protocols"].(map[string]any); ok {
+		names := make([]string, 0, len(protocols))
+		for name := range protocols {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if protocol, ok := protocols[name].(map[string]any); ok {
+				if endpoint, ok := protocol["endpoint"].(string); ok && endpoint != "" {
+					endpoints = append(endpoints, endpoint)
+				}
+			}
+		}
+	}
+	return endpoints
+}
+
+func hostsOverlap(a, b string) bool {
+	isWildcard := func(host string) bool {
+		return host == "" || host == "0.0.0.0" || host == "::"
+	}
+	return a == b || isWildcard(a) || isWildcard(b)
+}
+
+// isEnabledSection reports whether config has the named section and it isn't
+// explicitly disabled.
+func isEnabledSection(config map[string]any, key string) bool {
+	section, ok := config[key].(map[string]any)
+	if !ok {
+		return false
+	}
+	if enabled, ok := section["enabled"].(bool); ok {
+		return enabled
+	}
+	return true
+}
+
+func indexOfComponent(nodes []models.PipelineNodes, componentName string) int {
+	for i, node := range nodes {
+		if node.ComponentName == componentName {
+			return i
+		}
+	}
+	return -1
+}
+
+func componentIDs(nodes []models.PipelineNodes) []int {
+	ids := make([]int, len(nodes))
+	for i, node := range nodes {
+		ids[i] = node.ComponentID
+	}
+	return ids
+}
+
+func containsSignal(signals []string, signal string) bool {
+	for _, s := range signals {
+		if s == signal {
+			return true
+		}
+	}
+	return false
+}
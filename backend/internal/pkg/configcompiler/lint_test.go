@@ -0,0 +1,168 @@
+package configcompiler
+
+import (
+	"testing"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func lintRulesFired(report models.LintReport) map[string]string {
+	fired := make(map[string]string)
+	for _, issue := range report.Issues {
+		fired[issue.Rule] = issue.Severity
+	}
+	return fired
+}
+
+func TestLintGraph_CleanPipeline(t *testing.T) {
+	graph := models.PipelineGraph{
+		Nodes: []models.PipelineNodes{
+			{ComponentID: 1, Name: "otlp", ComponentName: "otlp_receiver", ComponentRole: "receiver", SupportedSignals: []string{"traces"},
+				Config: map[string]any{"protocols": map[string]any{"grpc": map[string]any{"endpoint": "0.0.0.0:4317"}}}},
+			{ComponentID: 2, Name: "limiter", ComponentName: "memorylimiter_processor", ComponentRole: "processor", SupportedSignals: []string{"traces"}},
+			{ComponentID: 3, Name: "sampling", ComponentName: "tailsampling_processor", ComponentRole: "processor", SupportedSignals: []string{"traces"}},
+			{ComponentID: 4, Name: "batch", ComponentName: "batch_processor", ComponentRole: "processor", SupportedSignals: []string{"traces"}},
+			{ComponentID: 5, Name: "upstream", ComponentName: "otlp_grpc_exporter", ComponentRole: "exporter", SupportedSignals: []string{"traces"},
+				Config: map[string]any{"endpoint": "collector:4317", "retry_on_failure": map[string]any{"enabled": true}}},
+		},
+		// Edges listed out of order on purpose; lint follows the edge direction
+		Edges: []models.PipelineEdges{
+			{Source: "4", Target: "5"},
+			{Source: "2", Target: "3"},
+			{Source: "1", Target: "2"},
+			{Source: "3", Target: "4"},
+		},
+	}
+
+	report := LintGraph(graph)
+	assert.Empty(t, report.Issues)
+	assert.Zero(t, report.Errors)
+	assert.Zero(t, report.Warnings)
+}
+
+func TestLintGraph_Rules(t *testing.T) {
+	graph := models.PipelineGraph{
+		Nodes: []models.PipelineNodes{
+			{ComponentID: 1, Name: "otlp", ComponentName: "otlp_receiver", ComponentRole: "receiver", SupportedSignals: []string{"logs"},
+				Config: map[string]any{"protocols": map[string]any{"http": map[string]any{"endpoint": "0.0.0.0:4318"}}}},
+			{ComponentID: 2, Name: "batch", ComponentName: "batch_processor", ComponentRole: "processor", SupportedSignals: []string{"logs", "traces"}},
+			{ComponentID: 3, Name: "sampling", ComponentName: "tailsampling_processor", ComponentRole: "processor", SupportedSignals: []string{"traces", "logs"}},
+			{ComponentID: 4, Name: "upstream", ComponentName: "otlphttp_exporter", ComponentRole: "exporter", SupportedSignals: []string{"logs"},
+				Config: map[string]any{"endpoint": "https://collector", "retry_on_failure": map[string]any{"enabled": false}}},
+			{ComponentID: 5, Name: "http_logs", ComponentName: "http_receiver", ComponentRole: "receiver", SupportedSignals: []string{"logs"},
+				Config: map[string]any{"endpoint": "localhost:4318"}},
+		},
+		Edges: []models.PipelineEdges{
+			{Source: "1", Target: "2"},
+			{Source: "5", Target: "2"},
+			{Source: "2", Target: "3"},
+			{Source: "3", Target: "4"},
+		},
+	}
+
+	report := LintGraph(graph)
+	fired := lintRulesFired(report)
+	assert.Equal(t, models.LintSeverityWarning, fired["memory-limiter-first"])
+	assert.Equal(t, models.LintSeverityWarning, fired["batch-last"])
+	assert.Equal(t, models.LintSeverityError, fired["tail-sampling-needs-traces"])
+	assert.Equal(t, models.LintSeverityWarning, fired["exporter-retry"])
+	assert.Equal(t, models.LintSeverityError, fired["duplicate-receiver-endpoint"])
+	assert.Equal(t, 2, report.Errors)
+	assert.Equal(t, 3, report.Warnings)
+}
+
+func TestLintGraph_ReceiverOnTelemetryPort(t *testing.T) {
+	graph := models.PipelineGraph{
+		Nodes: []models.PipelineNodes{
+			{ComponentID: 1, Name: "prom", ComponentName: "http_receiver", ComponentRole: "receiver", SupportedSignals: []string{"logs"},
+				Config: map[string]any{"endpoint": "0.0.0.0:9464"}},
+			{ComponentID: 2, Name: "debug", ComponentName: "debug_exporter", ComponentRole: "exporter", SupportedSignals: []string{"logs"}},
+		},
+		Edges:     []models.PipelineEdges{{Source: "1", Target: "2"}},
+		Telemetry: &models.PipelineTelemetry{Metrics: models.TelemetryMetrics{Port: 9464}},
+	}
+
+	report := LintGraph(graph)
+	assert.Equal(t, models.LintSeverityError, lintRulesFired(report)["duplicate-receiver-endpoint"])
+}
+
+func TestCompileGraphToJSON_LintError(t *testing.T) {
+	graph := models.PipelineGraph{
+		Nodes: []models.PipelineNodes{
+			{ComponentID: 1, Name: "a", ComponentName: "otlp_receiver", ComponentRole: "receiver", SupportedSignals: []string{"logs"},
+				Config: map[string]any{"endpoint": "0.0.0.0:4317"}},
+			{ComponentID: 2, Name: "b", ComponentName: "otlp_receiver", ComponentRole: "receiver", SupportedSignals: []string{"logs"},
+				Config: map[string]any{"endpoint": "0.0.0.0:4317"}},
+			{ComponentID: 3, Name: "debug", ComponentName: "debug_exporter", ComponentRole: "exporter", SupportedSignals: []string{"logs"}},
+		},
+		Edges: []models.PipelineEdges{
+			{Source: "1", Target: "3"},
+			{Source: "2", Target: "3"},
+		},
+	}
+
+	result, err := CompileGraphToJSON(graph)
+	assert.Nil(t, result)
+	var lintErr *LintError
+	assert.ErrorAs(t, err, &lintErr)
+	assert.Len(t, lintErr.Issues, 1)
+}
+
+func TestCompileGraphToJSON_ProcessorsInLintOrder(t *testing.T) {
+	// Component IDs don't follow the edges, and the processors branch and join
+	graph := models.PipelineGraph{
+		Nodes: []models.PipelineNodes{
+			{ComponentID: 1, Name: "batch", ComponentName: "batch_processor", ComponentRole: "processor", SupportedSignals: []string{"traces"}},
+			{ComponentID: 2, Name: "upstream", ComponentName: "otlp_grpc_exporter", ComponentRole: "exporter", SupportedSignals: []string{"traces"},
+				Config: map[string]any{"endpoint": "collector:4317", "retry_on_failure": map[string]any{"enabled": true}}},
+			{ComponentID: 3, Name: "attributes", ComponentName: "attributes_processor", ComponentRole: "processor", SupportedSignals: []string{"traces"}},
+			{ComponentID: 4, Name: "otlp", ComponentName: "otlp_receiver", ComponentRole: "receiver", SupportedSignals: []string{"traces"}},
+			{ComponentID: 5, Name: "sampling", ComponentName: "tailsampling_processor", ComponentRole: "processor", SupportedSignals: []string{"traces"}},
+			{ComponentID: 6, Name: "limiter", ComponentName: "memorylimiter_processor", ComponentRole: "processor", SupportedSignals: []string{"traces"}},
+			{ComponentID: 7, Name: "jaeger", ComponentName: "jaeger_receiver", ComponentRole: "receiver", SupportedSignals: []string{"traces"}},
+			// A second pipeline
+			{ComponentID: 8, Name: "debug", ComponentName: "debug_exporter", ComponentRole: "exporter", SupportedSignals: []string{"logs"}},
+			{ComponentID: 9, Name: "logs batch", ComponentName: "batch_processor", ComponentRole: "processor", SupportedSignals: []string{"logs"}},
+			{ComponentID: 10, Name: "logs limiter", ComponentName: "memorylimiter_processor", ComponentRole: "processor", SupportedSignals: []string{"logs"}},
+			{ComponentID: 11, Name: "filelog", ComponentName: "filelog_receiver", ComponentRole: "receiver", SupportedSignals: []string{"logs"}},
+		},
+		Edges: []models.PipelineEdges{
+			{Source: "4", Target: "6"},
+			{Source: "7", Target: "6"},
+			{Source: "6", Target: "3"},
+			{Source: "6", Target: "5"},
+			{Source: "3", Target: "1"},
+			{Source: "5", Target: "1"},
+			{Source: "1", Target: "2"},
+			{Source: "11", Target: "10"},
+			{Source: "10", Target: "9"},
+			{Source: "9", Target: "8"},
+		},
+	}
+
+	fired := lintRulesFired(LintGraph(graph))
+	assert.NotContains(t, fired, "memory-limiter-first")
+	assert.NotContains(t, fired, "batch-last")
+
+	split, err := splitPipelines(graph)
+	assert.NoError(t, err)
+	var linted [][]string
+	for _, pipeline := range split {
+		var aliases []string
+		for _, node := range pipeline.processors {
+			aliases = append(aliases, ComponentAlias(node))
+		}
+		linted = append(linted, aliases)
+	}
+
+	// Compiling is deterministic and keeps the order lint checked
+	for range 20 {
+		_, _, _, pipelines, err := buildPipelines(graph)
+		assert.NoError(t, err)
+		assert.Equal(t, linted[0], pipelines["traces/pipeline_1"].Processors)
+		assert.Equal(t, linted[1], pipelines["logs/pipeline_2"].Processors)
+	}
+	assert.Regexp(t, `^memory_limiter/`, linted[0][0])
+	assert.Regexp(t, `^batch/`, linted[0][3])
+}
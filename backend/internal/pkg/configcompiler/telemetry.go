@@ -0,0 +1,111 @@
+package configcompiler
+
+import (
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/constants"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+)
+
+// buildTelemetryService renders a pipeline's telemetry settings into the
+// collector's service.telemetry section. Pipelines without settings get the
+// default Prometheus pull reader on port 8888.
+func buildTelemetryService(telemetry *models.PipelineTelemetry) map[string]any {
+	if telemetry == nil {
+		return constants.TelemetryService
+	}
+
+	service := make(map[string]any)
+
+	logs := make(map[string]any)
+	if telemetry.Logs.Level != "" {
+		logs["level"] = telemetry.Logs.Level
+	}
+	if sampling := telemetry.Logs.Sampling; sampling != nil {
+		samplingConfig := map[string]any{
+			"enabled": sampling.Enabled,
+		}
+		if sampling.Tick != "" {
+			samplingConfig["tick"] = sampling.Tick
+		}
+		if sampling.Initial > 0 {
+			samplingConfig["initial"] = sampling.Initial
+		}
+		if sampling.Thereafter > 0 {
+			samplingConfig["thereafter"] = sampling.Thereafter
+		}
+		logs["sampling"] = samplingConfig
+	}
+	if len(logs) > 0 {
+		service["logs"] = logs
+	}
+
+	host := telemetry.Metrics.Host
+	if host == "" {
+		host = constants.DefaultPipelineTelemetry.Metrics.Host
+	}
+	port := telemetry.Metrics.Port
+	if port == 0 {
+		port = constants.DefaultTelemetryMetricsPort
+	}
+
+	// The pull reader is always present: the agent queue relies on it for health checks.
+	readers := []any{
+		map[string]any{
+			"pull": map[string]any{
+				"exporter": map[string]any{
+					"prometheus": map[string]any{
+						"host": host,
+						"port": port,
+					},
+				},
+			},
+		},
+	}
+	if telemetry.Metrics.OTLPEndpoint != "" {
+		readers = append(readers, map[string]any{
+			"periodic": map[string]any{
+				"exporter": map[string]any{
+					"otlp": otlpTelemetryExporter(telemetry.Metrics.OTLPEndpoint, telemetry.Metrics.OTLPProtocol),
+				},
+			},
+		})
+	}
+
+	metricsLevel := telemetry.Metrics.Level
+	if metricsLevel == "" {
+		metricsLevel = constants.DefaultPipelineTelemetry.Metrics.Level
+	}
+	service["metrics"] = map[string]any{
+		"level":   metricsLevel,
+		"readers": readers,
+	}
+
+	if telemetry.Traces.OTLPEndpoint != "" {
+		traces := map[string]any{
+			"processors": []any{
+				map[string]any{
+					"batch": map[string]any{
+						"exporter": map[string]any{
+							"otlp": otlpTelemetryExporter(telemetry.Traces.OTLPEndpoint, telemetry.Traces.OTLPProtocol),
+						},
+					},
+				},
+			},
+		}
+		if telemetry.Traces.Level != "" {
+			traces["level"] = telemetry.Traces.Level
+		}
+		service["traces"] = traces
+	}
+
+	return service
+}
+
+func otlpTelemetryExporter(endpoint, protocol string) map[string]any {
+	if protocol == "" {
+		protocol = "grpc"
+	}
+	return map[string]any{
+		"protocol": protocol,
+		"endpoint": endpoint,
+	}
+}
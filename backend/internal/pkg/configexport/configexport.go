@@ -0,0 +1,243 @@
+// Package configexport renders a compiled collector config in formats that
+// run outside the agent: plain YAML, values for the upstream OpenTelemetry
+// Collector Helm chart, or a ConfigMap plus DaemonSet manifest.
+package configexport
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/constants"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatYAML       = "yaml"
+	FormatHelm       = "helm"
+	FormatKubernetes = "kubernetes"
+)
+
+// Options tunes the Helm and Kubernetes formats.
+type Options struct {
+	Name      string // pipeline name, used for Kubernetes resource names
+	Namespace string
+	Image     string // repository:tag, defaults to the upstream contrib image
+}
+
+// helmChartDefaults are components and pipelines the upstream chart configures
+// by default. They're nulled in the exported values so the chart's otlp
+// receiver doesn't clash with the pipeline's own receivers.
+var helmChartDefaults = map[string][]string{
+	"receivers":  {"jaeger", "otlp", "prometheus", "zipkin"},
+	"processors": {"batch", "memory_limiter"},
+	"exporters":  {"debug"},
+}
+
+var helmChartDefaultPipelines = []string{"logs", "metrics", "traces"}
+
+var invalidResourceChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// Export renders config in the given format.
+func Export(config map[string]any, format string, opts Options) ([]byte, error) {
+	plain, err := toPlainMap(config)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case "", FormatYAML:
+		return marshalYAML(plain)
+	case FormatHelm:
+		return helmValues(plain, opts)
+	case FormatKubernetes:
+		return kubernetesManifest(plain, opts)
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+func helmValues(config map[string]any, opts Options) ([]byte, error) {
+	for section, names := range helmChartDefaults {
+		components, _ := config[section].(map[string]any)
+		if components == nil {
+			components = map[string]any{}
+			config[section] = components
+		}
+		for _, name := range names {
+			if _, ok := components[name]; !ok {
+				components[name] = nil
+			}
+		}
+	}
+	if service, ok := config["service"].(map[string]any); ok {
+		if pipelines, ok := service["pipelines"].(map[string]any); ok {
+			for _, name := range helmChartDefaultPipelines {
+				if _, exists := pipelines[name]; !exists {
+					pipelines[name] = nil
+				}
+			}
+		}
+	}
+
+	repository, tag := splitImage(opts.Image)
+	values := map[string]any{
+		"mode": "daemonset",
+		"image": map[string]any{
+			"repository": repository,
+			"tag":        tag,
+		},
+		"config": config,
+	}
+	return marshalYAML(values)
+}
+
+func kubernetesManifest(config map[string]any, opts Options) ([]byte, error) {
+	configYAML, err := marshalYAML(config)
+	if err != nil {
+		return nil, err
+	}
+
+	name := resourceName(opts.Name)
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = constants.DefaultExportNamespace
+	}
+	repository, tag := splitImage(opts.Image)
+	labels := map[string]any{"app": name}
+	checksum := sha256.Sum256(configYAML)
+
+	configMap := map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]any{"name": name, "namespace": namespace, "labels": labels},
+		"data":       map[string]any{"config.yaml": string(configYAML)},
+	}
+	daemonSet := map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "DaemonSet",
+		"metadata":   map[string]any{"name": name, "namespace": namespace, "labels": labels},
+		"spec": map[string]any{
+			"selector": map[string]any{"matchLabels": labels},
+			"template": map[string]any{
+				"metadata": map[string]any{
+					"labels": labels,
+					// Roll the pods whenever the config changes
+					"annotations": map[string]any{"checksum/config": hex.EncodeToString(checksum[:])},
+				},
+				"spec": map[string]any{
+					"hostNetwork": true,
+					"dnsPolicy":   "ClusterFirstWithHostNet",
+					"containers": []any{
+						map[string]any{
+							"name":            "otel-collector",
+							"image":           repository + ":" + tag,
+							"imagePullPolicy": "IfNotPresent",
+							"args":            []any{"--config=/conf/config.yaml"},
+							"volumeMounts":    []any{map[string]any{"name": "config", "mountPath": "/conf"}},
+						},
+					},
+					"volumes": []any{
+						map[string]any{"name": "config", "configMap": map[string]any{"name": name}},
+					},
+				},
+			},
+		},
+	}
+
+	return marshalYAML(configMap, daemonSet)
+}
+
+// IsSupportedFormat reports whether Export understands format.
+func IsSupportedFormat(format string) bool {
+	switch format {
+	case "", FormatYAML, FormatHelm, FormatKubernetes:
+		return true
+	}
+	return false
+}
+
+// marshalYAML encodes each document with two-space indentation, separating
+// multiple documents with "---".
+func marshalYAML(documents ...any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	for _, document := range documents {
+		if err := encoder.Encode(document); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resourceName turns a pipeline name into a valid Kubernetes resource name.
+func resourceName(pipelineName string) string {
+	name := invalidResourceChars.ReplaceAllString(strings.ToLower(pipelineName), "-")
+	name = strings.Trim(name, "-")
+	if len(name) > 50 {
+		name = strings.TrimRight(name[:50], "-")
+	}
+	if name == "" {
+		return "otel-collector"
+	}
+	return name + "-collector"
+}
+
+func splitImage(image string) (string, string) {
+	if image == "" {
+		return constants.ExportCollectorImageRepository, constants.ExportCollectorImageTag
+	}
+	// A colon after the last slash separates the tag, not a registry port
+	if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+		return image[:idx], image[idx+1:]
+	}
+	return image, "latest"
+}
+
+// toPlainMap round-trips the compiled config through JSON so it holds only
+// maps, slices and scalars, with whole numbers kept as integers.
+func toPlainMap(config map[string]any) (map[string]any, error) {
+	raw, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling config: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var plain map[string]any
+	if err := decoder.Decode(&plain); err != nil {
+		return nil, fmt.Errorf("error decoding config: %w", err)
+	}
+	return normalizeNumbers(plain).(map[string]any), nil
+}
+
+func normalizeNumbers(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeNumbers(item)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	default:
+		return value
+	}
+}
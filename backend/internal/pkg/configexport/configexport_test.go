@@ -0,0 +1,81 @@
+package configexport
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func testConfig() map[string]any {
+	return map[string]any{
+		"receivers":  map[string]any{"otlp/In_1a2b3c4d": map[string]any{"protocols": map[string]any{"grpc": map[string]any{"endpoint": "0.0.0.0:4317"}}}},
+		"processors": map[string]any{"memory_limiter/Limit_1a2b3c4d": map[string]any{"limit_mib": 1000000, "check_interval": "1s"}},
+		"exporters":  map[string]any{"debug/Out_1a2b3c4d": map[string]any{}},
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"logs/pipeline_1": map[string]any{
+					"receivers":  []string{"otlp/In_1a2b3c4d"},
+					"processors": []string{"memory_limiter/Limit_1a2b3c4d"},
+					"exporters":  []string{"debug/Out_1a2b3c4d"},
+				},
+			},
+		},
+	}
+}
+
+func TestExport_YAML(t *testing.T) {
+	out, err := Export(testConfig(), FormatYAML, Options{})
+	assert.NoError(t, err)
+	// Whole numbers stay integers instead of becoming 1e+06
+	assert.Contains(t, string(out), "limit_mib: 1000000")
+
+	var parsed map[string]any
+	assert.NoError(t, yaml.Unmarshal(out, &parsed))
+	assert.Contains(t, parsed, "service")
+}
+
+func TestExport_Helm(t *testing.T) {
+	out, err := Export(testConfig(), FormatHelm, Options{Image: "registry.local:5000/otelcol:1.2.3"})
+	assert.NoError(t, err)
+
+	var values map[string]any
+	assert.NoError(t, yaml.Unmarshal(out, &values))
+	assert.Equal(t, "daemonset", values["mode"])
+	assert.Equal(t, map[string]any{"repository": "registry.local:5000/otelcol", "tag": "1.2.3"}, values["image"])
+
+	config := values["config"].(map[string]any)
+	receivers := config["receivers"].(map[string]any)
+	assert.Contains(t, receivers, "otlp")
+	assert.Nil(t, receivers["otlp"])
+	pipelines := config["service"].(map[string]any)["pipelines"].(map[string]any)
+	assert.Nil(t, pipelines["logs"])
+	assert.NotNil(t, pipelines["logs/pipeline_1"])
+}
+
+func TestExport_Kubernetes(t *testing.T) {
+	out, err := Export(testConfig(), FormatKubernetes, Options{Name: "Prod Logs!"})
+	assert.NoError(t, err)
+
+	decoder := yaml.NewDecoder(strings.NewReader(string(out)))
+	var configMap, daemonSet map[string]any
+	assert.NoError(t, decoder.Decode(&configMap))
+	assert.NoError(t, decoder.Decode(&daemonSet))
+
+	assert.Equal(t, "ConfigMap", configMap["kind"])
+	assert.Equal(t, "DaemonSet", daemonSet["kind"])
+	metadata := daemonSet["metadata"].(map[string]any)
+	assert.Equal(t, "prod-logs-collector", metadata["name"])
+	assert.Equal(t, "observability", metadata["namespace"])
+
+	var embedded map[string]any
+	assert.NoError(t, yaml.Unmarshal([]byte(configMap["data"].(map[string]any)["config.yaml"].(string)), &embedded))
+	assert.Contains(t, embedded, "receivers")
+}
+
+func TestExport_UnsupportedFormat(t *testing.T) {
+	_, err := Export(testConfig(), "toml", Options{})
+	assert.Error(t, err)
+	assert.False(t, IsSupportedFormat("toml"))
+}
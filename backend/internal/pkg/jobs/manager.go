@@ -0,0 +1,395 @@
+// Package jobs runs long-running operations over many targets in the
+// background. Jobs and their per-target results are persisted, so they can be
+// polled after they finish and are resumed after a backend restart.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/constants"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+)
+
+// Retention is how long finished jobs can still be polled.
+const Retention = 7 * 24 * time.Hour
+
+// Executor processes one target of a job. params is the JSON the job was
+// enqueued with.
+type Executor func(jobId, target string, params json.RawMessage) error
+
+type JobRepositoryInterface interface {
+	CreateJob(job models.Job, params []byte) error
+	GetAllJobs() ([]models.Job, error)
+	GetJob(jobId string) (*models.Job, error)
+	GetJobParams(jobId string) ([]byte, error)
+	GetUnfinishedJobIDs() ([]string, error)
+	UpdateJobStatus(jobId, status string, finishedAt int64) error
+	UpdateJobTarget(jobId string, position int, result models.JobTargetResult) error
+	DeleteJobsFinishedBefore(cutoff int64) error
+}
+
+// Manager runs jobs with at most Concurrency targets of a job in flight. A
+// failed target is retried up to MaxAttempts times in total, waiting Backoff
+// before the first retry and twice as long before each following one.
+type Manager struct {
+	JobRepository JobRepositoryInterface
+	Concurrency   int
+	MaxAttempts   int
+	Backoff       time.Duration
+
+	mu        sync.Mutex
+	executors map[string]Executor
+	cancels   map[string]context.CancelFunc
+}
+
+// NewManager creates a Manager configured from constants.BULK_CONCURRENCY,
+// constants.JOB_MAX_ATTEMPTS and constants.JOB_RETRY_BACKOFF_SEC.
+func NewManager(jobRepository JobRepositoryInterface) *Manager {
+	return &Manager{
+		JobRepository: jobRepository,
+		Concurrency:   constants.BULK_CONCURRENCY,
+		MaxAttempts:   constants.JOB_MAX_ATTEMPTS,
+		Backoff:       time.Duration(constants.JOB_RETRY_BACKOFF_SEC) * time.Second,
+		executors:     make(map[string]Executor),
+		cancels:       make(map[string]context.CancelFunc),
+	}
+}
+
+// permanentError marks a failure that retrying can't fix.
+type permanentError struct {
+	err error
+}
+
+func (p permanentError) Error() string { return p.err.Error() }
+func (p permanentError) Unwrap() error { return p.err }
+
+// Permanent wraps an executor error so the target fails without retries.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// Register sets the executor for a job type. Executors have to be registered
+// before jobs of their type are enqueued or resumed.
+func (m *Manager) Register(jobType string, executor Executor) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.executors[jobType] = executor
+}
+
+// Enqueue stores a job running the executor of jobType for every target and
+// starts it in the background.
+func (m *Manager) Enqueue(jobType string, targets []string, params any) (*models.Job, error) {
+	return m.EnqueueWithOptions(jobType, targets, params, models.JobOptions{})
+}
+
+// EnqueueWithOptions is Enqueue for a job that runs with the given options.
+func (m *Manager) EnqueueWithOptions(jobType string, targets []string, params any, options models.JobOptions) (*models.Job, error) {
+	if options.Concurrency < 0 {
+		return nil, fmt.Errorf("job concurrency must not be negative")
+	}
+
+	executor, ok := m.executor(jobType)
+	if !ok {
+		return nil, fmt.Errorf("no executor registered for job type %s", jobType)
+	}
+
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job params: %w", err)
+	}
+
+	if err := m.JobRepository.DeleteJobsFinishedBefore(time.Now().Add(-Retention).Unix()); err != nil {
+		utils.Logger.Sugar().Errorf("Failed to delete expired jobs: %v", err)
+	}
+
+	now := utils.GetCurrentTime()
+	job := models.Job{
+		ID:        newJobID(),
+		Type:      jobType,
+		Status:    models.JobRunning,
+		Options:   options,
+		Results:   make([]models.JobTargetResult, len(targets)),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for i, target := range targets {
+		job.Results[i] = models.JobTargetResult{Target: target, Status: models.JobPending}
+	}
+	summarize(&job)
+
+	if err := m.JobRepository.CreateJob(job, paramsJSON); err != nil {
+		return nil, err
+	}
+
+	snapshot := job
+	snapshot.Results = append([]models.JobTargetResult(nil), job.Results...)
+	m.start(job, executor, paramsJSON)
+	return &snapshot, nil
+}
+
+// Resume restarts the jobs that were unfinished when the backend stopped.
+// Targets that were in flight are run again if they have attempts left.
+func (m *Manager) Resume() error {
+	ids, err := m.JobRepository.GetUnfinishedJobIDs()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		job, err := m.JobRepository.GetJob(id)
+		if err != nil {
+			return err
+		}
+
+		executor, ok := m.executor(job.Type)
+		if !ok {
+			utils.Logger.Sugar().Errorf("Failing job [ID: %s]: no executor registered for job type %s", job.ID, job.Type)
+			if err := m.JobRepository.UpdateJobStatus(job.ID, models.JobFailed, utils.GetCurrentTime()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		params, err := m.JobRepository.GetJobParams(id)
+		if err != nil {
+			return err
+		}
+
+		utils.Logger.Sugar().Infof("Resuming job [ID: %s] of type %s", job.ID, job.Type)
+		m.start(*job, executor, params)
+	}
+	return nil
+}
+
+func (m *Manager) GetAllJobs() ([]models.Job, error) {
+	return m.JobRepository.GetAllJobs()
+}
+
+func (m *Manager) GetJob(jobId string) (*models.Job, error) {
+	return m.JobRepository.GetJob(jobId)
+}
+
+// CancelJob stops a running job. Targets that haven't started, or are waiting
+// for a retry, are cancelled; targets in flight run to completion.
+func (m *Manager) CancelJob(jobId string) (*models.Job, error) {
+	m.mu.Lock()
+	cancel, running := m.cancels[jobId]
+	m.mu.Unlock()
+
+	if !running {
+		job, err := m.JobRepository.GetJob(jobId)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: job %s is %s", utils.ErrJobAlreadyFinished, jobId, job.Status)
+	}
+
+	cancel()
+	return m.JobRepository.GetJob(jobId)
+}
+
+// cancelJob cancels the context of a running job.
+func (m *Manager) cancelJob(jobId string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cancel, ok := m.cancels[jobId]; ok {
+		cancel()
+	}
+}
+
+func (m *Manager) executor(jobType string) (Executor, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	executor, ok := m.executors[jobType]
+	return executor, ok
+}
+
+func (m *Manager) start(job models.Job, executor Executor, params json.RawMessage) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m.mu.Lock()
+	m.cancels[job.ID] = cancel
+	m.mu.Unlock()
+
+	go m.run(ctx, job, executor, params)
+}
+
+func (m *Manager) run(ctx context.Context, job models.Job, executor Executor, params json.RawMessage) {
+	defer func() {
+		m.mu.Lock()
+		m.cancels[job.ID]()
+		delete(m.cancels, job.ID)
+		m.mu.Unlock()
+	}()
+
+	if job.Status == models.JobPending {
+		if err := m.JobRepository.UpdateJobStatus(job.ID, models.JobRunning, 0); err != nil {
+			utils.Logger.Sugar().Errorf("Failed to update job [ID: %s]: %v", job.ID, err)
+		}
+	}
+
+	concurrency := m.Concurrency
+	if job.Options.Concurrency > 0 {
+		concurrency = job.Options.Concurrency
+	}
+
+	// Each position is handed to one worker, which owns its result
+	positions := make(chan int)
+	var wg sync.WaitGroup
+	var halted atomic.Bool
+	if job.Options.HaltOnFailure {
+		// A job resumed after a target failed stays halted
+		for _, result := range job.Results {
+			if result.Status == models.JobFailed {
+				halted.Store(true)
+				m.cancelJob(job.ID)
+				break
+			}
+		}
+	}
+	for w := 0; w < max(1, min(concurrency, len(job.Results))); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range positions {
+				job.Results[i] = m.runTarget(ctx, job.ID, i, job.Results[i], executor, params)
+				if job.Options.HaltOnFailure && job.Results[i].Status == models.JobFailed && !halted.Swap(true) {
+					utils.Logger.Sugar().Warnf("Halting job [ID: %s] after target %s failed", job.ID, job.Results[i].Target)
+					m.cancelJob(job.ID)
+				}
+			}
+		}()
+	}
+	for i, result := range job.Results {
+		if !isDone(result.Status) {
+			positions <- i
+		}
+	}
+	close(positions)
+	wg.Wait()
+
+	summarize(&job)
+	status := jobStatus(job.Results)
+	if halted.Load() {
+		// The targets cancelled by the halt don't make the job cancelled
+		status = models.JobFailed
+	}
+	if err := m.JobRepository.UpdateJobStatus(job.ID, status, utils.GetCurrentTime()); err != nil {
+		utils.Logger.Sugar().Errorf("Failed to update job [ID: %s]: %v", job.ID, err)
+	}
+	utils.Logger.Sugar().Infof("Job [ID: %s] of type %s %s: %d succeeded, %d failed", job.ID, job.Type, status, job.Succeeded, job.Failed)
+}
+
+// runTarget runs the executor for one target until it succeeds, runs out of
+// attempts or the job is cancelled, persisting every state change.
+func (m *Manager) runTarget(ctx context.Context, jobId string, position int, result models.JobTargetResult, executor Executor, params json.RawMessage) models.JobTargetResult {
+	save := func() {
+		if err := m.JobRepository.UpdateJobTarget(jobId, position, result); err != nil {
+			utils.Logger.Sugar().Errorf("Failed to update target %s of job [ID: %s]: %v", result.Target, jobId, err)
+		}
+	}
+
+	for {
+		if ctx.Err() != nil {
+			result.Status = models.JobCancelled
+			save()
+			return result
+		}
+		if result.Attempts >= m.MaxAttempts {
+			// Only reachable for a target that was in flight during a restart
+			result.Status = models.JobFailed
+			if result.Error == "" {
+				result.Error = "interrupted by a backend restart"
+			}
+			save()
+			return result
+		}
+
+		result.Status = models.JobRunning
+		result.Attempts++
+		save()
+
+		err := executor(jobId, result.Target, params)
+		if err == nil {
+			result.Status = models.JobCompleted
+			result.Error = ""
+			save()
+			return result
+		}
+
+		result.Error = err.Error()
+		var permanent permanentError
+		if errors.As(err, &permanent) || result.Attempts >= m.MaxAttempts {
+			result.Status = models.JobFailed
+			save()
+			return result
+		}
+
+		result.Status = models.JobPending
+		save()
+		select {
+		case <-ctx.Done():
+		case <-time.After(m.Backoff << (result.Attempts - 1)):
+		}
+	}
+}
+
+// jobStatus derives the status of a finished job from its targets: cancelled
+// if any target was, else failed if any target failed.
+func jobStatus(results []models.JobTargetResult) string {
+	status := models.JobCompleted
+	for _, result := range results {
+		if result.Status == models.JobCancelled {
+			return models.JobCancelled
+		}
+		if result.Status == models.JobFailed {
+			status = models.JobFailed
+		}
+	}
+	return status
+}
+
+func isDone(status string) bool {
+	return status == models.JobCompleted || status == models.JobFailed || status == models.JobCancelled
+}
+
+// summarize derives a job's counters from its per-target results.
+func summarize(job *models.Job) {
+	job.Total = len(job.Results)
+	job.Succeeded, job.Failed = 0, 0
+	done := 0
+	for _, result := range job.Results {
+		switch result.Status {
+		case models.JobCompleted:
+			job.Succeeded++
+		case models.JobFailed:
+			job.Failed++
+		}
+		if isDone(result.Status) {
+			done++
+		}
+	}
+	job.Progress = progress(done, job.Total)
+}
+
+func progress(done, total int) int {
+	if total == 0 {
+		return 100
+	}
+	return done * 100 / total
+}
+
+func newJobID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,211 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	database "github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/db"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupManager(t *testing.T) (*Manager, *JobRepository) {
+	db, err := database.DBInit(":memory:")
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+
+	repository := NewJobRepository(db)
+	manager := NewManager(repository)
+	manager.Concurrency = 2
+	manager.Backoff = time.Millisecond
+	return manager, repository
+}
+
+func waitForJob(t *testing.T, manager *Manager, id string) *models.Job {
+	t.Helper()
+	var job *models.Job
+	assert.Eventually(t, func() bool {
+		job, _ = manager.GetJob(id)
+		return job != nil && job.FinishedAt != 0
+	}, time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestManager_RunsWithBoundedConcurrencyAndRetries(t *testing.T) {
+	manager, _ := setupManager(t)
+
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	calls := map[string]int{}
+	manager.Register("test", func(jobId, target string, params json.RawMessage) error {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		calls[target]++
+		attempt := calls[target]
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		switch {
+		case target == "2" && attempt == 1:
+			return errors.New("connection reset")
+		case target == "3":
+			return errors.New("agent unreachable")
+		case target == "4":
+			return Permanent(errors.New("agent doesn't exist"))
+		}
+		return nil
+	})
+
+	job, err := manager.Enqueue("test", []string{"1", "2", "3", "4", "5"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobRunning, job.Status)
+	assert.Equal(t, 5, job.Total)
+
+	finished := waitForJob(t, manager, job.ID)
+	assert.Equal(t, models.JobFailed, finished.Status)
+	assert.Equal(t, 3, finished.Succeeded)
+	assert.Equal(t, 2, finished.Failed)
+	assert.Equal(t, 100, finished.Progress)
+	assert.Equal(t, models.JobTargetResult{Target: "2", Status: models.JobCompleted, Attempts: 2}, finished.Results[1])
+	assert.Equal(t, models.JobTargetResult{Target: "3", Status: models.JobFailed, Attempts: 3, Error: "agent unreachable"}, finished.Results[2])
+	assert.Equal(t, models.JobTargetResult{Target: "4", Status: models.JobFailed, Attempts: 1, Error: "agent doesn't exist"}, finished.Results[3])
+	assert.LessOrEqual(t, peak, 2)
+
+	all, err := manager.GetAllJobs()
+	assert.NoError(t, err)
+	assert.Len(t, all, 1)
+	assert.Equal(t, 3, all[0].Succeeded)
+	assert.Nil(t, all[0].Results)
+}
+
+func TestManager_CancelJob(t *testing.T) {
+	manager, _ := setupManager(t)
+	manager.Concurrency = 1
+
+	release := make(chan struct{})
+	manager.Register("test", func(jobId, target string, params json.RawMessage) error {
+		<-release
+		return nil
+	})
+
+	job, err := manager.Enqueue("test", []string{"1", "2", "3"}, nil)
+	assert.NoError(t, err)
+
+	_, err = manager.CancelJob(job.ID)
+	assert.NoError(t, err)
+	close(release)
+
+	finished := waitForJob(t, manager, job.ID)
+	assert.Equal(t, models.JobCancelled, finished.Status)
+	assert.Equal(t, models.JobCancelled, finished.Results[2].Status)
+
+	_, err = manager.CancelJob(job.ID)
+	assert.ErrorIs(t, err, utils.ErrJobAlreadyFinished)
+
+	_, err = manager.CancelJob("missing")
+	assert.ErrorIs(t, err, utils.ErrJobDoesNotExists)
+}
+
+func TestManager_HaltOnFailure(t *testing.T) {
+	manager, _ := setupManager(t)
+
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	manager.Register("test", func(jobId, target string, params json.RawMessage) error {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		time.Sleep(5 * time.Millisecond)
+		if target == "2" {
+			return Permanent(errors.New("health check failed"))
+		}
+		return nil
+	})
+
+	job, err := manager.EnqueueWithOptions("test", []string{"1", "2", "3", "4"}, nil, models.JobOptions{Concurrency: 1, HaltOnFailure: true})
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobOptions{Concurrency: 1, HaltOnFailure: true}, job.Options)
+
+	finished := waitForJob(t, manager, job.ID)
+	assert.Equal(t, models.JobFailed, finished.Status)
+	assert.Equal(t, models.JobOptions{Concurrency: 1, HaltOnFailure: true}, finished.Options)
+	assert.Equal(t, models.JobCompleted, finished.Results[0].Status)
+	assert.Equal(t, models.JobFailed, finished.Results[1].Status)
+	assert.Equal(t, models.JobCancelled, finished.Results[2].Status)
+	assert.Equal(t, models.JobCancelled, finished.Results[3].Status)
+	assert.Equal(t, 1, peak)
+
+	_, err = manager.EnqueueWithOptions("test", []string{"1"}, nil, models.JobOptions{Concurrency: -1})
+	assert.Error(t, err)
+}
+
+func TestManager_ResumesUnfinishedJobs(t *testing.T) {
+	manager, repository := setupManager(t)
+
+	// A job left behind by a backend that stopped while the second target
+	// was in flight
+	params, _ := json.Marshal(map[string]string{"action": "stop"})
+	assert.NoError(t, repository.CreateJob(models.Job{
+		ID:     "resumed",
+		Type:   "test",
+		Status: models.JobRunning,
+		Results: []models.JobTargetResult{
+			{Target: "1", Status: models.JobCompleted, Attempts: 1},
+			{Target: "2", Status: models.JobRunning, Attempts: 1},
+			{Target: "3", Status: models.JobPending},
+		},
+	}, params))
+	assert.NoError(t, repository.CreateJob(models.Job{
+		ID:      "orphaned",
+		Type:    "unknown",
+		Status:  models.JobRunning,
+		Results: []models.JobTargetResult{{Target: "1", Status: models.JobPending}},
+	}, params))
+
+	var mu sync.Mutex
+	var ran []string
+	manager.Register("test", func(jobId, target string, params json.RawMessage) error {
+		assert.JSONEq(t, `{"action":"stop"}`, string(params))
+		mu.Lock()
+		defer mu.Unlock()
+		ran = append(ran, target)
+		return nil
+	})
+	assert.NoError(t, manager.Resume())
+
+	finished := waitForJob(t, manager, "resumed")
+	assert.Equal(t, models.JobCompleted, finished.Status)
+	assert.Equal(t, 2, finished.Results[1].Attempts)
+	assert.ElementsMatch(t, []string{"2", "3"}, ran)
+
+	orphaned, err := manager.GetJob("orphaned")
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobFailed, orphaned.Status)
+}
+
+func TestManager_UnknownJob(t *testing.T) {
+	manager, _ := setupManager(t)
+
+	_, err := manager.GetJob("missing")
+	assert.ErrorIs(t, err, utils.ErrJobDoesNotExists)
+
+	_, err = manager.Enqueue("unregistered", []string{"1"}, nil)
+	assert.Error(t, err)
+}
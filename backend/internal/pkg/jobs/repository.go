@@ -0,0 +1,206 @@
+package jobs
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
+)
+
+type JobRepository struct {
+	db *sql.DB
+}
+
+// NewJobRepository creates a new JobRepository
+func NewJobRepository(db *sql.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+// CreateJob stores a job with its targets.
+func (j *JobRepository) CreateJob(job models.Job, params []byte) error {
+	tx, err := j.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		"INSERT INTO jobs (job_id, type, status, params_json, concurrency, halt_on_failure, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		job.ID, job.Type, job.Status, string(params), job.Options.Concurrency, job.Options.HaltOnFailure, job.CreatedAt, job.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert job: %w", err)
+	}
+
+	for i, result := range job.Results {
+		_, err = tx.Exec(
+			"INSERT INTO job_targets (job_id, position, target, status, attempts, error) VALUES (?, ?, ?, ?, ?, ?)",
+			job.ID, i, result.Target, result.Status, result.Attempts, result.Error,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert job target: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetAllJobs returns every job, newest first, without per-target results.
+func (j *JobRepository) GetAllJobs() ([]models.Job, error) {
+	rows, err := j.db.Query(`
+		SELECT j.job_id, j.type, j.status, j.concurrency, j.halt_on_failure, j.created_at, j.updated_at, COALESCE(j.finished_at, 0),
+			COUNT(t.position),
+			COALESCE(SUM(t.status = ?), 0),
+			COALESCE(SUM(t.status = ?), 0),
+			COALESCE(SUM(t.status = ?), 0)
+		FROM jobs j
+		LEFT JOIN job_targets t ON t.job_id = j.job_id
+		GROUP BY j.job_id
+		ORDER BY j.created_at DESC, j.job_id`,
+		models.JobCompleted, models.JobFailed, models.JobCancelled,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []models.Job{}
+	for rows.Next() {
+		var job models.Job
+		var cancelled int
+		if err := rows.Scan(&job.ID, &job.Type, &job.Status, &job.Options.Concurrency, &job.Options.HaltOnFailure,
+			&job.CreatedAt, &job.UpdatedAt, &job.FinishedAt, &job.Total, &job.Succeeded, &job.Failed, &cancelled); err != nil {
+			return nil, err
+		}
+		job.Progress = progress(job.Succeeded+job.Failed+cancelled, job.Total)
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// GetJob returns a job with its per-target results.
+func (j *JobRepository) GetJob(jobId string) (*models.Job, error) {
+	var job models.Job
+	err := j.db.QueryRow(
+		"SELECT job_id, type, status, concurrency, halt_on_failure, created_at, updated_at, COALESCE(finished_at, 0) FROM jobs WHERE job_id = ?", jobId,
+	).Scan(&job.ID, &job.Type, &job.Status, &job.Options.Concurrency, &job.Options.HaltOnFailure, &job.CreatedAt, &job.UpdatedAt, &job.FinishedAt)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrJobDoesNotExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job: %w", err)
+	}
+
+	rows, err := j.db.Query("SELECT target, status, attempts, error FROM job_targets WHERE job_id = ? ORDER BY position", jobId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job targets: %w", err)
+	}
+	defer rows.Close()
+
+	job.Results = []models.JobTargetResult{}
+	for rows.Next() {
+		var result models.JobTargetResult
+		if err := rows.Scan(&result.Target, &result.Status, &result.Attempts, &result.Error); err != nil {
+			return nil, err
+		}
+		job.Results = append(job.Results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	summarize(&job)
+	return &job, nil
+}
+
+// GetJobParams returns the parameters a job was enqueued with.
+func (j *JobRepository) GetJobParams(jobId string) ([]byte, error) {
+	var params string
+	err := j.db.QueryRow("SELECT params_json FROM jobs WHERE job_id = ?", jobId).Scan(&params)
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrJobDoesNotExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job params: %w", err)
+	}
+	return []byte(params), nil
+}
+
+// GetUnfinishedJobIDs returns the jobs that were still pending or running,
+// oldest first.
+func (j *JobRepository) GetUnfinishedJobIDs() ([]string, error) {
+	rows, err := j.db.Query(
+		"SELECT job_id FROM jobs WHERE status IN (?, ?) ORDER BY created_at, job_id", models.JobPending, models.JobRunning,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unfinished jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// UpdateJobStatus sets a job's status. A non-zero finishedAt marks it done.
+func (j *JobRepository) UpdateJobStatus(jobId, status string, finishedAt int64) error {
+	var finished any
+	if finishedAt != 0 {
+		finished = finishedAt
+	}
+	_, err := j.db.Exec(
+		"UPDATE jobs SET status = ?, updated_at = ?, finished_at = ? WHERE job_id = ?",
+		status, utils.GetCurrentTime(), finished, jobId,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update job status: %w", err)
+	}
+	return nil
+}
+
+// UpdateJobTarget stores the state of the target at position.
+func (j *JobRepository) UpdateJobTarget(jobId string, position int, result models.JobTargetResult) error {
+	tx, err := j.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		"UPDATE job_targets SET status = ?, attempts = ?, error = ? WHERE job_id = ? AND position = ?",
+		result.Status, result.Attempts, result.Error, jobId, position,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update job target: %w", err)
+	}
+	if _, err = tx.Exec("UPDATE jobs SET updated_at = ? WHERE job_id = ?", utils.GetCurrentTime(), jobId); err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	return tx.Commit()
+}
+
+// DeleteJobsFinishedBefore removes jobs that finished before cutoff.
+func (j *JobRepository) DeleteJobsFinishedBefore(cutoff int64) error {
+	tx, err := j.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		"DELETE FROM job_targets WHERE job_id IN (SELECT job_id FROM jobs WHERE finished_at IS NOT NULL AND finished_at < ?)", cutoff,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete finished job targets: %w", err)
+	}
+	if _, err = tx.Exec("DELETE FROM jobs WHERE finished_at IS NOT NULL AND finished_at < ?", cutoff); err != nil {
+		return fmt.Errorf("failed to delete finished jobs: %w", err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,232 @@
+// Package labelselector parses and evaluates agent label selectors such as
+// "env=prod,region in (eu,us),!canary".
+package labelselector
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+)
+
+// ErrInvalidSelector is returned by Parse for malformed selectors.
+var ErrInvalidSelector = errors.New("invalid label selector")
+
+// Operators a requirement can use.
+const (
+	OpEquals       = "="
+	OpNotEquals    = "!="
+	OpIn           = "in"
+	OpNotIn        = "notin"
+	OpExists       = "exists"
+	OpDoesNotExist = "!"
+)
+
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_./-]*[A-Za-z0-9])?$`)
+
+// Requirement is one comma-separated term of a selector.
+type Requirement struct {
+	Key      string
+	Operator string
+	Values   []string
+}
+
+// Selector matches agents whose labels satisfy every requirement. The empty
+// selector matches nothing, so clearing a pipeline's selector can't claim
+// every agent.
+type Selector struct {
+	Requirements []Requirement
+}
+
+// Parse reads a selector. Supported terms are key=value (or key==value),
+// key!=value, key in (a,b), key notin (a,b), key and !key.
+func Parse(selector string) (*Selector, error) {
+	terms, err := splitTerms(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := &Selector{}
+	for _, term := range terms {
+		requirement, err := parseRequirement(term)
+		if err != nil {
+			return nil, err
+		}
+		parsed.Requirements = append(parsed.Requirements, requirement)
+	}
+	return parsed, nil
+}
+
+// Empty reports whether the selector has no requirements.
+func (s *Selector) Empty() bool {
+	return len(s.Requirements) == 0
+}
+
+// Matches reports whether labels satisfy every requirement.
+func (s *Selector) Matches(labels map[string]string) bool {
+	matched, _ := s.Explain(labels)
+	return matched
+}
+
+// Explain evaluates every requirement against labels and says why each one
+// did or didn't match.
+func (s *Selector) Explain(labels map[string]string) (bool, []models.RequirementResult) {
+	results := make([]models.RequirementResult, 0, len(s.Requirements))
+	matched := !s.Empty()
+	for _, requirement := range s.Requirements {
+		result := requirement.evaluate(labels)
+		matched = matched && result.Matched
+		results = append(results, result)
+	}
+	return matched, results
+}
+
+// String renders the selector in canonical form.
+func (s *Selector) String() string {
+	terms := make([]string, len(s.Requirements))
+	for i, requirement := range s.Requirements {
+		terms[i] = requirement.String()
+	}
+	return strings.Join(terms, ",")
+}
+
+func (r Requirement) String() string {
+	switch r.Operator {
+	case OpEquals, OpNotEquals:
+		return r.Key + r.Operator + r.Values[0]
+	case OpIn, OpNotIn:
+		return fmt.Sprintf("%s %s (%s)", r.Key, r.Operator, strings.Join(r.Values, ","))
+	case OpDoesNotExist:
+		return "!" + r.Key
+	default:
+		return r.Key
+	}
+}
+
+func (r Requirement) evaluate(labels map[string]string) models.RequirementResult {
+	value, present := labels[r.Key]
+	has := fmt.Sprintf("agent has %s=%s", r.Key, value)
+	missing := fmt.Sprintf("agent has no %s label", r.Key)
+
+	result := models.RequirementResult{Requirement: r.String()}
+	switch r.Operator {
+	case OpExists:
+		result.Matched = present
+	case OpDoesNotExist:
+		result.Matched = !present
+	case OpEquals, OpIn:
+		result.Matched = present && contains(r.Values, value)
+	case OpNotEquals, OpNotIn:
+		result.Matched = !present || !contains(r.Values, value)
+	}
+
+	if present {
+		result.Reason = has
+	} else {
+		result.Reason = missing
+	}
+	return result
+}
+
+// splitTerms splits on commas outside parentheses.
+func splitTerms(selector string) ([]string, error) {
+	var terms []string
+	depth, start := 0, 0
+	for i, ch := range selector {
+		switch ch {
+		case '(':
+			depth++
+			if depth > 1 {
+				return nil, fmt.Errorf("%w: nested parentheses in %q", ErrInvalidSelector, selector)
+			}
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("%w: unbalanced parentheses in %q", ErrInvalidSelector, selector)
+			}
+		case ',':
+			if depth == 0 {
+				terms = append(terms, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("%w: unbalanced parentheses in %q", ErrInvalidSelector, selector)
+	}
+	terms = append(terms, selector[start:])
+
+	if len(terms) == 1 && strings.TrimSpace(terms[0]) == "" {
+		return nil, nil
+	}
+	for i, term := range terms {
+		terms[i] = strings.TrimSpace(term)
+		if terms[i] == "" {
+			return nil, fmt.Errorf("%w: empty term in %q", ErrInvalidSelector, selector)
+		}
+	}
+	return terms, nil
+}
+
+var setTermPattern = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+
+func parseRequirement(term string) (Requirement, error) {
+	if match := setTermPattern.FindStringSubmatch(term); match != nil {
+		values, err := parseValues(match[3], term)
+		if err != nil {
+			return Requirement{}, err
+		}
+		return newRequirement(match[1], match[2], values, term)
+	}
+
+	for _, op := range []string{"!=", "==", "="} {
+		if key, value, found := strings.Cut(term, op); found {
+			value = strings.TrimSpace(value)
+			if value == "" || strings.ContainsAny(value, "=!()") {
+				return Requirement{}, fmt.Errorf("%w: bad value in %q", ErrInvalidSelector, term)
+			}
+			operator := OpEquals
+			if op == "!=" {
+				operator = OpNotEquals
+			}
+			return newRequirement(strings.TrimSpace(key), operator, []string{value}, term)
+		}
+	}
+
+	if key, found := strings.CutPrefix(term, "!"); found {
+		return newRequirement(strings.TrimSpace(key), OpDoesNotExist, nil, term)
+	}
+	return newRequirement(term, OpExists, nil, term)
+}
+
+func parseValues(list string, term string) ([]string, error) {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return nil, fmt.Errorf("%w: empty value in %q", ErrInvalidSelector, term)
+		}
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values, nil
+}
+
+func newRequirement(key, operator string, values []string, term string) (Requirement, error) {
+	if !labelKeyPattern.MatchString(key) {
+		return Requirement{}, fmt.Errorf("%w: bad label key in %q", ErrInvalidSelector, term)
+	}
+	return Requirement{Key: key, Operator: operator, Values: values}, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package labelselector
+
+import (
+	"testing"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	selector, err := Parse("env=prod, region in (us, eu),tier!=batch,team notin (a),gpu,!canary")
+	assert.NoError(t, err)
+	assert.Equal(t, []Requirement{
+		{Key: "env", Operator: OpEquals, Values: []string{"prod"}},
+		{Key: "region", Operator: OpIn, Values: []string{"eu", "us"}},
+		{Key: "tier", Operator: OpNotEquals, Values: []string{"batch"}},
+		{Key: "team", Operator: OpNotIn, Values: []string{"a"}},
+		{Key: "gpu", Operator: OpExists},
+		{Key: "canary", Operator: OpDoesNotExist},
+	}, selector.Requirements)
+	assert.Equal(t, "env=prod,region in (eu,us),tier!=batch,team notin (a),gpu,!canary", selector.String())
+
+	empty, err := Parse("  ")
+	assert.NoError(t, err)
+	assert.True(t, empty.Empty())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, selector := range []string{
+		"env=",
+		"env=prod,,region=eu",
+		"region in (eu,",
+		"region in ()",
+		"=prod",
+		"bad key=prod",
+		"env=a=b",
+	} {
+		_, err := Parse(selector)
+		assert.ErrorIs(t, err, ErrInvalidSelector, selector)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	selector, err := Parse("env=prod,region in (eu,us),!canary")
+	assert.NoError(t, err)
+
+	matched, results := selector.Explain(map[string]string{"env": "prod", "region": "eu"})
+	assert.True(t, matched)
+	assert.Equal(t, []models.RequirementResult{
+		{Requirement: "env=prod", Matched: true, Reason: "agent has env=prod"},
+		{Requirement: "region in (eu,us)", Matched: true, Reason: "agent has region=eu"},
+		{Requirement: "!canary", Matched: true, Reason: "agent has no canary label"},
+	}, results)
+
+	matched, results = selector.Explain(map[string]string{"env": "prod", "region": "ap", "canary": "true"})
+	assert.False(t, matched)
+	assert.False(t, results[1].Matched)
+	assert.Equal(t, "agent has region=ap", results[1].Reason)
+	assert.False(t, results[2].Matched)
+
+	assert.False(t, selector.Matches(map[string]string{}))
+}
+
+func TestEmptySelectorMatchesNothing(t *testing.T) {
+	selector, err := Parse("")
+	assert.NoError(t, err)
+	assert.False(t, selector.Matches(map[string]string{"env": "prod"}))
+}
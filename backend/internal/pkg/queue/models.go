@@ -13,6 +13,7 @@ type AgentStatus struct {
 	RetryRemaining int       `json:"retryRemaining"` // Number of retry attempts left
 	UpdatedAt      time.Time `json:"updatedAt"`      // Timestamp of the last status update
 	NextCheck      time.Time `json:"nextCheck"`      // Timestamp for the next check
+	DisconnectedAt time.Time `json:"disconnectedAt"` // When the agent was last marked disconnected
 }
 
 type AggregatedAgentMetrics struct {
@@ -5,6 +5,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/constants"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
 	io_prometheus_client "github.com/prometheus/client_model/go"
 )
@@ -14,12 +15,14 @@ type AgentQueueInterface interface {
 	AddAgent(id, hostname, ip string) error
 	RemoveAgent(id string) error
 	RefreshMonitoring() error
+	OnReconnect(handler func(agentID string))
 }
 
 type AgentQueueRepositoryInterface interface {
 	RefreshMonitoring() ([]AgentStatus, error)
 	UpdateAgentMetricsInDB(agg AggregatedAgentMetrics, rt RealtimeAgentMetrics) error
 	UpdateAgentStatus(agentID string, status string) error
+	GetAgentMetricsPort(agentID string) (int, error)
 }
 
 // AgentQueue handles agent monitoring and retry logic
@@ -31,17 +34,21 @@ type AgentQueue struct {
 	IntervalSecond  int
 	QueueRepository AgentQueueRepositoryInterface
 	Metrics         MetricsHelper
+	reconnected     func(agentID string)
+	// DisconnectedExpiry is how long a disconnected agent stays queued
+	DisconnectedExpiry time.Duration
 }
 
 // NewQueue creates a new AgentQueue
 func NewQueue(workerCount int, intervalSec int, queueRepository AgentQueueRepositoryInterface) AgentQueueInterface {
 	q := &AgentQueue{
-		agents:          make(map[string]*AgentStatus),
-		checkQueue:      make(chan string, workerCount*2),
-		workerCount:     workerCount,
-		IntervalSecond:  intervalSec,
-		QueueRepository: queueRepository,
-		Metrics:         DefaultMetricsHelper{},
+		agents:             make(map[string]*AgentStatus),
+		checkQueue:         make(chan string, workerCount*2),
+		workerCount:        workerCount,
+		IntervalSecond:     intervalSec,
+		QueueRepository:    queueRepository,
+		Metrics:            DefaultMetricsHelper{},
+		DisconnectedExpiry: time.Duration(constants.DISCONNECTED_EXPIRY_SEC) * time.Second,
 	}
 	q.startWorkers()
 	q.startRetryScheduler()
@@ -50,24 +57,36 @@ func NewQueue(workerCount int, intervalSec int, queueRepository AgentQueueReposi
 
 // AddAgent adds a new agent to the queue
 func (q *AgentQueue) AddAgent(id, hostname, ip string) error {
-	q.mutex.Lock()
-	defer q.mutex.Unlock()
-	if _, exists := q.agents[id]; exists {
-		utils.Logger.Error(fmt.Sprintf("Agent with ID: %s already being monitored.", id))
-		return fmt.Errorf("agent with ID: %s already being monitored", id)
-	}
-	q.agents[id] = &AgentStatus{
+	return q.addAgent(AgentStatus{
 		AgentID:        id,
 		Hostname:       hostname,
 		IP:             ip,
 		CurrentStatus:  "unknown",
 		RetryRemaining: 3,
-		NextCheck:      time.Now(), // eligible immediately
+	})
+}
+
+func (q *AgentQueue) addAgent(agent AgentStatus) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if _, exists := q.agents[agent.AgentID]; exists {
+		utils.Logger.Error(fmt.Sprintf("Agent with ID: %s already being monitored.", agent.AgentID))
+		return fmt.Errorf("agent with ID: %s already being monitored", agent.AgentID)
 	}
-	utils.Logger.Info(fmt.Sprintf("Successfully queued agent with ID: %s.", id))
+	agent.NextCheck = time.Now() // eligible immediately
+	q.agents[agent.AgentID] = &agent
+	utils.Logger.Info(fmt.Sprintf("Successfully queued agent with ID: %s.", agent.AgentID))
 	return nil
 }
 
+// OnReconnect sets a handler called, in its own goroutine, whenever a
+// disconnected agent answers a status check again.
+func (q *AgentQueue) OnReconnect(handler func(agentID string)) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.reconnected = handler
+}
+
 // RemoveAgent removes an agent from the queue
 func (q *AgentQueue) RemoveAgent(id string) error {
 	q.mutex.Lock()
@@ -77,7 +96,8 @@ func (q *AgentQueue) RemoveAgent(id string) error {
 	return nil
 }
 
-// RefreshMonitoring re-queues all existing agents (used at boot)
+// RefreshMonitoring re-queues all existing agents (used at boot), keeping
+// their last known status
 func (q *AgentQueue) RefreshMonitoring() error {
 	agents, err := q.QueueRepository.RefreshMonitoring()
 	if err != nil {
@@ -86,7 +106,12 @@ func (q *AgentQueue) RefreshMonitoring() error {
 	}
 
 	for _, agent := range agents {
-		if err := q.AddAgent(agent.AgentID, agent.Hostname, agent.IP); err != nil {
+		if agent.CurrentStatus == "disconnected" {
+			// When it disconnected isn't stored, so it gets a full expiry
+			agent.RetryRemaining = 0
+			agent.DisconnectedAt = time.Now()
+		}
+		if err := q.addAgent(agent); err != nil {
 			utils.Logger.Sugar().Errorf("Error adding agent [ID: %v] to queue", agent.AgentID)
 		}
 	}
@@ -126,7 +151,9 @@ func (q *AgentQueue) startRetryScheduler() {
 	}()
 }
 
-// Worker loop
+// Worker loop. Agents that keep failing their checks are marked disconnected
+// but stay queued, so the queue notices when they come back, until they've
+// been disconnected for DisconnectedExpiry.
 func (q *AgentQueue) worker() {
 	for agentID := range q.checkQueue {
 		q.mutex.RLock()
@@ -139,41 +166,62 @@ func (q *AgentQueue) worker() {
 
 		if err := q.checkAgentStatus(agent); err != nil {
 			q.mutex.Lock()
-			agent.RetryRemaining--
+			if agent.RetryRemaining > 0 {
+				agent.RetryRemaining--
+			}
 			if agent.RetryRemaining <= 0 {
+				if agent.CurrentStatus != "disconnected" {
+					agent.DisconnectedAt = time.Now()
+				}
 				agent.CurrentStatus = "disconnected"
 			} else {
 				agent.CurrentStatus = "unknown"
 			}
 			_ = q.QueueRepository.UpdateAgentStatus(agent.AgentID, agent.CurrentStatus)
+			expired := agent.CurrentStatus == "disconnected" && time.Since(agent.DisconnectedAt) >= q.DisconnectedExpiry
+			if expired {
+				delete(q.agents, agent.AgentID)
+			}
 			q.mutex.Unlock()
 
 			utils.Logger.Sugar().Errorf("Error checking status of agent [ID:%s], Attempts remaining: %v", agent.AgentID, agent.RetryRemaining)
+			if expired {
+				utils.Logger.Sugar().Warnf("Agent [ID:%s] disconnected for over %s, removed from the queue until it registers again", agent.AgentID, q.DisconnectedExpiry)
+			}
 		} else {
 			q.mutex.Lock()
+			reconnected := agent.CurrentStatus == "disconnected"
 			agent.RetryRemaining = 3
 			agent.CurrentStatus = "connected"
 			_ = q.QueueRepository.UpdateAgentStatus(agent.AgentID, "connected")
+			handler := q.reconnected
 			q.mutex.Unlock()
-		}
 
-		if agent.RetryRemaining <= 0 {
-			_ = q.RemoveAgent(agentID)
+			if reconnected {
+				utils.Logger.Sugar().Infof("Agent [ID:%s] reconnected", agent.AgentID)
+				if handler != nil {
+					go handler(agent.AgentID)
+				}
+			}
 		}
 	}
 }
 
 // checkAgentStatus fetches Prometheus metrics from agent
 func (q *AgentQueue) checkAgentStatus(agent *AgentStatus) error {
+	// The metrics port comes from the telemetry settings of the agent's pipeline
+	port, err := q.QueueRepository.GetAgentMetricsPort(agent.AgentID)
+	if err != nil {
+		utils.Logger.Sugar().Warnf("Failed to get metrics port for agent [ID:%s], using default: %v", agent.AgentID, err)
+		port = constants.DefaultTelemetryMetricsPort
+	}
+
 	endpoints := []string{
-		fmt.Sprintf("http://%s:8888/metrics", agent.Hostname),
-		fmt.Sprintf("http://%s:8888/metrics", agent.IP),
+		fmt.Sprintf("http://%s:%d/metrics", agent.Hostname, port),
+		fmt.Sprintf("http://%s:%d/metrics", agent.IP, port),
 	}
 
-	var (
-		metrics map[string]*io_prometheus_client.MetricFamily
-		err     error
-	)
+	var metrics map[string]*io_prometheus_client.MetricFamily
 	// try hostname first, then IP
 	for _, url := range endpoints {
 		metrics, err = q.Metrics.Fetch(url)
@@ -29,6 +29,10 @@ func (r *mockRepo) UpdateAgentStatus(agentID string, status string) error {
 	return nil
 }
 
+func (r *mockRepo) GetAgentMetricsPort(agentID string) (int, error) {
+	return 8888, nil
+}
+
 func (r *mockRepo) UpdateAgentMetricsInDB(_ queue.AggregatedAgentMetrics, _ queue.RealtimeAgentMetrics) error {
 	return nil
 }
@@ -50,9 +54,15 @@ func (m *mockMetricsHelper) ExtractValue(map[string]*io_prometheus_client.Metric
 	return 0
 }
 
+func (r *mockRepo) statuses() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.statusLog...)
+}
+
 // --- Test Case ---
 
-func TestAgentQueue_RetryScheduler_RemovesAfterFailures(t *testing.T) {
+func TestAgentQueue_RetryScheduler_MarksDisconnectedAfterFailures(t *testing.T) {
 	repo := &mockRepo{}
 	helper := &mockMetricsHelper{
 		fetchErrCount: 999, // always fail
@@ -66,28 +76,61 @@ func TestAgentQueue_RetryScheduler_RemovesAfterFailures(t *testing.T) {
 	err := q.AddAgent("agent-1", "agent1.test.local", "192.168.1.100")
 	assert.NoError(t, err)
 
-	// Poll for agent removal within timeout
-	timeout := time.After(6 * time.Second)
-	tick := time.Tick(200 * time.Millisecond)
-
-REMOVAL_WAIT:
-	for {
-		select {
-		case <-timeout:
-			t.Fatal("Agent was not removed within timeout")
-		case <-tick:
-			_, exists := q.GetAgent("agent-1")
-			if !exists {
-				break REMOVAL_WAIT
-			}
-		}
+	// Poll for the agent to be marked disconnected
+	assert.Eventually(t, func() bool {
+		return len(repo.statuses()) >= 3
+	}, 6*time.Second, 200*time.Millisecond, "Agent was not marked disconnected within timeout")
+
+	assert.Equal(t, []string{"unknown", "unknown", "disconnected"}, repo.statuses()[:3], "Unexpected agent status sequence")
+
+	// Disconnected agents stay queued so a reconnect is noticed
+	_, exists := q.GetAgent("agent-1")
+	assert.True(t, exists)
+}
+
+func TestAgentQueue_OnReconnect(t *testing.T) {
+	repo := &mockRepo{}
+	helper := &mockMetricsHelper{
+		fetchErrCount: 6, // three checks, hostname and IP each
 	}
 
-	// Safely copy log without deadlocking
-	repo.mu.Lock()
-	statuses := make([]string, len(repo.statusLog))
-	copy(statuses, repo.statusLog)
-	repo.mu.Unlock()
+	q := queue.NewQueue(1, 1, repo).(*queue.AgentQueue)
+	q.Metrics = helper
+
+	reconnected := make(chan string, 1)
+	q.OnReconnect(func(agentID string) { reconnected <- agentID })
+
+	err := q.AddAgent("agent-1", "agent1.test.local", "192.168.1.100")
+	assert.NoError(t, err)
+
+	select {
+	case id := <-reconnected:
+		assert.Equal(t, "agent-1", id)
+	case <-time.After(8 * time.Second):
+		t.Fatal("Reconnect handler was not called within timeout")
+	}
+	assert.Equal(t, []string{"unknown", "unknown", "disconnected", "connected"}, repo.statuses()[:4])
+}
+
+func TestAgentQueue_DropsExpiredDisconnectedAgent(t *testing.T) {
+	repo := &mockRepo{}
+	helper := &mockMetricsHelper{
+		fetchErrCount: 999, // always fail
+	}
+
+	q := queue.NewQueue(1, 1, repo).(*queue.AgentQueue)
+	q.Metrics = helper
+	q.DisconnectedExpiry = time.Second
+
+	err := q.AddAgent("agent-1", "agent1.test.local", "192.168.1.100")
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		_, exists := q.GetAgent("agent-1")
+		return !exists
+	}, 8*time.Second, 200*time.Millisecond, "Disconnected agent was not dropped after the expiry")
+	assert.Equal(t, []string{"unknown", "unknown", "disconnected"}, repo.statuses()[:3])
 
-	assert.Equal(t, []string{"unknown", "unknown", "disconnected"}, statuses, "Unexpected agent status sequence")
+	// A registering agent is queued again
+	assert.NoError(t, q.AddAgent("agent-1", "agent1.test.local", "192.168.1.100"))
 }
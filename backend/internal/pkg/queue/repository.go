@@ -2,9 +2,12 @@ package queue
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/constants"
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/utils"
 )
 
@@ -70,7 +73,7 @@ func (q *QueueRepository) RefreshMonitoring() ([]AgentStatus, error) {
 		SELECT a.id, a.hostname, a.ip, m.status
 		FROM agents a
 		JOIN aggregated_agent_metrics m ON a.id = m.agent_id
-		WHERE m.status IN ('unknown', 'connected')
+		WHERE m.status IN ('unknown', 'connected', 'disconnected')
 	`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query agents from DB: %w", err)
@@ -97,3 +100,34 @@ func (q *QueueRepository) RefreshMonitoring() ([]AgentStatus, error) {
 
 	return agents, nil
 }
+
+// GetAgentMetricsPort returns the port the agent's collector exposes its own
+// metrics on, as configured in the telemetry settings of its pipeline.
+func (q *QueueRepository) GetAgentMetricsPort(agentID string) (int, error) {
+	var telemetryJSON sql.NullString
+	err := q.db.QueryRow(`
+		SELECT p.telemetry_json
+		FROM agents a
+		JOIN pipelines p ON p.pipeline_id = a.pipeline_id
+		WHERE a.id = ?
+	`, agentID).Scan(&telemetryJSON)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// Unassigned agents run the default config
+			return constants.DefaultTelemetryMetricsPort, nil
+		}
+		return 0, fmt.Errorf("failed to query telemetry settings: %w", err)
+	}
+	if !telemetryJSON.Valid || telemetryJSON.String == "" {
+		return constants.DefaultTelemetryMetricsPort, nil
+	}
+
+	var telemetry models.PipelineTelemetry
+	if err := json.Unmarshal([]byte(telemetryJSON.String), &telemetry); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal telemetry settings: %w", err)
+	}
+	if telemetry.Metrics.Port == 0 {
+		return constants.DefaultTelemetryMetricsPort, nil
+	}
+	return telemetry.Metrics.Port, nil
+}
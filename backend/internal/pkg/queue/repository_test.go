@@ -39,7 +39,12 @@ func setupTestDB(t *testing.T) *sql.DB {
 		CREATE TABLE agents (
 			id TEXT PRIMARY KEY,
 			hostname TEXT,
-			ip TEXT
+			ip TEXT,
+			pipeline_id INTEGER
+		);
+		CREATE TABLE pipelines (
+			pipeline_id INTEGER PRIMARY KEY,
+			telemetry_json TEXT
 		);
 	`)
 	assert.NoError(t, err)
@@ -114,3 +119,27 @@ func TestRefreshMonitoring(t *testing.T) {
 	assert.Equal(t, "agent-3", agents[0].AgentID)
 	assert.Equal(t, "connected", agents[0].CurrentStatus)
 }
+
+func TestGetAgentMetricsPort(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewQueueRepository(db)
+
+	_, err := db.Exec(`INSERT INTO pipelines (pipeline_id, telemetry_json) VALUES (1, '{"metrics":{"level":"basic","port":9464}}'), (2, NULL)`)
+	assert.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO agents (id, hostname, ip, pipeline_id) VALUES ('a1', 'h1', '10.0.0.1', 1), ('a2', 'h2', '10.0.0.2', 2), ('a3', 'h3', '10.0.0.3', NULL)`)
+	assert.NoError(t, err)
+
+	port, err := repo.GetAgentMetricsPort("a1")
+	assert.NoError(t, err)
+	assert.Equal(t, 9464, port)
+
+	// Pipeline without telemetry settings
+	port, err = repo.GetAgentMetricsPort("a2")
+	assert.NoError(t, err)
+	assert.Equal(t, 8888, port)
+
+	// Agent not attached to any pipeline
+	port, err = repo.GetAgentMetricsPort("a3")
+	assert.NoError(t, err)
+	assert.Equal(t, 8888, port)
+}
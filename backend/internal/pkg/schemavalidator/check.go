@@ -0,0 +1,119 @@
+package schemavalidator
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+)
+
+var schemaTypeNames = map[string]bool{
+	"object": true, "array": true, "string": true, "boolean": true,
+	"number": true, "integer": true, "null": true,
+}
+
+// CheckSchema checks that a JSON Schema is well-formed for the subset
+// Validate supports, so uploaded schemas can't silently accept anything. The
+// errors point into the schema document.
+func CheckSchema(schema map[string]any) []models.FieldError {
+	v := &validator{}
+	v.checkSchema(schema, "")
+	return v.errors
+}
+
+func (v *validator) checkSchema(schema map[string]any, pointer string) {
+	switch t := schema["type"].(type) {
+	case nil:
+	case string:
+		if !schemaTypeNames[t] {
+			v.fail(pointer+"/type", "unknown type %q", t)
+		}
+	case []any:
+		for i, item := range t {
+			if name, ok := item.(string); !ok || !schemaTypeNames[name] {
+				v.fail(fmt.Sprintf("%s/type/%d", pointer, i), "unknown type %s", formatValue(item))
+			}
+		}
+	default:
+		v.fail(pointer+"/type", "must be a string or an array of strings")
+	}
+
+	for _, keyword := range []string{"title", "description"} {
+		if value, ok := schema[keyword]; ok {
+			if _, isString := value.(string); !isString {
+				v.fail(pointer+"/"+keyword, "must be a string")
+			}
+		}
+	}
+	if value, ok := schema["pattern"]; ok {
+		if pattern, isString := value.(string); !isString {
+			v.fail(pointer+"/pattern", "must be a string")
+		} else if _, err := regexp.Compile(pattern); err != nil {
+			v.fail(pointer+"/pattern", "invalid pattern: %v", err)
+		}
+	}
+	if value, ok := schema["enum"]; ok {
+		if enum, isArray := value.([]any); !isArray || len(enum) == 0 {
+			v.fail(pointer+"/enum", "must be a non-empty array")
+		}
+	}
+	for _, keyword := range []string{"minItems", "maxItems", "minLength", "maxLength"} {
+		if value, ok := schema[keyword]; ok {
+			if n, isNumber := number(value); !isNumber || n < 0 || n != math.Trunc(n) {
+				v.fail(pointer+"/"+keyword, "must be a non-negative integer")
+			}
+		}
+	}
+	for _, keyword := range []string{"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum"} {
+		if value, ok := schema[keyword]; ok {
+			if _, isNumber := number(value); !isNumber {
+				v.fail(pointer+"/"+keyword, "must be a number")
+			}
+		}
+	}
+
+	if value, ok := schema["properties"]; ok {
+		properties, isObject := value.(map[string]any)
+		if !isObject {
+			v.fail(pointer+"/properties", "must be an object")
+		}
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			v.checkSubschema(properties[name], pointer+"/properties/"+escapePointer(name))
+		}
+	}
+	if value, ok := schema["required"]; ok {
+		required, isArray := value.([]any)
+		if !isArray {
+			v.fail(pointer+"/required", "must be an array of strings")
+		}
+		for i, name := range required {
+			if _, isString := name.(string); !isString {
+				v.fail(fmt.Sprintf("%s/required/%d", pointer, i), "must be a string")
+			}
+		}
+	}
+	if value, ok := schema["additionalProperties"]; ok {
+		if _, isBool := value.(bool); !isBool {
+			v.checkSubschema(value, pointer+"/additionalProperties")
+		}
+	}
+	if value, ok := schema["items"]; ok {
+		v.checkSubschema(value, pointer+"/items")
+	}
+}
+
+func (v *validator) checkSubschema(value any, pointer string) {
+	subschema, ok := value.(map[string]any)
+	if !ok {
+		v.fail(pointer, "must be a schema object")
+		return
+	}
+	v.checkSchema(subschema, pointer)
+}
@@ -0,0 +1,302 @@
+package schemavalidator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+)
+
+// Validate checks a decoded JSON document against a JSON Schema and returns
+// one FieldError per violation, in document order. It supports the subset of
+// JSON Schema used by the component catalog: type, enum, const, properties,
+// required, additionalProperties, items, min/maxItems, min/maxLength,
+// pattern and numeric bounds.
+func Validate(schema map[string]any, document any) []models.FieldError {
+	v := &validator{}
+	v.validate(schema, document, "")
+	return v.errors
+}
+
+// ValidateJSON is Validate for a schema still in its raw JSON form.
+func ValidateJSON(rawSchema []byte, document any) ([]models.FieldError, error) {
+	var schema map[string]any
+	if err := json.Unmarshal(rawSchema, &schema); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	return Validate(schema, document), nil
+}
+
+type validator struct {
+	errors []models.FieldError
+}
+
+func (v *validator) fail(pointer, format string, args ...any) {
+	v.errors = append(v.errors, models.FieldError{
+		Pointer: pointer,
+		Reason:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) validate(schema map[string]any, value any, pointer string) {
+	if schema == nil {
+		return
+	}
+
+	if types := schemaTypes(schema); len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			if matchesType(t, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(pointer, "expected %s, got %s", strings.Join(types, " or "), typeName(value))
+			// Further keywords would only repeat the type mismatch
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, allowed := range enum {
+			if jsonEqual(allowed, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(pointer, "value %s is not one of %s", formatValue(value), formatEnum(enum))
+		}
+	}
+
+	if constant, ok := schema["const"]; ok && !jsonEqual(constant, value) {
+		v.fail(pointer, "value must be %s", formatValue(constant))
+	}
+
+	switch typed := value.(type) {
+	case map[string]any:
+		v.validateObject(schema, typed, pointer)
+	case []any:
+		v.validateArray(schema, typed, pointer)
+	case string:
+		v.validateString(schema, typed, pointer)
+	default:
+		if n, ok := number(value); ok {
+			v.validateNumber(schema, n, pointer)
+		}
+	}
+}
+
+func (v *validator) validateObject(schema map[string]any, object map[string]any, pointer string) {
+	properties, _ := schema["properties"].(map[string]any)
+
+	if required, ok := schema["required"].([]any); ok {
+		for _, r := range required {
+			name, ok := r.(string)
+			if !ok {
+				continue
+			}
+			if _, present := object[name]; !present {
+				v.fail(pointer+"/"+escapePointer(name), "required property is missing")
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		childPointer := pointer + "/" + escapePointer(key)
+		if propertySchema, ok := properties[key].(map[string]any); ok {
+			v.validate(propertySchema, object[key], childPointer)
+			continue
+		}
+
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.fail(childPointer, "property is not allowed")
+			}
+		case map[string]any:
+			v.validate(additional, object[key], childPointer)
+		}
+	}
+}
+
+func (v *validator) validateArray(schema map[string]any, array []any, pointer string) {
+	if minItems, ok := number(schema["minItems"]); ok && float64(len(array)) < minItems {
+		v.fail(pointer, "must contain at least %v item(s)", minItems)
+	}
+	if maxItems, ok := number(schema["maxItems"]); ok && float64(len(array)) > maxItems {
+		v.fail(pointer, "must contain at most %v item(s)", maxItems)
+	}
+
+	if itemSchema, ok := schema["items"].(map[string]any); ok {
+		for i, item := range array {
+			v.validate(itemSchema, item, fmt.Sprintf("%s/%d", pointer, i))
+		}
+	}
+}
+
+func (v *validator) validateString(schema map[string]any, value, pointer string) {
+	length := float64(len([]rune(value)))
+	if minLength, ok := number(schema["minLength"]); ok && length < minLength {
+		if minLength == 1 {
+			v.fail(pointer, "must not be empty")
+		} else {
+			v.fail(pointer, "must be at least %v character(s) long", minLength)
+		}
+	}
+	if maxLength, ok := number(schema["maxLength"]); ok && length > maxLength {
+		v.fail(pointer, "must be at most %v character(s) long", maxLength)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err == nil && !re.MatchString(value) {
+			v.fail(pointer, "does not match pattern %q", pattern)
+		}
+	}
+}
+
+func (v *validator) validateNumber(schema map[string]any, value float64, pointer string) {
+	if minimum, ok := number(schema["minimum"]); ok && value < minimum {
+		v.fail(pointer, "must be >= %v", minimum)
+	}
+	if maximum, ok := number(schema["maximum"]); ok && value > maximum {
+		v.fail(pointer, "must be <= %v", maximum)
+	}
+	if exclusiveMinimum, ok := number(schema["exclusiveMinimum"]); ok && value <= exclusiveMinimum {
+		v.fail(pointer, "must be > %v", exclusiveMinimum)
+	}
+	if exclusiveMaximum, ok := number(schema["exclusiveMaximum"]); ok && value >= exclusiveMaximum {
+		v.fail(pointer, "must be < %v", exclusiveMaximum)
+	}
+}
+
+func schemaTypes(schema map[string]any) []string {
+	switch t := schema["type"].(type) {
+	case string:
+		return []string{t}
+	case []any:
+		var types []string
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func matchesType(schemaType string, value any) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := number(value)
+		return ok
+	case "integer":
+		n, ok := number(value)
+		return ok && n == math.Trunc(n)
+	case "null":
+		return value == nil
+	}
+	// Unknown types are not ours to reject
+	return true
+}
+
+func typeName(value any) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if typed == math.Trunc(typed) {
+			return "integer"
+		}
+		return "number"
+	}
+	if _, ok := number(value); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// number accepts float64 (what encoding/json produces) as well as Go integer
+// types, so configs built in code validate the same as decoded ones.
+func number(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func jsonEqual(a, b any) bool {
+	if na, ok := number(a); ok {
+		nb, ok := number(b)
+		return ok && na == nb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func formatValue(value any) string {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(bytes)
+}
+
+func formatEnum(enum []any) string {
+	values := make([]string, len(enum))
+	for i, e := range enum {
+		values[i] = formatValue(e)
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+// escapePointer escapes a property name for use as a JSON pointer token (RFC 6901).
+func escapePointer(token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	return strings.ReplaceAll(token, "/", "~1")
+}
@@ -0,0 +1,124 @@
+package schemavalidator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const receiverSchema = `{
+	"type": "object",
+	"properties": {
+		"protocols": {
+			"type": "object",
+			"properties": {
+				"grpc": {
+					"type": "object",
+					"properties": {
+						"endpoint": {"type": "string", "minLength": 1}
+					},
+					"required": ["endpoint"]
+				}
+			}
+		},
+		"verbosity": {"type": "string", "enum": ["basic", "normal", "detailed"]},
+		"send_batch_size": {"type": "integer", "minimum": 0},
+		"brokers": {"type": "array", "items": {"type": "string", "minLength": 1}},
+		"strict": {
+			"type": "object",
+			"properties": {"a/b": {"type": "boolean"}},
+			"additionalProperties": false
+		}
+	},
+	"required": ["protocols"]
+}`
+
+func decode(t *testing.T, raw string) any {
+	var doc any
+	assert.NoError(t, json.Unmarshal([]byte(raw), &doc))
+	return doc
+}
+
+func TestValidateJSON_Valid(t *testing.T) {
+	doc := decode(t, `{"protocols": {"grpc": {"endpoint": "0.0.0.0:4317"}}, "verbosity": "basic", "send_batch_size": 10, "brokers": ["kafka:9092"]}`)
+
+	errs, err := ValidateJSON([]byte(receiverSchema), doc)
+	assert.NoError(t, err)
+	assert.Empty(t, errs)
+}
+
+func TestValidateJSON_ReportsEveryField(t *testing.T) {
+	doc := decode(t, `{
+		"protocols": {"grpc": {}},
+		"verbosity": "loud",
+		"send_batch_size": 1.5,
+		"brokers": ["kafka:9092", ""],
+		"strict": {"a/b": true, "extra": 1}
+	}`)
+
+	errs, err := ValidateJSON([]byte(receiverSchema), doc)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.FieldError{
+		{Pointer: "/brokers/1", Reason: "must not be empty"},
+		{Pointer: "/protocols/grpc/endpoint", Reason: "required property is missing"},
+		{Pointer: "/send_batch_size", Reason: "expected integer, got number"},
+		{Pointer: "/strict/extra", Reason: "property is not allowed"},
+		{Pointer: "/verbosity", Reason: `value "loud" is not one of ["basic", "normal", "detailed"]`},
+	}, errs)
+}
+
+func TestValidateJSON_MissingRequiredAtRoot(t *testing.T) {
+	errs, err := ValidateJSON([]byte(receiverSchema), map[string]any{})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.FieldError{{Pointer: "/protocols", Reason: "required property is missing"}}, errs)
+}
+
+func TestValidate_GoIntegerTypes(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"port": map[string]any{"type": "integer", "maximum": 65535},
+		},
+	}
+
+	assert.Empty(t, Validate(schema, map[string]any{"port": 8888}))
+	assert.Equal(t, []models.FieldError{{Pointer: "/port", Reason: "must be <= 65535"}}, Validate(schema, map[string]any{"port": 70000}))
+}
+
+func TestValidateJSON_InvalidSchema(t *testing.T) {
+	_, err := ValidateJSON([]byte(`{not json`), map[string]any{})
+	assert.Error(t, err)
+}
+
+func TestCheckSchema(t *testing.T) {
+	var schema map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(receiverSchema), &schema))
+	assert.Empty(t, CheckSchema(schema))
+
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"title": 3,
+		"properties": {
+			"endpoint": {"type": "text", "pattern": "("},
+			"brokers": {"type": "array", "items": "string", "minItems": -1},
+			"port": {"type": ["integer", "port"], "maximum": "65535"},
+			"level": {"enum": []}
+		},
+		"required": ["endpoint", 1],
+		"additionalProperties": {"type": "strings"}
+	}`), &schema))
+	assert.Equal(t, []models.FieldError{
+		{Pointer: "/title", Reason: "must be a string"},
+		{Pointer: "/properties/brokers/minItems", Reason: "must be a non-negative integer"},
+		{Pointer: "/properties/brokers/items", Reason: "must be a schema object"},
+		{Pointer: "/properties/endpoint/type", Reason: `unknown type "text"`},
+		{Pointer: "/properties/endpoint/pattern", Reason: "invalid pattern: error parsing regexp: missing closing ): `(`"},
+		{Pointer: "/properties/level/enum", Reason: "must be a non-empty array"},
+		{Pointer: "/properties/port/type/1", Reason: `unknown type "port"`},
+		{Pointer: "/properties/port/maximum", Reason: "must be a number"},
+		{Pointer: "/required/1", Reason: "must be a string"},
+		{Pointer: "/additionalProperties/type", Reason: `unknown type "strings"`},
+	}, CheckSchema(schema))
+}
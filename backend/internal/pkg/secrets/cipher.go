@@ -0,0 +1,63 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidMasterKey is returned by NewCipher when the master key isn't a
+// base64-encoded 32-byte key.
+var ErrInvalidMasterKey = errors.New("master key must be 32 bytes, base64-encoded")
+
+// Cipher encrypts secret values with AES-256-GCM. Each value gets a random
+// nonce, stored in front of the ciphertext.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher builds a Cipher from a base64-encoded 32-byte master key, e.g.
+// the output of `openssl rand -base64 32`.
+func NewCipher(masterKey string) (*Cipher, error) {
+	key, err := base64.StdEncoding.DecodeString(masterKey)
+	if err != nil || len(key) != 32 {
+		return nil, ErrInvalidMasterKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// Encrypt seals plaintext, binding it to name so a ciphertext can't be
+// swapped onto another secret.
+func (c *Cipher) Encrypt(name string, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, []byte(name)), nil
+}
+
+// Decrypt opens a value produced by Encrypt for the same name.
+func (c *Cipher) Decrypt(name string, data []byte) ([]byte, error) {
+	nonceSize := c.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("ciphertext is too short")
+	}
+	plaintext, err := c.aead.Open(nil, data[:nonceSize], data[nonceSize:], []byte(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret %s: %w", name, err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,39 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testMasterKey = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+
+func TestCipher_RoundTrip(t *testing.T) {
+	c, err := NewCipher(testMasterKey)
+	assert.NoError(t, err)
+
+	sealed, err := c.Encrypt("kafka_password", []byte("hunter2"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(sealed), "hunter2")
+
+	plaintext, err := c.Decrypt("kafka_password", sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", string(plaintext))
+
+	// The ciphertext is bound to the secret's name
+	_, err = c.Decrypt("other", sealed)
+	assert.Error(t, err)
+
+	again, err := c.Encrypt("kafka_password", []byte("hunter2"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, sealed, again)
+}
+
+func TestNewCipher_InvalidKey(t *testing.T) {
+	_, err := NewCipher("not base64!")
+	assert.ErrorIs(t, err, ErrInvalidMasterKey)
+
+	_, err = NewCipher(base64.StdEncoding.EncodeToString([]byte("too short")))
+	assert.ErrorIs(t, err, ErrInvalidMasterKey)
+}
@@ -0,0 +1,344 @@
+package secrets
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+)
+
+// Resolution modes for ${secret:name} references in compiled configs.
+const (
+	// ResolveAtCompile replaces references with the decrypted values before
+	// the config is sent to agents.
+	ResolveAtCompile = "compile"
+	// ResolveOnAgent rewrites references to ${env:CTRLB_SECRET_<NAME>} so the
+	// values never leave the backend; agents read them from their environment.
+	ResolveOnAgent = "env"
+)
+
+// RedactedValue replaces sensitive plaintext in API responses. Sending it back
+// in a graph keeps the stored value.
+const RedactedValue = "<redacted>"
+
+// EnvVarPrefix starts the environment variable names of ResolveOnAgent.
+const EnvVarPrefix = "CTRLB_SECRET_"
+
+var referencePattern = regexp.MustCompile(`\$\{secret:([A-Za-z0-9_.-]+)\}`)
+
+var envReferencePattern = regexp.MustCompile(`\$\{env:` + EnvVarPrefix + `[A-Za-z0-9_]+\}`)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// sensitiveKeys are config keys whose plaintext values get redacted. Keys are
+// compared lowercased with dashes and underscores removed.
+var sensitiveKeys = []string{
+	"password", "secret", "token", "apikey", "accesskey", "privatekey",
+	"authorization", "credentials", "connectionstring",
+}
+
+// Resolver looks up the decrypted value of a secret by name.
+type Resolver interface {
+	ResolveSecret(name string) (string, error)
+}
+
+// ValidName reports whether name can be used in a ${secret:name} reference.
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// EnvVarName is the variable ResolveOnAgent reads the secret from, e.g.
+// kafka.password becomes CTRLB_SECRET_KAFKA_PASSWORD.
+func EnvVarName(name string) string {
+	upper := strings.ToUpper(name)
+	return EnvVarPrefix + strings.NewReplacer(".", "_", "-", "_").Replace(upper)
+}
+
+// References lists the secret names referenced anywhere in the graph's node
+// configs, sorted and without duplicates.
+func References(graph models.PipelineGraph) []string {
+	seen := make(map[string]bool)
+	for _, node := range graph.Nodes {
+		walkStrings(node.Config, func(s string) string {
+			for _, match := range referencePattern.FindAllStringSubmatch(s, -1) {
+				seen[match[1]] = true
+			}
+			return s
+		})
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveGraph returns a copy of the graph with every ${secret:name} reference
+// replaced by its value.
+func ResolveGraph(graph models.PipelineGraph, resolver Resolver) (models.PipelineGraph, error) {
+	names := References(graph)
+	if len(names) == 0 {
+		return graph, nil
+	}
+	if resolver == nil {
+		return models.PipelineGraph{}, fmt.Errorf("cannot resolve secret %s: the secrets store is disabled", names[0])
+	}
+
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		value, err := resolver.ResolveSecret(name)
+		if err != nil {
+			return models.PipelineGraph{}, fmt.Errorf("cannot resolve secret %s: %w", name, err)
+		}
+		values[name] = value
+	}
+
+	return mapNodeConfigs(graph, func(config map[string]any) map[string]any {
+		return walkStrings(config, func(s string) string {
+			return referencePattern.ReplaceAllStringFunc(s, func(ref string) string {
+				return values[referencePattern.FindStringSubmatch(ref)[1]]
+			})
+		}).(map[string]any)
+	}), nil
+}
+
+// EnvGraph returns a copy of the graph with every ${secret:name} reference
+// rewritten to the ${env:...} reference of ResolveOnAgent.
+func EnvGraph(graph models.PipelineGraph) models.PipelineGraph {
+	return mapNodeConfigs(graph, func(config map[string]any) map[string]any {
+		return walkStrings(config, func(s string) string {
+			return referencePattern.ReplaceAllStringFunc(s, func(ref string) string {
+				return "${env:" + EnvVarName(referencePattern.FindStringSubmatch(ref)[1]) + "}"
+			})
+		}).(map[string]any)
+	})
+}
+
+// UnresolveGraph reverses ResolveGraph and EnvGraph for the named secrets: it
+// returns a copy of the graph with their ${env:...} references, and their
+// values where known, turned back into ${secret:name} references. Values are
+// replaced where they make up a whole string, or inside strings under
+// sensitive keys, such as "Bearer <token>".
+func UnresolveGraph(graph models.PipelineGraph, values map[string]string) models.PipelineGraph {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	// Longer values first, so a value inside another doesn't split it
+	sort.Slice(names, func(i, j int) bool {
+		if len(values[names[i]]) != len(values[names[j]]) {
+			return len(values[names[i]]) > len(values[names[j]])
+		}
+		return names[i] < names[j]
+	})
+
+	unresolveString := func(s string, sensitive bool) string {
+		for _, name := range names {
+			reference := "${secret:" + name + "}"
+			s = strings.ReplaceAll(s, "${env:"+EnvVarName(name)+"}", reference)
+			switch value := values[name]; {
+			case value == "":
+			case s == value:
+				s = reference
+			case sensitive:
+				s = strings.ReplaceAll(s, value, reference)
+			}
+		}
+		return s
+	}
+	return mapNodeConfigs(graph, func(config map[string]any) map[string]any {
+		return unresolve(config, false, unresolveString).(map[string]any)
+	})
+}
+
+// EnvReferences lists the ${env:...} references of ResolveOnAgent left in the
+// config, such as those of secrets UnresolveGraph didn't know.
+func EnvReferences(config map[string]any) []string {
+	var references []string
+	walkStrings(config, func(s string) string {
+		references = append(references, envReferencePattern.FindAllString(s, -1)...)
+		return s
+	})
+	sort.Strings(references)
+	return references
+}
+
+// SensitivePlaintext lists the plaintext values of sensitive keys in the
+// config, the values RedactConfig hides.
+func SensitivePlaintext(config map[string]any) []string {
+	var values []string
+	unresolve(config, false, func(s string, sensitive bool) string {
+		if sensitive && s != "" && !referenceInValue.MatchString(s) {
+			values = append(values, s)
+		}
+		return s
+	})
+	sort.Strings(values)
+	return values
+}
+
+// RedactGraph returns a copy of the graph with plaintext values of sensitive
+// keys replaced by RedactedValue. Values holding ${...} references, such as
+// "Bearer ${secret:token}", are left alone since the secret isn't inline.
+func RedactGraph(graph models.PipelineGraph) models.PipelineGraph {
+	return mapNodeConfigs(graph, func(config map[string]any) map[string]any {
+		return RedactConfig(config)
+	})
+}
+
+// RedactConfig is RedactGraph for a single config tree, such as a compiled
+// collector config.
+func RedactConfig(config map[string]any) map[string]any {
+	return redact(config, false).(map[string]any)
+}
+
+// RestoreRedacted puts stored values back wherever the incoming graph still
+// holds RedactedValue, so a graph fetched from the API can be saved unchanged.
+// Nodes are matched on component name and node name.
+func RestoreRedacted(incoming, stored models.PipelineGraph) models.PipelineGraph {
+	storedConfigs := make(map[string]map[string]any, len(stored.Nodes))
+	for _, node := range stored.Nodes {
+		storedConfigs[node.ComponentName+"/"+node.Name] = node.Config
+	}
+
+	restored := incoming
+	restored.Nodes = make([]models.PipelineNodes, len(incoming.Nodes))
+	for i, node := range incoming.Nodes {
+		if storedConfig, ok := storedConfigs[node.ComponentName+"/"+node.Name]; ok && node.Config != nil {
+			node.Config = restore(node.Config, storedConfig).(map[string]any)
+		}
+		restored.Nodes[i] = node
+	}
+	return restored
+}
+
+func mapNodeConfigs(graph models.PipelineGraph, fn func(map[string]any) map[string]any) models.PipelineGraph {
+	mapped := graph
+	mapped.Nodes = make([]models.PipelineNodes, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		if node.Config != nil {
+			node.Config = fn(node.Config)
+		}
+		mapped.Nodes[i] = node
+	}
+	return mapped
+}
+
+// walkStrings copies value, passing every string through fn.
+func walkStrings(value any, fn func(string) string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[key] = walkStrings(item, fn)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = walkStrings(item, fn)
+		}
+		return out
+	case string:
+		return fn(v)
+	default:
+		return v
+	}
+}
+
+// redact copies value; sensitive is set below a sensitive key so nested
+// values such as header maps are redacted too.
+func redact(value any, sensitive bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[key] = redact(item, sensitive || isSensitiveKey(key))
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = redact(item, sensitive)
+		}
+		return out
+	case string:
+		if sensitive && v != "" && !referenceInValue.MatchString(v) {
+			return RedactedValue
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// unresolve copies value like walkStrings, telling fn whether a string is
+// under a sensitive key.
+func unresolve(value any, sensitive bool, fn func(s string, sensitive bool) string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[key] = unresolve(item, sensitive || isSensitiveKey(key), fn)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = unresolve(item, sensitive, fn)
+		}
+		return out
+	case string:
+		return fn(v, sensitive)
+	default:
+		return v
+	}
+}
+
+func restore(incoming, stored any) any {
+	switch v := incoming.(type) {
+	case map[string]any:
+		storedMap, _ := stored.(map[string]any)
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[key] = restore(item, storedMap[key])
+		}
+		return out
+	case []any:
+		storedSlice, _ := stored.([]any)
+		out := make([]any, len(v))
+		for i, item := range v {
+			var storedItem any
+			if i < len(storedSlice) {
+				storedItem = storedSlice[i]
+			}
+			out[i] = restore(item, storedItem)
+		}
+		return out
+	case string:
+		if v == RedactedValue && stored != nil {
+			return stored
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	normalized := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(normalized, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// referenceInValue matches the references the collector or the backend
+// resolves, e.g. ${env:KAFKA_PASSWORD} or ${secret:kafka}.
+var referenceInValue = regexp.MustCompile(`\$\{[a-z]+:[^}]+\}`)
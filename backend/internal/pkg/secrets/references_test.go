@@ -0,0 +1,122 @@
+package secrets
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type mapResolver map[string]string
+
+func (m mapResolver) ResolveSecret(name string) (string, error) {
+	value, ok := m[name]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return value, nil
+}
+
+func kafkaGraph() models.PipelineGraph {
+	return models.PipelineGraph{
+		Nodes: []models.PipelineNodes{
+			{ComponentID: 1, Name: "Kafka", ComponentName: "kafka_exporter", Config: map[string]any{
+				"brokers": []any{"kafka:9092"},
+				"auth": map[string]any{
+					"sasl": map[string]any{
+						"username": "ingest",
+						"password": "${secret:kafka.password}",
+					},
+				},
+			}},
+			{ComponentID: 2, Name: "OTLP HTTP", ComponentName: "otlphttp_exporter", Config: map[string]any{
+				"endpoint": "https://ingest.example.com",
+				"headers":  map[string]any{"Authorization": "Bearer ${secret:ingest-token}", "X-Scope": "team-a"},
+			}},
+		},
+	}
+}
+
+func TestReferences(t *testing.T) {
+	assert.Equal(t, []string{"ingest-token", "kafka.password"}, References(kafkaGraph()))
+}
+
+func TestResolveGraph(t *testing.T) {
+	graph := kafkaGraph()
+	resolved, err := ResolveGraph(graph, mapResolver{"kafka.password": "hunter2", "ingest-token": "abc"})
+	assert.NoError(t, err)
+
+	sasl := resolved.Nodes[0].Config["auth"].(map[string]any)["sasl"].(map[string]any)
+	assert.Equal(t, "hunter2", sasl["password"])
+	assert.Equal(t, "Bearer abc", resolved.Nodes[1].Config["headers"].(map[string]any)["Authorization"])
+
+	// The input graph is left untouched
+	original := graph.Nodes[0].Config["auth"].(map[string]any)["sasl"].(map[string]any)
+	assert.Equal(t, "${secret:kafka.password}", original["password"])
+
+	_, err = ResolveGraph(graph, mapResolver{})
+	assert.ErrorContains(t, err, "cannot resolve secret ingest-token")
+
+	_, err = ResolveGraph(graph, nil)
+	assert.ErrorContains(t, err, "disabled")
+}
+
+func TestEnvGraph(t *testing.T) {
+	env := EnvGraph(kafkaGraph())
+
+	sasl := env.Nodes[0].Config["auth"].(map[string]any)["sasl"].(map[string]any)
+	assert.Equal(t, "${env:CTRLB_SECRET_KAFKA_PASSWORD}", sasl["password"])
+	assert.Equal(t, "Bearer ${env:CTRLB_SECRET_INGEST_TOKEN}", env.Nodes[1].Config["headers"].(map[string]any)["Authorization"])
+}
+
+func TestRedactAndRestore(t *testing.T) {
+	stored := models.PipelineGraph{
+		Nodes: []models.PipelineNodes{
+			{ComponentID: 1, Name: "OTLP HTTP", ComponentName: "otlphttp_exporter", Config: map[string]any{
+				"endpoint": "https://ingest.example.com",
+				"headers":  map[string]any{"x-api-key": "plaintext-key", "Authorization": "Bearer ${secret:token}"},
+			}},
+		},
+	}
+
+	redacted := RedactGraph(stored)
+	headers := redacted.Nodes[0].Config["headers"].(map[string]any)
+	assert.Equal(t, RedactedValue, headers["x-api-key"])
+	assert.Equal(t, "Bearer ${secret:token}", headers["Authorization"])
+	assert.Equal(t, "https://ingest.example.com", redacted.Nodes[0].Config["endpoint"])
+	assert.Equal(t, "plaintext-key", stored.Nodes[0].Config["headers"].(map[string]any)["x-api-key"])
+
+	// A redacted graph sent back with an edit keeps the stored secret
+	redacted.Nodes[0].Config["endpoint"] = "https://other.example.com"
+	restored := RestoreRedacted(redacted, stored)
+	restoredHeaders := restored.Nodes[0].Config["headers"].(map[string]any)
+	assert.Equal(t, "plaintext-key", restoredHeaders["x-api-key"])
+	assert.Equal(t, "https://other.example.com", restored.Nodes[0].Config["endpoint"])
+}
+
+func TestUnresolveGraph(t *testing.T) {
+	values := map[string]string{"kafka.password": "hunter2", "ingest-token": "abc"}
+	resolved, err := ResolveGraph(kafkaGraph(), mapResolver(values))
+	assert.NoError(t, err)
+	assert.Equal(t, kafkaGraph(), UnresolveGraph(resolved, values))
+
+	// Env references are mapped back without the values
+	assert.Equal(t, kafkaGraph(), UnresolveGraph(EnvGraph(kafkaGraph()), map[string]string{"kafka.password": "", "ingest-token": ""}))
+
+	// Short values are only replaced under sensitive keys
+	resolved.Nodes[1].Config["X-Scope"] = "abcdef"
+	unresolved := UnresolveGraph(resolved, values)
+	assert.Equal(t, "abcdef", unresolved.Nodes[1].Config["X-Scope"])
+}
+
+func TestEnvReferencesAndSensitivePlaintext(t *testing.T) {
+	config := EnvGraph(kafkaGraph()).Nodes[1].Config
+	assert.Equal(t, []string{"${env:CTRLB_SECRET_INGEST_TOKEN}"}, EnvReferences(config))
+	assert.Empty(t, SensitivePlaintext(config))
+
+	resolved, err := ResolveGraph(kafkaGraph(), mapResolver{"kafka.password": "hunter2", "ingest-token": "abc"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hunter2"}, SensitivePlaintext(resolved.Nodes[0].Config))
+	assert.Empty(t, EnvReferences(resolved.Nodes[0].Config))
+}
@@ -0,0 +1,72 @@
+// Package taps hands the samples agents send for a tap to the request
+// streaming that tap. Taps live only as long as that request, so nothing is
+// stored.
+package taps
+
+import (
+	"sync"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+)
+
+// Broker routes samples by tap ID to the tap's subscriber.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[string]subscriber
+}
+
+type subscriber struct {
+	samples chan models.TapSample
+	// buffer is how many samples may wait; the rest of the channel is kept
+	// free for Done samples
+	buffer int
+}
+
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[string]subscriber)}
+}
+
+// Subscribe opens a tap. Up to buffer samples wait for the subscriber before
+// further ones are dropped. Room for another finals samples is kept for Done
+// samples, so the subscriber learns when each agent is done however many
+// samples were dropped; a tap expecting one Done sample per agent passes the
+// number of agents. The returned function closes the tap.
+func (b *Broker) Subscribe(tapId string, buffer int, finals int) (<-chan models.TapSample, func()) {
+	samples := make(chan models.TapSample, buffer+finals)
+
+	b.mu.Lock()
+	b.subscribers[tapId] = subscriber{samples: samples, buffer: buffer}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return samples, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, tapId)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// Publish hands a sample to its tap's subscriber. It reports false if the
+// tap isn't open; a sample the subscriber has no room for is dropped. Done
+// samples can use the room kept for them.
+func (b *Broker) Publish(sample models.TapSample) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub, ok := b.subscribers[sample.TapID]
+	if !ok {
+		return false
+	}
+	// Only Publish adds samples and it holds the lock, so the length can
+	// only shrink before the send
+	if !sample.Done && len(sub.samples) >= sub.buffer {
+		return true
+	}
+	select {
+	case sub.samples <- sample:
+	default:
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package taps
+
+import (
+	"testing"
+
+	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBroker(t *testing.T) {
+	broker := NewBroker()
+	assert.False(t, broker.Publish(models.TapSample{TapID: "t1"}))
+
+	samples, closeTap := broker.Subscribe("t1", 1, 0)
+	assert.True(t, broker.Publish(models.TapSample{TapID: "t1", Count: 1}))
+	// No room left, the sample is dropped
+	assert.True(t, broker.Publish(models.TapSample{TapID: "t1", Count: 2}))
+	assert.False(t, broker.Publish(models.TapSample{TapID: "t2"}))
+	assert.Equal(t, models.TapSample{TapID: "t1", Count: 1}, <-samples)
+
+	closeTap()
+	closeTap()
+	assert.False(t, broker.Publish(models.TapSample{TapID: "t1"}))
+}
+
+func TestBroker_KeepsRoomForDoneSamples(t *testing.T) {
+	broker := NewBroker()
+	samples, closeTap := broker.Subscribe("t1", 1, 2)
+	defer closeTap()
+
+	assert.True(t, broker.Publish(models.TapSample{TapID: "t1", AgentID: 1, Count: 1}))
+	// The buffer is full, so further samples are dropped but Done ones aren't
+	assert.True(t, broker.Publish(models.TapSample{TapID: "t1", AgentID: 2, Count: 1}))
+	assert.True(t, broker.Publish(models.TapSample{TapID: "t1", AgentID: 1, Done: true}))
+	assert.True(t, broker.Publish(models.TapSample{TapID: "t1", AgentID: 2, Count: 2}))
+	assert.True(t, broker.Publish(models.TapSample{TapID: "t1", AgentID: 2, Done: true}))
+
+	assert.Equal(t, models.TapSample{TapID: "t1", AgentID: 1, Count: 1}, <-samples)
+	assert.Equal(t, models.TapSample{TapID: "t1", AgentID: 1, Done: true}, <-samples)
+	assert.Equal(t, models.TapSample{TapID: "t1", AgentID: 2, Done: true}, <-samples)
+	assert.Empty(t, samples)
+}
@@ -8,4 +8,36 @@ var ErrAgentDoesNotExists = errors.New("agent doesn't exist")
 
 var ErrPipelineDoesNotExists = errors.New("pipeline doesn't exist")
 
+var ErrTemplateDoesNotExists = errors.New("template doesn't exist")
+
+var ErrSecretDoesNotExists = errors.New("secret doesn't exist")
+
+var ErrComponentDoesNotExists = errors.New("component doesn't exist")
+
+var ErrGroupDoesNotExists = errors.New("agent group doesn't exist")
+
+var ErrJobDoesNotExists = errors.New("job doesn't exist")
+
+var ErrJobAlreadyFinished = errors.New("job has already finished")
+
+var ErrArtifactDoesNotExists = errors.New("agent artifact doesn't exist")
+
+var ErrUpgradeDoesNotExists = errors.New("agent upgrade doesn't exist")
+
+var ErrInvalidUpgrade = errors.New("invalid agent upgrade")
+
+var ErrInvalidDriftPolicy = errors.New("invalid drift policy")
+
+var ErrInvalidConfigApplyStatus = errors.New("invalid config apply status")
+
+var ErrInvalidPromotion = errors.New("invalid promotion")
+
 var ErrInvalidConfig = errors.New("agent returned 500 - invalid config")
+
+var ErrNodeDoesNotExists = errors.New("pipeline node doesn't exist")
+
+var ErrInvalidTap = errors.New("invalid tap")
+
+var ErrTapDoesNotExists = errors.New("tap isn't open")
+
+var ErrTapUnavailable = errors.New("no agent could start the tap")
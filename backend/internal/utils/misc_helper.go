@@ -2,6 +2,7 @@ package utils
 
 import (
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"strings"
@@ -47,3 +48,42 @@ func HashFromConfig(config map[string]any) string {
 	sum := sha1.Sum(bytes)
 	return hex.EncodeToString(sum[:])[:8]
 }
+
+// EffectiveConfigHash hashes a collector config the way agents hash the config
+// file they run, so desired and effective configs can be compared. Agents
+// don't write "enabled" keys, so they are left out; the rest is hashed as JSON
+// with sorted keys.
+func EffectiveConfigHash(config map[string]any) (string, error) {
+	raw, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	var normalized any
+	if err := json.Unmarshal(raw, &normalized); err != nil {
+		return "", err
+	}
+	raw, err = json.Marshal(stripEnabled(normalized))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func stripEnabled(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, val := range v {
+			if key == "enabled" {
+				delete(v, key)
+				continue
+			}
+			v[key] = stripEnabled(val)
+		}
+	case []any:
+		for i, val := range v {
+			v[i] = stripEnabled(val)
+		}
+	}
+	return value
+}
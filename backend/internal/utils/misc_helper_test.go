@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffectiveConfigHash(t *testing.T) {
+	desired := map[string]any{
+		"receivers": map[string]any{"otlp": map[string]any{"enabled": true, "endpoint": "0.0.0.0:4317"}},
+		"service": map[string]any{
+			"telemetry": map[string]any{"metrics": map[string]any{"level": "basic", "enabled": false}},
+			"pipelines": map[string]any{"logs": map[string]any{"receivers": []string{"otlp"}}},
+		},
+		"batch": map[string]any{"send_batch_size": 512},
+	}
+	// The same config as an agent reads it back from its YAML file
+	effective := map[string]any{
+		"batch":     map[string]any{"send_batch_size": float64(512)},
+		"receivers": map[string]any{"otlp": map[string]any{"endpoint": "0.0.0.0:4317"}},
+		"service": map[string]any{
+			"pipelines": map[string]any{"logs": map[string]any{"receivers": []any{"otlp"}}},
+			"telemetry": map[string]any{"metrics": map[string]any{"level": "basic"}},
+		},
+	}
+
+	desiredHash, err := EffectiveConfigHash(desired)
+	assert.NoError(t, err)
+	effectiveHash, err := EffectiveConfigHash(effective)
+	assert.NoError(t, err)
+	assert.Equal(t, desiredHash, effectiveHash)
+	assert.Len(t, desiredHash, 64)
+
+	// The caller's config is left untouched
+	assert.Contains(t, desired["receivers"].(map[string]any)["otlp"], "enabled")
+
+	effective["batch"] = map[string]any{"send_batch_size": 1024}
+	changedHash, err := EffectiveConfigHash(effective)
+	assert.NoError(t, err)
+	assert.NotEqual(t, desiredHash, changedHash)
+}
@@ -3,6 +3,8 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"net"
+	"time"
 
 	"github.com/ctrlb-hq/ctrlb-control-plane/backend/internal/models"
 	"github.com/mattn/go-sqlite3"
@@ -66,5 +68,70 @@ func ValidatePipelineRequest(request *models.CreatePipelineRequest) error {
 	if request.PipelineGraph.Edges == nil {
 		return fmt.Errorf("pipeline edges cannot be empty")
 	}
+	if err := ValidatePipelineTelemetry(request.PipelineGraph.Telemetry); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ValidatePipelineTelemetry(telemetry *models.PipelineTelemetry) error {
+	if telemetry == nil {
+		return nil
+	}
+
+	switch telemetry.Logs.Level {
+	case "", "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("invalid telemetry log level %q: must be one of debug, info, warn, error", telemetry.Logs.Level)
+	}
+	if sampling := telemetry.Logs.Sampling; sampling != nil {
+		if sampling.Tick != "" {
+			if _, err := time.ParseDuration(sampling.Tick); err != nil {
+				return fmt.Errorf("invalid telemetry log sampling tick %q: %v", sampling.Tick, err)
+			}
+		}
+		if sampling.Initial < 0 || sampling.Thereafter < 0 {
+			return fmt.Errorf("telemetry log sampling initial and thereafter cannot be negative")
+		}
+	}
+
+	switch telemetry.Metrics.Level {
+	case "", "basic", "normal", "detailed":
+	case "none":
+		return fmt.Errorf("telemetry metrics level cannot be none: the control plane polls collector metrics for agent health")
+	default:
+		return fmt.Errorf("invalid telemetry metrics level %q: must be one of basic, normal, detailed", telemetry.Metrics.Level)
+	}
+	if telemetry.Metrics.Port < 0 || telemetry.Metrics.Port > 65535 {
+		return fmt.Errorf("invalid telemetry metrics port %d", telemetry.Metrics.Port)
+	}
+	if telemetry.Metrics.Host != "" && net.ParseIP(telemetry.Metrics.Host) == nil && telemetry.Metrics.Host != "localhost" {
+		return fmt.Errorf("invalid telemetry metrics host %q: must be an IP address or localhost", telemetry.Metrics.Host)
+	}
+	if err := validateTelemetryOTLP("metrics", telemetry.Metrics.OTLPEndpoint, telemetry.Metrics.OTLPProtocol); err != nil {
+		return err
+	}
+
+	switch telemetry.Traces.Level {
+	case "", "none", "basic", "normal", "detailed":
+	default:
+		return fmt.Errorf("invalid telemetry traces level %q: must be one of none, basic, normal, detailed", telemetry.Traces.Level)
+	}
+	if err := validateTelemetryOTLP("traces", telemetry.Traces.OTLPEndpoint, telemetry.Traces.OTLPProtocol); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func validateTelemetryOTLP(signal, endpoint, protocol string) error {
+	switch protocol {
+	case "", "grpc", "http/protobuf":
+	default:
+		return fmt.Errorf("invalid telemetry %s OTLP protocol %q: must be grpc or http/protobuf", signal, protocol)
+	}
+	if protocol != "" && endpoint == "" {
+		return fmt.Errorf("telemetry %s OTLP protocol set without an endpoint", signal)
+	}
 	return nil
 }
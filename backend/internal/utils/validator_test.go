@@ -163,3 +163,36 @@ func TestIsUniqueViolation(t *testing.T) {
 		t.Error("expected false for non-unique violation")
 	}
 }
+
+func TestValidatePipelineTelemetry(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  *models.PipelineTelemetry
+		hasErr bool
+	}{
+		{name: "nil settings", input: nil, hasErr: false},
+		{
+			name: "valid settings",
+			input: &models.PipelineTelemetry{
+				Logs:    models.TelemetryLogs{Level: "warn", Sampling: &models.TelemetryLogSampling{Enabled: true, Tick: "5s"}},
+				Metrics: models.TelemetryMetrics{Level: "normal", Host: "0.0.0.0", Port: 9000, OTLPEndpoint: "otel:4317", OTLPProtocol: "grpc"},
+			},
+			hasErr: false,
+		},
+		{name: "bad log level", input: &models.PipelineTelemetry{Logs: models.TelemetryLogs{Level: "verbose"}}, hasErr: true},
+		{name: "bad sampling tick", input: &models.PipelineTelemetry{Logs: models.TelemetryLogs{Sampling: &models.TelemetryLogSampling{Tick: "soon"}}}, hasErr: true},
+		{name: "metrics disabled", input: &models.PipelineTelemetry{Metrics: models.TelemetryMetrics{Level: "none"}}, hasErr: true},
+		{name: "port out of range", input: &models.PipelineTelemetry{Metrics: models.TelemetryMetrics{Port: 70000}}, hasErr: true},
+		{name: "hostname as bind address", input: &models.PipelineTelemetry{Metrics: models.TelemetryMetrics{Host: "collector.local"}}, hasErr: true},
+		{name: "protocol without endpoint", input: &models.PipelineTelemetry{Traces: models.TelemetryTraces{OTLPProtocol: "grpc"}}, hasErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := utils.ValidatePipelineTelemetry(tt.input)
+			if (err != nil) != tt.hasErr {
+				t.Errorf("expected error: %v, got: %v", tt.hasErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package utils
+
+import (
+	"net/http"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UnmarshalYAMLRequest decodes a YAML request body. JSON is valid YAML, so
+// JSON bodies are accepted too.
+func UnmarshalYAMLRequest(r *http.Request, v any) error {
+	defer r.Body.Close()
+	return yaml.NewDecoder(r.Body).Decode(v)
+}
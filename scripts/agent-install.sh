@@ -12,7 +12,10 @@ SERVICE_FILE="/etc/systemd/system/${COLLECTOR_NAME}.service"
 # Read from env or prompt interactively
 BACKEND_URL="${BACKEND_URL:-}"
 PIPELINE_NAME="${PIPELINE_NAME:-}"
+PIPELINE_TEMPLATE="${PIPELINE_TEMPLATE:-}"
+PIPELINE_TEMPLATE_PARAMS="${PIPELINE_TEMPLATE_PARAMS:-}"
 STARTED_BY="${STARTED_BY:-}"
+AGENT_LABELS="${AGENT_LABELS:-}"
 
 # Require root
 if [ "$EUID" -ne 0 ]; then
@@ -22,12 +25,17 @@ fi
 
 # Prompt if not provided
 [ -z "$BACKEND_URL" ] && read -p "Enter backend URL: " BACKEND_URL
-[ -z "$PIPELINE_NAME" ] && read -p "Enter pipeline name: " PIPELINE_NAME
+# Labelled agents may be left for a pipeline label selector to pick up
+[ -z "$PIPELINE_NAME" ] && [ -z "$AGENT_LABELS" ] && read -p "Enter pipeline name: " PIPELINE_NAME
 [ -z "$STARTED_BY" ] && read -p "Enter started by (email): " STARTED_BY
 
 # Validate required fields
-if [[ -z "$BACKEND_URL" || -z "$PIPELINE_NAME" || -z "$STARTED_BY" ]]; then
-  echo "❌ BACKEND_URL, PIPELINE_NAME, and STARTED_BY are required."
+if [[ -z "$BACKEND_URL" || -z "$STARTED_BY" ]]; then
+  echo "❌ BACKEND_URL and STARTED_BY are required."
+  exit 1
+fi
+if [[ -z "$PIPELINE_NAME" && -z "$AGENT_LABELS" ]]; then
+  echo "❌ PIPELINE_NAME or AGENT_LABELS is required."
   exit 1
 fi
 
@@ -91,7 +99,10 @@ EOF
 cat <<EOF > "$ENV_FILE"
 BACKEND_URL=${BACKEND_URL}
 PIPELINE_NAME=${PIPELINE_NAME}
+PIPELINE_TEMPLATE=${PIPELINE_TEMPLATE}
+PIPELINE_TEMPLATE_PARAMS='${PIPELINE_TEMPLATE_PARAMS}'
 STARTED_BY=${STARTED_BY}
+AGENT_LABELS=${AGENT_LABELS}
 AGENT_CONFIG_PATH=${CONFIG_FILE}
 EOF
 